@@ -133,10 +133,26 @@ func NewArgs() *Args {
 			return nil
 		}).Bool()
 	}
+	kingpin.Flag("exit-on-rows", "exit with status 2 if the last statement returned rows").PreAction(func(*kingpin.ParseContext) error {
+		args.PVariables = append(args.PVariables, "exit_on_rows=on")
+		return nil
+	}).Bool()
+	kingpin.Flag("exit-on-empty", "exit with status 2 if the last statement returned no rows").PreAction(func(*kingpin.ParseContext) error {
+		args.PVariables = append(args.PVariables, "exit_on_empty=on")
+		return nil
+	}).Bool()
 	kingpin.Flag("quiet", "run quietly (no messages, only query output)").Short('q').PreAction(func(*kingpin.ParseContext) error {
 		args.Variables = append(args.Variables, "QUIET=on")
 		return nil
 	}).Bool()
+	kingpin.Flag("echo-queries", "display queries to standard error before they are executed").Short('e').PreAction(func(*kingpin.ParseContext) error {
+		args.Variables = append(args.Variables, "ECHO=queries")
+		return nil
+	}).Bool()
+	kingpin.Flag("echo-errors", "display failed queries to standard error").Short('b').PreAction(func(*kingpin.ParseContext) error {
+		args.Variables = append(args.Variables, "ECHO=errors")
+		return nil
+	}).Bool()
 	// add --set as a hidden alias for --variable
 	kingpin.Flag("variable", "set variable NAME to VALUE").Hidden().StringsVar(&args.Variables)
 	// add --version flag
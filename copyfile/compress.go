@@ -0,0 +1,86 @@
+package copyfile
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/rmasci/usql/text"
+)
+
+// compressionExts are the file extensions of compressed files, and their
+// compression.
+var compressionExts = map[string]string{
+	".gz":  "gzip",
+	".zst": "zstd",
+}
+
+// splitCompressionExt returns the compression of the file at path from its
+// extension, and the extension of the file without the compression
+// extension.
+func splitCompressionExt(path string) (string, string) {
+	ext := strings.ToLower(filepath.Ext(path))
+	compression, ok := compressionExts[ext]
+	if !ok {
+		return "", ext
+	}
+	return compression, strings.ToLower(filepath.Ext(strings.TrimSuffix(path, filepath.Ext(path))))
+}
+
+// parseFileCompression parses the name of a file compression.
+func parseFileCompression(s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return "", nil
+	case "gzip", "gz":
+		return "gzip", nil
+	case "zstd", "zst":
+		return "zstd", nil
+	}
+	return "", fmt.Errorf(text.InvalidOption, "compression")
+}
+
+// newDecompressor wraps r, the contents of a compressed file, decompressing
+// it as it is read.
+func newDecompressor(r io.Reader, compression string) (io.ReadCloser, error) {
+	switch compression {
+	case "gzip":
+		return gzip.NewReader(r)
+	case "zstd":
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	}
+	return nil, errors.New("unknown compression " + compression)
+}
+
+// newCompressor wraps w, compressing written data. Closing the returned
+// writer flushes the compressed data, but does not close w.
+func newCompressor(w io.Writer, compression string) (io.WriteCloser, error) {
+	switch compression {
+	case "gzip":
+		return gzip.NewWriter(w), nil
+	case "zstd":
+		return zstd.NewWriter(w)
+	}
+	return nil, errors.New("unknown compression " + compression)
+}
+
+// countingReader counts the bytes read from a reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read satisfies the io.Reader interface.
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,1059 @@
+// Package copyfile provides reading and writing of data files for usql's
+// \copy command.
+package copyfile
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rmasci/usql/text"
+)
+
+// Copy is a parsed \copy to or from a file, or between connections. The
+// forms are:
+//
+//	(QUERY) [ON @CONN] TO FILE [WITH (OPTIONS)]
+//	TABLE[(COL, ...)] [ON @CONN] TO FILE [WITH (OPTIONS)]
+//	TABLE[(COL, ...)] [ON @CONN] FROM FILE [WITH (OPTIONS)]
+//	(QUERY) [ON @CONN] TO TABLE[(COL, ...)] ON @DEST [WITH (OPTIONS)]
+//	TABLE[(COL, ...)] [ON @CONN] TO TABLE[(COL, ...)] ON @DEST [WITH (OPTIONS)]
+type Copy struct {
+	// Query is the query to copy to the file.
+	Query string
+	// Table is the table to copy to or from.
+	Table string
+	// Columns are the table columns.
+	Columns []string
+	// From is true when copying from the file to the table.
+	From bool
+	// Path is the file path.
+	Path string
+	// Conn is the name of the connection of the query or table, or empty for
+	// the current connection.
+	Conn string
+	// Dest is the name of the connection to copy the rows to, in which case
+	// Path is the destination table.
+	Dest string
+	// Params are the copy options.
+	Params map[string]string
+}
+
+// Parse parses a \copy to or from a file. Returns false when s is not one of
+// the file forms.
+func Parse(s string) (*Copy, bool, error) {
+	r, i := []rune(strings.TrimSpace(s)), 0
+	c := &Copy{
+		Params: make(map[string]string),
+	}
+	switch {
+	case len(r) == 0:
+		return nil, false, nil
+	case r[0] == '(':
+		end, err := matchParen(r, 0)
+		if err != nil {
+			return nil, false, nil
+		}
+		c.Query, i = strings.TrimSpace(string(r[1:end])), end+1
+	default:
+		start := i
+		for ; i < len(r) && !unicode.IsSpace(r[i]) && r[i] != '('; i++ {
+		}
+		c.Table = string(r[start:i])
+		if i = skipSpace(r, i); i < len(r) && r[i] == '(' {
+			end, err := matchParen(r, i)
+			if err != nil {
+				return nil, false, nil
+			}
+			for _, col := range strings.Split(string(r[i+1:end]), ",") {
+				if col = strings.TrimSpace(col); col != "" {
+					c.Columns = append(c.Columns, col)
+				}
+			}
+			i = end + 1
+		}
+	}
+	// connection
+	var err error
+	if word, j := readWord(r, skipSpace(r, i)); strings.EqualFold(word, "on") {
+		if c.Conn, i, err = readConn(r, skipSpace(r, j)); err != nil {
+			return nil, false, err
+		}
+	}
+	// direction
+	dir, i := readWord(r, skipSpace(r, i))
+	switch strings.ToLower(dir) {
+	case "to":
+	case "from":
+		c.From = true
+	default:
+		return nil, false, nil
+	}
+	if c.From && c.Query != "" {
+		return nil, false, text.ErrCopyFromQuery
+	}
+	// table on the destination connection
+	if table, j := readTable(r, skipSpace(r, i)); table != "" {
+		if word, k := readWord(r, skipSpace(r, j)); strings.EqualFold(word, "on") {
+			if c.From {
+				return nil, false, text.ErrCopyFromConnection
+			}
+			if c.Dest, i, err = readConn(r, skipSpace(r, k)); err != nil {
+				return nil, false, err
+			}
+			c.Path = table
+		}
+	}
+	// file
+	if c.Dest == "" {
+		if c.Path, i, err = readPath(r, skipSpace(r, i)); err != nil {
+			return nil, false, err
+		}
+		if c.Path == "" {
+			return nil, false, text.ErrMissingRequiredArgument
+		}
+	}
+	// options
+	word, j := readWord(r, skipSpace(r, i))
+	if strings.EqualFold(word, "with") {
+		i = j
+	}
+	if i = skipSpace(r, i); i < len(r) {
+		if r[i] != '(' {
+			return nil, false, fmt.Errorf(text.InvalidOption, string(r[i:]))
+		}
+		end, err := matchParen(r, i)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, opt := range splitOptions(r[i+1 : end]) {
+			if opt = strings.TrimSpace(opt); opt == "" {
+				continue
+			}
+			k, v := opt, "true"
+			if n := strings.IndexFunc(opt, func(r rune) bool { return unicode.IsSpace(r) || r == '=' }); n != -1 {
+				k, v = opt[:n], strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(opt[n:]), "="))
+			}
+			c.Params[strings.ToLower(k)] = unquote(v)
+		}
+		if i = skipSpace(r, end+1); i < len(r) {
+			return nil, false, fmt.Errorf(text.InvalidOption, string(r[i:]))
+		}
+	}
+	return c, true, nil
+}
+
+// SelectQuery returns the query to copy to the file.
+func (c *Copy) SelectQuery() string {
+	if c.Query != "" {
+		return c.Query
+	}
+	cols := "*"
+	if len(c.Columns) != 0 {
+		cols = strings.Join(c.Columns, ", ")
+	}
+	return "SELECT " + cols + " FROM " + c.Table
+}
+
+// InsertTable returns the table and column list to copy the file rows to,
+// using the file's columns when no columns were specified.
+func (c *Copy) InsertTable(rows *sql.Rows) (string, error) {
+	cols := c.Columns
+	if len(cols) == 0 {
+		var err error
+		if cols, err = rows.Columns(); err != nil {
+			return "", err
+		}
+	}
+	if len(cols) == 0 {
+		return c.Table, nil
+	}
+	return c.Table + "(" + strings.Join(cols, ", ") + ")", nil
+}
+
+// Execer executes queries.
+type Execer interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+}
+
+// InsertQuery returns the query inserting rows rows into the table columns,
+// using the insert verb (such as INSERT INTO or INSERT OR IGNORE INTO).
+func InsertQuery(verb, table string, columns []string, rows int, placeholder func(int) string) string {
+	values := make([]string, rows)
+	for i := range values {
+		placeholders := make([]string, len(columns))
+		for j := range placeholders {
+			placeholders[j] = placeholder(i*len(columns) + j + 1)
+		}
+		values[i] = "(" + strings.Join(placeholders, ", ") + ")"
+	}
+	return verb + " " + table + "(" + strings.Join(columns, ", ") + ") VALUES " + strings.Join(values, ", ")
+}
+
+// txBeginner begins transactions.
+type txBeginner interface {
+	BeginTx(context.Context, *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Import inserts the rows of the file into the table, opts.RowsPerInsert rows
+// at a time, using the query returned by insert for the inserted columns and
+// number of rows. When opts.BatchSize is set, the rows are inserted in
+// transactions of opts.BatchSize rows, each committed before the next begins,
+// and otherwise each insert is committed on its own. When opts.OnError is
+// "continue", rows that cannot be read or inserted are skipped, and written
+// with the error to the error log (when set). Returns the number of imported
+// (and committed) rows and rejected rows. The import is aborted once more
+// than opts.MaxErrors rows are rejected.
+func (c *Copy) Import(ctx context.Context, db Execer, insert func([]string, int) (string, error), opts Options) (int64, int64, error) {
+	if err := c.checkColumns(ctx, db, opts.ConflictColumns); err != nil {
+		return 0, 0, err
+	}
+	var b txBeginner
+	if opts.BatchSize != 0 {
+		var ok bool
+		if b, ok = db.(txBeginner); !ok {
+			return 0, 0, text.ErrCopyBatchInTransaction
+		}
+	}
+	var r driver.Rows
+	var err error
+	switch opts.Format {
+	case "csv":
+		r, err = newCSVReader(c.Path, opts)
+	case "parquet":
+		r, err = newParquetReader(ctx, c.Path, opts)
+	default:
+		return 0, 0, text.ErrJSONLImport
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	defer r.Close()
+	// rejected rows are logged with all file columns, including any not
+	// mapped to a table column
+	src, vals := r, func(dest []driver.Value) []driver.Value { return dest }
+	if opts.Filter != nil {
+		f, err := newFilteredRows(r, opts.Filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		r = f
+	}
+	if len(opts.Map) != 0 {
+		m, err := newMappedRows(r, opts.Map)
+		if err != nil {
+			return 0, 0, err
+		}
+		r, vals = m, func([]driver.Value) []driver.Value { return m.vals }
+	}
+	if len(opts.Columns) != 0 {
+		r = newTypedRows(r, opts.Columns)
+	}
+	cols := c.Columns
+	if len(cols) == 0 {
+		cols = r.Columns()
+	}
+	size := opts.RowsPerInsert
+	if size < 1 {
+		size = 1
+	}
+	// queries are the insert queries, by number of rows
+	queries := make(map[int]string)
+	query := func(n int) (string, error) {
+		if q, ok := queries[n]; ok {
+			return q, nil
+		}
+		q, err := insert(cols, n)
+		if err != nil {
+			return "", err
+		}
+		queries[n] = q
+		return q, nil
+	}
+	if _, err := query(size); err != nil {
+		return 0, 0, err
+	}
+	// open error log
+	var log *csv.Writer
+	if opts.ErrLog != "" {
+		f, err := os.OpenFile(opts.ErrLog, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return 0, 0, err
+		}
+		defer f.Close()
+		log = csv.NewWriter(f)
+		defer log.Flush()
+		if err := log.Write(append([]string{"error"}, src.Columns()...)); err != nil {
+			return 0, 0, err
+		}
+	}
+	dest := make([]driver.Value, len(r.Columns()))
+	args := make([]interface{}, 0, len(dest)*size)
+	var imported, rejected int64
+	reject := func(record []string, err error) error {
+		if opts.OnError != "continue" {
+			return err
+		}
+		rejected++
+		if log != nil {
+			if err := log.Write(append([]string{err.Error()}, record...)); err != nil {
+				return err
+			}
+		}
+		if opts.MaxErrors != 0 && rejected > int64(opts.MaxErrors) {
+			return fmt.Errorf(text.CopyMaxErrors, rejected)
+		}
+		return nil
+	}
+	// the rows of the current transaction, and of those the rows inserted,
+	// are only imported once committed
+	var tx *sql.Tx
+	var batched int
+	var pending int64
+	defer func() {
+		if tx != nil {
+			tx.Rollback()
+		}
+	}()
+	commit := func() error {
+		if tx == nil {
+			return nil
+		}
+		err := tx.Commit()
+		if tx = nil; err != nil {
+			return err
+		}
+		imported, batched, pending = imported+pending, 0, 0
+		return nil
+	}
+	// flush inserts the rows of args
+	flush := func(n int) error {
+		var ex Execer = db
+		if b != nil {
+			if tx == nil {
+				var err error
+				if tx, err = b.BeginTx(ctx, nil); err != nil {
+					return err
+				}
+			}
+			ex = tx
+		}
+		q, err := query(n)
+		if err != nil {
+			return err
+		}
+		res, err := ex.ExecContext(ctx, q, args...)
+		args = args[:0]
+		if err != nil {
+			return err
+		}
+		// rows ignored on conflict are not counted
+		count := int64(n)
+		if m, err := res.RowsAffected(); err == nil && m < count {
+			count = m
+		}
+		if b == nil {
+			imported += count
+			return nil
+		}
+		if batched, pending = batched+n, pending+count; batched >= opts.BatchSize {
+			return commit()
+		}
+		return nil
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return imported, rejected, err
+		}
+		var perr *csv.ParseError
+		switch err := r.Next(dest); {
+		case err == io.EOF:
+			if n := len(args) / len(dest); n != 0 {
+				if err := flush(n); err != nil {
+					return imported, rejected, err
+				}
+			}
+			return imported, rejected, commit()
+		case errors.As(err, &perr):
+			if err := reject(src.(*csvReader).last, err); err != nil {
+				return imported, rejected, err
+			}
+			continue
+		case err != nil:
+			return imported, rejected, err
+		}
+		for _, v := range dest {
+			args = append(args, v)
+		}
+		// insert once the rows fill an insert, or complete the batch
+		if n := len(args) / len(dest); n == size || b != nil && batched+n == opts.BatchSize {
+			if err := flush(n); err != nil {
+				values := vals(dest)
+				record := make([]string, len(values))
+				for i, v := range values {
+					record[i] = textValue(v, opts.Null, opts.TimeFormat)
+				}
+				if err := reject(record, err); err != nil {
+					return imported, rejected, err
+				}
+			}
+		}
+	}
+}
+
+// checkColumns checks that the columns exist in the table.
+func (c *Copy) checkColumns(ctx context.Context, db Execer, columns []string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	rows, err := db.QueryContext(ctx, "SELECT * FROM "+c.Table+" WHERE 1=0")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	for _, col := range columns {
+		if !containsFold(cols, col) {
+			return fmt.Errorf(text.CopyUnknownColumn, col, c.Table)
+		}
+	}
+	return nil
+}
+
+// containsFold returns true when v contains s, ignoring case.
+func containsFold(v []string, s string) bool {
+	for _, z := range v {
+		if strings.EqualFold(z, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Options are file copy options.
+type Options struct {
+	// Format is the file format.
+	Format string
+	// Header toggles a CSV header row.
+	Header bool
+	// Delimiter is the CSV field delimiter.
+	Delimiter rune
+	// Null is the CSV string representation of a null value.
+	Null string
+	// Compression is the Parquet compression codec.
+	Compression string
+	// FileCompression is the compression of CSV files, either "gzip",
+	// "zstd", or empty for none.
+	FileCompression string
+	// RowGroupSize is the number of rows per Parquet row group.
+	RowGroupSize int
+	// TimeFormat is the Go time layout used to write time values to text
+	// formats.
+	TimeFormat string
+	// OnError is the action when a row cannot be imported, either "stop" or
+	// "continue".
+	OnError string
+	// OnConflict is the action when an imported row conflicts with an
+	// existing row, either "update", "ignore", or empty for none.
+	OnConflict string
+	// ConflictColumns are the columns an updated row conflicts on.
+	ConflictColumns []string
+	// ErrLog is the path of the CSV file rejected rows are written to.
+	ErrLog string
+	// MaxErrors is the number of rejected rows after which the import is
+	// aborted, or 0 for no limit.
+	MaxErrors int
+	// Map maps file columns (by header name) to table columns. File columns
+	// not in the map are skipped.
+	Map []ColumnMap
+	// Filter is the filter rows are imported by, or nil to import all rows.
+	Filter *Filter
+	// BatchSize is the number of imported rows per transaction, or 0 to
+	// commit each insert on its own.
+	BatchSize int
+	// RowsPerInsert is the number of rows inserted by each INSERT statement.
+	RowsPerInsert int
+	// Create toggles creating the table, with the column types inferred from
+	// the file (see Infer).
+	Create bool
+	// SampleSize is the number of rows the column types are inferred from,
+	// or 0 for all rows.
+	SampleSize int
+	// Columns are the columns of the created table, whose integer, float, and
+	// boolean file values are converted to the column's type when imported.
+	Columns []Column
+	// ProgressInterval is the interval at which the progress is reported, or
+	// 0 to not report progress.
+	ProgressInterval time.Duration
+	// Progress is the progress the copied rows are counted by.
+	Progress *Progress
+	// FetchSize is the number of rows fetched at a time from a server-side
+	// cursor when copying a query to the file, or 0 to not use a cursor.
+	FetchSize int
+}
+
+// DefaultFetchSize is the default number of rows fetched at a time from a
+// server-side cursor.
+const DefaultFetchSize = 10000
+
+// NewOptions creates file copy options for the path from the params,
+// using pvars for unspecified values.
+func NewOptions(path string, params, pvars map[string]string) (Options, error) {
+	opts := Options{
+		Format:        strings.ToLower(params["format"]),
+		Header:        true,
+		Delimiter:     ',',
+		Compression:   pvars["parquet_compression"],
+		OnError:       "stop",
+		RowsPerInsert: 1,
+		SampleSize:    DefaultSampleSize,
+		FetchSize:     DefaultFetchSize,
+	}
+	var err error
+	if opts.ProgressInterval, err = parseProgressInterval(params); err != nil {
+		return Options{}, err
+	}
+	fileCompression, ext := splitCompressionExt(path)
+	if opts.Format == "" {
+		switch ext {
+		case ".csv":
+			opts.Format = "csv"
+		case ".tsv":
+			opts.Format, opts.Delimiter = "csv", '\t'
+		case ".parquet", ".pq":
+			opts.Format = "parquet"
+		case ".jsonl", ".ndjson":
+			opts.Format = "jsonl"
+		default:
+			return Options{}, text.ErrUnknownFileType
+		}
+	}
+	switch opts.Format {
+	case "ndjson":
+		opts.Format = "jsonl"
+	case "csv", "parquet", "jsonl":
+	default:
+		return Options{}, text.ErrUnknownFileType
+	}
+	if s, ok := params["header"]; ok {
+		if opts.Header, err = parseBool(s); err != nil {
+			return Options{}, fmt.Errorf(text.InvalidOption, "header")
+		}
+	}
+	if s, ok := params["delimiter"]; ok {
+		if r := []rune(s); len(r) == 1 {
+			opts.Delimiter = r[0]
+		} else {
+			return Options{}, fmt.Errorf(text.InvalidOption, "delimiter")
+		}
+	}
+	if s, ok := params["null"]; ok {
+		opts.Null = s
+	}
+	if s, ok := params["compression"]; ok {
+		if opts.Format == "parquet" {
+			opts.Compression = s
+		} else {
+			fileCompression = s
+		}
+	}
+	switch {
+	case opts.Format == "csv", opts.Format == "jsonl":
+		if opts.FileCompression, err = parseFileCompression(fileCompression); err != nil {
+			return Options{}, err
+		}
+	case fileCompression != "":
+		return Options{}, text.ErrCompressedParquet
+	}
+	size := pvars["parquet_row_group_size"]
+	if s, ok := params["row_group_size"]; ok {
+		size = s
+	}
+	if size != "" {
+		if opts.RowGroupSize, err = strconv.Atoi(size); err != nil || opts.RowGroupSize < 1 {
+			return Options{}, fmt.Errorf(text.InvalidOption, "row_group_size")
+		}
+	}
+	if s, ok := params["on_error"]; ok {
+		switch opts.OnError = strings.ToLower(s); opts.OnError {
+		case "stop", "continue":
+		default:
+			return Options{}, fmt.Errorf(text.InvalidOption, "on_error")
+		}
+	}
+	if s, ok := params["on_conflict"]; ok {
+		if opts.OnConflict, opts.ConflictColumns, err = parseConflict(s); err != nil {
+			return Options{}, err
+		}
+	}
+	if s, ok := params["errlog"]; ok {
+		opts.ErrLog = s
+	}
+	if s, ok := params["max_errors"]; ok {
+		if opts.MaxErrors, err = strconv.Atoi(s); err != nil || opts.MaxErrors < 0 {
+			return Options{}, fmt.Errorf(text.InvalidOption, "max_errors")
+		}
+	}
+	if s, ok := params["map"]; ok {
+		if opts.Map, err = parseMap(s); err != nil {
+			return Options{}, err
+		}
+	}
+	if s, ok := params["filter"]; ok {
+		if opts.Filter, err = ParseFilter(s); err != nil {
+			return Options{}, err
+		}
+	}
+	if s, ok := params["fetch_size"]; ok {
+		if opts.FetchSize, err = strconv.Atoi(s); err != nil || opts.FetchSize < 0 {
+			return Options{}, fmt.Errorf(text.InvalidOption, "fetch_size")
+		}
+	}
+	for _, name := range []string{"batch", "batch_size"} {
+		if s, ok := params[name]; ok {
+			if opts.BatchSize, err = strconv.Atoi(s); err != nil || opts.BatchSize < 0 {
+				return Options{}, fmt.Errorf(text.InvalidOption, name)
+			}
+		}
+	}
+	if s, ok := params["rows_per_insert"]; ok {
+		if opts.RowsPerInsert, err = strconv.Atoi(s); err != nil || opts.RowsPerInsert < 1 {
+			return Options{}, fmt.Errorf(text.InvalidOption, "rows_per_insert")
+		}
+	}
+	if s, ok := params["create"]; ok {
+		if opts.Create, err = parseBool(s); err != nil {
+			return Options{}, fmt.Errorf(text.InvalidOption, "create")
+		}
+	}
+	if s, ok := params["sample_size"]; ok {
+		if opts.SampleSize, err = strconv.Atoi(s); err != nil || opts.SampleSize < 0 {
+			return Options{}, fmt.Errorf(text.InvalidOption, "sample_size")
+		}
+	}
+	// rows are rejected one at a time, outside of a transaction
+	if opts.OnError == "continue" && (opts.BatchSize != 0 || opts.RowsPerInsert != 1) {
+		return Options{}, text.ErrCopyBatchOnError
+	}
+	return opts, nil
+}
+
+// Write writes rows to the file at path, returning the number of rows
+// written.
+func Write(ctx context.Context, path string, rows *sql.Rows, opts Options) (int64, error) {
+	return WriteBatches(ctx, path, func() (*sql.Rows, error) {
+		r := rows
+		rows = nil
+		return r, nil
+	}, opts)
+}
+
+// WriteBatches writes the batches of rows returned by next to the file at
+// path, such as the rows fetched from a server-side cursor, returning the
+// number of rows written. The first batch's columns are the file's columns.
+// Each batch is closed after being written, and batches are written until
+// next returns nil rows or a batch has no rows.
+func WriteBatches(ctx context.Context, path string, next func() (*sql.Rows, error), opts Options) (int64, error) {
+	rows, err := next()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if rows != nil {
+			rows.Close()
+		}
+	}()
+	cols, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	typs, err := rows.ColumnTypes()
+	if err != nil {
+		return 0, err
+	}
+	f, err := newFileWriter(path, opts.FileCompression)
+	if err != nil {
+		return 0, err
+	}
+	var w writer
+	switch opts.Format {
+	case "csv":
+		w, err = newCSVWriter(f, cols, opts)
+	case "parquet":
+		w, err = newParquetWriter(f, cols, typs, opts)
+	case "jsonl":
+		w, err = newJSONLWriter(f, cols, typs, opts)
+	}
+	if err != nil {
+		f.Close()
+		return 0, err
+	}
+	vals := make([]interface{}, len(cols))
+	for i := range vals {
+		vals[i] = new(interface{})
+	}
+	row := make([]interface{}, len(cols))
+	var n int64
+	for rows != nil {
+		var batch int64
+		for rows.Next() {
+			if err := ctx.Err(); err != nil {
+				f.Close()
+				return n, err
+			}
+			if err := rows.Scan(vals...); err != nil {
+				f.Close()
+				return n, err
+			}
+			for i, v := range vals {
+				row[i] = *v.(*interface{})
+			}
+			if err := w.Write(row); err != nil {
+				f.Close()
+				return n, err
+			}
+			opts.Progress.Row(-1)
+			n, batch = n+1, batch+1
+		}
+		if err := rows.Err(); err != nil {
+			f.Close()
+			return n, err
+		}
+		rows.Close()
+		if rows = nil; batch == 0 {
+			break
+		}
+		if rows, err = next(); err != nil {
+			f.Close()
+			return n, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		f.Close()
+		return n, err
+	}
+	return n, f.Close()
+}
+
+// Open opens the file at path, returning its rows.
+func Open(ctx context.Context, path string, opts Options) (*sql.Rows, func(), error) {
+	var r driver.Rows
+	var err error
+	switch opts.Format {
+	case "csv":
+		r, err = newCSVReader(path, opts)
+	case "parquet":
+		r, err = newParquetReader(ctx, path, opts)
+	default:
+		return nil, nil, text.ErrJSONLImport
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Filter != nil {
+		f, err := newFilteredRows(r, opts.Filter)
+		if err != nil {
+			r.Close()
+			return nil, nil, err
+		}
+		r = f
+	}
+	if len(opts.Map) != 0 {
+		m, err := newMappedRows(r, opts.Map)
+		if err != nil {
+			r.Close()
+			return nil, nil, err
+		}
+		r = m
+	}
+	if len(opts.Columns) != 0 {
+		r = newTypedRows(r, opts.Columns)
+	}
+	db := sql.OpenDB(connector{rows: r})
+	rows, err := db.QueryContext(ctx, path)
+	if err != nil {
+		r.Close()
+		db.Close()
+		return nil, nil, err
+	}
+	return rows, func() { rows.Close(); db.Close() }, nil
+}
+
+// writer is the interface for file writers.
+type writer interface {
+	Write([]interface{}) error
+	Close() error
+}
+
+// fileWriter is a file, compressed when written through a compressor.
+type fileWriter struct {
+	io.Writer
+	f *os.File
+	c io.WriteCloser
+}
+
+// newFileWriter creates the file at path, compressing written data with the
+// compression.
+func newFileWriter(path, compression string) (*fileWriter, error) {
+	f, err := os.OpenFile(path, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if compression == "" {
+		return &fileWriter{Writer: f, f: f}, nil
+	}
+	c, err := newCompressor(f, compression)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &fileWriter{Writer: c, f: f, c: c}, nil
+}
+
+// Close flushes the compressed data and closes the file.
+func (w *fileWriter) Close() error {
+	var err error
+	if w.c != nil {
+		err = w.c.Close()
+	}
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// connector is a database/sql connector for a set of file rows, allowing
+// file rows to be used with the drivers' copy implementations.
+type connector struct {
+	rows driver.Rows
+}
+
+// Connect satisfies the driver.Connector interface.
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return conn(c), nil
+}
+
+// Driver satisfies the driver.Connector interface.
+func (c connector) Driver() driver.Driver {
+	return fileDriver{}
+}
+
+// fileDriver is a placeholder driver for the connector.
+type fileDriver struct{}
+
+// Open satisfies the driver.Driver interface.
+func (fileDriver) Open(string) (driver.Conn, error) {
+	return nil, text.ErrNotSupported
+}
+
+// conn is a connection for a set of file rows.
+type conn struct {
+	rows driver.Rows
+}
+
+// Prepare satisfies the driver.Conn interface.
+func (c conn) Prepare(string) (driver.Stmt, error) {
+	return nil, text.ErrNotSupported
+}
+
+// Close satisfies the driver.Conn interface.
+func (c conn) Close() error {
+	return nil
+}
+
+// Begin satisfies the driver.Conn interface.
+func (c conn) Begin() (driver.Tx, error) {
+	return nil, text.ErrNotSupported
+}
+
+// QueryContext satisfies the driver.QueryerContext interface, returning the
+// file rows.
+func (c conn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return c.rows, nil
+}
+
+// matchParen returns the position of the parenthesis matching the one at i.
+func matchParen(r []rune, i int) (int, error) {
+	var depth int
+	var quote rune
+	for ; i < len(r); i++ {
+		switch c := r[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth--; depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, text.ErrUnterminatedParenthesis
+}
+
+// splitOptions splits r on commas not within quotes.
+func splitOptions(r []rune) []string {
+	var v []string
+	var quote rune
+	start, depth := 0, 0
+	for i, c := range r {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')' && depth != 0:
+			depth--
+		case c == ',' && depth == 0:
+			v, start = append(v, string(r[start:i])), i+1
+		}
+	}
+	return append(v, string(r[start:]))
+}
+
+// skipSpace returns the position of the next non-space rune.
+func skipSpace(r []rune, i int) int {
+	for ; i < len(r) && unicode.IsSpace(r[i]); i++ {
+	}
+	return i
+}
+
+// readWord reads a word starting at i.
+func readWord(r []rune, i int) (string, int) {
+	start := i
+	for ; i < len(r) && !unicode.IsSpace(r[i]) && r[i] != '('; i++ {
+	}
+	return string(r[start:i]), i
+}
+
+// readTable reads a table name and optional column list starting at i,
+// returning an empty string when the column list is not terminated.
+func readTable(r []rune, i int) (string, int) {
+	table, i := readWord(r, i)
+	j := skipSpace(r, i)
+	if j >= len(r) || r[j] != '(' {
+		return table, i
+	}
+	end, err := matchParen(r, j)
+	if err != nil {
+		return "", i
+	}
+	var cols []string
+	for _, col := range strings.Split(string(r[j+1:end]), ",") {
+		if col = strings.TrimSpace(col); col != "" {
+			cols = append(cols, col)
+		}
+	}
+	return table + "(" + strings.Join(cols, ", ") + ")", end + 1
+}
+
+// readConn reads a connection name (@NAME) starting at i.
+func readConn(r []rune, i int) (string, int, error) {
+	name, i := readWord(r, i)
+	if len(name) < 2 || name[0] != '@' {
+		return "", 0, text.ErrInvalidConnectionName
+	}
+	return name[1:], i, nil
+}
+
+// readPath reads a quoted or unquoted path starting at i.
+func readPath(r []rune, i int) (string, int, error) {
+	if i < len(r) && (r[i] == '\'' || r[i] == '"') {
+		quote, start := r[i], i+1
+		for i = start; i < len(r) && r[i] != quote; i++ {
+		}
+		if i >= len(r) {
+			return "", 0, text.ErrUnterminatedQuotedString
+		}
+		return string(r[start:i]), i + 1, nil
+	}
+	start := i
+	for ; i < len(r) && !unicode.IsSpace(r[i]); i++ {
+	}
+	return string(r[start:i]), i, nil
+}
+
+// unquote removes surrounding single or double quotes from s, where a
+// doubled quote within the quotes is a quote.
+func unquote(s string) string {
+	if len(s) > 1 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		q := s[:1]
+		return strings.ReplaceAll(s[1:len(s)-1], q+q, q)
+	}
+	return s
+}
+
+// parseProgressInterval parses the progress_interval and quiet params,
+// returning the interval at which the progress is reported, or 0 when quiet.
+func parseProgressInterval(params map[string]string) (time.Duration, error) {
+	d := DefaultProgressInterval
+	if s, ok := params["progress_interval"]; ok {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil || d <= 0 {
+			return 0, fmt.Errorf(text.InvalidOption, "progress_interval")
+		}
+	}
+	if s, ok := params["quiet"]; ok {
+		quiet, err := parseBool(s)
+		if err != nil {
+			return 0, fmt.Errorf(text.InvalidOption, "quiet")
+		}
+		if quiet {
+			d = 0
+		}
+	}
+	return d, nil
+}
+
+// parseBool parses a boolean option.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "1", "t", "true", "on", "yes":
+		return true, nil
+	case "0", "f", "false", "off", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf(text.InvalidOption, s)
+}
+
+// parseConflict parses the on_conflict option, either "ignore", or
+// "update(col, ...)" with the columns rows conflict on.
+func parseConflict(s string) (string, []string, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "ignore") {
+		return "ignore", nil, nil
+	}
+	if i := strings.IndexByte(s, '('); i != -1 && strings.EqualFold(strings.TrimSpace(s[:i]), "update") && strings.HasSuffix(s, ")") {
+		var cols []string
+		for _, col := range strings.Split(s[i+1:len(s)-1], ",") {
+			if col = strings.TrimSpace(col); col != "" {
+				cols = append(cols, col)
+			}
+		}
+		if len(cols) != 0 {
+			return "update", cols, nil
+		}
+	}
+	return "", nil, fmt.Errorf(text.InvalidOption, "on_conflict")
+}
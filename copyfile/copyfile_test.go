@@ -0,0 +1,531 @@
+package copyfile
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/rmasci/usql/text"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp *Copy
+		ok  bool
+		err error
+	}{
+		{``, nil, false, nil},
+		{`csvq:. sq:test.db 'select * from authors' authors`, nil, false, nil},
+		{`(select 1) to 'a.csv'`, &Copy{Query: "select 1", Path: "a.csv", Params: map[string]string{}}, true, nil},
+		{`(select ')') TO a.parquet`, &Copy{Query: "select ')'", Path: "a.parquet", Params: map[string]string{}}, true, nil},
+		{`t from 'a b.csv'`, &Copy{Table: "t", Path: "a b.csv", From: true, Params: map[string]string{}}, true, nil},
+		{`t(a, b) from a.csv with (header false, delimiter '|')`, &Copy{Table: "t", Columns: []string{"a", "b"}, Path: "a.csv", From: true, Params: map[string]string{"header": "false", "delimiter": "|"}}, true, nil},
+		{`t (a) to a.parquet (compression=zstd, ROW_GROUP_SIZE 5)`, &Copy{Table: "t", Columns: []string{"a"}, Path: "a.parquet", Params: map[string]string{"compression": "zstd", "row_group_size": "5"}}, true, nil},
+		{`t(a, c) from a.csv with (map 'x=a, z = c', header)`, &Copy{Table: "t", Columns: []string{"a", "c"}, Path: "a.csv", From: true, Params: map[string]string{"map": "x=a, z = c", "header": "true"}}, true, nil},
+		{`t from a.csv with (filter 'a = ''x, y''')`, &Copy{Table: "t", Path: "a.csv", From: true, Params: map[string]string{"filter": "a = 'x, y'"}}, true, nil},
+		{`(select 1) on @src to p on @dst with (batch_size 10)`, &Copy{Query: "select 1", Path: "p", Conn: "src", Dest: "dst", Params: map[string]string{"batch_size": "10"}}, true, nil},
+		{`t on @src to p (a,b ) ON @dst`, &Copy{Table: "t", Path: "p(a, b)", Conn: "src", Dest: "dst", Params: map[string]string{}}, true, nil},
+		{`t from a.csv with (on_conflict=update(a, b), header)`, &Copy{Table: "t", Path: "a.csv", From: true, Params: map[string]string{"on_conflict": "update(a, b)", "header": "true"}}, true, nil},
+		{`t from a.csv on @dst`, nil, false, text.ErrCopyFromConnection},
+		{`t on src to a.csv`, nil, false, text.ErrInvalidConnectionName},
+		{`(select 1) from a.csv`, nil, false, text.ErrCopyFromQuery},
+		{`t to 'a.csv`, nil, false, text.ErrUnterminatedQuotedString},
+		{`t to`, nil, false, text.ErrMissingRequiredArgument},
+		{`t to a.csv with (header`, nil, false, text.ErrUnterminatedParenthesis},
+	}
+	for i, test := range tests {
+		c, ok, err := Parse(test.s)
+		if err != test.err {
+			t.Fatalf("test %d expected error %v, got: %v", i, test.err, err)
+		}
+		if ok != test.ok {
+			t.Errorf("test %d expected ok=%t, got: %t", i, test.ok, ok)
+		}
+		if !reflect.DeepEqual(c, test.exp) {
+			t.Errorf("test %d expected %#v, got: %#v", i, test.exp, c)
+		}
+	}
+}
+
+func TestParseConflict(t *testing.T) {
+	tests := []struct {
+		s    string
+		exp  string
+		cols []string
+	}{
+		{"ignore", "ignore", nil},
+		{"IGNORE", "ignore", nil},
+		{"update(id)", "update", []string{"id"}},
+		{"update (a, b )", "update", []string{"a", "b"}},
+		{"update", "", nil},
+		{"update()", "", nil},
+		{"replace", "", nil},
+	}
+	for i, test := range tests {
+		s, cols, err := parseConflict(test.s)
+		switch {
+		case test.exp == "" && err == nil:
+			t.Errorf("test %d expected error, got: %q", i, s)
+		case test.exp != "" && err != nil:
+			t.Errorf("test %d expected no error, got: %v", i, err)
+		case s != test.exp || !reflect.DeepEqual(cols, test.cols):
+			t.Errorf("test %d expected %q %v, got: %q %v", i, test.exp, test.cols, s, cols)
+		}
+	}
+}
+
+func TestOpenMap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.csv")
+	if err := os.WriteFile(path, []byte("x,y,z\n1,2,3\n4,5,6\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts, err := NewOptions(path, map[string]string{"map": "z=c, X=a"}, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	rows, closeRows, err := Open(context.Background(), path, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer closeRows()
+	cols, err := rows.Columns()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if exp := []string{"c", "a"}; !reflect.DeepEqual(cols, exp) {
+		t.Errorf("expected columns %v, got: %v", exp, cols)
+	}
+	var res [][]string
+	for rows.Next() {
+		var c, a string
+		if err := rows.Scan(&c, &a); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		res = append(res, []string{c, a})
+	}
+	if exp := [][]string{{"3", "1"}, {"6", "4"}}; !reflect.DeepEqual(res, exp) {
+		t.Errorf("expected %v, got: %v", exp, res)
+	}
+	// missing file column
+	if opts.Map, err = parseMap("w=a"); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, _, err := Open(context.Background(), path, opts); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	cols := []string{"id", "Name", "status", "amount"}
+	rows := [][]driver.Value{
+		{"1", "a", "active", "50"},
+		{"2", "b", "inactive", "150"},
+		{"3", "c", nil, "9"},
+		{"4", "d e", "active", "1e3"},
+	}
+	tests := []struct {
+		s   string
+		exp []string
+	}{
+		{`status = 'active'`, []string{"1", "4"}},
+		{`status == 'active' AND amount > 100`, []string{"4"}},
+		{`amount >= 50 and amount <= 150`, []string{"1", "2"}},
+		{`amount < 10 OR name = 'd e'`, []string{"3", "4"}},
+		{`status != 'active'`, []string{"2"}},
+		{`status <> 'active' || status IS NULL`, []string{"2", "3"}},
+		{`status IS NOT NULL && NOT (id = 1 or id = 2)`, []string{"4"}},
+		{`"Name" > 'b'`, []string{"3", "4"}},
+		{`amount > -1.5 AND amount < 1.5e2`, []string{"1", "3"}},
+		{`'9' = amount`, []string{"3"}},
+	}
+	for i, test := range tests {
+		f, err := ParseFilter(test.s)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		fr, err := newFilteredRows(&testRows{cols: cols, vals: rows}, f)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		var res []string
+		dest := make([]driver.Value, len(cols))
+		for fr.Next(dest) == nil {
+			res = append(res, dest[0].(string))
+		}
+		if !reflect.DeepEqual(res, test.exp) {
+			t.Errorf("test %d expected %v, got: %v", i, test.exp, res)
+		}
+		if n := f.Filtered(); n != int64(len(rows)-len(test.exp)) {
+			t.Errorf("test %d expected %d filtered, got: %d", i, len(rows)-len(test.exp), n)
+		}
+	}
+	for i, s := range []string{``, `id`, `id =`, `id = 'a`, `(id = 1`, `id = 1 id = 2`, `id ~ 1`, `id IS 1`, `id = 1 AND`} {
+		if _, err := ParseFilter(s); err == nil {
+			t.Errorf("test %d expected error for %q, got: nil", i, s)
+		}
+	}
+	// missing file column
+	f, err := ParseFilter(`x = 1`)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := newFilteredRows(&testRows{cols: cols}, f); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+}
+
+// testRows are rows of values.
+type testRows struct {
+	cols []string
+	vals [][]driver.Value
+}
+
+func (r *testRows) Columns() []string { return r.cols }
+func (r *testRows) Close() error      { return nil }
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.vals) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.vals[0])
+	r.vals = r.vals[1:]
+	return nil
+}
+
+func TestInsertQuery(t *testing.T) {
+	placeholder := func(n int) string { return fmt.Sprintf("$%d", n) }
+	if s, exp := InsertQuery("INSERT INTO", "t", []string{"a", "b"}, 1, placeholder), "INSERT INTO t(a, b) VALUES ($1, $2)"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+	if s, exp := InsertQuery("INSERT INTO", "t", []string{"a", "b"}, 3, placeholder), "INSERT INTO t(a, b) VALUES ($1, $2), ($3, $4), ($5, $6)"; s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+}
+
+func TestImportBatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.csv")
+	// the 8th row conflicts with the 1st, failing the import, where only the
+	// rows committed before the failing insert (or batch) are imported
+	if err := os.WriteFile(path, []byte("id,v\n1,a\n2,b\n3,c\n4,d\n5,e\n6,f\n7,g\n1,h\n9,i\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		params map[string]string
+		exp    int64
+	}{
+		{map[string]string{"rows_per_insert": "2"}, 6},
+		{map[string]string{"rows_per_insert": "2", "batch_size": "4"}, 4},
+		{map[string]string{"rows_per_insert": "3", "batch": "3"}, 6},
+		{map[string]string{"batch_size": "100"}, 0},
+		{map[string]string{"rows_per_insert": "4"}, 4},
+	}
+	for i, test := range tests {
+		db, err := sql.Open("sqlite3", ":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		db.SetMaxOpenConns(1)
+		if _, err := db.Exec(`CREATE TABLE t (id INTEGER PRIMARY KEY, v TEXT)`); err != nil {
+			t.Fatal(err)
+		}
+		opts, err := NewOptions(path, test.params, nil)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		c := &Copy{Table: "t", Path: path, From: true}
+		insert := func(cols []string, rows int) (string, error) {
+			return InsertQuery("INSERT INTO", c.Table, cols, rows, func(int) string { return "?" }), nil
+		}
+		n, _, err := c.Import(context.Background(), db, insert, opts)
+		if err == nil {
+			t.Fatalf("test %d expected error, got: nil", i)
+		}
+		var count int64
+		if err := db.QueryRow(`SELECT COUNT(*) FROM t`).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		db.Close()
+		if n != test.exp || count != test.exp {
+			t.Errorf("test %d expected %d imported rows, got: %d (%d in table)", i, test.exp, n, count)
+		}
+	}
+	if _, err := NewOptions(path, map[string]string{"batch_size": "10", "on_error": "continue"}, nil); err != text.ErrCopyBatchOnError {
+		t.Errorf("expected error %v, got: %v", text.ErrCopyBatchOnError, err)
+	}
+}
+
+func TestInfer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.csv")
+	data := "id,price,ok,day,at,zip,mixed,tz,none\n" +
+		"1,1.5,true,2020-01-02,2020-01-02 03:04:05,01234,1,2020-01-02T03:04:05Z,\n" +
+		"2,2,FALSE,2021-03-04,2021-03-04,12345,x,2021-03-04T05:06:07+02:00,\n" +
+		"-3,1e3,false,2022-05-06,2022-05-06T07:08:09.123,54321,2.5,2022-05-06,\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		params map[string]string
+		cols   []string
+		exp    []Column
+	}{
+		{
+			map[string]string{"create": "true"},
+			nil,
+			[]Column{
+				{"id", TypeInt},
+				{"price", TypeFloat},
+				{"ok", TypeBool},
+				{"day", TypeDate},
+				{"at", TypeTimestamp},
+				{"zip", TypeText},
+				{"mixed", TypeText},
+				{"tz", TypeText},
+				{"none", TypeText},
+			},
+		},
+		{
+			map[string]string{"create": "true", "sample_size": "1", "map": "id=a, mixed, tz"},
+			nil,
+			[]Column{{"a", TypeInt}, {"mixed", TypeInt}, {"tz", TypeTimestampTZ}},
+		},
+		{
+			map[string]string{"create": "true", "filter": "id < 2", "map": "id, mixed"},
+			[]string{"b", "c"},
+			[]Column{{"b", TypeInt}, {"c", TypeFloat}},
+		},
+	}
+	for i, test := range tests {
+		opts, err := NewOptions(path, test.params, nil)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		c := &Copy{Table: "t", Columns: test.cols, Path: path, From: true}
+		cols, err := c.Infer(context.Background(), opts)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if !reflect.DeepEqual(cols, test.exp) {
+			t.Errorf("test %d expected %v, got: %v", i, test.exp, cols)
+		}
+		if n := opts.Filter.Filtered(); n != 0 {
+			t.Errorf("test %d expected no filtered rows, got: %d", i, n)
+		}
+	}
+	c := &Copy{Table: "t", Columns: []string{"a"}, Path: path, From: true}
+	if _, err := c.Infer(context.Background(), Options{Format: "csv", Header: true, Delimiter: ','}); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+	query := CreateTableQuery("t", []Column{{"a", TypeInt}, {"b", TypeText}}, strings.ToUpper)
+	if exp := "CREATE TABLE t (\n  a INT,\n  b TEXT\n)"; query != exp {
+		t.Errorf("expected %q, got: %q", exp, query)
+	}
+}
+
+func TestTypedRows(t *testing.T) {
+	cols := []Column{{"a", TypeInt}, {"b", TypeFloat}, {"c", TypeBool}, {"d", TypeDate}}
+	r := newTypedRows(&testRows{
+		cols: []string{"a", "b", "c", "d"},
+		vals: [][]driver.Value{
+			{"1", "2.5", "true", "2020-01-02"},
+			{"x", nil, "FALSE", nil},
+		},
+	}, cols)
+	exp := [][]driver.Value{
+		{int64(1), 2.5, true, "2020-01-02"},
+		{"x", nil, false, nil},
+	}
+	for i, vals := range exp {
+		dest := make([]driver.Value, len(cols))
+		if err := r.Next(dest); err != nil {
+			t.Fatalf("row %d expected no error, got: %v", i, err)
+		}
+		if !reflect.DeepEqual(dest, vals) {
+			t.Errorf("row %d expected %v, got: %v", i, vals, dest)
+		}
+	}
+}
+
+func TestCompression(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.csv")
+	if err := os.WriteFile(src, []byte("x,y\n1,2\n3,4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"b.csv.gz", "b.tsv.zst", "b.csv"} {
+		path, params := filepath.Join(dir, name), map[string]string{}
+		if name == "b.csv" {
+			params["compression"] = "gzip"
+		}
+		opts, err := NewOptions(path, params, nil)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got: %v", name, err)
+		}
+		if opts.FileCompression == "" {
+			t.Errorf("%s: expected file compression", name)
+		}
+		// write the source rows to the compressed file
+		srcOpts, _ := NewOptions(src, nil, nil)
+		rows, closeRows, err := Open(context.Background(), src, srcOpts)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got: %v", name, err)
+		}
+		n, err := Write(context.Background(), path, rows, opts)
+		closeRows()
+		if err != nil || n != 2 {
+			t.Fatalf("%s: expected 2 rows and no error, got: %d, %v", name, n, err)
+		}
+		// read back the compressed file
+		if rows, closeRows, err = Open(context.Background(), path, opts); err != nil {
+			t.Fatalf("%s: expected no error, got: %v", name, err)
+		}
+		var res [][]string
+		for rows.Next() {
+			var x, y string
+			if err := rows.Scan(&x, &y); err != nil {
+				t.Fatalf("%s: expected no error, got: %v", name, err)
+			}
+			res = append(res, []string{x, y})
+		}
+		closeRows()
+		if exp := [][]string{{"1", "2"}, {"3", "4"}}; !reflect.DeepEqual(res, exp) {
+			t.Errorf("%s: expected %v, got: %v", name, exp, res)
+		}
+	}
+	if _, err := NewOptions("a.parquet.gz", nil, nil); err == nil {
+		t.Errorf("expected error for compressed parquet file")
+	}
+	if _, err := NewOptions("a.csv", map[string]string{"compression": "lz4"}, nil); err == nil {
+		t.Errorf("expected error for unknown compression")
+	}
+}
+
+func TestWriteBatches(t *testing.T) {
+	dir := t.TempDir()
+	var batches []string
+	for i, data := range []string{"x,y\n1,2\n3,4\n", "x,y\n5,6\n", "x,y\n", "x,y\n7,8\n"} {
+		path := filepath.Join(dir, fmt.Sprintf("batch%d.csv", i))
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		batches = append(batches, path)
+	}
+	opts, err := NewOptions(batches[0], map[string]string{"fetch_size": "2"}, nil)
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case opts.FetchSize != 2:
+		t.Errorf("expected fetch size 2, got: %d", opts.FetchSize)
+	}
+	var closers []func()
+	defer func() {
+		for _, f := range closers {
+			f()
+		}
+	}()
+	next := func() (*sql.Rows, error) {
+		rows, closeRows, err := Open(context.Background(), batches[0], opts)
+		if err != nil {
+			return nil, err
+		}
+		batches, closers = batches[1:], append(closers, closeRows)
+		return rows, nil
+	}
+	// the empty batch ends the copy
+	path := filepath.Join(dir, "out.csv")
+	n, err := WriteBatches(context.Background(), path, next, opts)
+	if err != nil || n != 3 {
+		t.Fatalf("expected 3 rows and no error, got: %d, %v", n, err)
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := "x,y\n1,2\n3,4\n5,6\n"; string(buf) != exp {
+		t.Errorf("expected %q, got: %q", exp, string(buf))
+	}
+	if _, err := NewOptions("a.csv", map[string]string{"fetch_size": "-1"}, nil); err == nil {
+		t.Errorf("expected error for invalid fetch size")
+	}
+}
+
+func TestProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.csv")
+	if err := os.WriteFile(path, []byte("x,y\n1,2\n3,4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts, err := NewOptions(path, map[string]string{"progress_interval": "1h"}, nil)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var buf bytes.Buffer
+	opts.Progress = NewProgress(&buf, false, opts.ProgressInterval)
+	rows, closeRows, err := Open(context.Background(), path, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer closeRows()
+	for rows.Next() {
+	}
+	opts.Progress.start = time.Now().Add(-time.Second)
+	opts.Progress.report(opts.Progress.start.Add(time.Second))
+	if exp := "COPY 2 (in progress, 2 rows/s, 100%, ETA 00:00)\n"; buf.String() != exp {
+		t.Errorf("expected %q, got: %q", exp, buf.String())
+	}
+	for _, params := range []map[string]string{
+		{"quiet": "true"},
+		{"progress_interval": "5s", "quiet": "on"},
+	} {
+		if opts, err := NewOptions(path, params, nil); err != nil || opts.ProgressInterval != 0 {
+			t.Errorf("expected no progress for %v, got: %v %v", params, opts.ProgressInterval, err)
+		}
+	}
+	if _, err := NewOptions(path, map[string]string{"progress_interval": "0s"}, nil); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+}
+
+func TestJSONL(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "a.csv")
+	if err := os.WriteFile(src, []byte("id,name,note\n1,\"a \"\"b\"\"\",\n2,<c>,x\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	srcOpts, _ := NewOptions(src, map[string]string{"null": ""}, nil)
+	rows, closeRows, err := Open(context.Background(), src, srcOpts)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	defer closeRows()
+	path := filepath.Join(dir, "out.ndjson")
+	opts, err := NewOptions(path, nil, nil)
+	switch {
+	case err != nil:
+		t.Fatalf("expected no error, got: %v", err)
+	case opts.Format != "jsonl":
+		t.Fatalf("expected format jsonl, got: %q", opts.Format)
+	}
+	if n, err := Write(context.Background(), path, rows, opts); err != nil || n != 2 {
+		t.Fatalf("expected 2 rows and no error, got: %d, %v", n, err)
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `{"id":"1","name":"a \"b\"","note":null}` + "\n" + `{"id":"2","name":"<c>","note":"x"}` + "\n"
+	if string(buf) != exp {
+		t.Errorf("expected %q, got: %q", exp, string(buf))
+	}
+	if _, _, err := Open(context.Background(), path, opts); err != text.ErrJSONLImport {
+		t.Errorf("expected error %v, got: %v", text.ErrJSONLImport, err)
+	}
+}
@@ -0,0 +1,191 @@
+package copyfile
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// csvReader reads rows from a CSV file.
+type csvReader struct {
+	f *os.File
+	// d is the decompressor of a compressed file, and c counts the
+	// compressed bytes read.
+	d     io.ReadCloser
+	c     *countingReader
+	r     *csv.Reader
+	cols  []string
+	first []string
+	null  string
+	// last is the last record read, used when logging rejected records.
+	last     []string
+	progress *Progress
+}
+
+// newCSVReader creates a CSV reader for the file at path.
+func newCSVReader(path string, opts Options) (*csvReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	cr := &csvReader{
+		f:        f,
+		null:     opts.Null,
+		progress: opts.Progress,
+	}
+	var rd io.Reader = f
+	if opts.FileCompression != "" {
+		cr.c = &countingReader{r: f}
+		if cr.d, err = newDecompressor(cr.c, opts.FileCompression); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		rd = cr.d
+	}
+	cr.r = csv.NewReader(rd)
+	cr.r.Comma, cr.r.ReuseRecord = opts.Delimiter, false
+	first, err := cr.r.Read()
+	switch {
+	case err == io.EOF:
+		cr.Close()
+		return nil, fmt.Errorf("%s: empty file", path)
+	case err != nil:
+		cr.Close()
+		return nil, err
+	}
+	if fi, err := f.Stat(); err == nil {
+		cr.progress.SetTotal(fi.Size())
+	}
+	if opts.Header {
+		cr.cols = first
+	} else {
+		cr.cols, cr.first = make([]string, len(first)), first
+		for i := range first {
+			cr.cols[i] = "column" + strconv.Itoa(i+1)
+		}
+	}
+	return cr, nil
+}
+
+// Columns satisfies the driver.Rows interface.
+func (r *csvReader) Columns() []string {
+	return r.cols
+}
+
+// Close satisfies the driver.Rows interface.
+func (r *csvReader) Close() error {
+	if r.d != nil {
+		r.d.Close()
+	}
+	return r.f.Close()
+}
+
+// Next satisfies the driver.Rows interface.
+func (r *csvReader) Next(dest []driver.Value) error {
+	record := r.first
+	if record != nil {
+		r.first = nil
+	} else {
+		var err error
+		record, err = r.r.Read()
+		if r.last = record; err != nil {
+			return err
+		}
+	}
+	// the position in a compressed file is the compressed bytes read, as its
+	// total is the size of the file
+	pos := r.r.InputOffset()
+	if r.c != nil {
+		pos = r.c.n
+	}
+	r.progress.Row(pos)
+	for i := range dest {
+		switch {
+		case i >= len(record), record[i] == r.null:
+			dest[i] = nil
+		default:
+			dest[i] = record[i]
+		}
+	}
+	return nil
+}
+
+// ColumnTypeScanType satisfies the driver.RowsColumnTypeScanType interface.
+func (r *csvReader) ColumnTypeScanType(int) reflect.Type {
+	return reflect.TypeOf(sql.NullString{})
+}
+
+// ColumnTypeDatabaseTypeName satisfies the
+// driver.RowsColumnTypeDatabaseTypeName interface.
+func (r *csvReader) ColumnTypeDatabaseTypeName(int) string {
+	return "TEXT"
+}
+
+// csvWriter writes rows to a CSV file.
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+	null   string
+	tfmt   string
+}
+
+// newCSVWriter creates a CSV writer, writing the header when enabled.
+func newCSVWriter(w io.Writer, cols []string, opts Options) (*csvWriter, error) {
+	cw := &csvWriter{
+		w:      csv.NewWriter(w),
+		record: make([]string, len(cols)),
+		null:   opts.Null,
+		tfmt:   opts.TimeFormat,
+	}
+	cw.w.Comma = opts.Delimiter
+	if cw.tfmt == "" {
+		cw.tfmt = time.RFC3339Nano
+	}
+	if opts.Header {
+		if err := cw.w.Write(cols); err != nil {
+			return nil, err
+		}
+	}
+	return cw, nil
+}
+
+// Write writes a row.
+func (w *csvWriter) Write(row []interface{}) error {
+	for i, v := range row {
+		w.record[i] = textValue(v, w.null, w.tfmt)
+	}
+	return w.w.Write(w.record)
+}
+
+// Close flushes the writer.
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// textValue converts a scanned value to its text representation.
+func textValue(v interface{}, null, tfmt string) string {
+	switch x := v.(type) {
+	case nil:
+		return null
+	case []byte:
+		return string(x)
+	case string:
+		return x
+	case time.Time:
+		return x.Format(tfmt)
+	case bool:
+		return strconv.FormatBool(x)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,458 @@
+package copyfile
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/rmasci/usql/text"
+)
+
+// Filter is a row filter applied to the rows of a file before they are
+// imported, such as "status = 'active' AND amount > 100". A filter compares
+// file columns (by header name) and literals with =, !=, <>, <, <=, > and >=,
+// checks columns with IS [NOT] NULL, and combines conditions with AND, OR,
+// NOT and parentheses. Values are compared as numbers when both are numbers,
+// and otherwise as strings. A comparison with a null value is false.
+type Filter struct {
+	expr filterNode
+	// cols are the names of the file columns used by the filter.
+	cols []string
+	// filtered is the number of rows filtered out.
+	filtered int64
+}
+
+// ParseFilter parses a filter expression.
+func ParseFilter(s string) (*Filter, error) {
+	toks, err := tokenizeFilter(s)
+	if err != nil {
+		return nil, fmt.Errorf(text.CopyInvalidFilter, err)
+	}
+	p := &filterParser{toks: toks, f: new(Filter)}
+	if p.f.expr, err = p.or(); err == nil && p.peek().typ != filterEOF {
+		err = fmt.Errorf("unexpected %s", p.peek())
+	}
+	if err != nil {
+		return nil, fmt.Errorf(text.CopyInvalidFilter, err)
+	}
+	return p.f, nil
+}
+
+// Filtered returns the number of rows filtered out.
+func (f *Filter) Filtered() int64 {
+	if f == nil {
+		return 0
+	}
+	return f.filtered
+}
+
+// filteredRows are file rows matching a filter.
+type filteredRows struct {
+	driver.Rows
+	f *Filter
+	// idx are the indexes of the filter's columns in the file columns.
+	idx []int
+}
+
+// newFilteredRows creates filtered rows for the file rows, returning an error
+// when a filter column is not a file column.
+func newFilteredRows(r driver.Rows, f *Filter) (*filteredRows, error) {
+	src := r.Columns()
+	fr := &filteredRows{
+		Rows: r,
+		f:    f,
+		idx:  make([]int, len(f.cols)),
+	}
+	for i, c := range f.cols {
+		if fr.idx[i] = indexOf(src, c); fr.idx[i] == -1 {
+			return nil, fmt.Errorf(text.CopyFilterNotFound, c)
+		}
+	}
+	return fr, nil
+}
+
+// Next satisfies the driver.Rows interface, skipping rows not matching the
+// filter.
+func (r *filteredRows) Next(dest []driver.Value) error {
+	for {
+		if err := r.Rows.Next(dest); err != nil {
+			return err
+		}
+		if r.f.expr.match(dest, r.idx) {
+			return nil
+		}
+		r.f.filtered++
+	}
+}
+
+// ColumnTypeScanType satisfies the driver.RowsColumnTypeScanType interface.
+func (r *filteredRows) ColumnTypeScanType(i int) reflect.Type {
+	if t, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return t.ColumnTypeScanType(i)
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+// ColumnTypeDatabaseTypeName satisfies the
+// driver.RowsColumnTypeDatabaseTypeName interface.
+func (r *filteredRows) ColumnTypeDatabaseTypeName(i int) string {
+	if t, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return t.ColumnTypeDatabaseTypeName(i)
+	}
+	return ""
+}
+
+// filterNode is a condition of a filter.
+type filterNode interface {
+	// match returns whether the row values match the condition, where idx
+	// are the indexes of the filter's columns in the values.
+	match(vals []driver.Value, idx []int) bool
+}
+
+// filterAnd is a condition matching when both conditions match.
+type filterAnd struct {
+	l, r filterNode
+}
+
+func (n filterAnd) match(vals []driver.Value, idx []int) bool {
+	return n.l.match(vals, idx) && n.r.match(vals, idx)
+}
+
+// filterOr is a condition matching when either condition matches.
+type filterOr struct {
+	l, r filterNode
+}
+
+func (n filterOr) match(vals []driver.Value, idx []int) bool {
+	return n.l.match(vals, idx) || n.r.match(vals, idx)
+}
+
+// filterNot is a condition matching when the condition does not match.
+type filterNot struct {
+	n filterNode
+}
+
+func (n filterNot) match(vals []driver.Value, idx []int) bool {
+	return !n.n.match(vals, idx)
+}
+
+// filterIsNull is a condition matching when the operand is (or, when not is
+// true, is not) null.
+type filterIsNull struct {
+	v   filterOperand
+	not bool
+}
+
+func (n filterIsNull) match(vals []driver.Value, idx []int) bool {
+	_, ok := n.v.value(vals, idx)
+	return ok == n.not
+}
+
+// filterCompare is a comparison of two operands.
+type filterCompare struct {
+	op   string
+	l, r filterOperand
+}
+
+func (n filterCompare) match(vals []driver.Value, idx []int) bool {
+	l, ok := n.l.value(vals, idx)
+	if !ok {
+		return false
+	}
+	r, ok := n.r.value(vals, idx)
+	if !ok {
+		return false
+	}
+	c := strings.Compare(l, r)
+	if a, err := strconv.ParseFloat(l, 64); err == nil {
+		if b, err := strconv.ParseFloat(r, 64); err == nil {
+			switch {
+			case a < b:
+				c = -1
+			case a > b:
+				c = 1
+			default:
+				c = 0
+			}
+		}
+	}
+	switch n.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default: // >=
+		return c >= 0
+	}
+}
+
+// filterOperand is a file column (the index of a filter column), or a
+// literal when col is -1.
+type filterOperand struct {
+	col int
+	lit string
+}
+
+// value returns the text value of the operand, or false when null.
+func (o filterOperand) value(vals []driver.Value, idx []int) (string, bool) {
+	if o.col == -1 {
+		return o.lit, true
+	}
+	v := vals[idx[o.col]]
+	if v == nil {
+		return "", false
+	}
+	return textValue(v, "", time.RFC3339Nano), true
+}
+
+// filter token types.
+const (
+	filterEOF = iota
+	filterIdent
+	filterString
+	filterNumber
+	filterOp
+	filterKeyword
+)
+
+// filterToken is a token of a filter expression.
+type filterToken struct {
+	typ int
+	s   string
+}
+
+// String satisfies the fmt.Stringer interface.
+func (t filterToken) String() string {
+	switch t.typ {
+	case filterEOF:
+		return "end of filter"
+	case filterString:
+		return fmt.Sprintf("'%s'", strings.ReplaceAll(t.s, "'", "''"))
+	}
+	return fmt.Sprintf("%q", t.s)
+}
+
+// tokenizeFilter splits a filter expression into tokens.
+func tokenizeFilter(s string) ([]filterToken, error) {
+	var toks []filterToken
+	r := []rune(s)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '\'' || c == '"':
+			// quoted strings and identifiers, with a doubled quote escaping
+			// the quote
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(r); j++ {
+				if r[j] == c {
+					if j+1 < len(r) && r[j+1] == c {
+						sb.WriteRune(c)
+						j++
+						continue
+					}
+					break
+				}
+				sb.WriteRune(r[j])
+			}
+			if j >= len(r) {
+				return nil, fmt.Errorf("unterminated %c", c)
+			}
+			typ := filterString
+			if c == '"' {
+				typ = filterIdent
+			}
+			toks, i = append(toks, filterToken{typ, sb.String()}), j+1
+		case c == '(' || c == ')':
+			toks, i = append(toks, filterToken{filterOp, string(c)}), i+1
+		case strings.ContainsRune("=!<>&|", c):
+			j := i + 1
+			for j < len(r) && strings.ContainsRune("=!<>&|", r[j]) {
+				j++
+			}
+			op := string(r[i:j])
+			switch op {
+			case "=", "==":
+				op = "="
+			case "!=", "<>":
+				op = "!="
+			case "<", "<=", ">", ">=":
+			case "&&":
+				op = "AND"
+			case "||":
+				op = "OR"
+			default:
+				return nil, fmt.Errorf("invalid operator %q", op)
+			}
+			typ := filterOp
+			if op == "AND" || op == "OR" {
+				typ = filterKeyword
+			}
+			toks, i = append(toks, filterToken{typ, op}), j
+		case unicode.IsDigit(c), (c == '-' || c == '+' || c == '.') && i+1 < len(r) && (unicode.IsDigit(r[i+1]) || r[i+1] == '.'):
+			j := i + 1
+			for j < len(r) && (unicode.IsLetter(r[j]) || unicode.IsDigit(r[j]) || r[j] == '.' ||
+				(r[j] == '-' || r[j] == '+') && (r[j-1] == 'e' || r[j-1] == 'E')) {
+				j++
+			}
+			num := string(r[i:j])
+			if _, err := strconv.ParseFloat(num, 64); err != nil {
+				return nil, fmt.Errorf("invalid number %q", num)
+			}
+			toks, i = append(toks, filterToken{filterNumber, num}), j
+		case unicode.IsLetter(c) || c == '_':
+			j := i + 1
+			for j < len(r) && (unicode.IsLetter(r[j]) || unicode.IsDigit(r[j]) || r[j] == '_' || r[j] == '.') {
+				j++
+			}
+			word := string(r[i:j])
+			switch u := strings.ToUpper(word); u {
+			case "AND", "OR", "NOT", "IS", "NULL":
+				toks = append(toks, filterToken{filterKeyword, u})
+			default:
+				toks = append(toks, filterToken{filterIdent, word})
+			}
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q", c)
+		}
+	}
+	return toks, nil
+}
+
+// filterParser is a recursive descent parser of filter expressions.
+type filterParser struct {
+	toks []filterToken
+	i    int
+	f    *Filter
+}
+
+// peek returns the next token.
+func (p *filterParser) peek() filterToken {
+	if p.i < len(p.toks) {
+		return p.toks[p.i]
+	}
+	return filterToken{typ: filterEOF}
+}
+
+// next returns the next token, advancing the parser.
+func (p *filterParser) next() filterToken {
+	t := p.peek()
+	if p.i < len(p.toks) {
+		p.i++
+	}
+	return t
+}
+
+// accept advances the parser when the next token is the keyword or operator.
+func (p *filterParser) accept(s string) bool {
+	if t := p.peek(); (t.typ == filterKeyword || t.typ == filterOp) && t.s == s {
+		p.i++
+		return true
+	}
+	return false
+}
+
+// or parses conditions combined with OR.
+func (p *filterParser) or() (filterNode, error) {
+	n, err := p.and()
+	for err == nil && p.accept("OR") {
+		var r filterNode
+		if r, err = p.and(); err == nil {
+			n = filterOr{n, r}
+		}
+	}
+	return n, err
+}
+
+// and parses conditions combined with AND.
+func (p *filterParser) and() (filterNode, error) {
+	n, err := p.not()
+	for err == nil && p.accept("AND") {
+		var r filterNode
+		if r, err = p.not(); err == nil {
+			n = filterAnd{n, r}
+		}
+	}
+	return n, err
+}
+
+// not parses a condition, a negated condition, or a parenthesized
+// expression.
+func (p *filterParser) not() (filterNode, error) {
+	switch {
+	case p.accept("NOT"):
+		n, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{n}, nil
+	case p.accept("("):
+		n, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("expected \")\", got %s", p.peek())
+		}
+		return n, nil
+	}
+	return p.compare()
+}
+
+// compare parses a comparison, or an IS [NOT] NULL check.
+func (p *filterParser) compare() (filterNode, error) {
+	l, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	if p.accept("IS") {
+		not := p.accept("NOT")
+		if !p.accept("NULL") {
+			return nil, fmt.Errorf("expected NULL, got %s", p.peek())
+		}
+		return filterIsNull{l, not}, nil
+	}
+	t := p.next()
+	if t.typ != filterOp || t.s == "(" || t.s == ")" {
+		return nil, fmt.Errorf("expected comparison operator, got %s", t)
+	}
+	r, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	return filterCompare{t.s, l, r}, nil
+}
+
+// operand parses a column or a literal.
+func (p *filterParser) operand() (filterOperand, error) {
+	switch t := p.next(); t.typ {
+	case filterIdent:
+		i := -1
+		for j, c := range p.f.cols {
+			if c == t.s {
+				i = j
+			}
+		}
+		if i == -1 {
+			i, p.f.cols = len(p.f.cols), append(p.f.cols, t.s)
+		}
+		return filterOperand{col: i}, nil
+	case filterString, filterNumber:
+		return filterOperand{col: -1, lit: t.s}, nil
+	default:
+		return filterOperand{}, fmt.Errorf("expected column or value, got %s", t)
+	}
+}
@@ -0,0 +1,281 @@
+package copyfile
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rmasci/usql/text"
+)
+
+// Column types inferred from the values of a file's columns.
+const (
+	TypeInt         = "int"
+	TypeFloat       = "float"
+	TypeBool        = "bool"
+	TypeDate        = "date"
+	TypeTimestamp   = "timestamp"
+	TypeTimestampTZ = "timestamptz"
+	TypeText        = "text"
+)
+
+// DefaultSampleSize is the default number of rows sampled when inferring the
+// column types of a file.
+const DefaultSampleSize = 1000
+
+// Column is a table column created for a file column.
+type Column struct {
+	// Name is the column name.
+	Name string
+	// Type is the inferred column type.
+	Type string
+}
+
+// Infer infers the types of the file's columns from the values of its first
+// opts.SampleSize rows, or of all rows when 0. A column whose values have
+// conflicting types is text, except for integers and floats, which are
+// floats, and dates and timestamps (without time zones), which are
+// timestamps. A column having only null values is text. The columns are
+// named by the specified columns, when any.
+func (c *Copy) Infer(ctx context.Context, opts Options) ([]Column, error) {
+	var r driver.Rows
+	var err error
+	switch opts.Format {
+	case "csv":
+		r, err = newCSVReader(c.Path, opts)
+	case "parquet":
+		r, err = newParquetReader(ctx, c.Path, opts)
+	default:
+		return nil, text.ErrJSONLImport
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	// rows not matching the filter are not imported, so their values do not
+	// widen the types, nor are they counted as filtered
+	if opts.Filter != nil {
+		f, err := newFilteredRows(r, opts.Filter)
+		if err != nil {
+			return nil, err
+		}
+		r = f
+		defer func() { opts.Filter.filtered = 0 }()
+	}
+	if len(opts.Map) != 0 {
+		if r, err = newMappedRows(r, opts.Map); err != nil {
+			return nil, err
+		}
+	}
+	cols := make([]Column, len(r.Columns()))
+	for i, name := range r.Columns() {
+		cols[i].Name = name
+	}
+	if len(c.Columns) != 0 {
+		if len(c.Columns) != len(cols) {
+			return nil, fmt.Errorf(text.CopyColumnCount, len(c.Columns), len(cols))
+		}
+		for i, name := range c.Columns {
+			cols[i].Name = name
+		}
+	}
+	dest := make([]driver.Value, len(cols))
+	for n := 0; opts.SampleSize == 0 || n < opts.SampleSize; n++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var perr *csv.ParseError
+		switch err := r.Next(dest); {
+		case err == io.EOF:
+			return inferredColumns(cols), nil
+		case errors.As(err, &perr) && opts.OnError == "continue":
+			// rows rejected when imported
+			continue
+		case err != nil:
+			return nil, err
+		}
+		for i, v := range dest {
+			if typ := valueType(v); typ != "" {
+				cols[i].Type = widenType(cols[i].Type, typ)
+			}
+		}
+	}
+	return inferredColumns(cols), nil
+}
+
+// inferredColumns returns the columns, where a column without an inferred
+// type (having only null values) is text.
+func inferredColumns(cols []Column) []Column {
+	for i := range cols {
+		if cols[i].Type == "" {
+			cols[i].Type = TypeText
+		}
+	}
+	return cols
+}
+
+// CreateTableQuery returns the query creating the table with the columns,
+// using the database type returned by dataType for each column type.
+func CreateTableQuery(table string, cols []Column, dataType func(string) string) string {
+	defs := make([]string, len(cols))
+	for i, col := range cols {
+		defs[i] = "  " + col.Name + " " + dataType(col.Type)
+	}
+	return "CREATE TABLE " + table + " (\n" + strings.Join(defs, ",\n") + "\n)"
+}
+
+// timestampLayouts are the layouts of timestamp values without a time zone,
+// and timestampTZLayouts the layouts of those with one. Fractional seconds
+// are accepted by the layouts when parsing.
+var (
+	timestampLayouts   = []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05"}
+	timestampTZLayouts = []string{"2006-01-02 15:04:05Z07:00", time.RFC3339}
+)
+
+// valueType returns the type of a file value, or an empty string when null.
+func valueType(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return textType(x)
+	case []byte:
+		return TypeText
+	case bool:
+		return TypeBool
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return TypeInt
+	case float32, float64:
+		return TypeFloat
+	case time.Time:
+		return TypeTimestamp
+	}
+	return TypeText
+}
+
+// textType returns the type of a text value. Empty values, integers with
+// leading zeros (such as postal codes), and the special float values are
+// text.
+func textType(s string) string {
+	switch {
+	case s == "":
+		return TypeText
+	case strings.EqualFold(s, "true"), strings.EqualFold(s, "false"):
+		return TypeBool
+	}
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if d := strings.TrimLeft(s, "+-"); len(d) > 1 && d[0] == '0' {
+			return TypeText
+		}
+		return TypeInt
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil && strings.ContainsAny(s, "0123456789") && !strings.ContainsAny(s, "xXpP_") {
+		return TypeFloat
+	}
+	if _, err := time.Parse("2006-01-02", s); err == nil {
+		return TypeDate
+	}
+	for _, layout := range timestampLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return TypeTimestamp
+		}
+	}
+	for _, layout := range timestampTZLayouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return TypeTimestampTZ
+		}
+	}
+	return TypeText
+}
+
+// widenType returns the type of a column having values of both types.
+func widenType(a, b string) string {
+	switch {
+	case a == "", a == b:
+		return b
+	case a == TypeInt && b == TypeFloat, a == TypeFloat && b == TypeInt:
+		return TypeFloat
+	case a == TypeDate && b == TypeTimestamp, a == TypeTimestamp && b == TypeDate:
+		return TypeTimestamp
+	}
+	return TypeText
+}
+
+// typedRows are file rows whose text values of integer, float, and boolean
+// columns are converted to their column's type. Date and timestamp values
+// are left as text, to be parsed by the database.
+type typedRows struct {
+	driver.Rows
+	typs []string
+}
+
+// newTypedRows creates typed rows for the file rows.
+func newTypedRows(r driver.Rows, cols []Column) *typedRows {
+	tr := &typedRows{
+		Rows: r,
+		typs: make([]string, len(r.Columns())),
+	}
+	for i := 0; i < len(cols) && i < len(tr.typs); i++ {
+		tr.typs[i] = cols[i].Type
+	}
+	return tr
+}
+
+// Next satisfies the driver.Rows interface. Values that cannot be converted
+// are left unchanged.
+func (r *typedRows) Next(dest []driver.Value) error {
+	if err := r.Rows.Next(dest); err != nil {
+		return err
+	}
+	for i, v := range dest {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		switch r.typs[i] {
+		case TypeInt:
+			if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+				dest[i] = n
+			}
+		case TypeFloat:
+			if f, err := strconv.ParseFloat(s, 64); err == nil {
+				dest[i] = f
+			}
+		case TypeBool:
+			if b, err := strconv.ParseBool(s); err == nil {
+				dest[i] = b
+			}
+		}
+	}
+	return nil
+}
+
+// ColumnTypeScanType satisfies the driver.RowsColumnTypeScanType interface.
+// Converted values are scanned as is, as are values that could not be
+// converted, leaving the database to reject them.
+func (r *typedRows) ColumnTypeScanType(i int) reflect.Type {
+	switch r.typs[i] {
+	case TypeInt, TypeFloat, TypeBool:
+		return reflect.TypeOf(new(interface{})).Elem()
+	}
+	if t, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return t.ColumnTypeScanType(i)
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+// ColumnTypeDatabaseTypeName satisfies the
+// driver.RowsColumnTypeDatabaseTypeName interface.
+func (r *typedRows) ColumnTypeDatabaseTypeName(i int) string {
+	if t, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return t.ColumnTypeDatabaseTypeName(i)
+	}
+	return ""
+}
@@ -0,0 +1,73 @@
+package copyfile
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rmasci/usql/jsonenc"
+)
+
+// jsonlWriter writes rows to a JSON Lines file, as one object per row keyed
+// by the column names.
+type jsonlWriter struct {
+	w    *bufio.Writer
+	keys [][]byte
+	// typs are the upper cased database type names of the columns, whose
+	// values are written as by the json format (see jsonenc.Value)
+	typs []string
+	tfmt string
+	buf  bytes.Buffer
+}
+
+// newJSONLWriter creates a JSON Lines writer.
+func newJSONLWriter(w io.Writer, cols []string, typs []*sql.ColumnType, opts Options) (*jsonlWriter, error) {
+	jw := &jsonlWriter{
+		w:    bufio.NewWriter(w),
+		keys: make([][]byte, len(cols)),
+		typs: make([]string, len(cols)),
+		tfmt: opts.TimeFormat,
+	}
+	if jw.tfmt == "" {
+		jw.tfmt = time.RFC3339Nano
+	}
+	for i, col := range cols {
+		var err error
+		if jw.keys[i], err = jsonenc.Marshal(col); err != nil {
+			return nil, err
+		}
+		if i < len(typs) {
+			jw.typs[i] = strings.ToUpper(typs[i].DatabaseTypeName())
+		}
+	}
+	return jw, nil
+}
+
+// Write writes a row.
+func (w *jsonlWriter) Write(row []interface{}) error {
+	w.buf.Reset()
+	w.buf.WriteByte('{')
+	for i, v := range row {
+		if i != 0 {
+			w.buf.WriteByte(',')
+		}
+		w.buf.Write(w.keys[i])
+		w.buf.WriteByte(':')
+		b, err := jsonenc.Marshal(jsonenc.Value(v, w.typs[i], w.tfmt))
+		if err != nil {
+			return err
+		}
+		w.buf.Write(b)
+	}
+	w.buf.WriteString("}\n")
+	_, err := w.w.Write(w.buf.Bytes())
+	return err
+}
+
+// Close flushes the writer.
+func (w *jsonlWriter) Close() error {
+	return w.w.Flush()
+}
@@ -0,0 +1,143 @@
+package copyfile
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/rmasci/usql/text"
+)
+
+// ColumnMap maps a file column to a table column.
+type ColumnMap struct {
+	// Source is the file column name.
+	Source string
+	// Target is the table column name.
+	Target string
+}
+
+// parseMap parses a column mapping in the form of "src=dst, src2=dst2",
+// where a column without a target is copied to the table column of the same
+// name.
+func parseMap(s string) ([]ColumnMap, error) {
+	var v []ColumnMap
+	for _, m := range strings.Split(s, ",") {
+		if m = strings.TrimSpace(m); m == "" {
+			continue
+		}
+		src, dst, ok := strings.Cut(m, "=")
+		src, dst = strings.TrimSpace(src), strings.TrimSpace(dst)
+		if !ok {
+			dst = src
+		}
+		if src == "" || dst == "" {
+			return nil, fmt.Errorf(text.InvalidOption, "map")
+		}
+		v = append(v, ColumnMap{Source: src, Target: dst})
+	}
+	if len(v) == 0 {
+		return nil, fmt.Errorf(text.InvalidOption, "map")
+	}
+	return v, nil
+}
+
+// MapColumns sets the table columns to the targets of the column mapping
+// (when set), so that table columns not in the mapping get their default
+// values. Returns an error when a target is not in the specified column
+// list.
+func (c *Copy) MapColumns(opts Options) error {
+	if len(opts.Map) == 0 {
+		return nil
+	}
+	if !c.From {
+		return fmt.Errorf(text.InvalidOption, "map")
+	}
+	cols := make([]string, len(opts.Map))
+	for i, m := range opts.Map {
+		if len(c.Columns) != 0 && indexOf(c.Columns, m.Target) == -1 {
+			return fmt.Errorf(text.CopyMapNotInColumns, m.Target)
+		}
+		cols[i] = m.Target
+	}
+	c.Columns = cols
+	return nil
+}
+
+// mappedRows are file rows having only the mapped columns, in the order of
+// the mapping.
+type mappedRows struct {
+	driver.Rows
+	cols []string
+	idx  []int
+	// vals are the values of all file columns of the last read row.
+	vals []driver.Value
+}
+
+// newMappedRows creates mapped rows for the file rows, returning an error
+// when a mapped column is not a file column.
+func newMappedRows(r driver.Rows, m []ColumnMap) (*mappedRows, error) {
+	src := r.Columns()
+	mr := &mappedRows{
+		Rows: r,
+		cols: make([]string, len(m)),
+		idx:  make([]int, len(m)),
+		vals: make([]driver.Value, len(src)),
+	}
+	for i, c := range m {
+		if mr.idx[i] = indexOf(src, c.Source); mr.idx[i] == -1 {
+			return nil, fmt.Errorf(text.CopyMapColumnNotFound, c.Source)
+		}
+		mr.cols[i] = c.Target
+	}
+	return mr, nil
+}
+
+// Columns satisfies the driver.Rows interface.
+func (r *mappedRows) Columns() []string {
+	return r.cols
+}
+
+// Next satisfies the driver.Rows interface.
+func (r *mappedRows) Next(dest []driver.Value) error {
+	if err := r.Rows.Next(r.vals); err != nil {
+		return err
+	}
+	for i, j := range r.idx {
+		dest[i] = r.vals[j]
+	}
+	return nil
+}
+
+// ColumnTypeScanType satisfies the driver.RowsColumnTypeScanType interface.
+func (r *mappedRows) ColumnTypeScanType(i int) reflect.Type {
+	if t, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return t.ColumnTypeScanType(r.idx[i])
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+// ColumnTypeDatabaseTypeName satisfies the
+// driver.RowsColumnTypeDatabaseTypeName interface.
+func (r *mappedRows) ColumnTypeDatabaseTypeName(i int) string {
+	if t, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return t.ColumnTypeDatabaseTypeName(r.idx[i])
+	}
+	return ""
+}
+
+// indexOf returns the index of the column name in cols, matching an exact
+// name before a case-insensitive one, or -1 when not found.
+func indexOf(cols []string, name string) int {
+	for i, col := range cols {
+		if col == name {
+			return i
+		}
+	}
+	for i, col := range cols {
+		if strings.EqualFold(col, name) {
+			return i
+		}
+	}
+	return -1
+}
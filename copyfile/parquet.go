@@ -0,0 +1,457 @@
+package copyfile
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/decimal128"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/apache/arrow/go/v14/parquet"
+	"github.com/apache/arrow/go/v14/parquet/compress"
+	"github.com/apache/arrow/go/v14/parquet/file"
+	"github.com/apache/arrow/go/v14/parquet/pqarrow"
+)
+
+// defaultRowGroupSize is the default number of rows per Parquet row group.
+const defaultRowGroupSize = 10000
+
+// parquetWriter writes rows to a Parquet file, one row group per
+// RowGroupSize rows.
+type parquetWriter struct {
+	fw    *pqarrow.FileWriter
+	rb    *array.RecordBuilder
+	size  int
+	count int
+}
+
+// newParquetWriter creates a Parquet writer, mapping the column types to
+// Parquet logical types.
+func newParquetWriter(w io.Writer, cols []string, typs []*sql.ColumnType, opts Options) (*parquetWriter, error) {
+	codec, err := parquetCodec(opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+	size := opts.RowGroupSize
+	if size == 0 {
+		size = defaultRowGroupSize
+	}
+	fields := make([]arrow.Field, len(cols))
+	for i, col := range cols {
+		fields[i] = arrow.Field{
+			Name:     col,
+			Type:     arrowType(typs[i]),
+			Nullable: true,
+		}
+	}
+	schema := arrow.NewSchema(fields, nil)
+	props := parquet.NewWriterProperties(
+		parquet.WithCompression(codec),
+		parquet.WithMaxRowGroupLength(int64(size)),
+	)
+	// hide the writer's Close, as the file is closed by the caller
+	fw, err := pqarrow.NewFileWriter(schema, struct{ io.Writer }{w}, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return nil, err
+	}
+	return &parquetWriter{
+		fw:   fw,
+		rb:   array.NewRecordBuilder(memory.DefaultAllocator, schema),
+		size: size,
+	}, nil
+}
+
+// Write writes a row, flushing a row group when full.
+func (w *parquetWriter) Write(row []interface{}) error {
+	for i, v := range row {
+		if err := appendValue(w.rb.Field(i), v); err != nil {
+			return fmt.Errorf("column %d: %w", i+1, err)
+		}
+	}
+	if w.count++; w.count >= w.size {
+		return w.flush()
+	}
+	return nil
+}
+
+// Close flushes the remaining rows and writes the file footer.
+func (w *parquetWriter) Close() error {
+	defer w.rb.Release()
+	if w.count != 0 {
+		if err := w.flush(); err != nil {
+			return err
+		}
+	}
+	return w.fw.Close()
+}
+
+// flush writes the buffered rows as a row group.
+func (w *parquetWriter) flush() error {
+	rec := w.rb.NewRecord()
+	defer rec.Release()
+	w.count = 0
+	return w.fw.Write(rec)
+}
+
+// parquetCodec returns the Parquet compression codec.
+func parquetCodec(name string) (compress.Compression, error) {
+	switch strings.ToLower(name) {
+	case "", "snappy":
+		return compress.Codecs.Snappy, nil
+	case "zstd":
+		return compress.Codecs.Zstd, nil
+	case "gzip":
+		return compress.Codecs.Gzip, nil
+	case "none", "uncompressed":
+		return compress.Codecs.Uncompressed, nil
+	}
+	return compress.Codecs.Uncompressed, fmt.Errorf("invalid parquet compression %q", name)
+}
+
+// arrowType returns the arrow type for the column type.
+func arrowType(typ *sql.ColumnType) arrow.DataType {
+	name := strings.ToUpper(typ.DatabaseTypeName())
+	switch {
+	case strings.Contains(name, "DECIMAL"), strings.Contains(name, "NUMERIC"), name == "NUMBER":
+		if prec, scale, ok := typ.DecimalSize(); ok && 0 < prec && prec <= 38 && 0 <= scale && scale <= prec {
+			return &arrow.Decimal128Type{Precision: int32(prec), Scale: int32(scale)}
+		}
+		return arrow.BinaryTypes.String
+	case strings.Contains(name, "BYTEA"), strings.Contains(name, "BLOB"), strings.Contains(name, "BINARY"):
+		return arrow.BinaryTypes.Binary
+	case strings.Contains(name, "INTERVAL"), strings.Contains(name, "POINT"):
+		return arrow.BinaryTypes.String
+	case strings.Contains(name, "INT"):
+		return arrow.PrimitiveTypes.Int64
+	case strings.Contains(name, "FLOAT"), strings.Contains(name, "DOUBLE"), strings.Contains(name, "REAL"):
+		return arrow.PrimitiveTypes.Float64
+	case strings.Contains(name, "BOOL"):
+		return arrow.FixedWidthTypes.Boolean
+	case strings.Contains(name, "TIMESTAMP"), strings.Contains(name, "DATETIME"):
+		return arrow.FixedWidthTypes.Timestamp_us
+	}
+	switch t := typ.ScanType(); {
+	case t == nil:
+	case t == reflect.TypeOf(sql.NullInt64{}), t == reflect.TypeOf(sql.NullInt32{}), t == reflect.TypeOf(sql.NullInt16{}):
+		return arrow.PrimitiveTypes.Int64
+	case t == reflect.TypeOf(sql.NullFloat64{}):
+		return arrow.PrimitiveTypes.Float64
+	case t == reflect.TypeOf(sql.NullBool{}):
+		return arrow.FixedWidthTypes.Boolean
+	case t == reflect.TypeOf(sql.NullTime{}), t == reflect.TypeOf(time.Time{}):
+		return arrow.FixedWidthTypes.Timestamp_us
+	default:
+		switch t.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint8, reflect.Uint16, reflect.Uint32:
+			return arrow.PrimitiveTypes.Int64
+		case reflect.Float32, reflect.Float64:
+			return arrow.PrimitiveTypes.Float64
+		case reflect.Bool:
+			return arrow.FixedWidthTypes.Boolean
+		}
+	}
+	return arrow.BinaryTypes.String
+}
+
+// appendValue appends a scanned value to the builder.
+func appendValue(b array.Builder, v interface{}) error {
+	if v == nil {
+		b.AppendNull()
+		return nil
+	}
+	switch x := b.(type) {
+	case *array.Int64Builder:
+		i, err := toInt64(v)
+		if err != nil {
+			return err
+		}
+		x.Append(i)
+	case *array.Float64Builder:
+		f, err := toFloat64(v)
+		if err != nil {
+			return err
+		}
+		x.Append(f)
+	case *array.BooleanBuilder:
+		switch z := v.(type) {
+		case bool:
+			x.Append(z)
+		default:
+			i, err := toInt64(v)
+			if err != nil {
+				return err
+			}
+			x.Append(i != 0)
+		}
+	case *array.TimestampBuilder:
+		t, err := toTime(v)
+		if err != nil {
+			return err
+		}
+		x.Append(arrow.Timestamp(t.UnixMicro()))
+	case *array.Decimal128Builder:
+		typ := x.Type().(*arrow.Decimal128Type)
+		n, err := decimal128.FromString(textValue(v, "", time.RFC3339Nano), typ.Precision, typ.Scale)
+		if err != nil {
+			return err
+		}
+		x.Append(n)
+	case *array.BinaryBuilder:
+		switch z := v.(type) {
+		case []byte:
+			x.Append(z)
+		default:
+			x.Append([]byte(textValue(v, "", time.RFC3339Nano)))
+		}
+	case *array.StringBuilder:
+		x.Append(textValue(v, "", time.RFC3339Nano))
+	default:
+		return fmt.Errorf("unsupported builder %T", b)
+	}
+	return nil
+}
+
+// toInt64 converts v to an int64.
+func toInt64(v interface{}) (int64, error) {
+	switch x := v.(type) {
+	case int64:
+		return x, nil
+	case int:
+		return int64(x), nil
+	case int32:
+		return int64(x), nil
+	case int16:
+		return int64(x), nil
+	case int8:
+		return int64(x), nil
+	case uint64:
+		return int64(x), nil
+	case uint32:
+		return int64(x), nil
+	case uint16:
+		return int64(x), nil
+	case uint8:
+		return int64(x), nil
+	case float64:
+		return int64(x), nil
+	case float32:
+		return int64(x), nil
+	case bool:
+		if x {
+			return 1, nil
+		}
+		return 0, nil
+	case []byte:
+		return strconv.ParseInt(string(x), 10, 64)
+	case string:
+		return strconv.ParseInt(x, 10, 64)
+	}
+	return 0, fmt.Errorf("cannot convert %T to int64", v)
+}
+
+// toFloat64 converts v to a float64.
+func toFloat64(v interface{}) (float64, error) {
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case float32:
+		return float64(x), nil
+	case []byte:
+		return strconv.ParseFloat(string(x), 64)
+	case string:
+		return strconv.ParseFloat(x, 64)
+	}
+	i, err := toInt64(v)
+	if err != nil {
+		return 0, fmt.Errorf("cannot convert %T to float64", v)
+	}
+	return float64(i), nil
+}
+
+// timeLayouts are the layouts used to parse text time values.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// toTime converts v to a time.
+func toTime(v interface{}) (time.Time, error) {
+	var s string
+	switch x := v.(type) {
+	case time.Time:
+		return x, nil
+	case []byte:
+		s = string(x)
+	case string:
+		s = x
+	default:
+		return time.Time{}, fmt.Errorf("cannot convert %T to time", v)
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as time", s)
+}
+
+// parquetReader reads rows from a Parquet file.
+type parquetReader struct {
+	f    *file.Reader
+	rr   pqarrow.RecordReader
+	cols []string
+	typs []arrow.DataType
+	rec  arrow.Record
+	row  int
+	// n is the number of rows read.
+	n        int64
+	progress *Progress
+}
+
+// newParquetReader creates a Parquet reader for the file at path.
+func newParquetReader(ctx context.Context, path string, opts Options) (*parquetReader, error) {
+	f, err := file.OpenParquetFile(path, false)
+	if err != nil {
+		return nil, err
+	}
+	fr, err := pqarrow.NewFileReader(f, pqarrow.ArrowReadProperties{BatchSize: defaultRowGroupSize}, memory.DefaultAllocator)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	schema, err := fr.Schema()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	rr, err := fr.GetRecordReader(ctx, nil, nil)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r := &parquetReader{
+		f:        f,
+		rr:       rr,
+		progress: opts.Progress,
+	}
+	r.progress.SetTotal(f.NumRows())
+	for _, field := range schema.Fields() {
+		r.cols, r.typs = append(r.cols, field.Name), append(r.typs, field.Type)
+	}
+	return r, nil
+}
+
+// Columns satisfies the driver.Rows interface.
+func (r *parquetReader) Columns() []string {
+	return r.cols
+}
+
+// Close satisfies the driver.Rows interface.
+func (r *parquetReader) Close() error {
+	r.rr.Release()
+	return r.f.Close()
+}
+
+// Next satisfies the driver.Rows interface.
+func (r *parquetReader) Next(dest []driver.Value) error {
+	for r.rec == nil || r.row >= int(r.rec.NumRows()) {
+		if !r.rr.Next() {
+			if err := r.rr.Err(); err != nil && err != io.EOF {
+				return err
+			}
+			return io.EOF
+		}
+		r.rec, r.row = r.rr.Record(), 0
+	}
+	for i := range dest {
+		dest[i] = arrowValue(r.rec.Column(i), r.row)
+	}
+	r.row, r.n = r.row+1, r.n+1
+	r.progress.Row(r.n)
+	return nil
+}
+
+// ColumnTypeScanType satisfies the driver.RowsColumnTypeScanType interface.
+func (r *parquetReader) ColumnTypeScanType(index int) reflect.Type {
+	switch r.typs[index].ID() {
+	case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64, arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64:
+		return reflect.TypeOf(sql.NullInt64{})
+	case arrow.FLOAT32, arrow.FLOAT64:
+		return reflect.TypeOf(sql.NullFloat64{})
+	case arrow.BOOL:
+		return reflect.TypeOf(sql.NullBool{})
+	case arrow.TIMESTAMP, arrow.DATE32, arrow.DATE64:
+		return reflect.TypeOf(sql.NullTime{})
+	case arrow.BINARY, arrow.LARGE_BINARY, arrow.FIXED_SIZE_BINARY:
+		return reflect.TypeOf([]byte(nil))
+	}
+	return reflect.TypeOf(sql.NullString{})
+}
+
+// ColumnTypeDatabaseTypeName satisfies the
+// driver.RowsColumnTypeDatabaseTypeName interface.
+func (r *parquetReader) ColumnTypeDatabaseTypeName(index int) string {
+	return strings.ToUpper(r.typs[index].Name())
+}
+
+// arrowValue returns the driver value at row i of the array.
+func arrowValue(arr arrow.Array, i int) driver.Value {
+	if arr.IsNull(i) {
+		return nil
+	}
+	switch x := arr.(type) {
+	case *array.Int8:
+		return int64(x.Value(i))
+	case *array.Int16:
+		return int64(x.Value(i))
+	case *array.Int32:
+		return int64(x.Value(i))
+	case *array.Int64:
+		return x.Value(i)
+	case *array.Uint8:
+		return int64(x.Value(i))
+	case *array.Uint16:
+		return int64(x.Value(i))
+	case *array.Uint32:
+		return int64(x.Value(i))
+	case *array.Uint64:
+		return int64(x.Value(i))
+	case *array.Float32:
+		return float64(x.Value(i))
+	case *array.Float64:
+		return x.Value(i)
+	case *array.Boolean:
+		return x.Value(i)
+	case *array.String:
+		return x.Value(i)
+	case *array.LargeString:
+		return x.Value(i)
+	case *array.Binary:
+		return append([]byte(nil), x.Value(i)...)
+	case *array.LargeBinary:
+		return append([]byte(nil), x.Value(i)...)
+	case *array.FixedSizeBinary:
+		return append([]byte(nil), x.Value(i)...)
+	case *array.Timestamp:
+		return x.Value(i).ToTime(x.DataType().(*arrow.TimestampType).Unit)
+	case *array.Date32:
+		return x.Value(i).ToTime()
+	case *array.Date64:
+		return x.Value(i).ToTime()
+	case *array.Decimal128:
+		return x.Value(i).ToString(x.DataType().(*arrow.Decimal128Type).Scale)
+	}
+	return arr.ValueStr(i)
+}
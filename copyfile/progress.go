@@ -0,0 +1,129 @@
+package copyfile
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rmasci/usql/text"
+)
+
+// DefaultProgressInterval is the default interval at which the progress of a
+// copy is reported.
+const DefaultProgressInterval = 2 * time.Second
+
+// Progress periodically reports the progress of a copy: the number of rows
+// copied, the rate, and when the total is known, the percentage done and the
+// estimated time remaining. A nil Progress reports nothing.
+type Progress struct {
+	w        io.Writer
+	tty      bool
+	interval time.Duration
+	start    time.Time
+	// rows is the number of rows copied.
+	rows atomic.Int64
+	// pos and total are the position in, and the total of, the copy's
+	// source, in units of the source (such as bytes of a CSV file).
+	pos   atomic.Int64
+	total atomic.Int64
+	done  chan struct{}
+	wg    sync.WaitGroup
+	// wrote is true when a line has been written.
+	wrote bool
+}
+
+// NewProgress creates a progress writing to w every interval. When tty is
+// true, each report overwrites the previous one using a carriage return,
+// otherwise each report is written on its own line.
+func NewProgress(w io.Writer, tty bool, interval time.Duration) *Progress {
+	return &Progress{
+		w:        w,
+		tty:      tty,
+		interval: interval,
+	}
+}
+
+// Start starts reporting the progress.
+func (p *Progress) Start() {
+	if p == nil {
+		return
+	}
+	p.start, p.done = time.Now(), make(chan struct{})
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		t := time.NewTicker(p.interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-p.done:
+				return
+			case now := <-t.C:
+				p.report(now)
+			}
+		}
+	}()
+}
+
+// Stop stops reporting the progress, clearing the updating line when one was
+// written to a terminal.
+func (p *Progress) Stop() {
+	if p == nil || p.done == nil {
+		return
+	}
+	close(p.done)
+	p.wg.Wait()
+	p.done = nil
+	if p.tty && p.wrote {
+		fmt.Fprint(p.w, "\r\x1b[K")
+	}
+}
+
+// Row counts a copied row, and when pos is not negative, sets the position in
+// the copy's source.
+func (p *Progress) Row(pos int64) {
+	if p == nil {
+		return
+	}
+	p.rows.Add(1)
+	if pos >= 0 {
+		p.pos.Store(pos)
+	}
+}
+
+// SetTotal sets the total of the copy's source, in the same units as the
+// position passed to Row.
+func (p *Progress) SetTotal(total int64) {
+	if p != nil {
+		p.total.Store(total)
+	}
+}
+
+// report writes the progress.
+func (p *Progress) report(now time.Time) {
+	rows, elapsed := p.rows.Load(), now.Sub(p.start)
+	rate := int64(float64(rows) / elapsed.Seconds())
+	s := fmt.Sprintf(text.CopyProgressRate, rows, rate)
+	if pos, total := p.pos.Load(), p.total.Load(); pos > 0 && total > 0 && pos <= total {
+		eta := time.Duration(float64(elapsed) * float64(total-pos) / float64(pos))
+		s = fmt.Sprintf(text.CopyProgressETA, rows, rate, 100*pos/total, formatETA(eta))
+	}
+	if p.tty {
+		fmt.Fprint(p.w, "\r"+s+"\x1b[K")
+	} else {
+		fmt.Fprintln(p.w, s)
+	}
+	p.wrote = true
+}
+
+// formatETA formats the estimated time remaining as [h:]mm:ss.
+func formatETA(d time.Duration) string {
+	d = d.Round(time.Second)
+	h, m, s := int(d/time.Hour), int(d/time.Minute)%60, int(d/time.Second)%60
+	if h != 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%02d:%02d", m, s)
+}
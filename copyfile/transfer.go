@@ -0,0 +1,176 @@
+package copyfile
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/rmasci/usql/text"
+)
+
+// TransferOptions are the options for a copy between connections.
+type TransferOptions struct {
+	// BatchSize is the number of rows copied per batch (and transaction), or
+	// 0 to copy all rows in a single batch.
+	BatchSize int
+	// Progress is the number of rows after which the progress is reported,
+	// or 0 to not report progress.
+	Progress int
+	// ProgressInterval is the interval at which the progress is reported, or
+	// 0 to not report progress.
+	ProgressInterval time.Duration
+	// Reporter is the progress the copied rows are counted by.
+	Reporter *Progress
+}
+
+// NewTransferOptions creates the options for a copy between connections from
+// the params.
+func NewTransferOptions(params map[string]string) (TransferOptions, error) {
+	var opts TransferOptions
+	var err error
+	if opts.ProgressInterval, err = parseProgressInterval(params); err != nil {
+		return TransferOptions{}, err
+	}
+	if s, ok := params["batch_size"]; ok {
+		if opts.BatchSize, err = strconv.Atoi(s); err != nil || opts.BatchSize < 0 {
+			return TransferOptions{}, fmt.Errorf(text.InvalidOption, "batch_size")
+		}
+	}
+	if s, ok := params["progress"]; ok {
+		if opts.Progress, err = strconv.Atoi(s); err != nil || opts.Progress < 0 {
+			return TransferOptions{}, fmt.Errorf(text.InvalidOption, "progress")
+		}
+	}
+	// quiet also disables the progress every opts.Progress rows
+	if opts.ProgressInterval == 0 {
+		opts.Progress = 0
+	}
+	return opts, nil
+}
+
+// Transfer copies the rows in batches of opts.BatchSize rows, passing the rows
+// of each batch to copy (such as the destination driver's copy), and calling
+// progress every opts.Progress rows. Returns the number of copied rows.
+func Transfer(ctx context.Context, rows *sql.Rows, opts TransferOptions, copy func(*sql.Rows) (int64, error), progress func(int64)) (int64, error) {
+	r, err := newBatchRows(rows, opts, progress)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	for rows.Next() {
+		r.pending, r.count = true, 0
+		db := sql.OpenDB(connector{rows: r})
+		batch, err := db.QueryContext(ctx, "")
+		if err != nil {
+			db.Close()
+			return n, err
+		}
+		i, err := copy(batch)
+		batch.Close()
+		db.Close()
+		n += i
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, rows.Err()
+}
+
+// batchRows are the rows of a batch, read from the source rows.
+type batchRows struct {
+	rows     *sql.Rows
+	cols     []string
+	typs     []*sql.ColumnType
+	size     int
+	progress int
+	report   func(int64)
+	reporter *Progress
+	// pending is true when the source rows have been advanced to a row that
+	// has not yet been read.
+	pending bool
+	// count is the number of rows read in the batch.
+	count int
+	// total is the number of rows read in all batches.
+	total int64
+	vals  []interface{}
+}
+
+// newBatchRows creates batch rows for the source rows.
+func newBatchRows(rows *sql.Rows, opts TransferOptions, report func(int64)) (*batchRows, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	typs, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	r := &batchRows{
+		rows:     rows,
+		cols:     cols,
+		typs:     typs,
+		size:     opts.BatchSize,
+		progress: opts.Progress,
+		report:   report,
+		reporter: opts.Reporter,
+		vals:     make([]interface{}, len(cols)),
+	}
+	for i := range r.vals {
+		r.vals[i] = new(interface{})
+	}
+	return r, nil
+}
+
+// Columns satisfies the driver.Rows interface.
+func (r *batchRows) Columns() []string {
+	return r.cols
+}
+
+// Close satisfies the driver.Rows interface. The source rows are left open
+// for the next batch.
+func (r *batchRows) Close() error {
+	return nil
+}
+
+// Next satisfies the driver.Rows interface.
+func (r *batchRows) Next(dest []driver.Value) error {
+	switch {
+	case r.size != 0 && r.count >= r.size:
+		return io.EOF
+	case r.pending:
+		r.pending = false
+	case !r.rows.Next():
+		return io.EOF
+	}
+	if err := r.rows.Scan(r.vals...); err != nil {
+		return err
+	}
+	for i, v := range r.vals {
+		dest[i] = *(v.(*interface{}))
+	}
+	r.count, r.total = r.count+1, r.total+1
+	r.reporter.Row(-1)
+	if r.progress != 0 && r.report != nil && r.total%int64(r.progress) == 0 {
+		r.report(r.total)
+	}
+	return nil
+}
+
+// ColumnTypeScanType satisfies the driver.RowsColumnTypeScanType interface.
+func (r *batchRows) ColumnTypeScanType(i int) reflect.Type {
+	if typ := r.typs[i].ScanType(); typ != nil {
+		return typ
+	}
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+// ColumnTypeDatabaseTypeName satisfies the
+// driver.RowsColumnTypeDatabaseTypeName interface.
+func (r *batchRows) ColumnTypeDatabaseTypeName(i int) string {
+	return r.typs[i].DatabaseTypeName()
+}
@@ -0,0 +1,63 @@
+// Package bigquery defines and registers usql's Google BigQuery driver.
+//
+// Connection URLs are of the form bigquery://projectID/dataset, where the
+// dataset is used for unqualified table names.
+//
+// See: https://github.com/googleapis/google-cloud-go/tree/main/bigquery
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"cloud.google.com/go/bigquery" // DRIVER
+	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/drivers/metadata"
+	"google.golang.org/api/googleapi"
+)
+
+func init() {
+	drivers.Register("bigquery", drivers.Driver{
+		AllowMultilineComments: true,
+		Version: func(context.Context, drivers.DB) (string, error) {
+			return "BigQuery", nil
+		},
+		User: func(ctx context.Context, db drivers.DB) (string, error) {
+			var user string
+			if err := db.QueryRowContext(ctx, `SELECT SESSION_USER()`).Scan(&user); err != nil {
+				return "", err
+			}
+			return user, nil
+		},
+		IsPasswordErr: func(err error) bool {
+			var e *googleapi.Error
+			return errors.As(err, &e) && (e.Code == http.StatusUnauthorized || e.Code == http.StatusForbidden)
+		},
+		Err: func(err error) (string, string) {
+			var e *bigquery.Error
+			if errors.As(err, &e) {
+				return e.Reason, e.Message
+			}
+			var ge *googleapi.Error
+			if errors.As(err, &ge) {
+				msg := ge.Message
+				if msg == "" {
+					msg = http.StatusText(ge.Code)
+				}
+				return strconv.Itoa(ge.Code), msg
+			}
+			return "", err.Error()
+		},
+		// results are paged (or streamed with the Storage Read API), so avoid
+		// buffering the entire result set when encoding results
+		BufferRows:        1000,
+		NewMetadataReader: NewReader,
+		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
+			return metadata.NewDefaultWriter(NewReader(db, opts...))(db, w)
+		},
+		Copy: drivers.CopyWithInsert(func(int) string { return "?" }),
+	})
+}
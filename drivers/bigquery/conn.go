@@ -0,0 +1,422 @@
+package bigquery
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+func init() {
+	sql.Register("bigquery", drv{})
+}
+
+// drv is a database/sql driver for BigQuery.
+type drv struct{}
+
+// Open satisfies the driver.Driver interface.
+func (d drv) Open(name string) (driver.Conn, error) {
+	c, err := d.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.Connect(context.Background())
+}
+
+// OpenConnector satisfies the driver.DriverContext interface.
+func (drv) OpenConnector(name string) (driver.Connector, error) {
+	return newConnector(context.Background(), name)
+}
+
+// connector is a database/sql connector for a BigQuery project.
+//
+// The connection string is a URL of the form
+// bigquery://projectID/dataset?opts, where the dataset is used for unqualified
+// table names in queries. Supported options are:
+//
+//	credentials  - path to a service account key file (otherwise the
+//	               application default credentials are used, such as the
+//	               GOOGLE_APPLICATION_CREDENTIALS environment variable)
+//	location     - location in which to run queries (such as US or EU)
+//	storage_read - whether to read large results using the Storage Read API
+//	               (default true)
+type connector struct {
+	client    *bigquery.Client
+	projectID string
+	datasetID string
+}
+
+// newConnector creates a connector for the connection string.
+func newConnector(ctx context.Context, dsn string) (*connector, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	projectID := u.Host
+	if projectID == "" || projectID == "localhost" {
+		projectID = bigquery.DetectProjectID
+	}
+	q := u.Query()
+	var opts []option.ClientOption
+	if s := q.Get("credentials"); s != "" {
+		opts = append(opts, option.WithCredentialsFile(s))
+	}
+	client, err := bigquery.NewClient(ctx, projectID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	client.Location = q.Get("location")
+	if s := q.Get("storage_read"); s == "" || s == "true" || s == "1" {
+		if err := client.EnableStorageReadClient(ctx, opts...); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	return &connector{
+		client:    client,
+		projectID: client.Project(),
+		datasetID: strings.Trim(u.Path, "/"),
+	}, nil
+}
+
+// Connect satisfies the driver.Connector interface.
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{c: c}, nil
+}
+
+// Driver satisfies the driver.Connector interface.
+func (c *connector) Driver() driver.Driver {
+	return drv{}
+}
+
+// Close satisfies the io.Closer interface, closing the client when the
+// sql.DB is closed.
+func (c *connector) Close() error {
+	return c.client.Close()
+}
+
+// conn is a connection to a BigQuery project. As the client is stateless, a
+// conn only wraps the connector's client.
+type conn struct {
+	c *connector
+}
+
+// Prepare satisfies the driver.Conn interface.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{c: c, query: query}, nil
+}
+
+// Close satisfies the driver.Conn interface.
+func (c *conn) Close() error {
+	return nil
+}
+
+// Begin satisfies the driver.Conn interface.
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+// Ping satisfies the driver.Pinger interface.
+func (c *conn) Ping(ctx context.Context) error {
+	it, err := c.query(`SELECT 1`, nil).Read(ctx)
+	if err != nil {
+		return err
+	}
+	var row []bigquery.Value
+	if err := it.Next(&row); err != nil && err != iterator.Done {
+		return err
+	}
+	return nil
+}
+
+// QueryContext satisfies the driver.QueryerContext interface.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	it, err := c.query(query, args).Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(it)
+}
+
+// ExecContext satisfies the driver.ExecerContext interface.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	job, err := c.query(query, args).Run(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := status.Err(); err != nil {
+		return nil, err
+	}
+	var n int64
+	if stats, ok := status.Statistics.Details.(*bigquery.QueryStatistics); ok {
+		n = stats.NumDMLAffectedRows
+	}
+	return driver.RowsAffected(n), nil
+}
+
+// query creates a query using the connection's default dataset, passing
+// named arguments as named parameters (@name), and all others as positional
+// parameters (?).
+func (c *conn) query(query string, args []driver.NamedValue) *bigquery.Query {
+	q := c.c.client.Query(query)
+	if c.c.datasetID != "" {
+		q.DefaultProjectID, q.DefaultDatasetID = c.c.projectID, c.c.datasetID
+	}
+	for _, arg := range args {
+		q.Parameters = append(q.Parameters, bigquery.QueryParameter{
+			Name:  arg.Name,
+			Value: arg.Value,
+		})
+	}
+	return q
+}
+
+// stmt is a prepared statement.
+type stmt struct {
+	c     *conn
+	query string
+}
+
+// Close satisfies the driver.Stmt interface.
+func (s *stmt) Close() error {
+	return nil
+}
+
+// NumInput satisfies the driver.Stmt interface.
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+// Exec satisfies the driver.Stmt interface.
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.c.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+// Query satisfies the driver.Stmt interface.
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.c.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+// namedValues converts positional values to named values.
+func namedValues(args []driver.Value) []driver.NamedValue {
+	v := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		v[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return v
+}
+
+// rows are the rows of a query result, read from the row iterator as they
+// are needed, so that large results are streamed from the server.
+type rows struct {
+	it     *bigquery.RowIterator
+	schema bigquery.Schema
+	next   []bigquery.Value
+	err    error
+}
+
+// newRows creates rows for the row iterator. As the schema of a result may
+// only be available after reading the first row, the first row is read
+// ahead.
+func newRows(it *bigquery.RowIterator) (*rows, error) {
+	r := &rows{it: it}
+	switch r.err = it.Next(&r.next); {
+	case r.err == iterator.Done:
+		r.err = io.EOF
+	case r.err != nil:
+		return nil, r.err
+	}
+	r.schema = it.Schema
+	return r, nil
+}
+
+// Columns satisfies the driver.Rows interface.
+func (r *rows) Columns() []string {
+	cols := make([]string, len(r.schema))
+	for i, f := range r.schema {
+		cols[i] = f.Name
+	}
+	return cols
+}
+
+// ColumnTypeDatabaseTypeName satisfies the
+// driver.RowsColumnTypeDatabaseTypeName interface.
+func (r *rows) ColumnTypeDatabaseTypeName(i int) string {
+	return typeName(r.schema[i])
+}
+
+// ColumnTypeScanType satisfies the driver.RowsColumnTypeScanType interface.
+func (r *rows) ColumnTypeScanType(i int) reflect.Type {
+	f := r.schema[i]
+	if f.Repeated {
+		return reflect.TypeOf("")
+	}
+	switch f.Type {
+	case bigquery.IntegerFieldType:
+		return reflect.TypeOf(int64(0))
+	case bigquery.FloatFieldType:
+		return reflect.TypeOf(float64(0))
+	case bigquery.BooleanFieldType:
+		return reflect.TypeOf(false)
+	case bigquery.BytesFieldType:
+		return reflect.TypeOf([]byte(nil))
+	case bigquery.TimestampFieldType:
+		return reflect.TypeOf(time.Time{})
+	}
+	return reflect.TypeOf("")
+}
+
+// Close satisfies the driver.Rows interface.
+func (r *rows) Close() error {
+	r.next, r.err = nil, io.EOF
+	return nil
+}
+
+// Next satisfies the driver.Rows interface.
+func (r *rows) Next(dest []driver.Value) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i, f := range r.schema {
+		v, err := convert(r.next[i], f)
+		if err != nil {
+			return err
+		}
+		dest[i] = v
+	}
+	// read ahead, with any error reported by the following call
+	r.next = nil
+	if r.err = r.it.Next(&r.next); r.err == iterator.Done {
+		r.err = io.EOF
+	}
+	return nil
+}
+
+// typeName returns the type name of the field, where repeated fields are
+// ARRAY<type>.
+func typeName(f *bigquery.FieldSchema) string {
+	typ := string(f.Type)
+	if f.Repeated {
+		return "ARRAY<" + typ + ">"
+	}
+	return typ
+}
+
+// convert converts a BigQuery value to a driver value. Records and repeated
+// fields are converted to JSON.
+func convert(v bigquery.Value, f *bigquery.FieldSchema) (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if f.Repeated || f.Type == bigquery.RecordFieldType {
+		var buf bytes.Buffer
+		if err := writeJSON(&buf, v, f, f.Repeated); err != nil {
+			return nil, err
+		}
+		return buf.String(), nil
+	}
+	switch x := v.(type) {
+	case string, int64, float64, bool, []byte, time.Time:
+		return x, nil
+	case *big.Rat:
+		return numericString(x, f), nil
+	case fmt.Stringer:
+		return x.String(), nil
+	}
+	return fmt.Sprint(v), nil
+}
+
+// numericString formats a NUMERIC or BIGNUMERIC value, without trailing
+// zeros.
+func numericString(x *big.Rat, f *bigquery.FieldSchema) string {
+	s := bigquery.NumericString(x)
+	if f.Type == bigquery.BigNumericFieldType {
+		s = bigquery.BigNumericString(x)
+	}
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+// writeJSON writes the value of the field as JSON, preserving the order of
+// the fields of records.
+func writeJSON(buf *bytes.Buffer, v bigquery.Value, f *bigquery.FieldSchema, repeated bool) error {
+	switch x := v.(type) {
+	case nil:
+		buf.WriteString("null")
+		return nil
+	case []bigquery.Value:
+		if repeated {
+			buf.WriteByte('[')
+			for i, z := range x {
+				if i != 0 {
+					buf.WriteByte(',')
+				}
+				if err := writeJSON(buf, z, f, false); err != nil {
+					return err
+				}
+			}
+			buf.WriteByte(']')
+			return nil
+		}
+		// record
+		buf.WriteByte('{')
+		for i, z := range x {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			name, err := json.Marshal(f.Schema[i].Name)
+			if err != nil {
+				return err
+			}
+			buf.Write(name)
+			buf.WriteByte(':')
+			if err := writeJSON(buf, z, f.Schema[i], f.Schema[i].Repeated); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	case string:
+		if f.Type == bigquery.JSONFieldType && json.Valid([]byte(x)) {
+			buf.WriteString(x)
+			return nil
+		}
+	case *big.Rat:
+		buf.WriteString(numericString(x, f))
+		return nil
+	case float64:
+		// NaN and infinity are not valid JSON numbers
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			v = strconv.FormatFloat(x, 'g', -1, 64)
+		}
+	case fmt.Stringer:
+		v = x.String()
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	return nil
+}
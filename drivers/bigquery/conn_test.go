@@ -0,0 +1,52 @@
+package bigquery
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+)
+
+func TestConvert(t *testing.T) {
+	author := &bigquery.FieldSchema{
+		Name: "author",
+		Type: bigquery.RecordFieldType,
+		Schema: bigquery.Schema{
+			{Name: "name", Type: bigquery.StringFieldType},
+			{Name: "born", Type: bigquery.DateFieldType},
+			{Name: "tags", Type: bigquery.StringFieldType, Repeated: true},
+			{Name: "meta", Type: bigquery.JSONFieldType},
+		},
+	}
+	tests := []struct {
+		v   bigquery.Value
+		f   *bigquery.FieldSchema
+		exp interface{}
+	}{
+		{nil, &bigquery.FieldSchema{Type: bigquery.StringFieldType}, nil},
+		{int64(15), &bigquery.FieldSchema{Type: bigquery.IntegerFieldType}, int64(15)},
+		{big.NewRat(5, 2), &bigquery.FieldSchema{Type: bigquery.NumericFieldType}, "2.5"},
+		{civil.Date{Year: 2004, Month: 5, Day: 1}, &bigquery.FieldSchema{Type: bigquery.DateFieldType}, "2004-05-01"},
+		{
+			[]bigquery.Value{1.5, math.Inf(1), nil},
+			&bigquery.FieldSchema{Type: bigquery.FloatFieldType, Repeated: true},
+			`[1.5,"+Inf",null]`,
+		},
+		{
+			[]bigquery.Value{"Ann", civil.Date{Year: 1970, Month: 1, Day: 2}, []bigquery.Value{"a", "b"}, `{"x": 1}`},
+			author,
+			`{"name":"Ann","born":"1970-01-02","tags":["a","b"],"meta":{"x": 1}}`,
+		},
+	}
+	for i, test := range tests {
+		v, err := convert(test.v, test.f)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if v != test.exp {
+			t.Errorf("test %d expected %v, got: %v", i, test.exp, v)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package bigquery
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/drivers/metadata"
+	"google.golang.org/api/iterator"
+)
+
+type metaReader struct {
+	metadata.LoggingReader
+	db drivers.DB
+}
+
+var _ metadata.CatalogReader = &metaReader{}
+var _ metadata.SchemaReader = &metaReader{}
+var _ metadata.TableReader = &metaReader{}
+var _ metadata.ColumnReader = &metaReader{}
+
+// NewReader creates a metadata reader for BigQuery, where datasets are
+// treated as both catalogs and schemas.
+func NewReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
+	return metadata.NewPluginReader(&metaReader{
+		LoggingReader: metadata.NewLoggingReader(db, opts...),
+		db:            db,
+	})
+}
+
+func (r metaReader) Catalogs(f metadata.Filter) (*metadata.CatalogSet, error) {
+	names, err := r.datasets(f.Name, true)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]metadata.Catalog, len(names))
+	for i, name := range names {
+		results[i] = metadata.Catalog{Catalog: name}
+	}
+	return metadata.NewCatalogSet(results), nil
+}
+
+func (r metaReader) Schemas(f metadata.Filter) (*metadata.SchemaSet, error) {
+	names, err := r.datasets(f.Name, f.WithSystem)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]metadata.Schema, len(names))
+	for i, name := range names {
+		results[i] = metadata.Schema{Schema: name}
+	}
+	return metadata.NewSchemaSet(results), nil
+}
+
+// Tables lists the tables of the datasets matching the schema pattern, or of
+// the connection's dataset, or when the connection has no dataset, of all
+// datasets.
+func (r metaReader) Tables(f metadata.Filter) (*metadata.TableSet, error) {
+	c, err := r.connector()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	switch {
+	case f.Schema != "":
+		names, err = r.datasets(f.Schema, f.WithSystem)
+	case c.datasetID != "":
+		names = []string{c.datasetID}
+	default:
+		names, err = r.datasets("", f.WithSystem)
+	}
+	if err != nil {
+		return nil, err
+	}
+	results := []metadata.Table{}
+	for _, name := range names {
+		tables, err := r.tables(c.projectID, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range tables {
+			if match(f.Name, table.Name) && (len(f.Types) == 0 || contains(f.Types, table.Type)) {
+				results = append(results, table)
+			}
+		}
+	}
+	return metadata.NewTableSet(results), nil
+}
+
+// Columns lists the fields of a table, where the fields of records are
+// flattened, and named by their path (record.field).
+func (r metaReader) Columns(f metadata.Filter) (*metadata.ColumnSet, error) {
+	c, err := r.connector()
+	if err != nil {
+		return nil, err
+	}
+	name := f.Schema
+	if name == "" {
+		name = c.datasetID
+	}
+	md, err := c.client.Dataset(name).Table(f.Parent).Metadata(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	results := []metadata.Column{}
+	var add func(string, bigquery.Schema)
+	add = func(prefix string, schema bigquery.Schema) {
+		for _, field := range schema {
+			if match(f.Name, prefix+field.Name) {
+				nullable := metadata.YES
+				if field.Required || field.Repeated {
+					nullable = metadata.NO
+				}
+				results = append(results, metadata.Column{
+					Catalog:         c.projectID,
+					Schema:          name,
+					Table:           f.Parent,
+					Name:            prefix + field.Name,
+					OrdinalPosition: len(results) + 1,
+					DataType:        typeName(field),
+					Default:         field.DefaultValueExpression,
+					ColumnSize:      int(field.MaxLength),
+					DecimalDigits:   int(field.Scale),
+					IsNullable:      nullable,
+				})
+			}
+			if field.Type == bigquery.RecordFieldType {
+				add(prefix+field.Name+".", field.Schema)
+			}
+		}
+	}
+	add("", md.Schema)
+	return metadata.NewColumnSet(results), nil
+}
+
+// tableTypes are the table types reported by BigQuery, mapped to the types
+// used by the metadata writer.
+var tableTypes = map[string]string{
+	"BASE TABLE": "TABLE",
+	"CLONE":      "TABLE",
+	"SNAPSHOT":   "TABLE",
+	"EXTERNAL":   "TABLE",
+}
+
+// tables returns the tables in the dataset.
+func (r metaReader) tables(projectID, datasetID string) ([]metadata.Table, error) {
+	qstr := fmt.Sprintf("SELECT table_name, table_type FROM `%s.%s`.INFORMATION_SCHEMA.TABLES ORDER BY table_name", projectID, datasetID)
+	rows, closeRows, err := r.Query(qstr)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	var results []metadata.Table
+	for rows.Next() {
+		t := metadata.Table{Catalog: projectID, Schema: datasetID}
+		if err := rows.Scan(&t.Name, &t.Type); err != nil {
+			return nil, err
+		}
+		if typ, ok := tableTypes[t.Type]; ok {
+			t.Type = typ
+		}
+		results = append(results, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// datasets returns the names of the datasets matching the pattern, including
+// hidden datasets (starting with _) when withSystem is true.
+func (r metaReader) datasets(pattern string, withSystem bool) ([]string, error) {
+	c, err := r.connector()
+	if err != nil {
+		return nil, err
+	}
+	it := c.client.Datasets(context.Background())
+	it.ListHidden = withSystem
+	var names []string
+	for {
+		ds, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if match(pattern, ds.DatasetID) {
+			names = append(names, ds.DatasetID)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// connector returns the connector of the database, as listing datasets and
+// reading table schemas uses the BigQuery API.
+func (r metaReader) connector() (*connector, error) {
+	db, ok := r.db.(interface {
+		Conn(context.Context) (*sql.Conn, error)
+	})
+	if !ok {
+		return nil, errors.New("not a BigQuery database")
+	}
+	sc, err := db.Conn(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer sc.Close()
+	var c *connector
+	err = sc.Raw(func(v interface{}) error {
+		z, ok := v.(*conn)
+		if !ok {
+			return errors.New("not a BigQuery connection")
+		}
+		c = z.c
+		return nil
+	})
+	return c, err
+}
+
+// match returns true when s matches the SQL LIKE pattern.
+func match(pattern, s string) bool {
+	if pattern == "" {
+		return true
+	}
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, c := range pattern {
+		switch c {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	ok, _ := regexp.MatchString(sb.String(), s)
+	return ok
+}
+
+// contains returns true when v contains s.
+func contains(v []string, s string) bool {
+	for _, z := range v {
+		if z == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+// Package clickhouse defines and registers usql's ClickHouse driver.
+//
+// See: https://github.com/ClickHouse/clickhouse-go
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2" // DRIVER
+	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/drivers/metadata"
+)
+
+func init() {
+	drivers.Register("clickhouse", drivers.Driver{
+		AllowMultilineComments: true,
+		Version: func(ctx context.Context, db drivers.DB) (string, error) {
+			var ver string
+			if err := db.QueryRowContext(ctx, `SELECT version()`).Scan(&ver); err != nil {
+				return "", err
+			}
+			return "ClickHouse " + ver, nil
+		},
+		User: func(ctx context.Context, db drivers.DB) (string, error) {
+			var user string
+			if err := db.QueryRowContext(ctx, `SELECT currentUser()`).Scan(&user); err != nil {
+				return "", err
+			}
+			return user, nil
+		},
+		ChangePassword: func(db drivers.DB, user, newpw, _ string) error {
+			_, err := db.Exec(`ALTER USER ` + user + ` IDENTIFIED BY '` + newpw + `'`)
+			return err
+		},
+		IsPasswordErr: func(err error) bool {
+			if e, ok := err.(*clickhouse.Exception); ok {
+				return e.Code == 516
+			}
+			return false
+		},
+		RowsAffected: func(sql.Result) (int64, error) {
+			return 0, nil
+		},
+		Err: func(err error) (string, string) {
+			if e, ok := err.(*clickhouse.Exception); ok {
+				return strconv.Itoa(int(e.Code)), strings.TrimSpace(e.Message)
+			}
+			return "", err.Error()
+		},
+		// rows are read from the server in blocks, so avoid buffering the
+		// entire result set when encoding results
+		BufferRows:        1000,
+		NewMetadataReader: NewReader,
+		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
+			writerOpts := []metadata.WriterOption{
+				metadata.WithSystemSchemas([]string{"system", "information_schema", "INFORMATION_SCHEMA"}),
+				metadata.WithTableDetails(func(out io.Writer, schema, table string) error {
+					return describeTable(db, out, schema, table)
+				}),
+			}
+			return metadata.NewDefaultWriter(NewReader(db, opts...), writerOpts...)(db, w)
+		},
+		Copy: drivers.CopyWithInsert(func(int) string { return "?" }),
+	})
+}
+
+// describeTable writes the engine, partition key, and sorting key of a
+// table.
+func describeTable(db drivers.DB, w io.Writer, schema, table string) error {
+	schemaExpr, vals := "currentDatabase()", []interface{}{table}
+	if schema != "" {
+		schemaExpr, vals = "?", append(vals, schema)
+	}
+	var engine, partitionKey, sortingKey string
+	err := db.QueryRow(`SELECT engine, partition_key, sorting_key
+FROM system.tables
+WHERE name = ? AND database = `+schemaExpr, vals...).Scan(&engine, &partitionKey, &sortingKey)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil
+	case err != nil:
+		return err
+	}
+	for _, v := range []struct {
+		name, value string
+	}{
+		{"Engine", engine},
+		{"Partition key", partitionKey},
+		{"Sorting key", sortingKey},
+	} {
+		if v.value == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", v.name, v.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package clickhouse
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/drivers/metadata"
+)
+
+type metaReader struct {
+	metadata.LoggingReader
+	limit int
+}
+
+var _ metadata.SchemaReader = &metaReader{}
+var _ metadata.TableReader = &metaReader{}
+var _ metadata.ColumnReader = &metaReader{}
+var _ metadata.MaterializedViewReader = &metaReader{}
+
+// NewReader creates a metadata reader for ClickHouse, reading from the system
+// tables.
+func NewReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
+	return metadata.NewPluginReader(&metaReader{
+		LoggingReader: metadata.NewLoggingReader(db, opts...),
+	})
+}
+
+func (r *metaReader) SetLimit(l int) {
+	r.limit = l
+}
+
+func (r metaReader) Schemas(f metadata.Filter) (*metadata.SchemaSet, error) {
+	qstr := `SELECT
+  name,
+  ''
+FROM system.databases
+`
+	conds, vals := r.conditions(f, "name", "")
+	rows, closeRows, err := r.query(qstr, conds, "name", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewSchemaSet([]metadata.Schema{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Schema{}
+	for rows.Next() {
+		rec := metadata.Schema{}
+		err = rows.Scan(&rec.Schema, &rec.Catalog)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewSchemaSet(results), nil
+}
+
+func (r metaReader) Tables(f metadata.Filter) (*metadata.TableSet, error) {
+	qstr := `SELECT
+  database,
+  name,
+  type,
+  rows,
+  size,
+  comment
+FROM (
+  SELECT
+    database,
+    name,
+    multiIf(engine = 'MaterializedView', 'MATERIALIZED VIEW', engine LIKE '%View', 'VIEW', is_temporary, 'LOCAL TEMPORARY', database = 'system', 'SYSTEM TABLE', 'TABLE') AS type,
+    toInt64(COALESCE(total_rows, 0)) AS rows,
+    formatReadableSize(COALESCE(total_bytes, 0)) AS size,
+    comment
+  FROM system.tables
+)
+`
+	conds, vals := r.conditions(f, "database", "name")
+	if len(f.Types) != 0 {
+		pholders := []string{"''"}
+		for _, t := range f.Types {
+			vals = append(vals, t)
+			pholders = append(pholders, "?")
+		}
+		conds = append(conds, fmt.Sprintf("type IN (%s)", strings.Join(pholders, ", ")))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "database, type, name", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewTableSet([]metadata.Table{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Table{}
+	for rows.Next() {
+		rec := metadata.Table{}
+		err = rows.Scan(&rec.Schema, &rec.Name, &rec.Type, &rec.Rows, &rec.Size, &rec.Comment)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewTableSet(results), nil
+}
+
+func (r metaReader) Columns(f metadata.Filter) (*metadata.ColumnSet, error) {
+	qstr := `SELECT
+  database,
+  table,
+  name,
+  toInt64(position),
+  type,
+  default_expression,
+  toInt64(COALESCE(numeric_precision, character_octet_length, 0)),
+  toInt64(COALESCE(numeric_scale, 0)),
+  toInt64(COALESCE(numeric_precision_radix, 10)),
+  toInt64(COALESCE(character_octet_length, 0)),
+  if(startsWith(type, 'Nullable('), 'YES', 'NO')
+FROM system.columns
+`
+	conds, vals := r.conditions(metadata.Filter{
+		Schema:      f.Schema,
+		Name:        f.Parent,
+		WithSystem:  f.WithSystem,
+		OnlyVisible: f.OnlyVisible,
+	}, "database", "table")
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, "name LIKE ?")
+	}
+	rows, closeRows, err := r.query(qstr, conds, "database, table, position", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewColumnSet([]metadata.Column{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Column{}
+	for rows.Next() {
+		rec := metadata.Column{}
+		err = rows.Scan(
+			&rec.Schema,
+			&rec.Table,
+			&rec.Name,
+			&rec.OrdinalPosition,
+			&rec.DataType,
+			&rec.Default,
+			&rec.ColumnSize,
+			&rec.DecimalDigits,
+			&rec.NumPrecRadix,
+			&rec.CharOctetLength,
+			&rec.IsNullable,
+		)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewColumnSet(results), nil
+}
+
+func (r metaReader) MaterializedViews(f metadata.Filter) (*metadata.MaterializedViewSet, error) {
+	qstr := `SELECT
+  database,
+  name,
+  as_select
+FROM system.tables
+`
+	conds, vals := r.conditions(f, "database", "name")
+	conds = append(conds, "engine = 'MaterializedView'")
+	rows, closeRows, err := r.query(qstr, conds, "database, name", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewMaterializedViewSet([]metadata.MaterializedView{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.MaterializedView{}
+	for rows.Next() {
+		rec := metadata.MaterializedView{}
+		err = rows.Scan(&rec.Schema, &rec.Name, &rec.Definition)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewMaterializedViewSet(results), nil
+}
+
+// conditions builds the conditions filtering by the database (schema) and
+// name columns.
+func (r metaReader) conditions(f metadata.Filter, schemaCol, nameCol string) ([]string, []interface{}) {
+	var conds []string
+	var vals []interface{}
+	if f.OnlyVisible {
+		conds = append(conds, schemaCol+" = currentDatabase()")
+	}
+	if !f.WithSystem {
+		conds = append(conds, schemaCol+" NOT IN ('system', 'information_schema', 'INFORMATION_SCHEMA')")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, schemaCol+" LIKE ?")
+	}
+	if f.Name != "" {
+		col := nameCol
+		if col == "" {
+			col = schemaCol
+		}
+		vals = append(vals, f.Name)
+		conds = append(conds, col+" LIKE ?")
+	}
+	return conds, vals
+}
+
+func (r metaReader) query(qstr string, conds []string, order string, vals ...interface{}) (*sql.Rows, func(), error) {
+	if len(conds) != 0 {
+		qstr += "\nWHERE " + strings.Join(conds, " AND ")
+	}
+	if order != "" {
+		qstr += "\nORDER BY " + order
+	}
+	if r.limit != 0 {
+		qstr += fmt.Sprintf("\nLIMIT %d", r.limit)
+	}
+	return r.Query(qstr, vals...)
+}
@@ -123,8 +123,10 @@ func NewDefaultCompleter(opts ...Option) readline.AutoCompleter {
 			`\!`,
 			`\?`,
 			`\a`,
+			`\batch`,
 			`\begin`,
 			`\c`,
+			`\clist`,
 			`\connect`,
 			`\C`,
 			`\cd`,
@@ -132,11 +134,21 @@ func NewDefaultCompleter(opts ...Option) readline.AutoCompleter {
 			`\conninfo`,
 			`\copyright`,
 			`\copy`,
+			`\cset`,
 			`\d+`,
 			`\da+`,
 			`\da`,
 			`\daS+`,
 			`\daS`,
+			`\dconfig+`,
+			`\dconfig`,
+			`\delcmd`,
+			`\des+`,
+			`\des`,
+			`\det+`,
+			`\det`,
+			`\dew+`,
+			`\dew`,
 			`\df+`,
 			`\df`,
 			`\dfS+`,
@@ -153,6 +165,12 @@ func NewDefaultCompleter(opts ...Option) readline.AutoCompleter {
 			`\dn`,
 			`\dnS+`,
 			`\dnS`,
+			`\dos`,
+			`\dosS`,
+			`\dp+`,
+			`\dp`,
+			`\dpS+`,
+			`\dpS`,
 			`\drivers`,
 			`\ds+`,
 			`\ds`,
@@ -168,20 +186,39 @@ func NewDefaultCompleter(opts ...Option) readline.AutoCompleter {
 			`\dv`,
 			`\dvS+`,
 			`\dvS`,
+			`\dx+`,
+			`\dx`,
 			`\e`,
 			`\echo`,
+			`\elif`,
+			`\encoding`,
+			`\else`,
+			`\endbatch`,
+			`\endif`,
+			`\endloop`,
+			`\errverbose`,
+			`\eval`,
+			`\export`,
 			`\f`,
 			`\g`,
+			`\gdesc`,
 			`\gexec`,
+			`\gjson`,
+			`\graph`,
 			`\gset`,
+			`\gstore`,
 			`\gx`,
 			`\H`,
 			`\i`,
+			`\if`,
 			`\ir`,
 			`\l+`,
 			`\l`,
+			`\loop`,
+			`\newcmd`,
 			`\p`,
 			`\password`,
+			`\profile`,
 			`\prompt`,
 			`\pset`,
 			`\q`,
@@ -190,13 +227,16 @@ func NewDefaultCompleter(opts ...Option) readline.AutoCompleter {
 			`\rollback`,
 			`\set`,
 			`\setenv`,
+			`\sf`,
 			`\t`,
 			`\T`,
 			`\timing`,
 			`\unset`,
 			`\w`,
+			`\waitfor`,
 			`\watch`,
 			`\x`,
+			`\z`,
 			`\Z`,
 		},
 	}
@@ -482,22 +522,26 @@ func (c completer) complete(previousWords []string, text []rune) [][]rune {
 		return c.completeWithCatalogs(text)
 	}
 	if TailMatches(MATCH_CASE, previousWords, `\pset`) {
-		return CompleteFromList(text, `border`, `columns`, `expanded`, `fieldsep`, `fieldsep_zero`,
-			`footer`, `format`, `linestyle`, `null`, `numericlocale`, `pager`, `pager_min_lines`,
-			`recordsep`, `recordsep_zero`, `tableattr`, `title`, `title`, `tuples_only`,
+		return CompleteFromList(text, `border`, `columns`, `describe_exact_rows`, `describe_size`, `exit_on_empty`, `exit_on_rows`, `expanded`, `fieldsep`, `fieldsep_zero`,
+			`footer`, `format`, `fwf_align`, `fwf_widths`, `json_indent`, `json_pretty`, `linestyle`, `max_col_width`, `max_col_width_exclude`, `null`,
+			`numericlocale`, `pager`, `pager_min_lines`,
+			`recordsep`, `recordsep_zero`, `tableattr`, `timing_file`, `timing_format`, `title`, `title`, `tuples_only`,
 			`unicode_border_linestyle`, `unicode_column_linestyle`, `unicode_header_linestyle`)
 	}
 	if TailMatches(MATCH_CASE, previousWords, `\pset`, `expanded`) {
 		return CompleteFromList(text, "auto", "on", "off")
 	}
+	if TailMatches(MATCH_CASE, previousWords, `\pset`, `timing_format`) {
+		return CompleteFromList(text, "json", "text")
+	}
 	if TailMatches(MATCH_CASE, previousWords, `\pset`, `pager`) {
 		return CompleteFromList(text, "always", "on", "off")
 	}
-	if TailMatches(MATCH_CASE, previousWords, `\pset`, `fieldsep_zero|footer|numericlocale|pager|recordsep_zero|tuples_only`) {
+	if TailMatches(MATCH_CASE, previousWords, `\pset`, `fieldsep_zero|footer|json_pretty|numericlocale|pager|recordsep_zero|tuples_only`) {
 		return CompleteFromList(text, "on", "off")
 	}
 	if TailMatches(MATCH_CASE, previousWords, `\pset`, `format`) {
-		return CompleteFromList(text, "unaligned", "aligned", "wrapped", "html", "asciidoc", "latex", "latex-longtable", "troff-ms", "csv", "json", "vertical")
+		return CompleteFromList(text, "unaligned", "aligned", "wrapped", "html", "asciidoc", "latex", "latex-longtable", "troff-ms", "csv", "json", "vertical", "fwf")
 	}
 	if TailMatches(MATCH_CASE, previousWords, `\pset`, `linestyle`) {
 		return CompleteFromList(text, "ascii", "old-ascii", "unicode")
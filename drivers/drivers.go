@@ -4,11 +4,15 @@ package drivers
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 	"unicode"
@@ -17,7 +21,9 @@ import (
 	"github.com/alecthomas/chroma/v2/lexers"
 	"github.com/gohxs/readline"
 	"github.com/xo/dburl"
+	"github.com/rmasci/usql/copyfile"
 	"github.com/rmasci/usql/drivers/completer"
+	"github.com/rmasci/usql/drivers/explain"
 	"github.com/rmasci/usql/drivers/metadata"
 	"github.com/rmasci/usql/stmt"
 	"github.com/rmasci/usql/text"
@@ -69,6 +75,8 @@ type Driver struct {
 	Version func(context.Context, DB) (string, error)
 	// User will be used by User if defined.
 	User func(context.Context, DB) (string, error)
+	// TLS will be used by TLS if defined.
+	TLS func(context.Context, DB) (*TLSInfo, error)
 	// ChangePassword will be used by ChangePassword if defined.
 	ChangePassword func(DB, string, string, string) error
 	// IsPasswordErr will be used by IsPasswordErr if defined.
@@ -81,6 +89,9 @@ type Driver struct {
 	RowsAffected func(sql.Result) (int64, error)
 	// Err will be used by Error.Error if defined.
 	Err func(error) (string, string)
+	// ErrDetails will be used by ErrDetails if defined, returning the
+	// additional detail fields (such as a hint) of a driver error.
+	ErrDetails func(error) []ErrDetail
 	// ConvertBytes will be used by ConvertBytes to convert a raw []byte
 	// slice to a string if defined.
 	ConvertBytes func([]byte, string) (string, error)
@@ -98,6 +109,14 @@ type Driver struct {
 	BatchAsTransaction bool
 	// BatchQueryPrefixes will be used by BatchQueryPrefixes if defined.
 	BatchQueryPrefixes map[string]string
+	// Placeholder returns the query parameter placeholder for the n-th (1
+	// based) parameter, used when binding variables. Defaults to "?".
+	Placeholder func(n int) string
+	// BufferRows is the number of rows buffered when encoding query results
+	// (used to size columns) before they are written. Drivers returning large
+	// result sets set this so results are streamed. Defaults to buffering all
+	// rows.
+	BufferRows int
 	// NewMetadataReader returns a db metadata introspector.
 	NewMetadataReader func(db DB, opts ...metadata.ReaderOption) metadata.Reader
 	// NewMetadataWriter returns a db metadata printer.
@@ -106,6 +125,30 @@ type Driver struct {
 	NewCompleter func(db DB, opts ...completer.Option) readline.AutoCompleter
 	// Copy rows into the database table
 	Copy func(ctx context.Context, db *sql.DB, rows *sql.Rows, table string) (int64, error)
+	// CopyFile natively imports the file at path (in format csv or parquet)
+	// into the database table columns, or into the table columns named by the
+	// file's columns when columns is empty.
+	CopyFile func(ctx context.Context, db *sql.DB, path, format, table string, columns []string) (int64, error)
+	// Upsert returns the query inserting rows rows into the table columns,
+	// where a row conflicting on the target columns with an existing row
+	// updates the existing row, or is ignored when target is empty (see the
+	// \copy on_conflict option).
+	Upsert func(table string, columns, target []string, rows int) string
+	// Explain executes the query with the database's EXPLAIN ANALYZE,
+	// returning the parsed execution plan.
+	Explain func(ctx context.Context, db DB, sqlstr string, args ...interface{}) (*explain.Plan, error)
+	// Cursor declares a server-side cursor for the query, returning a func
+	// fetching the next fetchSize rows of the cursor, and a func closing the
+	// cursor.
+	Cursor func(ctx context.Context, db DB, sqlstr string, fetchSize int) (func() (*sql.Rows, error), func() error, error)
+	// Limit wraps the query, returning at most n of its rows (see \g LIMIT),
+	// or false when the limit cannot be applied to the query. Defaults to
+	// LimitWithLimit.
+	Limit func(sqlstr string, n int) (string, bool)
+	// DataTypes are the database types of the column types inferred when
+	// creating a table for an imported file (see the \copy create option),
+	// overriding DefaultDataTypes.
+	DataTypes map[string]string
 }
 
 // drivers are registered drivers.
@@ -145,6 +188,23 @@ func LowerColumnNames(u *dburl.URL) bool {
 	return false
 }
 
+// Placeholder returns the query parameter placeholder func for a driver.
+func Placeholder(u *dburl.URL) func(int) string {
+	if d, ok := drivers[u.Driver]; ok && d.Placeholder != nil {
+		return d.Placeholder
+	}
+	return func(int) string { return "?" }
+}
+
+// BufferRows returns the number of rows to buffer when encoding query results
+// for a driver.
+func BufferRows(u *dburl.URL) int {
+	if d, ok := drivers[u.Driver]; ok {
+		return d.BufferRows
+	}
+	return 0
+}
+
 // UseColumnTypes returns whether or not a driver should uses column types.
 func UseColumnTypes(u *dburl.URL) bool {
 	if d, ok := drivers[u.Driver]; ok {
@@ -245,6 +305,26 @@ func User(ctx context.Context, u *dburl.URL, db DB) (string, error) {
 	return user, nil
 }
 
+// TLSInfo is the TLS (SSL) status of a database connection.
+type TLSInfo struct {
+	// Encrypted is true when the connection is encrypted.
+	Encrypted bool
+	// Version is the TLS protocol version, when known.
+	Version string
+	// Cipher is the cipher, when known.
+	Cipher string
+}
+
+// TLS returns the TLS status of the database connection for a driver, or nil
+// when the driver does not expose it.
+func TLS(ctx context.Context, u *dburl.URL, db DB) (*TLSInfo, error) {
+	if d, ok := drivers[u.Driver]; ok && d.TLS != nil {
+		info, err := d.TLS(ctx, db)
+		return info, WrapErr(u.Driver, err)
+	}
+	return nil, nil
+}
+
 // Process processes the sql query for a driver.
 func Process(u *dburl.URL, prefix, sqlstr string) (string, string, bool, error) {
 	if d, ok := drivers[u.Driver]; ok && d.Process != nil {
@@ -457,6 +537,57 @@ func ForceQueryParameters(params []string) func(*dburl.URL) {
 	}
 }
 
+// ClientTLSParams are the URL query parameters read by ClientTLSConfig.
+var ClientTLSParams = []string{
+	"sslrootcert", "ssl-ca",
+	"sslcert", "ssl-cert",
+	"sslkey", "ssl-key",
+}
+
+// ClientTLSConfig builds a TLS configuration from the sslrootcert, sslcert,
+// and sslkey query parameters of the URL (or their MySQL style equivalents
+// ssl-ca, ssl-cert, and ssl-key). Returns nil when none of the parameters are
+// present.
+func ClientTLSConfig(u *dburl.URL) (*tls.Config, error) {
+	q := u.Query()
+	param := func(names ...string) string {
+		for _, name := range names {
+			if v := q.Get(name); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+	rootCert, cert, key := param("sslrootcert", "ssl-ca"), param("sslcert", "ssl-cert"), param("sslkey", "ssl-key")
+	if rootCert == "" && cert == "" && key == "" {
+		return nil, nil
+	}
+	cfg := &tls.Config{
+		ServerName: u.Hostname(),
+	}
+	if rootCert != "" {
+		buf, err := os.ReadFile(rootCert)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(buf) {
+			return nil, text.ErrInvalidRootCertificate
+		}
+	}
+	switch {
+	case cert != "" && key != "":
+		pair, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	case cert != "" || key != "":
+		return nil, text.ErrMissingClientCertificate
+	}
+	return cfg, nil
+}
+
 // NewMetadataReader wraps creating a new database introspector for a driver.
 func NewMetadataReader(ctx context.Context, u *dburl.URL, db DB, w io.Writer, opts ...metadata.ReaderOption) (metadata.Reader, error) {
 	d, ok := drivers[u.Driver]
@@ -525,6 +656,244 @@ func Copy(ctx context.Context, u *dburl.URL, stdout, stderr func() io.Writer, ro
 	return d.Copy(ctx, db, rows, table)
 }
 
+// CopyDB copies the result set to the destination sql.DB, using the copy
+// handler for the URL's driver.
+func CopyDB(ctx context.Context, u *dburl.URL, db *sql.DB, rows *sql.Rows, table string) (int64, error) {
+	d, ok := drivers[u.Driver]
+	if !ok {
+		return 0, WrapErr(u.Driver, text.ErrDriverNotAvailable)
+	}
+	if d.Copy == nil {
+		return 0, fmt.Errorf(text.NotSupportedByDriver, "copy", u.Driver)
+	}
+	return d.Copy(ctx, db, rows, table)
+}
+
+// Upsert returns the query inserting rows rows into the table columns,
+// updating the existing row when a row conflicts on the target columns, or
+// ignoring the row when target is empty.
+func Upsert(u *dburl.URL, table string, columns, target []string, rows int) (string, error) {
+	d, ok := drivers[u.Driver]
+	if !ok {
+		return "", WrapErr(u.Driver, text.ErrDriverNotAvailable)
+	}
+	if d.Upsert == nil {
+		return "", fmt.Errorf(text.NotSupportedByDriver, "on_conflict", u.Driver)
+	}
+	return d.Upsert(table, columns, target, rows), nil
+}
+
+// CopyFile natively imports the file at path into the database table, if
+// supported by the URL's driver. Returns false when the driver does not
+// support native file imports.
+func CopyFile(ctx context.Context, u *dburl.URL, db *sql.DB, path, format, table string, columns []string) (int64, bool, error) {
+	d, ok := drivers[u.Driver]
+	if !ok || d.CopyFile == nil {
+		return 0, false, nil
+	}
+	n, err := d.CopyFile(ctx, db, path, format, table, columns)
+	return n, true, err
+}
+
+// Cursor declares a server-side cursor for the query, if supported by the
+// URL's driver, returning a func fetching the next fetchSize rows of the
+// cursor, and a func closing the cursor. Returns false when the driver does
+// not support cursors, or the query is not a SELECT, TABLE, or VALUES query.
+func Cursor(ctx context.Context, u *dburl.URL, db DB, sqlstr string, fetchSize int) (func() (*sql.Rows, error), func() error, bool, error) {
+	d, ok := drivers[u.Driver]
+	if !ok || d.Cursor == nil || fetchSize == 0 {
+		return nil, nil, false, nil
+	}
+	switch prefix, _, _ := strings.Cut(stmt.FindPrefix(sqlstr, true, true, true), " "); prefix {
+	case "SELECT", "TABLE", "VALUES":
+	default:
+		return nil, nil, false, nil
+	}
+	fetch, closeCursor, err := d.Cursor(ctx, db, sqlstr, fetchSize)
+	return fetch, closeCursor, true, err
+}
+
+// Limit wraps the query, returning at most n of its rows, using the URL's
+// driver's limit syntax. Returns false when the query is not a SELECT, TABLE,
+// VALUES, or WITH query, or when the driver cannot apply the limit to the
+// query, leaving the query unchanged.
+func Limit(u *dburl.URL, sqlstr string, n int) (string, bool) {
+	switch prefix, _, _ := strings.Cut(stmt.FindPrefix(sqlstr, true, true, true), " "); prefix {
+	case "SELECT", "TABLE", "VALUES", "WITH":
+	default:
+		return sqlstr, false
+	}
+	limit := LimitWithLimit
+	if d, ok := drivers[u.Driver]; ok && d.Limit != nil {
+		limit = d.Limit
+	}
+	if s, ok := limit(strings.TrimRight(strings.TrimSpace(sqlstr), ";"), n); ok {
+		return s, true
+	}
+	return sqlstr, false
+}
+
+// DefaultDataTypes are the default database types of the column types
+// inferred when creating a table for an imported file.
+var DefaultDataTypes = map[string]string{
+	"int":         "BIGINT",
+	"float":       "DOUBLE PRECISION",
+	"bool":        "BOOLEAN",
+	"date":        "DATE",
+	"timestamp":   "TIMESTAMP",
+	"timestamptz": "TIMESTAMP WITH TIME ZONE",
+	"text":        "TEXT",
+}
+
+// DataType returns the database type of the column type inferred when
+// creating a table for an imported file, using the URL's driver's data
+// types.
+func DataType(u *dburl.URL, typ string) string {
+	if d, ok := drivers[u.Driver]; ok {
+		if s, ok := d.DataTypes[typ]; ok {
+			return s
+		}
+	}
+	if s, ok := DefaultDataTypes[typ]; ok {
+		return s
+	}
+	return DefaultDataTypes["text"]
+}
+
+// LimitWithLimit wraps the query in a subquery limited with LIMIT, as
+// supported by PostgreSQL, MySQL, and SQLite. The query is on its own lines,
+// so that a trailing line comment does not comment out the limit.
+func LimitWithLimit(sqlstr string, n int) (string, bool) {
+	return fmt.Sprintf("SELECT * FROM (\n%s\n) _ LIMIT %d", sqlstr, n), true
+}
+
+// LimitWithTop limits the query as supported by SQL Server, which does not
+// allow a subquery to be ordered or to have a WITH clause. A SELECT query
+// having neither set operators nor TOP is limited with TOP, and other ordered
+// queries with OFFSET 0 ROWS FETCH NEXT (which cannot fetch 0 rows). Other
+// queries cannot be limited.
+func LimitWithTop(sqlstr string, n int) (string, bool) {
+	words := topLevelWords(sqlstr)
+	var orderBy, offset, trailing, setOp bool
+	for i, w := range words {
+		switch w.word {
+		case "ORDER":
+			orderBy = orderBy || i+1 < len(words) && words[i+1].word == "BY"
+		case "OFFSET", "FETCH":
+			// TOP cannot be combined with OFFSET
+			offset = true
+		case "FOR", "OPTION":
+			// OFFSET cannot be appended after these clauses
+			trailing = true
+		case "UNION", "EXCEPT", "INTERSECT":
+			setOp = true
+		}
+	}
+	if !setOp && !offset && len(words) != 0 && words[0].word == "SELECT" {
+		i := 1
+		if i < len(words) && (words[i].word == "DISTINCT" || words[i].word == "ALL") {
+			i++
+		}
+		if i == len(words) || words[i].word != "TOP" {
+			end := words[i-1].end
+			return fmt.Sprintf("%s TOP %d%s", sqlstr[:end], n, sqlstr[end:]), true
+		}
+	}
+	if orderBy && !offset && !trailing && n > 0 {
+		return fmt.Sprintf("%s\nOFFSET 0 ROWS FETCH NEXT %d ROWS ONLY", sqlstr, n), true
+	}
+	return sqlstr, false
+}
+
+// topLevelWord is a keyword or identifier of a query, outside of any
+// parentheses.
+type topLevelWord struct {
+	word string
+	end  int
+}
+
+// topLevelWords returns the upper cased keywords and identifiers of the
+// query that are not within parentheses, strings, quoted identifiers, or
+// comments.
+func topLevelWords(sqlstr string) []topLevelWord {
+	var words []topLevelWord
+	depth := 0
+	for i := 0; i < len(sqlstr); {
+		c := sqlstr[i]
+		switch {
+		case c == '\'' || c == '"' || c == '[':
+			end := c
+			if c == '[' {
+				end = ']'
+			}
+			i++
+			for i < len(sqlstr) {
+				if sqlstr[i] == end {
+					if i+1 < len(sqlstr) && sqlstr[i+1] == end {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			i++
+		case strings.HasPrefix(sqlstr[i:], "--"):
+			if j := strings.IndexByte(sqlstr[i:], '\n'); j != -1 {
+				i += j + 1
+			} else {
+				i = len(sqlstr)
+			}
+		case strings.HasPrefix(sqlstr[i:], "/*"):
+			if j := strings.Index(sqlstr[i+2:], "*/"); j != -1 {
+				i += j + 4
+			} else {
+				i = len(sqlstr)
+			}
+		case c == '(':
+			depth, i = depth+1, i+1
+		case c == ')':
+			depth, i = depth-1, i+1
+		case isWordByte(c):
+			j := i + 1
+			for j < len(sqlstr) && isWordByte(sqlstr[j]) {
+				j++
+			}
+			if depth == 0 {
+				words = append(words, topLevelWord{word: strings.ToUpper(sqlstr[i:j]), end: j})
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+	return words
+}
+
+// isWordByte returns true when c is part of a keyword or identifier.
+func isWordByte(c byte) bool {
+	return c == '_' || c == '@' || c == '#' || c == '$' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c >= 0x80
+}
+
+// LimitWithFetchFirst wraps the query in a subquery limited with FETCH FIRST,
+// as supported by Oracle (12c and later) and DB2. The subquery's alias does not
+// begin with an underscore, as Oracle identifiers cannot.
+func LimitWithFetchFirst(sqlstr string, n int) (string, bool) {
+	return fmt.Sprintf("SELECT * FROM (\n%s\n) q FETCH FIRST %d ROWS ONLY", sqlstr, n), true
+}
+
+// Explain executes the query with the database's EXPLAIN ANALYZE, returning
+// the parsed execution plan, if supported by the URL's driver. Returns false
+// when the driver does not provide structured execution plans.
+func Explain(ctx context.Context, u *dburl.URL, db DB, sqlstr string, args ...interface{}) (*explain.Plan, bool, error) {
+	d, ok := drivers[u.Driver]
+	if !ok || d.Explain == nil {
+		return nil, false, nil
+	}
+	plan, err := d.Explain(ctx, db, sqlstr, args...)
+	return plan, true, err
+}
+
 // CopyWithInsert builds a copy handler based on insert.
 func CopyWithInsert(placeholder func(int) string) func(ctx context.Context, db *sql.DB, rows *sql.Rows, table string) (int64, error) {
 	if placeholder == nil {
@@ -605,6 +974,67 @@ func CopyWithInsert(placeholder func(int) string) func(ctx context.Context, db *
 	}
 }
 
+// updateColumns returns the columns not in target, which are updated when a
+// row conflicts on the target columns.
+func updateColumns(columns, target []string) []string {
+	var cols []string
+	for _, col := range columns {
+		if !slices.ContainsFunc(target, func(s string) bool { return strings.EqualFold(s, col) }) {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// UpsertOnConflict builds an upsert handler using INSERT ... ON CONFLICT, as
+// supported by PostgreSQL.
+func UpsertOnConflict(placeholder func(int) string) func(string, []string, []string, int) string {
+	if placeholder == nil {
+		placeholder = func(n int) string { return fmt.Sprintf("$%d", n) }
+	}
+	return func(table string, columns, target []string, rows int) string {
+		query := copyfile.InsertQuery("INSERT INTO", table, columns, rows, placeholder)
+		cols := updateColumns(columns, target)
+		if len(target) == 0 || len(cols) == 0 {
+			return query + " ON CONFLICT DO NOTHING"
+		}
+		set := make([]string, len(cols))
+		for i, col := range cols {
+			set[i] = col + " = EXCLUDED." + col
+		}
+		return query + " ON CONFLICT (" + strings.Join(target, ", ") + ") DO UPDATE SET " + strings.Join(set, ", ")
+	}
+}
+
+// UpsertOnDuplicateKey builds an upsert handler using INSERT ... ON DUPLICATE
+// KEY UPDATE, as supported by MySQL. As rows conflict on any unique key, the
+// target columns are only excluded from the updated columns.
+func UpsertOnDuplicateKey(placeholder func(int) string) func(string, []string, []string, int) string {
+	return func(table string, columns, target []string, rows int) string {
+		cols := updateColumns(columns, target)
+		if len(target) == 0 || len(cols) == 0 {
+			return copyfile.InsertQuery("INSERT IGNORE INTO", table, columns, rows, placeholder)
+		}
+		set := make([]string, len(cols))
+		for i, col := range cols {
+			set[i] = col + " = VALUES(" + col + ")"
+		}
+		return copyfile.InsertQuery("INSERT INTO", table, columns, rows, placeholder) + " ON DUPLICATE KEY UPDATE " + strings.Join(set, ", ")
+	}
+}
+
+// UpsertOrReplace builds an upsert handler using INSERT OR REPLACE, as
+// supported by SQLite, where conflicting rows (on any unique key) are
+// replaced.
+func UpsertOrReplace(placeholder func(int) string) func(string, []string, []string, int) string {
+	return func(table string, columns, target []string, rows int) string {
+		if len(target) == 0 {
+			return copyfile.InsertQuery("INSERT OR IGNORE INTO", table, columns, rows, placeholder)
+		}
+		return copyfile.InsertQuery("INSERT OR REPLACE INTO", table, columns, rows, placeholder)
+	}
+}
+
 func init() {
 	dburl.OdbcIgnoreQueryPrefixes = []string{"usql_"}
 }
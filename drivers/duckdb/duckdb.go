@@ -0,0 +1,124 @@
+// Package duckdb defines and registers usql's DuckDB driver. Requires CGO.
+//
+// See: https://github.com/marcboeker/go-duckdb
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"strconv"
+	"strings"
+
+	_ "github.com/marcboeker/go-duckdb" // DRIVER
+	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/drivers/metadata"
+	infos "github.com/rmasci/usql/drivers/metadata/informationschema"
+	"github.com/rmasci/usql/env"
+	"github.com/rmasci/usql/text"
+	"github.com/xo/dburl"
+	"github.com/xo/tblfmt"
+)
+
+func init() {
+	// allow duckdb:// (without a path) to open an in-memory database
+	if scheme := dburl.Unregister("duckdb"); scheme != nil {
+		scheme.Generator = genDSN
+		dburl.Register(*scheme)
+	}
+	// dburl's header regexp does not match headers containing invalid
+	// UTF-8, so check the magic bytes directly
+	dburl.RegisterFileType("duckdb", isDuckDBHeader, `(?i)\.duckdb$`)
+	newReader := infos.New(
+		infos.WithFunctions(false),
+		infos.WithSequences(false),
+		infos.WithIndexes(false),
+		infos.WithConstraints(false),
+		infos.WithTriggers(false),
+		infos.WithTablePrivileges(false),
+		infos.WithColumnPrivileges(false),
+		infos.WithUsagePrivileges(false),
+		infos.WithSystemSchemas([]string{"information_schema", "pg_catalog"}),
+		infos.WithCurrentSchema("CURRENT_SCHEMA()"),
+	)
+	drivers.Register("duckdb", drivers.Driver{
+		AllowMultilineComments: true,
+		Version: func(ctx context.Context, db drivers.DB) (string, error) {
+			var ver string
+			err := db.QueryRowContext(ctx, `SELECT library_version FROM pragma_version()`).Scan(&ver)
+			if err != nil {
+				return "", err
+			}
+			return "DuckDB " + ver, nil
+		},
+		Placeholder: func(n int) string {
+			return "$" + strconv.Itoa(n)
+		},
+		NewMetadataReader: newReader,
+		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
+			writerOpts := []metadata.WriterOption{
+				metadata.WithListAllDbs(func(pattern string, verbose bool) error {
+					return listAllDbs(db, w, pattern, verbose)
+				}),
+			}
+			return metadata.NewDefaultWriter(newReader(db, opts...), writerOpts...)(db, w)
+		},
+		Copy:     drivers.CopyWithInsert(nil),
+		CopyFile: copyFile,
+	})
+}
+
+// genDSN generates a DuckDB DSN from the URL, returning an empty DSN (an
+// in-memory database) when the URL does not have a path.
+func genDSN(u *dburl.URL) (string, string, error) {
+	if u.Opaque == "" && u.Host == "" && u.Path == "" {
+		if u.RawQuery != "" {
+			return "?" + u.RawQuery, "", nil
+		}
+		return "", "", nil
+	}
+	return dburl.GenOpaque(u)
+}
+
+// isDuckDBHeader returns true when the header is a DuckDB storage header.
+//
+// See: https://duckdb.org/internals/storage
+func isDuckDBHeader(buf []byte) bool {
+	return len(buf) >= 12 && string(buf[8:12]) == "DUCK"
+}
+
+// listAllDbs lists the attached databases.
+func listAllDbs(db drivers.DB, w io.Writer, pattern string, verbose bool) error {
+	rows, err := db.Query(`SELECT database_name AS "Name", path AS "Path", type AS "Type" FROM duckdb_databases() ORDER BY database_name`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	params := env.Pall()
+	params["title"] = "List of databases"
+	return tblfmt.EncodeAll(w, rows, params)
+}
+
+// copyFile imports the file at path into the table using DuckDB's native
+// file readers. The file's columns are inserted into the table columns of
+// the same name when no columns are specified.
+func copyFile(ctx context.Context, db *sql.DB, path, format, table string, columns []string) (int64, error) {
+	src := "'" + strings.ReplaceAll(path, "'", "''") + "'"
+	switch format {
+	case "csv":
+		src = "read_csv_auto(" + src + ")"
+	case "parquet":
+		src = "read_parquet(" + src + ")"
+	default:
+		return 0, text.ErrUnknownFileType
+	}
+	query := "INSERT INTO " + table + " BY NAME SELECT * FROM " + src
+	if len(columns) != 0 {
+		query = "INSERT INTO " + table + " (" + strings.Join(columns, ", ") + ") SELECT * FROM " + src
+	}
+	res, err := db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
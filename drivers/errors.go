@@ -1,6 +1,8 @@
 package drivers
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 	"unicode"
 )
@@ -56,3 +58,46 @@ func (e *Error) Unwrap() error {
 func chop(s, prefix string) string {
 	return strings.TrimLeftFunc(strings.TrimPrefix(strings.TrimSpace(s), prefix+":"), unicode.IsSpace)
 }
+
+// ErrDetail is a labeled detail field of an error.
+type ErrDetail struct {
+	Name  string
+	Value string
+}
+
+// ErrDetails returns all available detail fields for an error: the driver, the
+// error type, the error code and message, and any additional driver specific
+// fields. Fields with empty values are omitted.
+func ErrDetails(err error) []ErrDetail {
+	var e *Error
+	if !errors.As(err, &e) {
+		return []ErrDetail{
+			{"Type", fmt.Sprintf("%T", err)},
+			{"Message", err.Error()},
+		}
+	}
+	d, ok := drivers[e.Driver]
+	name, code, msg := e.Driver, "", e.Err.Error()
+	if ok && d.Name != "" {
+		name = d.Name
+	}
+	if ok && d.Err != nil {
+		code, msg = d.Err(e.Err)
+	}
+	details := []ErrDetail{
+		{"Driver", name},
+		{"Type", fmt.Sprintf("%T", e.Err)},
+		{"Code", code},
+		{"Message", chop(msg, e.Driver)},
+	}
+	if ok && d.ErrDetails != nil {
+		details = append(details, d.ErrDetails(e.Err)...)
+	}
+	var v []ErrDetail
+	for _, detail := range details {
+		if detail.Value != "" {
+			v = append(v, detail)
+		}
+	}
+	return v
+}
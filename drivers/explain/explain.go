@@ -0,0 +1,274 @@
+// Package explain provides query execution plans, parsers for the plan
+// formats of the drivers' EXPLAIN ANALYZE output, and a renderer displaying a
+// plan as a tree.
+package explain
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rmasci/usql/text"
+)
+
+// Plan is a query execution plan.
+type Plan struct {
+	// Root is the plan's top node.
+	Root *Node
+	// PlanningTime is the time spent planning the query, in milliseconds (0
+	// when not known).
+	PlanningTime float64
+	// ExecutionTime is the time spent executing the query, in milliseconds
+	// (0 when not known).
+	ExecutionTime float64
+}
+
+// Node is a node of a query execution plan.
+type Node struct {
+	// Name is the node's description (ie, "Seq Scan on books").
+	Name string
+	// Details are additional node details (ie, filter conditions).
+	Details []string
+	// EstimatedRows is the planner's estimated number of rows.
+	EstimatedRows float64
+	// ActualRows is the actual number of rows (per loop).
+	ActualRows float64
+	// StartupTime is the time until the first row, in milliseconds.
+	StartupTime float64
+	// TotalTime is the time until the last row, in milliseconds.
+	TotalTime float64
+	// Loops is the number of times the node was executed.
+	Loops int64
+	// Children are the child nodes.
+	Children []*Node
+}
+
+// Write writes the plan as a tree to w.
+func Write(w io.Writer, p *Plan) error {
+	var err error
+	write := func(s string) {
+		if err == nil {
+			_, err = io.WriteString(w, s)
+		}
+	}
+	var walk func(*Node, string, string)
+	walk = func(n *Node, prefix, childPrefix string) {
+		write(prefix + n.Name + "  (" + stats(n) + ")\n")
+		detailPrefix := childPrefix + "   "
+		if len(n.Children) != 0 {
+			detailPrefix = childPrefix + "│  "
+		}
+		for _, d := range n.Details {
+			write(detailPrefix + d + "\n")
+		}
+		for i, c := range n.Children {
+			if i == len(n.Children)-1 {
+				walk(c, childPrefix+"└─ ", childPrefix+"   ")
+			} else {
+				walk(c, childPrefix+"├─ ", childPrefix+"│  ")
+			}
+		}
+	}
+	walk(p.Root, "", "")
+	if p.PlanningTime != 0 || p.ExecutionTime != 0 {
+		write("\n")
+	}
+	if p.PlanningTime != 0 {
+		write("Planning time: " + formatFloat(p.PlanningTime) + " ms\n")
+	}
+	if p.ExecutionTime != 0 {
+		write("Execution time: " + formatFloat(p.ExecutionTime) + " ms\n")
+	}
+	return err
+}
+
+// stats returns the timing and row statistics of a node, flagging row
+// estimates off by a factor of 10 or more.
+func stats(n *Node) string {
+	est := "estimated rows=" + formatFloat(n.EstimatedRows)
+	if n.Loops == 0 {
+		return "never executed, " + est
+	}
+	s := fmt.Sprintf("time=%s..%s ms, rows=%s, loops=%d, %s",
+		formatFloat(n.StartupTime), formatFloat(n.TotalTime),
+		formatFloat(n.ActualRows), n.Loops, est,
+	)
+	if lo, hi := min(n.ActualRows, n.EstimatedRows), max(n.ActualRows, n.EstimatedRows); lo > 0 && hi/lo >= 10 {
+		s += ", off by " + strconv.FormatFloat(hi/lo, 'f', 0, 64) + "x"
+	}
+	return s
+}
+
+// formatFloat formats f using the fewest digits necessary.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// pgDetails are the PostgreSQL plan node keys shown as node details.
+var pgDetails = []string{
+	"Index Cond",
+	"Recheck Cond",
+	"Hash Cond",
+	"Merge Cond",
+	"Join Filter",
+	"Filter",
+	"Rows Removed by Filter",
+	"Sort Key",
+	"Group Key",
+}
+
+// ParsePostgres parses a PostgreSQL EXPLAIN (ANALYZE, FORMAT JSON) plan.
+func ParsePostgres(buf []byte) (*Plan, error) {
+	var v []struct {
+		Plan          map[string]interface{} `json:"Plan"`
+		PlanningTime  float64                `json:"Planning Time"`
+		ExecutionTime float64                `json:"Execution Time"`
+	}
+	if err := json.Unmarshal(buf, &v); err != nil {
+		return nil, fmt.Errorf(text.InvalidQueryPlan, err)
+	}
+	if len(v) == 0 || v[0].Plan == nil {
+		return nil, fmt.Errorf(text.InvalidQueryPlan, "no plan")
+	}
+	return &Plan{
+		Root:          pgNode(v[0].Plan),
+		PlanningTime:  v[0].PlanningTime,
+		ExecutionTime: v[0].ExecutionTime,
+	}, nil
+}
+
+// pgNode converts a PostgreSQL JSON plan node.
+func pgNode(m map[string]interface{}) *Node {
+	str := func(key string) string {
+		s, _ := m[key].(string)
+		return s
+	}
+	num := func(key string) float64 {
+		f, _ := m[key].(float64)
+		return f
+	}
+	name := str("Node Type")
+	if name == "Aggregate" {
+		switch str("Strategy") {
+		case "Hashed":
+			name = "HashAggregate"
+		case "Sorted":
+			name = "GroupAggregate"
+		}
+	}
+	if typ := str("Join Type"); typ != "" && typ != "Inner" {
+		name = strings.TrimSuffix(name, " Join") + " " + typ + " Join"
+	}
+	if b, _ := m["Parallel Aware"].(bool); b {
+		name = "Parallel " + name
+	}
+	if s := str("Index Name"); s != "" {
+		name += " using " + s
+	}
+	for _, key := range []string{"Relation Name", "CTE Name", "Function Name"} {
+		if s := str(key); s != "" {
+			name += " on " + s
+			if alias := str("Alias"); alias != "" && alias != s {
+				name += " " + alias
+			}
+			break
+		}
+	}
+	n := &Node{
+		Name:          name,
+		EstimatedRows: num("Plan Rows"),
+		ActualRows:    num("Actual Rows"),
+		StartupTime:   num("Actual Startup Time"),
+		TotalTime:     num("Actual Total Time"),
+		Loops:         int64(num("Actual Loops")),
+	}
+	for _, key := range pgDetails {
+		switch v := m[key].(type) {
+		case string:
+			n.Details = append(n.Details, key+": "+v)
+		case float64:
+			n.Details = append(n.Details, key+": "+formatFloat(v))
+		case []interface{}:
+			s := make([]string, len(v))
+			for i, z := range v {
+				s[i] = fmt.Sprint(z)
+			}
+			n.Details = append(n.Details, key+": "+strings.Join(s, ", "))
+		}
+	}
+	children, _ := m["Plans"].([]interface{})
+	for _, c := range children {
+		if c, ok := c.(map[string]interface{}); ok {
+			n.Children = append(n.Children, pgNode(c))
+		}
+	}
+	return n
+}
+
+// MySQL EXPLAIN ANALYZE statistics.
+var (
+	mysqlNumber  = `([0-9]+(?:\.[0-9]+)?(?:e[+-]?[0-9]+)?)`
+	mysqlCostRE  = regexp.MustCompile(`\s*\(cost=` + mysqlNumber + `(?:\.\.` + mysqlNumber + `)? rows=` + mysqlNumber + `\)`)
+	mysqlTimeRE  = regexp.MustCompile(`\s*\(actual time=` + mysqlNumber + `\.\.` + mysqlNumber + ` rows=` + mysqlNumber + ` loops=([0-9]+)\)`)
+	mysqlNeverRE = regexp.MustCompile(`\s*\(never executed\)`)
+)
+
+// ParseMySQL parses a MySQL EXPLAIN ANALYZE (tree format) plan.
+func ParseMySQL(s string) (*Plan, error) {
+	type entry struct {
+		indent int
+		node   *Node
+	}
+	var root, last *Node
+	var stack []entry
+	for _, line := range strings.Split(s, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if !strings.HasPrefix(trimmed, "-> ") {
+			// continuation of a multiline node description
+			if last != nil && strings.TrimSpace(line) != "" {
+				last.Name += " " + strings.TrimSpace(line)
+			}
+			continue
+		}
+		indent := len(line) - len(trimmed)
+		last = mysqlNode(strings.TrimPrefix(trimmed, "-> "))
+		for len(stack) != 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		switch {
+		case len(stack) != 0:
+			parent := stack[len(stack)-1].node
+			parent.Children = append(parent.Children, last)
+		case root != nil:
+			return nil, fmt.Errorf(text.InvalidQueryPlan, "multiple root nodes")
+		default:
+			root = last
+		}
+		stack = append(stack, entry{indent, last})
+	}
+	if root == nil {
+		return nil, fmt.Errorf(text.InvalidQueryPlan, "no plan")
+	}
+	return &Plan{Root: root}, nil
+}
+
+// mysqlNode parses a MySQL plan node line.
+func mysqlNode(s string) *Node {
+	n := new(Node)
+	if m := mysqlCostRE.FindStringSubmatch(s); m != nil {
+		n.EstimatedRows, _ = strconv.ParseFloat(m[3], 64)
+		s = strings.Replace(s, m[0], "", 1)
+	}
+	if m := mysqlTimeRE.FindStringSubmatch(s); m != nil {
+		n.StartupTime, _ = strconv.ParseFloat(m[1], 64)
+		n.TotalTime, _ = strconv.ParseFloat(m[2], 64)
+		n.ActualRows, _ = strconv.ParseFloat(m[3], 64)
+		n.Loops, _ = strconv.ParseInt(m[4], 10, 64)
+		s = strings.Replace(s, m[0], "", 1)
+	}
+	n.Name = strings.TrimSpace(mysqlNeverRE.ReplaceAllString(s, ""))
+	return n
+}
@@ -0,0 +1,81 @@
+package explain
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParsePostgres(t *testing.T) {
+	const buf = `[{
+  "Plan": {
+    "Node Type": "Hash Join", "Join Type": "Inner", "Plan Rows": 3,
+    "Actual Startup Time": 0.031, "Actual Total Time": 0.036, "Actual Rows": 2, "Actual Loops": 1,
+    "Hash Cond": "(b.author_id = a.author_id)",
+    "Plans": [
+      {"Node Type": "Seq Scan", "Relation Name": "books", "Alias": "b", "Plan Rows": 1070,
+       "Actual Startup Time": 0.006, "Actual Total Time": 0.007, "Actual Rows": 4, "Actual Loops": 1},
+      {"Node Type": "Hash", "Plan Rows": 3,
+       "Actual Startup Time": 0.012, "Actual Total Time": 0.013, "Actual Rows": 1, "Actual Loops": 1,
+       "Plans": [
+         {"Node Type": "Seq Scan", "Relation Name": "authors", "Alias": "a", "Plan Rows": 3,
+          "Actual Startup Time": 0.008, "Actual Total Time": 0.009, "Actual Rows": 1, "Actual Loops": 1,
+          "Filter": "(name = 'Unknown Master'::text)", "Rows Removed by Filter": 1}
+       ]}
+    ]
+  },
+  "Planning Time": 0.162,
+  "Execution Time": 0.061
+}]`
+	const exp = `Hash Join  (time=0.031..0.036 ms, rows=2, loops=1, estimated rows=3)
+│  Hash Cond: (b.author_id = a.author_id)
+├─ Seq Scan on books b  (time=0.006..0.007 ms, rows=4, loops=1, estimated rows=1070, off by 268x)
+└─ Hash  (time=0.012..0.013 ms, rows=1, loops=1, estimated rows=3)
+   └─ Seq Scan on authors a  (time=0.008..0.009 ms, rows=1, loops=1, estimated rows=3)
+         Filter: (name = 'Unknown Master'::text)
+         Rows Removed by Filter: 1
+
+Planning time: 0.162 ms
+Execution time: 0.061 ms
+`
+	plan, err := ParsePostgres([]byte(buf))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := write(t, plan); s != exp {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, s)
+	}
+}
+
+func TestParseMySQL(t *testing.T) {
+	const s = `-> Nested loop inner join  (cost=1.60 rows=3) (actual time=0.052..0.061 rows=2 loops=1)
+    -> Filter: (a.name = 'Unknown Master')  (cost=0.55 rows=1) (actual time=0.031..0.035 rows=1 loops=1)
+        -> Table scan on a  (cost=0.55 rows=3) (actual time=0.028..0.032 rows=3 loops=1)
+    -> Index lookup on b using author_id (author_id=a.author_id)  (cost=1.05 rows=3) (actual time=0.019..0.024 rows=2 loops=1)
+    -> Table scan on c  (cost=0.35 rows=1) (never executed)
+`
+	const exp = `Nested loop inner join  (time=0.052..0.061 ms, rows=2, loops=1, estimated rows=3)
+├─ Filter: (a.name = 'Unknown Master')  (time=0.031..0.035 ms, rows=1, loops=1, estimated rows=1)
+│  └─ Table scan on a  (time=0.028..0.032 ms, rows=3, loops=1, estimated rows=3)
+├─ Index lookup on b using author_id (author_id=a.author_id)  (time=0.019..0.024 ms, rows=2, loops=1, estimated rows=3)
+└─ Table scan on c  (never executed, estimated rows=1)
+`
+	plan, err := ParseMySQL(s)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := write(t, plan); s != exp {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, s)
+	}
+	if _, err := ParseMySQL("id\tselect_type\n"); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+}
+
+func write(t *testing.T, plan *Plan) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, plan); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,52 @@
+package firebird
+
+import (
+	"testing"
+)
+
+func TestParseDialect(t *testing.T) {
+	tests := []struct {
+		s       string
+		exp     string
+		dialect int
+	}{
+		{"user:pass@localhost/db.fdb", "user:pass@localhost/db.fdb", 0},
+		{"user:pass@localhost/db.fdb?role=admin", "user:pass@localhost/db.fdb?role=admin", 0},
+		{"user:pass@localhost/db.fdb?dialect=1", "user:pass@localhost/db.fdb", 1},
+		{"user:pass@localhost/db.fdb?dialect=3&role=admin", "user:pass@localhost/db.fdb?role=admin", 3},
+	}
+	for i, test := range tests {
+		s, dialect, err := parseDialect(test.s)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s != test.exp || dialect != test.dialect {
+			t.Errorf("test %d expected %q and %d, got: %q and %d", i, test.exp, test.dialect, s, dialect)
+		}
+	}
+	if _, _, err := parseDialect("localhost/db.fdb?dialect=2"); err == nil {
+		t.Errorf("expected error for invalid dialect")
+	}
+}
+
+func TestTypeName(t *testing.T) {
+	tests := []struct {
+		typ, sub, length, prec, scale int
+		exp                           string
+	}{
+		{8, 0, 4, 0, 0, "INTEGER"},
+		{16, 1, 8, 18, -2, "NUMERIC(18,2)"},
+		{8, 2, 4, 9, -3, "DECIMAL(9,3)"},
+		{37, 0, 50, 0, 0, "VARCHAR(50)"},
+		{14, 0, 1, 0, 0, "CHAR(1)"},
+		{261, 1, 8, 0, 0, "BLOB SUB_TYPE TEXT"},
+		{261, 0, 8, 0, 0, "BLOB"},
+		{35, 0, 8, 0, 0, "TIMESTAMP"},
+		{99, 0, 0, 0, 0, "UNKNOWN(99)"},
+	}
+	for i, test := range tests {
+		if s := typeName(test.typ, test.sub, test.length, test.prec, test.scale); s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package firebird
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/drivers/metadata"
+)
+
+type metaReader struct {
+	metadata.LoggingReader
+}
+
+var _ metadata.CatalogReader = &metaReader{}
+var _ metadata.TableReader = &metaReader{}
+var _ metadata.ColumnReader = &metaReader{}
+
+// NewReader creates a metadata reader for Firebird, reading the relations and
+// their fields from the RDB$RELATIONS and RDB$RELATION_FIELDS system tables.
+func NewReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
+	return metadata.NewPluginReader(&metaReader{
+		LoggingReader: metadata.NewLoggingReader(db, opts...),
+	})
+}
+
+// Catalogs lists the current database, as Firebird servers do not list the
+// databases they serve.
+func (r metaReader) Catalogs(f metadata.Filter) (*metadata.CatalogSet, error) {
+	qstr := `SELECT TRIM(MON$DATABASE_NAME) FROM MON$DATABASE`
+	vals := []interface{}{}
+	if f.Name != "" {
+		qstr += ` WHERE UPPER(MON$DATABASE_NAME) LIKE UPPER(?)`
+		vals = append(vals, f.Name)
+	}
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	results := []metadata.Catalog{}
+	for rows.Next() {
+		var c metadata.Catalog
+		if err := rows.Scan(&c.Catalog); err != nil {
+			return nil, err
+		}
+		results = append(results, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return metadata.NewCatalogSet(results), nil
+}
+
+// Tables lists the relations, and when f.WithSystem is true, the system
+// tables. As unquoted identifiers are stored in upper case, names are matched
+// case-insensitively.
+func (r metaReader) Tables(f metadata.Filter) (*metadata.TableSet, error) {
+	qstr := `SELECT table_name, table_type FROM (
+  SELECT
+    TRIM(RDB$RELATION_NAME) AS table_name,
+    CASE
+      WHEN RDB$SYSTEM_FLAG = 1 AND RDB$VIEW_BLR IS NOT NULL THEN 'SYSTEM VIEW'
+      WHEN RDB$SYSTEM_FLAG = 1 THEN 'SYSTEM TABLE'
+      WHEN RDB$VIEW_BLR IS NOT NULL THEN 'VIEW'
+      WHEN RDB$RELATION_TYPE IN (4, 5) THEN 'GLOBAL TEMPORARY'
+      ELSE 'TABLE'
+    END AS table_type
+  FROM RDB$RELATIONS
+) t`
+	conds, vals := []string{}, []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "table_type NOT LIKE 'SYSTEM %'")
+	}
+	if f.Name != "" {
+		conds = append(conds, "UPPER(table_name) LIKE UPPER(?)")
+		vals = append(vals, f.Name)
+	}
+	if len(f.Types) != 0 {
+		conds = append(conds, "table_type IN ("+placeholders(len(f.Types))+")")
+		for _, t := range f.Types {
+			vals = append(vals, t)
+		}
+	}
+	if len(conds) != 0 {
+		qstr += " WHERE " + strings.Join(conds, " AND ")
+	}
+	rows, closeRows, err := r.Query(qstr+" ORDER BY table_name", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	results := []metadata.Table{}
+	for rows.Next() {
+		var t metadata.Table
+		if err := rows.Scan(&t.Name, &t.Type); err != nil {
+			return nil, err
+		}
+		results = append(results, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return metadata.NewTableSet(results), nil
+}
+
+// Columns lists the fields of the relations, with the data types of their
+// domains.
+func (r metaReader) Columns(f metadata.Filter) (*metadata.ColumnSet, error) {
+	qstr := `SELECT
+  TRIM(rf.RDB$RELATION_NAME),
+  TRIM(rf.RDB$FIELD_NAME),
+  COALESCE(rf.RDB$FIELD_POSITION, 0) + 1,
+  fd.RDB$FIELD_TYPE,
+  COALESCE(fd.RDB$FIELD_SUB_TYPE, 0),
+  COALESCE(fd.RDB$CHARACTER_LENGTH, fd.RDB$FIELD_LENGTH, 0),
+  COALESCE(fd.RDB$FIELD_PRECISION, 0),
+  COALESCE(fd.RDB$FIELD_SCALE, 0),
+  COALESCE(rf.RDB$NULL_FLAG, fd.RDB$NULL_FLAG, 0),
+  COALESCE(rf.RDB$DEFAULT_SOURCE, fd.RDB$DEFAULT_SOURCE)
+FROM RDB$RELATION_FIELDS rf
+  JOIN RDB$FIELDS fd ON fd.RDB$FIELD_NAME = rf.RDB$FIELD_SOURCE`
+	conds, vals := []string{}, []interface{}{}
+	if f.Parent != "" {
+		conds = append(conds, "UPPER(TRIM(rf.RDB$RELATION_NAME)) LIKE UPPER(?)")
+		vals = append(vals, f.Parent)
+	}
+	if f.Name != "" {
+		conds = append(conds, "UPPER(TRIM(rf.RDB$FIELD_NAME)) LIKE UPPER(?)")
+		vals = append(vals, f.Name)
+	}
+	if len(conds) != 0 {
+		qstr += " WHERE " + strings.Join(conds, " AND ")
+	}
+	rows, closeRows, err := r.Query(qstr+" ORDER BY rf.RDB$RELATION_NAME, rf.RDB$FIELD_POSITION", vals...)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	results := []metadata.Column{}
+	for rows.Next() {
+		var c metadata.Column
+		var typ, sub, length, prec, scale, notNull int
+		var def sql.NullString
+		if err := rows.Scan(&c.Table, &c.Name, &c.OrdinalPosition, &typ, &sub, &length, &prec, &scale, &notNull, &def); err != nil {
+			return nil, err
+		}
+		c.DataType = typeName(typ, sub, length, prec, scale)
+		c.ColumnSize, c.DecimalDigits = length, -scale
+		if prec != 0 {
+			c.ColumnSize = prec
+		}
+		c.IsNullable = metadata.YES
+		if notNull != 0 {
+			c.IsNullable = metadata.NO
+		}
+		c.Default = strings.TrimSpace(def.String)
+		if len(c.Default) > 8 && strings.EqualFold(c.Default[:8], "DEFAULT ") {
+			c.Default = strings.TrimSpace(c.Default[8:])
+		}
+		results = append(results, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return metadata.NewColumnSet(results), nil
+}
+
+// typeNames are the names of the field types of RDB$FIELDS.
+var typeNames = map[int]string{
+	7:   "SMALLINT",
+	8:   "INTEGER",
+	10:  "FLOAT",
+	12:  "DATE",
+	13:  "TIME",
+	14:  "CHAR",
+	16:  "BIGINT",
+	23:  "BOOLEAN",
+	24:  "DECFLOAT(16)",
+	25:  "DECFLOAT(34)",
+	26:  "INT128",
+	27:  "DOUBLE PRECISION",
+	28:  "TIME WITH TIME ZONE",
+	29:  "TIMESTAMP WITH TIME ZONE",
+	35:  "TIMESTAMP",
+	37:  "VARCHAR",
+	261: "BLOB",
+}
+
+// typeName returns the SQL type of a field type, where exact numerics are
+// integer types with a sub type of 1 (NUMERIC) or 2 (DECIMAL).
+func typeName(typ, sub, length, prec, scale int) string {
+	switch name := typeNames[typ]; {
+	case name == "":
+		return fmt.Sprintf("UNKNOWN(%d)", typ)
+	case (typ == 7 || typ == 8 || typ == 16 || typ == 26) && (sub == 1 || sub == 2):
+		name = "NUMERIC"
+		if sub == 2 {
+			name = "DECIMAL"
+		}
+		return fmt.Sprintf("%s(%d,%d)", name, prec, -scale)
+	case typ == 14 || typ == 37:
+		return fmt.Sprintf("%s(%d)", name, length)
+	case typ == 261 && sub == 1:
+		return "BLOB SUB_TYPE TEXT"
+	default:
+		return name
+	}
+}
+
+// placeholders returns n comma separated placeholders.
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
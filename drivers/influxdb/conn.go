@@ -0,0 +1,365 @@
+package influxdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/flight"
+	"github.com/apache/arrow/go/v14/arrow/flight/flightsql"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"google.golang.org/grpc/metadata"
+)
+
+func init() {
+	sql.Register("influxdb", drv{})
+}
+
+// drv is a database/sql driver for InfluxDB 3.
+type drv struct{}
+
+// Open satisfies the driver.Driver interface.
+func (d drv) Open(name string) (driver.Conn, error) {
+	c, err := d.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.Connect(context.Background())
+}
+
+// OpenConnector satisfies the driver.DriverContext interface.
+func (drv) OpenConnector(name string) (driver.Connector, error) {
+	cfg, err := parseConfig(name)
+	if err != nil {
+		return nil, err
+	}
+	client, err := dial(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &connector{client: client, cfg: cfg}, nil
+}
+
+// connector is a database/sql connector for an InfluxDB 3 bucket.
+type connector struct {
+	client *flightsql.Client
+	cfg    config
+}
+
+// Connect satisfies the driver.Connector interface.
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{c: c}, nil
+}
+
+// Driver satisfies the driver.Connector interface.
+func (c *connector) Driver() driver.Driver {
+	return drv{}
+}
+
+// Close satisfies the io.Closer interface, closing the client when the
+// sql.DB is closed.
+func (c *connector) Close() error {
+	return c.client.Close()
+}
+
+// conn is a connection to an InfluxDB 3 bucket. As the client multiplexes
+// requests over a single gRPC connection, a conn only wraps the connector's
+// client.
+type conn struct {
+	c *connector
+}
+
+// Prepare satisfies the driver.Conn interface.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{c: c, query: query}, nil
+}
+
+// Close satisfies the driver.Conn interface.
+func (c *conn) Close() error {
+	return nil
+}
+
+// Begin satisfies the driver.Conn interface.
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+// Ping satisfies the driver.Pinger interface.
+func (c *conn) Ping(ctx context.Context) error {
+	r, err := c.QueryContext(ctx, `SELECT 1`, nil)
+	if err != nil {
+		return err
+	}
+	return r.Close()
+}
+
+// QueryContext satisfies the driver.QueryerContext interface.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) != 0 {
+		return nil, errors.New("query parameters are not supported")
+	}
+	ctx = c.context(ctx)
+	info, err := c.c.client.Execute(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return newRows(ctx, c.c.client, info)
+}
+
+// ExecContext satisfies the driver.ExecerContext interface.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if len(args) != 0 {
+		return nil, errors.New("query parameters are not supported")
+	}
+	n, err := c.c.client.ExecuteUpdate(c.context(ctx), query)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(n), nil
+}
+
+// context returns the context with the request metadata for the
+// connection's token and bucket.
+func (c *conn) context(ctx context.Context) context.Context {
+	var md []string
+	if c.c.cfg.token != "" {
+		md = append(md, "authorization", "Bearer "+c.c.cfg.token)
+	}
+	if c.c.cfg.bucket != "" {
+		md = append(md, "database", c.c.cfg.bucket, "bucket-name", c.c.cfg.bucket)
+	}
+	if c.c.cfg.org != "" {
+		md = append(md, "org", c.c.cfg.org)
+	}
+	return metadata.AppendToOutgoingContext(ctx, md...)
+}
+
+// stmt is a prepared statement.
+type stmt struct {
+	c     *conn
+	query string
+}
+
+// Close satisfies the driver.Stmt interface.
+func (s *stmt) Close() error {
+	return nil
+}
+
+// NumInput satisfies the driver.Stmt interface.
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+// Exec satisfies the driver.Stmt interface.
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.c.ExecContext(context.Background(), s.query, namedValues(args))
+}
+
+// Query satisfies the driver.Stmt interface.
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.c.QueryContext(context.Background(), s.query, namedValues(args))
+}
+
+// namedValues converts positional values to named values.
+func namedValues(args []driver.Value) []driver.NamedValue {
+	v := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		v[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return v
+}
+
+// rows are the rows of a query result, read from the endpoints of the result
+// one record batch at a time, so that large results are streamed from the
+// server.
+type rows struct {
+	ctx       context.Context
+	client    *flightsql.Client
+	endpoints []*flight.FlightEndpoint
+	schema    *arrow.Schema
+	r         *flight.Reader
+	rec       arrow.Record
+	row       int
+}
+
+// newRows creates rows for the query result. As servers may not include the
+// schema in the result's info, the schema is otherwise read from the first
+// endpoint.
+func newRows(ctx context.Context, client *flightsql.Client, info *flight.FlightInfo) (*rows, error) {
+	r := &rows{ctx: ctx, client: client, endpoints: info.Endpoint}
+	var err error
+	switch {
+	case len(info.Schema) != 0:
+		if r.schema, err = flight.DeserializeSchema(info.Schema, memory.DefaultAllocator); err != nil {
+			return nil, err
+		}
+	case len(r.endpoints) != 0:
+		if err := r.open(); err != nil {
+			return nil, err
+		}
+		r.schema = r.r.Schema()
+	default:
+		r.schema = arrow.NewSchema(nil, nil)
+	}
+	return r, nil
+}
+
+// Columns satisfies the driver.Rows interface.
+func (r *rows) Columns() []string {
+	cols := make([]string, r.schema.NumFields())
+	for i, f := range r.schema.Fields() {
+		cols[i] = f.Name
+	}
+	return cols
+}
+
+// ColumnTypeDatabaseTypeName satisfies the
+// driver.RowsColumnTypeDatabaseTypeName interface.
+func (r *rows) ColumnTypeDatabaseTypeName(i int) string {
+	return typeName(r.schema.Field(i).Type)
+}
+
+// ColumnTypeScanType satisfies the driver.RowsColumnTypeScanType interface.
+func (r *rows) ColumnTypeScanType(i int) reflect.Type {
+	typ := r.schema.Field(i).Type
+	if d, ok := typ.(*arrow.DictionaryType); ok {
+		typ = d.ValueType
+	}
+	switch typ.ID() {
+	case arrow.INT8, arrow.INT16, arrow.INT32, arrow.INT64, arrow.UINT8, arrow.UINT16, arrow.UINT32, arrow.UINT64:
+		return reflect.TypeOf(int64(0))
+	case arrow.FLOAT16, arrow.FLOAT32, arrow.FLOAT64:
+		return reflect.TypeOf(float64(0))
+	case arrow.BOOL:
+		return reflect.TypeOf(false)
+	case arrow.BINARY, arrow.LARGE_BINARY, arrow.FIXED_SIZE_BINARY:
+		return reflect.TypeOf([]byte(nil))
+	case arrow.TIMESTAMP, arrow.DATE32, arrow.DATE64:
+		return reflect.TypeOf(time.Time{})
+	}
+	return reflect.TypeOf("")
+}
+
+// Close satisfies the driver.Rows interface.
+func (r *rows) Close() error {
+	if r.r != nil {
+		r.r.Release()
+	}
+	r.r, r.rec, r.endpoints = nil, nil, nil
+	return nil
+}
+
+// Next satisfies the driver.Rows interface.
+func (r *rows) Next(dest []driver.Value) error {
+	for r.rec == nil || r.row >= int(r.rec.NumRows()) {
+		if err := r.next(); err != nil {
+			return err
+		}
+	}
+	for i := range dest {
+		dest[i] = convert(r.rec.Column(i), r.row)
+	}
+	r.row++
+	return nil
+}
+
+// next reads the next record batch, from the next endpoint when the current
+// endpoint's batches have been read.
+func (r *rows) next() error {
+	r.rec, r.row = nil, 0
+	if r.r != nil && r.r.Next() {
+		r.rec = r.r.Record()
+		return nil
+	}
+	if r.r != nil {
+		err := r.r.Err()
+		r.r.Release()
+		r.r = nil
+		if err != nil && err != io.EOF {
+			return err
+		}
+	}
+	if len(r.endpoints) == 0 {
+		return io.EOF
+	}
+	return r.open()
+}
+
+// open opens a reader for the next endpoint.
+func (r *rows) open() error {
+	var err error
+	if r.r, err = r.client.DoGet(r.ctx, r.endpoints[0].GetTicket()); err != nil {
+		return err
+	}
+	r.endpoints = r.endpoints[1:]
+	return nil
+}
+
+// typeName returns the type name of the arrow type, where dictionary encoded
+// types (such as tags) are named by their value type.
+func typeName(typ arrow.DataType) string {
+	if d, ok := typ.(*arrow.DictionaryType); ok {
+		typ = d.ValueType
+	}
+	return typ.String()
+}
+
+// convert returns the driver value at row i of the array.
+func convert(arr arrow.Array, i int) driver.Value {
+	if arr.IsNull(i) {
+		return nil
+	}
+	switch x := arr.(type) {
+	case *array.Dictionary:
+		return convert(x.Dictionary(), x.GetValueIndex(i))
+	case *array.Int8:
+		return int64(x.Value(i))
+	case *array.Int16:
+		return int64(x.Value(i))
+	case *array.Int32:
+		return int64(x.Value(i))
+	case *array.Int64:
+		return x.Value(i)
+	case *array.Uint8:
+		return int64(x.Value(i))
+	case *array.Uint16:
+		return int64(x.Value(i))
+	case *array.Uint32:
+		return int64(x.Value(i))
+	case *array.Uint64:
+		return int64(x.Value(i))
+	case *array.Float16:
+		return float64(x.Value(i).Float32())
+	case *array.Float32:
+		return float64(x.Value(i))
+	case *array.Float64:
+		return x.Value(i)
+	case *array.Boolean:
+		return x.Value(i)
+	case *array.String:
+		return x.Value(i)
+	case *array.LargeString:
+		return x.Value(i)
+	case *array.Binary:
+		return append([]byte(nil), x.Value(i)...)
+	case *array.LargeBinary:
+		return append([]byte(nil), x.Value(i)...)
+	case *array.FixedSizeBinary:
+		return append([]byte(nil), x.Value(i)...)
+	case *array.Timestamp:
+		return x.Value(i).ToTime(x.DataType().(*arrow.TimestampType).Unit)
+	case *array.Date32:
+		return x.Value(i).ToTime()
+	case *array.Date64:
+		return x.Value(i).ToTime()
+	case *array.Decimal128:
+		return x.Value(i).ToString(x.DataType().(*arrow.Decimal128Type).Scale)
+	}
+	return arr.ValueStr(i)
+}
@@ -0,0 +1,68 @@
+package influxdb
+
+import (
+	"testing"
+
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp config
+	}{
+		{"influxdb://localhost/metrics", config{addr: "localhost:8181", bucket: "metrics"}},
+		{"influxdb://:tok@example.com/metrics?org=acme", config{addr: "example.com:443", bucket: "metrics", org: "acme", token: "tok", tls: true}},
+		{"influxdb://example.com:8086?bucket=metrics&token=tok&tls=false", config{addr: "example.com:8086", bucket: "metrics", token: "tok"}},
+	}
+	for i, test := range tests {
+		cfg, err := parseConfig(test.s)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if cfg != test.exp {
+			t.Errorf("test %d expected %+v, got: %+v", i, test.exp, cfg)
+		}
+	}
+	if _, err := parseConfig("influxdb://localhost/metrics?tls=maybe"); err == nil {
+		t.Errorf("expected error for invalid tls option")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	b := array.NewDictionaryBuilder(memory.DefaultAllocator, &arrow.DictionaryType{
+		IndexType: arrow.PrimitiveTypes.Int32,
+		ValueType: arrow.BinaryTypes.String,
+	}).(*array.BinaryDictionaryBuilder)
+	defer b.Release()
+	for _, s := range []string{"east", "west", "east"} {
+		if err := b.AppendString(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b.AppendNull()
+	arr := b.NewArray()
+	defer arr.Release()
+	for i, exp := range []interface{}{"east", "west", "east", nil} {
+		if v := convert(arr, i); v != exp {
+			t.Errorf("row %d expected %v, got: %v", i, exp, v)
+		}
+	}
+}
+
+func TestColumnType(t *testing.T) {
+	tests := []struct {
+		name, typ, exp string
+	}{
+		{"host", "Dictionary(Int32, Utf8)", "Utf8 (tag)"},
+		{"usage", "Float64", "Float64 (field)"},
+		{"time", "Timestamp(Nanosecond, None)", "Timestamp(Nanosecond, None)"},
+	}
+	for i, test := range tests {
+		if s := columnType(test.name, test.typ); s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+	}
+}
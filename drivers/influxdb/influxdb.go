@@ -0,0 +1,133 @@
+// Package influxdb defines and registers usql's InfluxDB 3 (IOx) driver.
+//
+// Connection URLs are of the form influxdb://:token@host:port/bucket, where
+// queries are SQL, sent using the FlightSQL protocol.
+//
+// See: https://github.com/apache/arrow/tree/main/go/arrow/flight/flightsql
+package influxdb
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow/go/v14/arrow/flight/flightsql" // DRIVER
+	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/drivers/metadata"
+	"github.com/xo/dburl"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func init() {
+	dburl.Register(dburl.Scheme{
+		Driver:    "influxdb",
+		Generator: dburl.GenScheme("influxdb"),
+		Transport: dburl.TransportTCP,
+		Aliases:   []string{"ix", "iox", "influx"},
+	})
+	drivers.Register("influxdb", drivers.Driver{
+		AllowMultilineComments: true,
+		Version: func(context.Context, drivers.DB) (string, error) {
+			return "InfluxDB", nil
+		},
+		User: func(context.Context, drivers.DB) (string, error) {
+			return "", nil
+		},
+		IsPasswordErr: func(err error) bool {
+			var e interface{ GRPCStatus() *status.Status }
+			if !errors.As(err, &e) {
+				return false
+			}
+			code := e.GRPCStatus().Code()
+			return code == codes.Unauthenticated || code == codes.PermissionDenied
+		},
+		Err: func(err error) (string, string) {
+			var e interface{ GRPCStatus() *status.Status }
+			if errors.As(err, &e) {
+				s := e.GRPCStatus()
+				return s.Code().String(), s.Message()
+			}
+			return "", err.Error()
+		},
+		// results are streamed as record batches, so avoid buffering the
+		// entire result set when encoding results
+		BufferRows:        1000,
+		NewMetadataReader: NewReader,
+		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
+			return metadata.NewDefaultWriter(NewReader(db, opts...))(db, w)
+		},
+	})
+}
+
+// config is the configuration of a connection.
+type config struct {
+	addr   string
+	bucket string
+	org    string
+	token  string
+	tls    bool
+}
+
+// parseConfig parses the connection string, a URL of the form
+// influxdb://[:token@]host[:port]/bucket?opts. Supported options are:
+//
+//	bucket - bucket (database) to query, when not in the path
+//	org    - organization of the bucket
+//	token  - API token, when not the password of the URL
+//	tls    - whether to connect using TLS (default true, and false when
+//	         connecting to localhost)
+//
+// The port defaults to 443 when using TLS, and 8181 otherwise.
+func parseConfig(dsn string) (config, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return config{}, err
+	}
+	q := u.Query()
+	cfg := config{
+		bucket: strings.Trim(u.Path, "/"),
+		org:    q.Get("org"),
+		token:  q.Get("token"),
+		tls:    u.Hostname() != "localhost" && u.Hostname() != "127.0.0.1",
+	}
+	if cfg.bucket == "" {
+		cfg.bucket = q.Get("bucket")
+	}
+	if pass, ok := u.User.Password(); ok && cfg.token == "" {
+		cfg.token = pass
+	}
+	if s := q.Get("tls"); s != "" {
+		if cfg.tls, err = strconv.ParseBool(s); err != nil {
+			return config{}, fmt.Errorf("invalid tls option %q", s)
+		}
+	}
+	port := u.Port()
+	switch {
+	case port != "":
+	case cfg.tls:
+		port = "443"
+	default:
+		port = "8181"
+	}
+	cfg.addr = net.JoinHostPort(u.Hostname(), port)
+	return cfg, nil
+}
+
+// dial creates a FlightSQL client for the configuration.
+func dial(ctx context.Context, cfg config) (*flightsql.Client, error) {
+	creds := insecure.NewCredentials()
+	if cfg.tls {
+		creds = credentials.NewTLS(&tls.Config{})
+	}
+	return flightsql.NewClientCtx(ctx, cfg.addr, nil, nil, grpc.WithTransportCredentials(creds))
+}
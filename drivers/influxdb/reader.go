@@ -0,0 +1,166 @@
+package influxdb
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/drivers/metadata"
+)
+
+type metaReader struct {
+	metadata.LoggingReader
+}
+
+var _ metadata.SchemaReader = &metaReader{}
+var _ metadata.TableReader = &metaReader{}
+var _ metadata.ColumnReader = &metaReader{}
+
+// NewReader creates a metadata reader for InfluxDB 3, where measurements are
+// the tables of the iox schema.
+func NewReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
+	return metadata.NewPluginReader(&metaReader{
+		LoggingReader: metadata.NewLoggingReader(db, opts...),
+	})
+}
+
+// systemSchemas are the schemas of the system tables.
+var systemSchemas = []string{"information_schema", "system"}
+
+func (r metaReader) Schemas(f metadata.Filter) (*metadata.SchemaSet, error) {
+	rows, closeRows, err := r.Query(`SELECT DISTINCT table_schema FROM information_schema.tables ORDER BY table_schema`)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	results := []metadata.Schema{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		if match(f.Name, name) && (f.WithSystem || !contains(systemSchemas, name)) {
+			results = append(results, metadata.Schema{Schema: name})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return metadata.NewSchemaSet(results), nil
+}
+
+// tableTypes are the table types reported by InfluxDB, mapped to the types
+// used by the metadata writer.
+var tableTypes = map[string]string{
+	"BASE TABLE": "TABLE",
+}
+
+// Tables lists the measurements, and when f.WithSystem is true, the system
+// tables.
+func (r metaReader) Tables(f metadata.Filter) (*metadata.TableSet, error) {
+	rows, closeRows, err := r.Query(`SELECT table_catalog, table_schema, table_name, table_type FROM information_schema.tables ORDER BY table_schema, table_name`)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	results := []metadata.Table{}
+	for rows.Next() {
+		var t metadata.Table
+		if err := rows.Scan(&t.Catalog, &t.Schema, &t.Name, &t.Type); err != nil {
+			return nil, err
+		}
+		if typ, ok := tableTypes[t.Type]; ok {
+			t.Type = typ
+		}
+		switch {
+		case !f.WithSystem && f.Schema == "" && contains(systemSchemas, t.Schema),
+			!match(f.Schema, t.Schema),
+			!match(f.Name, t.Name),
+			len(f.Types) != 0 && !contains(f.Types, t.Type):
+			continue
+		}
+		results = append(results, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return metadata.NewTableSet(results), nil
+}
+
+// Columns lists the columns of a table, where the data types of tags and
+// fields are suffixed with (tag) and (field).
+func (r metaReader) Columns(f metadata.Filter) (*metadata.ColumnSet, error) {
+	rows, closeRows, err := r.Query(`SELECT table_catalog, table_schema, table_name, column_name, ordinal_position, data_type, is_nullable FROM information_schema.columns ORDER BY table_schema, table_name, ordinal_position`)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	results := []metadata.Column{}
+	for rows.Next() {
+		var c metadata.Column
+		var nullable string
+		if err := rows.Scan(&c.Catalog, &c.Schema, &c.Table, &c.Name, &c.OrdinalPosition, &c.DataType, &nullable); err != nil {
+			return nil, err
+		}
+		switch {
+		case f.Schema == "" && contains(systemSchemas, c.Schema),
+			!match(f.Schema, c.Schema),
+			!match(f.Parent, c.Table),
+			!match(f.Name, c.Name):
+			continue
+		}
+		c.DataType, c.IsNullable = columnType(c.Name, c.DataType), metadata.Bool(nullable)
+		results = append(results, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return metadata.NewColumnSet(results), nil
+}
+
+// dictionaryRE matches the data type of dictionary encoded columns.
+var dictionaryRE = regexp.MustCompile(`^Dictionary\(\w+, (.+)\)$`)
+
+// columnType returns the data type of a column, where tags are dictionary
+// encoded columns, and fields all columns other than the time column.
+func columnType(name, typ string) string {
+	switch m := dictionaryRE.FindStringSubmatch(typ); {
+	case m != nil:
+		return m[1] + " (tag)"
+	case name == "time" && strings.HasPrefix(typ, "Timestamp"):
+		return typ
+	}
+	return typ + " (field)"
+}
+
+// match returns true when s matches the SQL LIKE pattern.
+func match(pattern, s string) bool {
+	if pattern == "" {
+		return true
+	}
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, c := range pattern {
+		switch c {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	ok, _ := regexp.MatchString(sb.String(), s)
+	return ok
+}
+
+// contains returns true when v contains s.
+func contains(v []string, s string) bool {
+	for _, z := range v {
+		if z == s {
+			return true
+		}
+	}
+	return false
+}
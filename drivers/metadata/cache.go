@@ -0,0 +1,207 @@
+package metadata
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Cache caches the rows of metadata queries, so that catalog queries of
+// metadata readers and completers are not repeated for each describe command
+// or completion.
+type Cache struct {
+	ttl     func() time.Duration
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	db      *sql.DB
+}
+
+// cacheEntry is the rows of a cached query.
+type cacheEntry struct {
+	cols    []string
+	vals    [][]driver.Value
+	expires time.Time
+}
+
+// NewCache creates a metadata query cache, caching rows for the duration
+// returned by ttl, where a duration of 0 disables the cache.
+func NewCache(ttl func() time.Duration) *Cache {
+	c := &Cache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+	c.db = sql.OpenDB(cacheConnector{})
+	return c
+}
+
+// Clear removes all cached rows.
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]cacheEntry)
+}
+
+// DB wraps db, caching the rows of its queries. Statements other than
+// queries, and queries of a single row, are not cached.
+func (c *Cache) DB(db DB) DB {
+	return cachedDB{DB: db, c: c}
+}
+
+// query returns the rows of the query, from the cache when not expired.
+func (c *Cache) query(ctx context.Context, db DB, query string, args ...interface{}) (*sql.Rows, error) {
+	ttl := c.ttl()
+	if ttl <= 0 {
+		return db.QueryContext(ctx, query, args...)
+	}
+	key := query
+	if len(args) != 0 {
+		key += fmt.Sprintf("\x00%#v", args)
+	}
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	c.mu.Unlock()
+	if !ok || time.Now().After(e.expires) {
+		var err error
+		if e, err = readEntry(ctx, db, query, args...); err != nil {
+			return nil, err
+		}
+		e.expires = time.Now().Add(ttl)
+		c.mu.Lock()
+		c.entries[key] = e
+		c.mu.Unlock()
+	}
+	return c.db.QueryContext(ctx, query, &e)
+}
+
+// readEntry reads all rows of the query.
+func readEntry(ctx context.Context, db DB, query string, args ...interface{}) (cacheEntry, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return cacheEntry{}, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return cacheEntry{}, err
+	}
+	e := cacheEntry{cols: cols}
+	for rows.Next() {
+		dest := make([]interface{}, len(cols))
+		for i := range dest {
+			dest[i] = new(interface{})
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return cacheEntry{}, err
+		}
+		vals := make([]driver.Value, len(cols))
+		for i, v := range dest {
+			vals[i] = *v.(*interface{})
+			if x, ok := vals[i].(driver.Valuer); ok {
+				if vals[i], err = x.Value(); err != nil {
+					return cacheEntry{}, err
+				}
+			}
+		}
+		e.vals = append(e.vals, vals)
+	}
+	return e, rows.Err()
+}
+
+// cachedDB is a database whose query rows are cached.
+type cachedDB struct {
+	DB
+	c *Cache
+}
+
+// Query satisfies the DB interface.
+func (db cachedDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.c.query(context.Background(), db.DB, query, args...)
+}
+
+// QueryContext satisfies the DB interface.
+func (db cachedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return db.c.query(ctx, db.DB, query, args...)
+}
+
+// cacheConnector is a database/sql connector returning the cached rows of a
+// query, passed as the query's argument.
+type cacheConnector struct{}
+
+// Connect satisfies the driver.Connector interface.
+func (c cacheConnector) Connect(context.Context) (driver.Conn, error) {
+	return cacheConn{}, nil
+}
+
+// Driver satisfies the driver.Connector interface.
+func (c cacheConnector) Driver() driver.Driver {
+	return cacheDriver{}
+}
+
+// cacheDriver is the driver of the cache connector.
+type cacheDriver struct{}
+
+// Open satisfies the driver.Driver interface.
+func (cacheDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("not supported")
+}
+
+// cacheConn is a connection returning the cached rows of a query.
+type cacheConn struct{}
+
+// Prepare satisfies the driver.Conn interface.
+func (c cacheConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+// Close satisfies the driver.Conn interface.
+func (c cacheConn) Close() error {
+	return nil
+}
+
+// Begin satisfies the driver.Conn interface.
+func (c cacheConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+// CheckNamedValue satisfies the driver.NamedValueChecker interface, allowing
+// the cached rows to be passed as the query's argument.
+func (c cacheConn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+// QueryContext satisfies the driver.QueryerContext interface.
+func (c cacheConn) QueryContext(_ context.Context, _ string, args []driver.NamedValue) (driver.Rows, error) {
+	e := args[0].Value.(*cacheEntry)
+	return &cacheRows{cols: e.cols, vals: e.vals}, nil
+}
+
+// cacheRows are the cached rows of a query.
+type cacheRows struct {
+	cols []string
+	vals [][]driver.Value
+}
+
+// Columns satisfies the driver.Rows interface.
+func (r *cacheRows) Columns() []string {
+	return r.cols
+}
+
+// Close satisfies the driver.Rows interface.
+func (r *cacheRows) Close() error {
+	return nil
+}
+
+// Next satisfies the driver.Rows interface.
+func (r *cacheRows) Next(dest []driver.Value) error {
+	if len(r.vals) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.vals[0])
+	r.vals = r.vals[1:]
+	return nil
+}
@@ -0,0 +1,89 @@
+package metadata
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestCache(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(`CREATE TABLE a (x INTEGER, y TEXT, z BLOB)`); err != nil {
+		t.Fatal(err)
+	}
+	ttl := time.Minute
+	c := NewCache(func() time.Duration { return ttl })
+	cdb := c.DB(db)
+	names := func() []string {
+		rows, err := cdb.Query(`SELECT name FROM sqlite_master WHERE type = ? ORDER BY name`, "table")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		defer rows.Close()
+		var v []string
+		for rows.Next() {
+			var s string
+			if err := rows.Scan(&s); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			v = append(v, s)
+		}
+		return v
+	}
+	if v := names(); len(v) != 1 || v[0] != "a" {
+		t.Fatalf("expected [a], got: %v", v)
+	}
+	if _, err := db.Exec(`CREATE TABLE b (x INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+	// cached
+	if v := names(); len(v) != 1 {
+		t.Errorf("expected cached [a], got: %v", v)
+	}
+	c.Clear()
+	if v := names(); len(v) != 2 {
+		t.Errorf("expected [a b], got: %v", v)
+	}
+	// disabled
+	if _, err := db.Exec(`CREATE TABLE c (x INTEGER)`); err != nil {
+		t.Fatal(err)
+	}
+	ttl = 0
+	if v := names(); len(v) != 3 {
+		t.Errorf("expected [a b c], got: %v", v)
+	}
+	// values are kept when replayed
+	ttl = time.Minute
+	if _, err := db.Exec(`INSERT INTO a VALUES (1, 'one', x'00ff'), (NULL, NULL, NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		rows, err := cdb.Query(`SELECT x, y, z FROM a ORDER BY x DESC`)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		var x []sql.NullInt64
+		var y []sql.NullString
+		var z [][]byte
+		for rows.Next() {
+			var a sql.NullInt64
+			var b sql.NullString
+			var c []byte
+			if err := rows.Scan(&a, &b, &c); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			x, y, z = append(x, a), append(y, b), append(z, c)
+		}
+		rows.Close()
+		if len(x) != 2 || x[0].Int64 != 1 || x[1].Valid || y[0].String != "one" || string(z[0]) != "\x00\xff" || z[1] != nil {
+			t.Errorf("test %d unexpected values: %v %v %v", i, x, y, z)
+		}
+	}
+}
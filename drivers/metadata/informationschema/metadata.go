@@ -22,6 +22,7 @@ type InformationSchema struct {
 	hasIndexes          bool
 	hasConstraints      bool
 	hasCheckConstraints bool
+	hasTriggers         bool
 	hasTablePrivileges  bool
 	hasColumnPrivileges bool
 	hasUsagePrivileges  bool
@@ -30,6 +31,7 @@ type InformationSchema struct {
 	systemSchemas       []string
 	currentSchema       string
 	dataTypeFormatter   func(metadata.Column) string
+	identifierCase      func(string) string
 }
 
 var _ metadata.BasicReader = &InformationSchema{}
@@ -46,6 +48,7 @@ const (
 	ColumnsNumericScale     = ClauseName("columns.numeric_scale")
 	ColumnsNumericPrecRadix = ClauseName("columns.numeric_precision_radix")
 	ColumnsCharOctetLength  = ClauseName("columns.character_octet_length")
+	ColumnsGenerated        = ClauseName("columns.generated")
 
 	FunctionColumnsColumnSize       = ClauseName("function_columns.column_size")
 	FunctionColumnsNumericScale     = ClauseName("function_columns.numeric_scale")
@@ -57,10 +60,17 @@ const (
 	ConstraintIsDeferrable      = ClauseName("constraint_columns.is_deferrable")
 	ConstraintInitiallyDeferred = ClauseName("constraint_columns.initially_deferred")
 	ConstraintJoinCond          = ClauseName("constraint_join.fk")
+	ConstraintCheckClause       = ClauseName("constraint_columns.check_clause")
 
 	SequenceColumnsIncrement = ClauseName("sequence_columns.increment")
 
 	PrivilegesGrantor = ClauseName("privileges.grantor")
+
+	SchemataSchemaOwner = ClauseName("schemata.schema_owner")
+
+	TablesRows = ClauseName("tables.rows")
+
+	IndexesColumns = ClauseName("indexes.columns")
 )
 
 // New InformationSchema reader
@@ -72,6 +82,7 @@ func New(opts ...metadata.ReaderOption) func(drivers.DB, ...metadata.ReaderOptio
 		hasIndexes:          true,
 		hasConstraints:      true,
 		hasCheckConstraints: true,
+		hasTriggers:         true,
 		hasTablePrivileges:  true,
 		hasColumnPrivileges: true,
 		hasUsagePrivileges:  true,
@@ -81,6 +92,8 @@ func New(opts ...metadata.ReaderOption) func(drivers.DB, ...metadata.ReaderOptio
 			ColumnsNumericScale:             "COALESCE(numeric_scale, 0)",
 			ColumnsNumericPrecRadix:         "COALESCE(numeric_precision_radix, 10)",
 			ColumnsCharOctetLength:          "COALESCE(character_octet_length, 0)",
+			ColumnsGenerated:                "''",
+			IndexesColumns:                  "''",
 			FunctionColumnsColumnSize:       "COALESCE(character_maximum_length, numeric_precision, datetime_precision, 0)",
 			FunctionColumnsNumericScale:     "COALESCE(numeric_scale, 0)",
 			FunctionColumnsNumericPrecRadix: "COALESCE(numeric_precision_radix, 10)",
@@ -88,11 +101,15 @@ func New(opts ...metadata.ReaderOption) func(drivers.DB, ...metadata.ReaderOptio
 			FunctionsSecurityType:           "security_type",
 			ConstraintIsDeferrable:          "t.is_deferrable",
 			ConstraintInitiallyDeferred:     "t.initially_deferred",
+			ConstraintCheckClause:           "COALESCE(c.check_clause, '')",
 			SequenceColumnsIncrement:        "increment",
 			PrivilegesGrantor:               "grantor",
+			SchemataSchemaOwner:             "COALESCE(schema_owner, '')",
+			TablesRows:                      "0",
 		},
 		systemSchemas:     []string{"information_schema"},
 		dataTypeFormatter: func(col metadata.Column) string { return col.DataType },
+		identifierCase:    func(s string) string { return s },
 	}
 	// aply InformationSchema specific options
 	for _, o := range opts {
@@ -150,6 +167,13 @@ func WithCheckConstraints(con bool) metadata.ReaderOption {
 	}
 }
 
+// WithTriggers when the `triggers` table exists
+func WithTriggers(trig bool) metadata.ReaderOption {
+	return func(r metadata.Reader) {
+		r.(*InformationSchema).hasTriggers = trig
+	}
+}
+
 // WithSequences when the `sequences` table exists
 func WithSequences(seq bool) metadata.ReaderOption {
 	return func(r metadata.Reader) {
@@ -200,6 +224,15 @@ func WithDataTypeFormatter(f func(metadata.Column) string) metadata.ReaderOption
 	}
 }
 
+// WithIdentifierCase function to convert catalog, schema, and object name
+// patterns from filters to the case used by the database when storing
+// identifiers
+func WithIdentifierCase(f func(string) string) metadata.ReaderOption {
+	return func(r metadata.Reader) {
+		r.(*InformationSchema).identifierCase = f
+	}
+}
+
 func (s *InformationSchema) SetLimit(l int) {
 	s.limit = l
 }
@@ -219,6 +252,7 @@ func (s InformationSchema) Columns(f metadata.Filter) (*metadata.ColumnSet, erro
 		s.clauses[ColumnsNumericScale],
 		s.clauses[ColumnsNumericPrecRadix],
 		s.clauses[ColumnsCharOctetLength],
+		s.clauses[ColumnsGenerated],
 	}
 
 	qstr := "SELECT\n  " + strings.Join(columns, ",\n  ") + " FROM information_schema.columns\n"
@@ -253,6 +287,7 @@ func (s InformationSchema) Columns(f metadata.Filter) (*metadata.ColumnSet, erro
 			&rec.DecimalDigits,
 			&rec.NumPrecRadix,
 			&rec.CharOctetLength,
+			&rec.Generated,
 		)
 		if err != nil {
 			return nil, err
@@ -272,7 +307,8 @@ func (s InformationSchema) Tables(f metadata.Filter) (*metadata.TableSet, error)
   table_catalog,
   table_schema,
   table_name,
-  table_type
+  table_type,
+  ` + s.clauses[TablesRows] + ` AS table_rows
 FROM information_schema.tables
 `
 	conds, vals := s.conditions(1, f, formats{
@@ -298,7 +334,8 @@ SELECT
   sequence_catalog AS table_catalog,
   sequence_schema AS table_schema,
   sequence_name AS table_name,
-  'SEQUENCE' AS table_type
+  'SEQUENCE' AS table_type,
+  0 AS table_rows
 FROM information_schema.sequences
 `
 		conds, seqVals := s.conditions(len(vals)+1, f, formats{
@@ -324,7 +361,7 @@ FROM information_schema.sequences
 	results := []metadata.Table{}
 	for rows.Next() {
 		rec := metadata.Table{}
-		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Type)
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.Type, &rec.Rows)
 		if err != nil {
 			return nil, err
 		}
@@ -340,7 +377,8 @@ FROM information_schema.sequences
 func (s InformationSchema) Schemas(f metadata.Filter) (*metadata.SchemaSet, error) {
 	qstr := `SELECT
   schema_name,
-  catalog_name
+  catalog_name,
+  ` + s.clauses[SchemataSchemaOwner] + `
 FROM information_schema.schemata
 `
 	conds, vals := s.conditions(1, f, formats{
@@ -360,7 +398,7 @@ FROM information_schema.schemata
 	results := []metadata.Schema{}
 	for rows.Next() {
 		rec := metadata.Schema{}
-		err = rows.Scan(&rec.Schema, &rec.Catalog)
+		err = rows.Scan(&rec.Schema, &rec.Catalog, &rec.Owner)
 		if err != nil {
 			return nil, err
 		}
@@ -511,7 +549,8 @@ func (s InformationSchema) Indexes(f metadata.Filter) (*metadata.IndexSet, error
   index_name,
   CASE WHEN non_unique = 0 THEN 'YES' ELSE 'NO' END AS is_unique,
   CASE WHEN index_name = 'PRIMARY' THEN 'YES' ELSE 'NO' END AS is_primary,
-  index_type
+  index_type,
+  ` + s.clauses[IndexesColumns] + `
 FROM information_schema.statistics
 `
 	conds, vals := s.conditions(1, f, formats{
@@ -541,7 +580,7 @@ GROUP BY table_catalog, index_schema, table_name, index_name,
 	results := []metadata.Index{}
 	for rows.Next() {
 		rec := metadata.Index{}
-		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Table, &rec.Name, &rec.IsUnique, &rec.IsPrimary, &rec.Type)
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Table, &rec.Name, &rec.IsUnique, &rec.IsPrimary, &rec.Type, &rec.Columns)
 		if err != nil {
 			return nil, err
 		}
@@ -627,7 +666,7 @@ func (s InformationSchema) Constraints(f metadata.Filter) (*metadata.ConstraintS
 		"COALESCE(r.match_option, '') AS match_options",
 		"COALESCE(r.update_rule, '') AS update_rule",
 		"COALESCE(r.delete_rule, '') AS delete_rule",
-		"COALESCE(c.check_clause, '') AS check_clause",
+		s.clauses[ConstraintCheckClause] + " AS check_clause",
 	}
 
 	qstr := "SELECT\n  " + strings.Join(columns, ",\n  ") + `
@@ -802,6 +841,73 @@ LEFT JOIN information_schema.key_column_usage f ON r.unique_constraint_catalog =
 	return metadata.NewConstraintColumnSet(results), nil
 }
 
+// Triggers from selected catalog (or all, if empty), matching schemas, tables
+// and names. Triggers fired by multiple events are merged into a single
+// trigger.
+func (s InformationSchema) Triggers(f metadata.Filter) (*metadata.TriggerSet, error) {
+	if !s.hasTriggers {
+		return nil, text.ErrNotSupported
+	}
+
+	qstr := `SELECT
+  event_object_catalog,
+  event_object_schema,
+  event_object_table,
+  trigger_name,
+  action_timing,
+  event_manipulation,
+  action_orientation,
+  action_statement
+FROM information_schema.triggers
+`
+	conds, vals := s.conditions(1, f, formats{
+		catalog:    "event_object_catalog LIKE %s",
+		schema:     "event_object_schema LIKE %s",
+		notSchemas: "event_object_schema NOT IN (%s)",
+		parent:     "event_object_table LIKE %s",
+		name:       "trigger_name LIKE %s",
+	})
+	rows, closeRows, err := s.query(qstr, conds, "event_object_catalog, event_object_schema, event_object_table, trigger_name", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewTriggerSet([]metadata.Trigger{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Trigger{}
+	var events []string
+	var timing, orientation, statement string
+	// define sets the definition of the last trigger
+	define := func() {
+		if len(results) != 0 {
+			rec := &results[len(results)-1]
+			rec.Definition = fmt.Sprintf("%s %s ON %s FOR EACH %s %s", timing, strings.Join(events, " OR "), rec.Table, orientation, statement)
+		}
+	}
+	for rows.Next() {
+		rec := metadata.Trigger{}
+		var event string
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Table, &rec.Name, &timing, &event, &orientation, &statement)
+		if err != nil {
+			return nil, err
+		}
+		if n := len(results); n != 0 && results[n-1].Catalog == rec.Catalog && results[n-1].Schema == rec.Schema &&
+			results[n-1].Table == rec.Table && results[n-1].Name == rec.Name {
+			events = append(events, event)
+			define()
+			continue
+		}
+		results, events = append(results, rec), []string{event}
+		define()
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewTriggerSet(results), nil
+}
+
 // Sequences from selected catalog (or all, if empty), matching schemas and names
 func (s InformationSchema) Sequences(f metadata.Filter) (*metadata.SequenceSet, error) {
 	if !s.hasSequences {
@@ -1002,6 +1108,11 @@ func (s InformationSchema) PrivilegeSummaries(f metadata.Filter) (*metadata.Priv
 func (s InformationSchema) conditions(baseParam int, filter metadata.Filter, formats formats) ([]string, []interface{}) {
 	conds := []string{}
 	vals := []interface{}{}
+	for _, v := range []*string{&filter.Catalog, &filter.Schema, &filter.Parent, &filter.Reference, &filter.Name} {
+		if *v != "" {
+			*v = s.identifierCase(*v)
+		}
+	}
 	if filter.Catalog != "" && formats.catalog != "" {
 		vals = append(vals, filter.Catalog)
 		conds = append(conds, fmt.Sprintf(formats.catalog, s.pf(baseParam)))
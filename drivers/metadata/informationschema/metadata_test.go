@@ -165,6 +165,7 @@ var (
 					infos.FunctionColumnsNumericScale:     "0",
 					infos.FunctionColumnsNumericPrecRadix: "0",
 					infos.FunctionColumnsCharOctetLength:  "0",
+					infos.SchemataSchemaOwner:             "''",
 				}),
 			},
 		},
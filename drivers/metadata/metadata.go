@@ -21,8 +21,19 @@ type ExtendedReader interface {
 	ConstraintColumnReader
 	FunctionReader
 	FunctionColumnReader
+	FunctionDefinitionReader
 	SequenceReader
 	PrivilegeSummaryReader
+	SettingReader
+	MaterializedViewReader
+	TableSizeReader
+	ForeignServerReader
+	ForeignTableReader
+	ForeignDataWrapperReader
+	ExtensionReader
+	ExtensionObjectReader
+	ObjectReader
+	RoleReader
 }
 
 // BasicReader of common database metadata like schemas, tables and columns.
@@ -104,6 +115,13 @@ type FunctionColumnReader interface {
 	FunctionColumns(Filter) (*FunctionColumnSet, error)
 }
 
+// FunctionDefinitionReader lists database functions with their complete
+// definitions as the source.
+type FunctionDefinitionReader interface {
+	Reader
+	FunctionDefinitions(Filter) (*FunctionSet, error)
+}
+
 // SequenceReader lists sequences.
 type SequenceReader interface {
 	Reader
@@ -116,6 +134,69 @@ type PrivilegeSummaryReader interface {
 	PrivilegeSummaries(Filter) (*PrivilegeSummarySet, error)
 }
 
+// SettingReader lists server configuration parameters.
+type SettingReader interface {
+	Reader
+	Settings(Filter) (*SettingSet, error)
+}
+
+// MaterializedViewReader lists materialized views.
+type MaterializedViewReader interface {
+	Reader
+	MaterializedViews(Filter) (*MaterializedViewSet, error)
+}
+
+// TableSizeReader lists the total on-disk size of tables, including their
+// indexes and other associated storage.
+type TableSizeReader interface {
+	Reader
+	TableSizes(Filter) (*TableSizeSet, error)
+}
+
+// ForeignServerReader lists foreign servers.
+type ForeignServerReader interface {
+	Reader
+	ForeignServers(Filter) (*ForeignServerSet, error)
+}
+
+// ForeignTableReader lists foreign tables.
+type ForeignTableReader interface {
+	Reader
+	ForeignTables(Filter) (*ForeignTableSet, error)
+}
+
+// ForeignDataWrapperReader lists foreign-data wrappers.
+type ForeignDataWrapperReader interface {
+	Reader
+	ForeignDataWrappers(Filter) (*ForeignDataWrapperSet, error)
+}
+
+// ExtensionReader lists installed extensions.
+type ExtensionReader interface {
+	Reader
+	Extensions(Filter) (*ExtensionSet, error)
+}
+
+// ExtensionObjectReader lists objects owned by extensions.
+type ExtensionObjectReader interface {
+	Reader
+	ExtensionObjects(Filter) (*ExtensionObjectSet, error)
+}
+
+// ObjectReader searches tables, views, columns, functions, and indexes by
+// name, where the Name of the filter is a lower case LIKE pattern matched
+// against lower case object names.
+type ObjectReader interface {
+	Reader
+	Objects(Filter) (*ObjectSet, error)
+}
+
+// RoleReader lists roles (or users), with their attributes and memberships.
+type RoleReader interface {
+	Reader
+	Roles(Filter) (*RoleSet, error)
+}
+
 // Reader of any database metadata in a structured format.
 type Reader interface{}
 
@@ -159,7 +240,25 @@ type Writer interface {
 	// ShowStats \ss
 	ShowStats(*dburl.URL, string, string, bool, int) error
 	// ListPrivilegeSummaries \dp
-	ListPrivilegeSummaries(*dburl.URL, string, bool) error
+	ListPrivilegeSummaries(*dburl.URL, string, bool, bool) error
+	// ListSettings \dconfig
+	ListSettings(*dburl.URL, string, bool) error
+	// ListMaterializedViews \dm
+	ListMaterializedViews(*dburl.URL, string, bool, bool) error
+	// ListSequences \ds+
+	ListSequences(*dburl.URL, string, bool, bool) error
+	// ListForeignServers \des
+	ListForeignServers(*dburl.URL, string, bool) error
+	// ListForeignTables \det
+	ListForeignTables(*dburl.URL, string, bool) error
+	// ListForeignDataWrappers \dew
+	ListForeignDataWrappers(*dburl.URL, string, bool) error
+	// ListExtensions \dx
+	ListExtensions(*dburl.URL, string, bool) error
+	// SearchObjects \dos
+	SearchObjects(*dburl.URL, string, bool) error
+	// ListRoles \du, \droles
+	ListRoles(*dburl.URL, string, bool, bool) error
 }
 
 type CatalogSet struct {
@@ -231,8 +330,10 @@ func (s SchemaSet) Get() *Schema {
 }
 
 type Schema struct {
-	Schema  string
-	Catalog string
+	Schema           string
+	Catalog          string
+	Owner            string
+	AccessPrivileges string
 }
 
 func (s Schema) Values() []interface{} {
@@ -318,6 +419,7 @@ func NewColumnSet(v []Column) *ColumnSet {
 				"Decimal Digits",
 				"Precision Radix",
 				"Octet Length",
+				"Generated",
 			},
 		},
 	}
@@ -341,6 +443,9 @@ type Column struct {
 	NumPrecRadix    int
 	CharOctetLength int
 	IsNullable      Bool
+	// Generated describes how the values of an identity or generated column
+	// are generated, such as "generated always as identity", or is empty.
+	Generated string
 }
 
 type Bool string
@@ -364,6 +469,7 @@ func (c Column) Values() []interface{} {
 		c.DecimalDigits,
 		c.NumPrecRadix,
 		c.CharOctetLength,
+		c.Generated,
 	}
 }
 
@@ -474,6 +580,9 @@ type Index struct {
 	IsUnique  Bool
 	Type      string
 	Columns   string
+	// Predicate is the condition of a partial index.
+	Predicate string
+	Size      string
 }
 
 func (i Index) Values() []interface{} {
@@ -847,6 +956,10 @@ type Sequence struct {
 	Max       string
 	Increment string
 	Cycles    Bool
+	// LastValue is the last value returned by the sequence, or empty when
+	// not yet used or not known
+	LastValue string
+	Cache     string
 }
 
 func (s Sequence) Values() []interface{} {
@@ -908,6 +1021,491 @@ func (s PrivilegeSummary) Values() []interface{} {
 	}
 }
 
+type SettingSet struct {
+	resultSet
+}
+
+func NewSettingSet(v []Setting) *SettingSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &SettingSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Parameter",
+				"Value",
+			},
+		},
+	}
+}
+
+func (s SettingSet) Get() *Setting {
+	return s.results[s.current-1].(*Setting)
+}
+
+// Setting is a server configuration parameter
+type Setting struct {
+	Name        string
+	Value       string
+	Type        string
+	Context     string
+	Description string
+}
+
+func (s Setting) Values() []interface{} {
+	return []interface{}{
+		s.Name,
+		s.Value,
+	}
+}
+
+type ResultColumnSet struct {
+	resultSet
+}
+
+func NewResultColumnSet(v []ResultColumn) *ResultColumnSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &ResultColumnSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Column",
+				"Type",
+			},
+		},
+	}
+}
+
+func (s ResultColumnSet) Get() *ResultColumn {
+	return s.results[s.current-1].(*ResultColumn)
+}
+
+// ResultColumn is a column of a query's result
+type ResultColumn struct {
+	Name string
+	Type string
+}
+
+func (c ResultColumn) Values() []interface{} {
+	return []interface{}{
+		c.Name,
+		c.Type,
+	}
+}
+
+type MaterializedViewSet struct {
+	resultSet
+}
+
+func NewMaterializedViewSet(v []MaterializedView) *MaterializedViewSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &MaterializedViewSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Schema",
+				"Name",
+				"Type",
+			},
+		},
+	}
+}
+
+func (s MaterializedViewSet) Get() *MaterializedView {
+	return s.results[s.current-1].(*MaterializedView)
+}
+
+// MaterializedView is a materialized view, with its refresh state
+type MaterializedView struct {
+	Catalog     string
+	Schema      string
+	Name        string
+	State       string
+	LastRefresh string
+	Definition  string
+}
+
+func (v MaterializedView) Values() []interface{} {
+	return []interface{}{
+		v.Schema,
+		v.Name,
+		"materialized view",
+	}
+}
+
+type TableSizeSet struct {
+	resultSet
+}
+
+func NewTableSizeSet(v []TableSize) *TableSizeSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &TableSizeSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Schema",
+				"Name",
+				"Size",
+			},
+		},
+	}
+}
+
+func (s TableSizeSet) Get() *TableSize {
+	return s.results[s.current-1].(*TableSize)
+}
+
+// TableSize is the total on-disk size of a table, in bytes
+type TableSize struct {
+	Catalog string
+	Schema  string
+	Name    string
+	Size    int64
+}
+
+func (s TableSize) Values() []interface{} {
+	return []interface{}{
+		s.Schema,
+		s.Name,
+		s.Size,
+	}
+}
+
+type ForeignServerSet struct {
+	resultSet
+}
+
+func NewForeignServerSet(v []ForeignServer) *ForeignServerSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &ForeignServerSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Name",
+				"Owner",
+				"Foreign-data wrapper",
+			},
+		},
+	}
+}
+
+func (s ForeignServerSet) Get() *ForeignServer {
+	return s.results[s.current-1].(*ForeignServer)
+}
+
+// ForeignServer is a foreign server
+type ForeignServer struct {
+	Name        string
+	Owner       string
+	Wrapper     string
+	Privileges  string
+	Type        string
+	Version     string
+	Options     string
+	Description string
+}
+
+func (s ForeignServer) Values() []interface{} {
+	return []interface{}{
+		s.Name,
+		s.Owner,
+		s.Wrapper,
+	}
+}
+
+type ForeignTableSet struct {
+	resultSet
+}
+
+func NewForeignTableSet(v []ForeignTable) *ForeignTableSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &ForeignTableSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Schema",
+				"Table",
+				"Server",
+			},
+		},
+	}
+}
+
+func (s ForeignTableSet) Get() *ForeignTable {
+	return s.results[s.current-1].(*ForeignTable)
+}
+
+// ForeignTable is a foreign table, and the server it is stored on
+type ForeignTable struct {
+	Catalog     string
+	Schema      string
+	Name        string
+	Server      string
+	Options     string
+	Description string
+}
+
+func (t ForeignTable) Values() []interface{} {
+	return []interface{}{
+		t.Schema,
+		t.Name,
+		t.Server,
+	}
+}
+
+type ForeignDataWrapperSet struct {
+	resultSet
+}
+
+func NewForeignDataWrapperSet(v []ForeignDataWrapper) *ForeignDataWrapperSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &ForeignDataWrapperSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Name",
+				"Owner",
+				"Handler",
+				"Validator",
+			},
+		},
+	}
+}
+
+func (s ForeignDataWrapperSet) Get() *ForeignDataWrapper {
+	return s.results[s.current-1].(*ForeignDataWrapper)
+}
+
+// ForeignDataWrapper is a foreign-data wrapper
+type ForeignDataWrapper struct {
+	Name        string
+	Owner       string
+	Handler     string
+	Validator   string
+	Privileges  string
+	Options     string
+	Description string
+}
+
+func (w ForeignDataWrapper) Values() []interface{} {
+	return []interface{}{
+		w.Name,
+		w.Owner,
+		w.Handler,
+		w.Validator,
+	}
+}
+
+type ExtensionSet struct {
+	resultSet
+}
+
+func NewExtensionSet(v []Extension) *ExtensionSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &ExtensionSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Name",
+				"Version",
+				"Schema",
+				"Description",
+			},
+		},
+	}
+}
+
+func (s ExtensionSet) Get() *Extension {
+	return s.results[s.current-1].(*Extension)
+}
+
+// Extension is an installed extension, or plugin
+type Extension struct {
+	Name        string
+	Version     string
+	Schema      string
+	Description string
+}
+
+func (e Extension) Values() []interface{} {
+	return []interface{}{
+		e.Name,
+		e.Version,
+		e.Schema,
+		e.Description,
+	}
+}
+
+type ExtensionObjectSet struct {
+	resultSet
+}
+
+func NewExtensionObjectSet(v []ExtensionObject) *ExtensionObjectSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &ExtensionObjectSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Object description",
+			},
+		},
+	}
+}
+
+func (s ExtensionObjectSet) Get() *ExtensionObject {
+	return s.results[s.current-1].(*ExtensionObject)
+}
+
+// ExtensionObject is an object owned by an extension
+type ExtensionObject struct {
+	Extension   string
+	Description string
+}
+
+func (o ExtensionObject) Values() []interface{} {
+	return []interface{}{
+		o.Description,
+	}
+}
+
+type ObjectSet struct {
+	resultSet
+}
+
+func NewObjectSet(v []Object) *ObjectSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &ObjectSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Schema",
+				"Name",
+				"Type",
+				"Table",
+			},
+		},
+	}
+}
+
+func (s ObjectSet) Get() *Object {
+	return s.results[s.current-1].(*Object)
+}
+
+// Object is a database object found by name, where Table is the table of a
+// column or index
+type Object struct {
+	Schema string
+	Name   string
+	Type   string
+	Table  string
+}
+
+func (o Object) Values() []interface{} {
+	return []interface{}{
+		o.Schema,
+		o.Name,
+		o.Type,
+		o.Table,
+	}
+}
+
+type RoleSet struct {
+	resultSet
+}
+
+func NewRoleSet(v []Role) *RoleSet {
+	r := make([]Result, len(v))
+	for i := range v {
+		r[i] = &v[i]
+	}
+	return &RoleSet{
+		resultSet: resultSet{
+			results: r,
+			columns: []string{
+				"Role name",
+				"Attributes",
+			},
+		},
+	}
+}
+
+func (s RoleSet) Get() *Role {
+	return s.results[s.current-1].(*Role)
+}
+
+// Role is a role (or user) and its attributes, where a ConnectionLimit of -1
+// is no limit, and MemberOf is a comma separated list of the roles it is a
+// member of
+type Role struct {
+	Name            string
+	Superuser       bool
+	CreateRole      bool
+	CreateDB        bool
+	Login           bool
+	Replication     bool
+	BypassRLS       bool
+	ConnectionLimit int
+	ValidUntil      string
+	MemberOf        string
+}
+
+// Attributes returns the attributes of the role, as displayed by psql.
+func (r Role) Attributes() string {
+	var v []string
+	for _, a := range []struct {
+		ok   bool
+		name string
+	}{
+		{r.Superuser, "Superuser"},
+		{r.CreateRole, "Create role"},
+		{r.CreateDB, "Create DB"},
+		{!r.Login, "Cannot login"},
+		{r.Replication, "Replication"},
+		{r.BypassRLS, "Bypass RLS"},
+	} {
+		if a.ok {
+			v = append(v, a.name)
+		}
+	}
+	return strings.Join(v, ", ")
+}
+
+func (r Role) Values() []interface{} {
+	return []interface{}{
+		r.Name,
+		r.Attributes(),
+	}
+}
+
 // ObjectPrivilege represents a privilege granted on a database object.
 type ObjectPrivilege struct {
 	Grantee       string
@@ -173,3 +173,139 @@ func TestColumnPrivileges_String(t *testing.T) {
 		})
 	}
 }
+
+type functionDefinitionReader []Function
+
+func (r functionDefinitionReader) FunctionDefinitions(Filter) (*FunctionSet, error) {
+	return NewFunctionSet(r), nil
+}
+
+func TestFunctionSource(t *testing.T) {
+	r := functionDefinitionReader{
+		{Schema: "public", Name: "f", ArgTypes: "integer", Source: "f(integer)"},
+		{Schema: "public", Name: "f", ArgTypes: "integer, text", Source: "f(integer, text)"},
+		{Schema: "public", Name: "g", Source: "g()"},
+		{Schema: "other", Name: "g", Source: "other.g()"},
+	}
+	tests := []struct {
+		name string
+		want string
+		err  string
+	}{
+		{"f(integer)", "f(integer)", ""},
+		{"public.f( INTEGER,text )", "f(integer, text)", ""},
+		{"public.g", "g()", ""},
+		{"other.g()", "other.g()", ""},
+		{"f", "", `more than one function named "f"`},
+		{"g", "", `more than one function named "g"`},
+		{"f(text)", "", `function "f" does not exist`},
+		{"h", "", `function "h" does not exist`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := FunctionSource(r, test.name)
+			switch {
+			case test.err != "" && (err == nil || err.Error() != test.err):
+				t.Fatalf("expected error %q, got: %v", test.err, err)
+			case test.err == "" && err != nil:
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("expected %q, got: %q", test.want, got)
+			}
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{0, "0 bytes"},
+		{8192, "8192 bytes"},
+		{10 * 1024, "10 kB"},
+		{48 * 1024 * 1024, "48 MB"},
+		{3 * 1024 * 1024 * 1024 * 1024, "3072 GB"},
+		{20 * 1024 * 1024 * 1024 * 1024, "20 TB"},
+	}
+	for _, test := range tests {
+		if got := formatSize(test.size); got != test.want {
+			t.Errorf("formatSize(%d) expected %q, got: %q", test.size, test.want, got)
+		}
+	}
+}
+
+func TestRoleAttributes(t *testing.T) {
+	tests := []struct {
+		role Role
+		want string
+	}{
+		{Role{Login: true}, ""},
+		{Role{}, "Cannot login"},
+		{Role{Superuser: true, CreateRole: true, CreateDB: true, Login: true, Replication: true, BypassRLS: true}, "Superuser, Create role, Create DB, Replication, Bypass RLS"},
+		{Role{CreateDB: true}, "Create DB, Cannot login"},
+	}
+	for i, test := range tests {
+		if got := test.role.Attributes(); got != test.want {
+			t.Errorf("test %d expected %q, got: %q", i, test.want, got)
+		}
+	}
+}
+
+func TestObjectPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		like    string
+		match   []string
+		noMatch []string
+	}{
+		{"User", "%user%", []string{"users", "active_users", "user_id"}, []string{"orders"}},
+		{"*_idx", "%_idx", []string{"users_name_idx"}, []string{"idx_users"}},
+		{"user?", "user_", []string{"users"}, []string{"user", "user_id"}},
+	}
+	for _, test := range tests {
+		like := objectPattern(test.pattern)
+		if like != test.like {
+			t.Errorf("objectPattern(%q) expected %q, got: %q", test.pattern, test.like, like)
+		}
+		re := likeRegexp(like)
+		for _, s := range test.match {
+			if !re.MatchString(s) {
+				t.Errorf("expected %q to match %q", like, s)
+			}
+		}
+		for _, s := range test.noMatch {
+			if re.MatchString(s) {
+				t.Errorf("expected %q to not match %q", like, s)
+			}
+		}
+	}
+}
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		pattern      string
+		schema, name string
+		err          bool
+	}{
+		{"authors", "", "authors", false},
+		{"public.auth*", "public", "auth%", false},
+		{`"My Schema"."my.table"`, "My Schema", "my.table", false},
+		{"[dbo].[order details]", "dbo", "order details", false},
+		{`[a]]b].x`, "a]b", "x", false},
+		{`"a""b"`, "", `a"b`, false},
+		{"[dbo", "", "", true},
+	}
+	for _, test := range tests {
+		schema, name, err := parsePattern(test.pattern)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("parsePattern(%q) expected error", test.pattern)
+		case !test.err && err != nil:
+			t.Errorf("parsePattern(%q) expected no error, got: %v", test.pattern, err)
+		case schema != test.schema || name != test.name:
+			t.Errorf("parsePattern(%q) expected %q, %q, got: %q, %q", test.pattern, test.schema, test.name, schema, name)
+		}
+	}
+}
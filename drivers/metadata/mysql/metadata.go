@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/gohxs/readline"
@@ -10,9 +11,17 @@ import (
 	infos "github.com/rmasci/usql/drivers/metadata/informationschema"
 )
 
+type metaReader struct {
+	metadata.LoggingReader
+}
+
+var _ metadata.SettingReader = &metaReader{}
+var _ metadata.TableSizeReader = &metaReader{}
+var _ metadata.ExtensionReader = &metaReader{}
+var _ metadata.RoleReader = &metaReader{}
+
 var (
-	// NewReader for MySQL databases
-	NewReader = infos.New(
+	newIS = infos.New(
 		infos.WithPlaceholder(func(int) string { return "?" }),
 		infos.WithSequences(false),
 		infos.WithCheckConstraints(false),
@@ -23,12 +32,30 @@ var (
 			infos.ConstraintIsDeferrable:          "''",
 			infos.ConstraintInitiallyDeferred:     "''",
 			infos.PrivilegesGrantor:               "''",
+			infos.SchemataSchemaOwner:             "''",
 			infos.ConstraintJoinCond:              "AND r.referenced_table_name = f.table_name",
+			infos.TablesRows:                      "COALESCE(table_rows, 0)",
+			infos.IndexesColumns:                  "GROUP_CONCAT(column_name ORDER BY seq_in_index SEPARATOR ', ')",
+			infos.ColumnsGenerated: `CASE
+  WHEN extra LIKE '%auto_increment%' THEN 'auto_increment'
+  WHEN extra LIKE 'VIRTUAL GENERATED%' THEN CONCAT('generated always as (', generation_expression, ') virtual')
+  WHEN extra LIKE 'STORED GENERATED%' THEN CONCAT('generated always as (', generation_expression, ') stored')
+  ELSE ''
+END`,
 		}),
 		infos.WithSystemSchemas([]string{"mysql", "information_schema", "performance_schema", "sys"}),
 		infos.WithCurrentSchema("COALESCE(DATABASE(), '%')"),
 		infos.WithUsagePrivileges(false),
 	)
+	// NewReader for MySQL databases
+	NewReader = func(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
+		return metadata.NewPluginReader(
+			newIS(db, opts...),
+			&metaReader{
+				LoggingReader: metadata.NewLoggingReader(db, opts...),
+			},
+		)
+	}
 	// NewCompleter for MySQL databases
 	NewCompleter = func(db drivers.DB, opts ...completer.Option) readline.AutoCompleter {
 		readerOpts := []metadata.ReaderOption{
@@ -67,3 +94,188 @@ func completeWithSchemas(reader metadata.Reader, text []rune) [][]rune {
 	}
 	return completer.CompleteFromList(text, schemaNames...)
 }
+
+func (r metaReader) Settings(f metadata.Filter) (*metadata.SettingSet, error) {
+	qstr, vals := "SHOW VARIABLES", []interface{}{}
+	if f.Name != "" {
+		qstr, vals = qstr+" LIKE ?", append(vals, f.Name)
+	}
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewSettingSet([]metadata.Setting{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Setting{}
+	for rows.Next() {
+		rec := metadata.Setting{}
+		if err := rows.Scan(&rec.Name, &rec.Value); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewSettingSet(results), nil
+}
+
+func (r metaReader) TableSizes(f metadata.Filter) (*metadata.TableSizeSet, error) {
+	qstr := `SELECT
+  table_schema,
+  table_name,
+  COALESCE(data_length, 0) + COALESCE(index_length, 0)
+FROM information_schema.tables
+WHERE table_type = 'BASE TABLE'`
+	vals := []interface{}{}
+	if f.OnlyVisible {
+		qstr += " AND table_schema = DATABASE()"
+	}
+	if !f.WithSystem {
+		qstr += " AND table_schema NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')"
+	}
+	if f.Schema != "" {
+		qstr, vals = qstr+" AND table_schema LIKE ?", append(vals, f.Schema)
+	}
+	if f.Name != "" {
+		qstr, vals = qstr+" AND table_name LIKE ?", append(vals, f.Name)
+	}
+	rows, closeRows, err := r.Query(qstr+"\nORDER BY 1, 2", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewTableSizeSet([]metadata.TableSize{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.TableSize{}
+	for rows.Next() {
+		rec := metadata.TableSize{}
+		if err := rows.Scan(&rec.Schema, &rec.Name, &rec.Size); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewTableSizeSet(results), nil
+}
+
+// Extensions lists the installed plugins, as displayed by SHOW PLUGINS.
+func (r metaReader) Extensions(f metadata.Filter) (*metadata.ExtensionSet, error) {
+	qstr := `SELECT
+  plugin_name,
+  plugin_version,
+  CONCAT(plugin_type, ' (', plugin_status, ')', COALESCE(CONCAT(': ', plugin_description), ''))
+FROM information_schema.plugins`
+	vals := []interface{}{}
+	if f.Name != "" {
+		qstr, vals = qstr+" WHERE plugin_name LIKE ?", append(vals, f.Name)
+	}
+	rows, closeRows, err := r.Query(qstr+"\nORDER BY 1", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewExtensionSet([]metadata.Extension{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Extension{}
+	for rows.Next() {
+		rec := metadata.Extension{}
+		if err := rows.Scan(&rec.Name, &rec.Version, &rec.Description); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewExtensionSet(results), nil
+}
+
+// Roles lists the accounts of mysql.user (as user@host), with their
+// privileges as attributes, and the roles granted to them from
+// mysql.role_edges when it exists (MySQL 8.0+). As accounts can only be
+// locked, accounts are able to login unless locked, and a password expiring
+// is displayed as its valid until time.
+func (r metaReader) Roles(f metadata.Filter) (*metadata.RoleSet, error) {
+	var n int
+	if err := r.queryRow(`SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = 'mysql' AND table_name = 'role_edges'`, &n); err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	memberOf := "''"
+	if n != 0 {
+		memberOf = `COALESCE((
+    SELECT GROUP_CONCAT(CONCAT(e.from_user, '@', e.from_host) ORDER BY 1 SEPARATOR ', ')
+    FROM mysql.role_edges e
+    WHERE e.to_user = u.user AND e.to_host = u.host
+  ), '')`
+	}
+	qstr := `SELECT
+  CONCAT(u.user, '@', u.host),
+  u.super_priv = 'Y',
+  u.create_user_priv = 'Y',
+  u.create_priv = 'Y',
+  u.account_locked <> 'Y',
+  u.repl_slave_priv = 'Y',
+  CASE WHEN u.max_user_connections = 0 THEN -1 ELSE u.max_user_connections END,
+  CASE
+    WHEN u.password_expired = 'Y' THEN 'expired'
+    WHEN u.password_lifetime > 0 THEN CAST(DATE_ADD(u.password_last_changed, INTERVAL u.password_lifetime DAY) AS CHAR)
+    ELSE ''
+  END,
+  ` + memberOf + `
+FROM mysql.user u
+WHERE 1 = 1`
+	vals := []interface{}{}
+	if !f.WithSystem {
+		qstr += " AND u.user NOT IN ('mysql.sys', 'mysql.session', 'mysql.infoschema')"
+	}
+	if f.Name != "" {
+		qstr, vals = qstr+" AND u.user LIKE ?", append(vals, f.Name)
+	}
+	rows, closeRows, err := r.Query(qstr+"\nORDER BY 1", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewRoleSet([]metadata.Role{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Role{}
+	for rows.Next() {
+		rec := metadata.Role{}
+		if err := rows.Scan(&rec.Name, &rec.Superuser, &rec.CreateRole, &rec.CreateDB, &rec.Login, &rec.Replication, &rec.ConnectionLimit, &rec.ValidUntil, &rec.MemberOf); err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewRoleSet(results), nil
+}
+
+// queryRow scans the first row of the query into dest.
+func (r metaReader) queryRow(qstr string, dest ...interface{}) error {
+	rows, closeRows, err := r.Query(qstr)
+	if err != nil {
+		return err
+	}
+	defer closeRows()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	return rows.Scan(dest...)
+}
@@ -18,6 +18,7 @@ type metaReader struct {
 var _ metadata.BasicReader = &metaReader{}
 var _ metadata.IndexReader = &metaReader{}
 var _ metadata.IndexColumnReader = &metaReader{}
+var _ metadata.MaterializedViewReader = &metaReader{}
 
 func NewReader() func(drivers.DB, ...metadata.ReaderOption) metadata.Reader {
 	return func(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
@@ -177,11 +178,22 @@ func (r metaReader) Columns(f metadata.Filter) (*metadata.ColumnSet, error) {
   c.table_name,
   c.column_name,
   c.column_id AS ordinal_position,
-  c.data_type,
+  CASE
+    WHEN c.data_type = 'NUMBER' AND c.data_precision IS NOT NULL AND COALESCE(c.data_scale, 0) != 0
+      THEN 'NUMBER(' || c.data_precision || ',' || c.data_scale || ')'
+    WHEN c.data_type = 'NUMBER' AND c.data_precision IS NOT NULL
+      THEN 'NUMBER(' || c.data_precision || ')'
+    WHEN c.data_type = 'NUMBER' AND c.data_scale = 0
+      THEN 'INTEGER'
+    WHEN c.data_type IN ('CHAR', 'NCHAR', 'VARCHAR2', 'NVARCHAR2')
+      THEN c.data_type || '(' || c.char_length || CASE c.char_used WHEN 'C' THEN ' CHAR' END || ')'
+    WHEN c.data_type = 'RAW'
+      THEN 'RAW(' || c.data_length || ')'
+    ELSE c.data_type END AS data_type,
   CASE c.nullable
     WHEN 'Y' THEN 'YES'
     ELSE  'NO'  END AS nullable,
-  COALESCE(c.data_length, c.data_precision, 0),
+  COALESCE(c.data_precision, c.data_length, 0),
   COALESCE(c.data_scale, 0),
   CASE c.data_type
            WHEN 'FLOAT'  THEN  2
@@ -434,14 +446,60 @@ ORDER BY o.owner, o.table_name, o.index_name, b.column_position`
 	return metadata.NewIndexColumnSet(results), nil
 }
 
+func (r metaReader) MaterializedViews(f metadata.Filter) (*metadata.MaterializedViewSet, error) {
+	qstr := `SELECT
+  m.owner,
+  m.mview_name,
+  m.staleness,
+  TO_CHAR(m.last_refresh_date, 'YYYY-MM-DD HH24:MI:SS'),
+  m.query
+FROM all_mviews m
+`
+	conds, vals := r.conditions(f, formats{
+		schema:     "m.owner LIKE %s",
+		notSchemas: "m.owner NOT IN (%s)",
+		name:       "m.mview_name LIKE :%d",
+	})
+	if len(conds) != 0 {
+		qstr += " WHERE " + strings.Join(conds, " AND ")
+	}
+	qstr += `
+ORDER BY m.owner, m.mview_name`
+	rows, closeRows, err := r.Query(qstr, vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewMaterializedViewSet([]metadata.MaterializedView{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.MaterializedView{}
+	for rows.Next() {
+		rec := metadata.MaterializedView{}
+		var state, lastRefresh, definition sql.NullString
+		err = rows.Scan(&rec.Schema, &rec.Name, &state, &lastRefresh, &definition)
+		if err != nil {
+			return nil, err
+		}
+		rec.State, rec.LastRefresh, rec.Definition = state.String, lastRefresh.String, definition.String
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewMaterializedViewSet(results), nil
+}
+
 func (r metaReader) conditions(filter metadata.Filter, formats formats) ([]string, []interface{}) {
 	baseParam := 1
 	conds := []string{}
 	vals := []interface{}{}
 	if filter.Schema != "" && formats.schema != "" {
-		vals = append(vals, strings.ToUpper(filter.Schema))
-		conds = append(conds, fmt.Sprintf(formats.schema, fmt.Sprintf(":%d", baseParam)))
-		baseParam++
+		cond, v := foldCond(func(n int) string {
+			return fmt.Sprintf(formats.schema, fmt.Sprintf(":%d", n))
+		}, filter.Schema, &baseParam)
+		vals, conds = append(vals, v...), append(conds, cond)
 	}
 
 	if !filter.WithSystem && formats.notSchemas != "" {
@@ -451,14 +509,16 @@ func (r metaReader) conditions(filter metadata.Filter, formats formats) ([]strin
 		conds = append(conds, fmt.Sprintf(formats.schema, "user"))
 	}
 	if filter.Parent != "" && formats.parent != "" {
-		vals = append(vals, strings.ToUpper(filter.Parent))
-		conds = append(conds, fmt.Sprintf(formats.parent, baseParam))
-		baseParam++
+		cond, v := foldCond(func(n int) string {
+			return fmt.Sprintf(formats.parent, n)
+		}, filter.Parent, &baseParam)
+		vals, conds = append(vals, v...), append(conds, cond)
 	}
 	if filter.Name != "" && formats.name != "" {
-		vals = append(vals, strings.ToUpper(filter.Name))
-		conds = append(conds, fmt.Sprintf(formats.name, baseParam))
-		baseParam++
+		cond, v := foldCond(func(n int) string {
+			return fmt.Sprintf(formats.name, n)
+		}, filter.Name, &baseParam)
+		vals, conds = append(vals, v...), append(conds, cond)
 	}
 	if len(filter.Types) != 0 && formats.types != "" {
 		pholders := []string{}
@@ -475,6 +535,19 @@ func (r metaReader) conditions(filter metadata.Filter, formats formats) ([]strin
 	return conds, vals
 }
 
+// foldCond returns the condition matching the name either as is, or folded to
+// upper case, as Oracle does for unquoted identifiers, incrementing n by the
+// number of placeholders used.
+func foldCond(format func(int) string, name string, n *int) (string, []interface{}) {
+	cond, vals := format(*n), []interface{}{name}
+	*n++
+	if upper := strings.ToUpper(name); upper != name {
+		cond, vals = "("+cond+" OR "+format(*n)+")", append(vals, upper)
+		*n++
+	}
+	return cond, vals
+}
+
 type formats struct {
 	schema     string
 	notSchemas string
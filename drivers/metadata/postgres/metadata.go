@@ -18,11 +18,24 @@ type metaReader struct {
 }
 
 var _ metadata.CatalogReader = &metaReader{}
+var _ metadata.SchemaReader = &metaReader{}
 var _ metadata.TableReader = &metaReader{}
 var _ metadata.ColumnStatReader = &metaReader{}
 var _ metadata.IndexReader = &metaReader{}
 var _ metadata.IndexColumnReader = &metaReader{}
 var _ metadata.TriggerReader = &metaReader{}
+var _ metadata.FunctionDefinitionReader = &metaReader{}
+var _ metadata.SettingReader = &metaReader{}
+var _ metadata.MaterializedViewReader = &metaReader{}
+var _ metadata.SequenceReader = &metaReader{}
+var _ metadata.TableSizeReader = &metaReader{}
+var _ metadata.ForeignServerReader = &metaReader{}
+var _ metadata.ForeignTableReader = &metaReader{}
+var _ metadata.ForeignDataWrapperReader = &metaReader{}
+var _ metadata.ExtensionReader = &metaReader{}
+var _ metadata.ExtensionObjectReader = &metaReader{}
+var _ metadata.ObjectReader = &metaReader{}
+var _ metadata.RoleReader = &metaReader{}
 
 func NewReader() func(drivers.DB, ...metadata.ReaderOption) metadata.Reader {
 	return func(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
@@ -31,6 +44,29 @@ func NewReader() func(drivers.DB, ...metadata.ReaderOption) metadata.Reader {
 			infos.WithCustomClauses(map[infos.ClauseName]string{
 				infos.ColumnsColumnSize:         "COALESCE(character_maximum_length, numeric_precision, datetime_precision, interval_precision, 0)",
 				infos.FunctionColumnsColumnSize: "COALESCE(character_maximum_length, numeric_precision, datetime_precision, interval_precision, 0)",
+				// attgenerated is read using to_jsonb, as it does not exist
+				// before PostgreSQL 12, and tells stored from the virtual
+				// generated columns of PostgreSQL 18
+				infos.ColumnsGenerated: `CASE
+  WHEN is_identity = 'YES' THEN 'generated ' || lower(identity_generation) || ' as identity (start ' || identity_start || ' increment ' || identity_increment || ' min ' || identity_minimum || ' max ' || identity_maximum || CASE WHEN identity_cycle = 'YES' THEN ' cycle' ELSE '' END || ')'
+  WHEN is_generated = 'ALWAYS' THEN 'generated always as (' || generation_expression || ') ' || COALESCE((
+    SELECT CASE to_jsonb(a.*) ->> 'attgenerated' WHEN 'v' THEN 'virtual' ELSE 'stored' END
+    FROM pg_catalog.pg_attribute a
+    JOIN pg_catalog.pg_class pc ON pc.oid = a.attrelid
+    JOIN pg_catalog.pg_namespace pn ON pn.oid = pc.relnamespace
+    WHERE pn.nspname = table_schema AND pc.relname = table_name AND a.attname = column_name
+  ), 'stored')
+  ELSE ''
+END`,
+				// use the check clause as written by pg_get_constraintdef, which
+				// excludes the implicit NOT NULL constraints
+				infos.ConstraintCheckClause: `COALESCE((
+  SELECT substring(pg_catalog.pg_get_constraintdef(pc.oid, true) from 7)
+  FROM pg_catalog.pg_constraint pc
+  JOIN pg_catalog.pg_namespace pn ON pn.oid = pc.connamespace
+  WHERE pc.contype = 'c' AND pc.conname = t.constraint_name AND pn.nspname = t.constraint_schema
+  LIMIT 1
+), '')`,
 			}),
 			infos.WithSystemSchemas([]string{"pg_catalog", "pg_toast", "information_schema"}),
 			infos.WithCurrentSchema("CURRENT_SCHEMA"),
@@ -129,6 +165,46 @@ FROM pg_catalog.pg_database d`
 	return metadata.NewCatalogSetWithColumns(results, catalogsColumnName), nil
 }
 
+func (r metaReader) Schemas(f metadata.Filter) (*metadata.SchemaSet, error) {
+	qstr := `SELECT n.nspname as "Name",
+  pg_catalog.current_database() as "Catalog",
+  pg_catalog.pg_get_userbyid(n.nspowner) as "Owner",
+  COALESCE(pg_catalog.array_to_string(n.nspacl, E'\n'),'') AS "Access privileges"
+FROM pg_catalog.pg_namespace n
+`
+	conds := []string{}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "n.nspname !~ '^pg_' AND n.nspname <> 'information_schema'")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("n.nspname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "1", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewSchemaSet([]metadata.Schema{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Schema{}
+	for rows.Next() {
+		rec := metadata.Schema{}
+		err = rows.Scan(&rec.Schema, &rec.Catalog, &rec.Owner, &rec.AccessPrivileges)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewSchemaSet(results), nil
+}
+
 func (r metaReader) Tables(f metadata.Filter) (*metadata.TableSet, error) {
 	qstr := `SELECT n.nspname as "Schema",
   c.relname as "Name",
@@ -282,7 +358,14 @@ SELECT
 		WHEN 'i' THEN 'index' 
 		WHEN 'I' THEN 'partitioned index' 
 	END
-   ) as "Type"
+   ) as "Type",
+  COALESCE(pg_catalog.array_to_string(ARRAY(
+    SELECT pg_catalog.pg_get_indexdef(i.indexrelid, k, true)
+    FROM pg_catalog.generate_series(1, i.indnatts) k
+    ORDER BY k
+  ), ', '), '') as "Columns",
+  COALESCE(pg_catalog.pg_get_expr(i.indpred, i.indrelid, true), '') as "Predicate",
+  pg_catalog.pg_size_pretty(pg_catalog.pg_relation_size(c.oid)) as "Size"
 FROM pg_catalog.pg_class c
      LEFT JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
      LEFT JOIN pg_catalog.pg_index i ON i.indexrelid = c.oid
@@ -320,7 +403,7 @@ FROM pg_catalog.pg_class c
 	results := []metadata.Index{}
 	for rows.Next() {
 		rec := metadata.Index{}
-		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Table, &rec.Name, &rec.IsUnique, &rec.IsPrimary, &rec.Type)
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Table, &rec.Name, &rec.IsPrimary, &rec.IsUnique, &rec.Type, &rec.Columns, &rec.Predicate, &rec.Size)
 		if err != nil {
 			return nil, err
 		}
@@ -452,6 +535,685 @@ FROM
 	return metadata.NewTriggerSet(results), nil
 }
 
+func (r metaReader) FunctionDefinitions(f metadata.Filter) (*metadata.FunctionSet, error) {
+	qstr := `SELECT
+  pg_catalog.current_database(),
+  n.nspname,
+  p.proname,
+  p.proname || '_' || p.oid,
+  CASE p.prokind WHEN 'p' THEN 'PROCEDURE' ELSE 'FUNCTION' END,
+  pg_catalog.oidvectortypes(p.proargtypes),
+  pg_catalog.pg_get_functiondef(p.oid)
+FROM pg_catalog.pg_proc p
+     LEFT JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+`
+	conds := []string{"p.prokind IN ('f', 'p', 'w')"}
+	vals := []interface{}{}
+	if f.OnlyVisible {
+		conds = append(conds, "pg_catalog.pg_function_is_visible(p.oid)")
+	}
+	if !f.WithSystem {
+		conds = append(conds, "n.nspname NOT IN ('pg_catalog', 'information_schema')")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("n.nspname LIKE $%d", len(vals)))
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("p.proname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "1, 2, 3, 6", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewFunctionSet([]metadata.Function{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Function{}
+	for rows.Next() {
+		rec := metadata.Function{}
+		err = rows.Scan(&rec.Catalog, &rec.Schema, &rec.Name, &rec.SpecificName, &rec.Type, &rec.ArgTypes, &rec.Source)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewFunctionSet(results), nil
+}
+
+func (r metaReader) Settings(f metadata.Filter) (*metadata.SettingSet, error) {
+	qstr := `SELECT
+  name,
+  pg_catalog.current_setting(name),
+  vartype,
+  context,
+  COALESCE(short_desc, '')
+FROM pg_catalog.pg_settings
+`
+	conds := []string{}
+	vals := []interface{}{}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("name LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "name", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewSettingSet([]metadata.Setting{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Setting{}
+	for rows.Next() {
+		rec := metadata.Setting{}
+		err = rows.Scan(&rec.Name, &rec.Value, &rec.Type, &rec.Context, &rec.Description)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewSettingSet(results), nil
+}
+
+func (r metaReader) MaterializedViews(f metadata.Filter) (*metadata.MaterializedViewSet, error) {
+	qstr := `SELECT
+  schemaname,
+  matviewname,
+  CASE WHEN ispopulated THEN 'populated' ELSE 'not populated' END,
+  COALESCE(definition, '')
+FROM pg_catalog.pg_matviews
+`
+	conds := []string{}
+	vals := []interface{}{}
+	if f.OnlyVisible {
+		conds = append(conds, "pg_catalog.pg_table_is_visible(format('%I.%I', schemaname, matviewname)::regclass)")
+	}
+	if !f.WithSystem {
+		conds = append(conds, "schemaname NOT IN ('pg_catalog', 'information_schema')")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("schemaname LIKE $%d", len(vals)))
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("matviewname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "1, 2", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewMaterializedViewSet([]metadata.MaterializedView{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.MaterializedView{}
+	for rows.Next() {
+		rec := metadata.MaterializedView{}
+		err = rows.Scan(&rec.Schema, &rec.Name, &rec.State, &rec.Definition)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewMaterializedViewSet(results), nil
+}
+
+// Sequences reads the sequences from pg_sequences, or before PostgreSQL 10,
+// from the sequence relations themselves.
+func (r metaReader) Sequences(f metadata.Filter) (*metadata.SequenceSet, error) {
+	var exists bool
+	switch err := r.queryRow(`SELECT pg_catalog.to_regclass('pg_catalog.pg_sequences') IS NOT NULL`, &exists); {
+	case err == sql.ErrNoRows:
+		return metadata.NewSequenceSet([]metadata.Sequence{}), nil
+	case err != nil:
+		return nil, err
+	}
+	qstr := `SELECT
+  schemaname,
+  sequencename,
+  data_type::text,
+  start_value::text,
+  min_value::text,
+  max_value::text,
+  increment_by::text,
+  CASE WHEN cycle THEN 'YES' ELSE 'NO' END,
+  COALESCE(last_value::text, ''),
+  cache_size::text
+FROM pg_catalog.pg_sequences
+`
+	schema, name := "schemaname", "sequencename"
+	if !exists {
+		qstr = `SELECT
+  n.nspname,
+  c.relname
+FROM pg_catalog.pg_class c
+  JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+`
+		schema, name = "n.nspname", "c.relname"
+	}
+	conds := []string{}
+	vals := []interface{}{}
+	if !exists {
+		conds = append(conds, "c.relkind = 'S'")
+	}
+	if f.OnlyVisible {
+		conds = append(conds, fmt.Sprintf("pg_catalog.pg_table_is_visible(format('%%I.%%I', %s, %s)::regclass)", schema, name))
+	}
+	if !f.WithSystem {
+		conds = append(conds, schema+" NOT IN ('pg_catalog', 'information_schema')")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("%s LIKE $%d", schema, len(vals)))
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("%s LIKE $%d", name, len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "1, 2", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewSequenceSet([]metadata.Sequence{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Sequence{}
+	for rows.Next() {
+		rec := metadata.Sequence{}
+		if exists {
+			err = rows.Scan(&rec.Schema, &rec.Name, &rec.DataType, &rec.Start, &rec.Min, &rec.Max, &rec.Increment, &rec.Cycles, &rec.LastValue, &rec.Cache)
+		} else {
+			err = rows.Scan(&rec.Schema, &rec.Name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	closeRows()
+	if !exists {
+		for i := range results {
+			if err := r.readSequence(&results[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return metadata.NewSequenceSet(results), nil
+}
+
+// readSequence reads the state of a sequence from its relation, as done
+// before PostgreSQL 10.
+func (r metaReader) readSequence(rec *metadata.Sequence) error {
+	var cycled, called bool
+	qstr := fmt.Sprintf(`SELECT
+  last_value::text,
+  start_value::text,
+  min_value::text,
+  max_value::text,
+  increment_by::text,
+  cache_value::text,
+  is_cycled,
+  is_called
+FROM %s.%s`, pq.QuoteIdentifier(rec.Schema), pq.QuoteIdentifier(rec.Name))
+	err := r.queryRow(qstr, &rec.LastValue, &rec.Start, &rec.Min, &rec.Max, &rec.Increment, &rec.Cache, &cycled, &called)
+	if err != nil {
+		return err
+	}
+	rec.DataType, rec.Cycles = "bigint", metadata.NO
+	if cycled {
+		rec.Cycles = metadata.YES
+	}
+	if !called {
+		rec.LastValue = ""
+	}
+	return nil
+}
+
+func (r metaReader) TableSizes(f metadata.Filter) (*metadata.TableSizeSet, error) {
+	qstr := `SELECT
+  n.nspname,
+  c.relname,
+  pg_catalog.pg_total_relation_size(c.oid)
+FROM pg_catalog.pg_class c
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+`
+	conds := []string{"c.relkind IN ('r', 'p', 'm')"}
+	vals := []interface{}{}
+	if f.OnlyVisible {
+		conds = append(conds, "pg_catalog.pg_table_is_visible(c.oid)")
+	}
+	if !f.WithSystem {
+		conds = append(conds, "n.nspname NOT IN ('pg_catalog', 'information_schema')")
+	}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("n.nspname LIKE $%d", len(vals)))
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("c.relname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "1, 2", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewTableSizeSet([]metadata.TableSize{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.TableSize{}
+	for rows.Next() {
+		rec := metadata.TableSize{}
+		err = rows.Scan(&rec.Schema, &rec.Name, &rec.Size)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewTableSizeSet(results), nil
+}
+
+// fdwOptions formats a foreign-data wrapper, server, or table options column
+// the same as psql.
+func fdwOptions(col string) string {
+	return fmt.Sprintf(`CASE WHEN %[1]s IS NULL THEN '' ELSE '(' || pg_catalog.array_to_string(ARRAY(
+    SELECT pg_catalog.quote_ident(option_name) || ' ' || pg_catalog.quote_literal(option_value)
+    FROM pg_catalog.pg_options_to_table(%[1]s)
+  ), ', ') || ')' END`, col)
+}
+
+func (r metaReader) ForeignServers(f metadata.Filter) (*metadata.ForeignServerSet, error) {
+	qstr := `SELECT
+  s.srvname,
+  pg_catalog.pg_get_userbyid(s.srvowner),
+  w.fdwname,
+  COALESCE(pg_catalog.array_to_string(s.srvacl, E'\n'), ''),
+  COALESCE(s.srvtype, ''),
+  COALESCE(s.srvversion, ''),
+  ` + fdwOptions("s.srvoptions") + `,
+  COALESCE(d.description, '')
+FROM pg_catalog.pg_foreign_server s
+  JOIN pg_catalog.pg_foreign_data_wrapper w ON w.oid = s.srvfdw
+  LEFT JOIN pg_catalog.pg_description d ON d.classoid = s.tableoid AND d.objoid = s.oid AND d.objsubid = 0
+`
+	conds := []string{}
+	vals := []interface{}{}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("s.srvname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "1", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewForeignServerSet([]metadata.ForeignServer{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.ForeignServer{}
+	for rows.Next() {
+		rec := metadata.ForeignServer{}
+		err = rows.Scan(&rec.Name, &rec.Owner, &rec.Wrapper, &rec.Privileges, &rec.Type, &rec.Version, &rec.Options, &rec.Description)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewForeignServerSet(results), nil
+}
+
+func (r metaReader) ForeignTables(f metadata.Filter) (*metadata.ForeignTableSet, error) {
+	qstr := `SELECT
+  n.nspname,
+  c.relname,
+  s.srvname,
+  ` + fdwOptions("ft.ftoptions") + `,
+  COALESCE(pg_catalog.obj_description(c.oid, 'pg_class'), '')
+FROM pg_catalog.pg_foreign_table ft
+  JOIN pg_catalog.pg_class c ON c.oid = ft.ftrelid
+  JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+  JOIN pg_catalog.pg_foreign_server s ON s.oid = ft.ftserver
+`
+	conds := []string{}
+	vals := []interface{}{}
+	if f.Schema != "" {
+		vals = append(vals, f.Schema)
+		conds = append(conds, fmt.Sprintf("n.nspname LIKE $%d", len(vals)))
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("c.relname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "1, 2", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewForeignTableSet([]metadata.ForeignTable{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.ForeignTable{}
+	for rows.Next() {
+		rec := metadata.ForeignTable{}
+		err = rows.Scan(&rec.Schema, &rec.Name, &rec.Server, &rec.Options, &rec.Description)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewForeignTableSet(results), nil
+}
+
+func (r metaReader) ForeignDataWrappers(f metadata.Filter) (*metadata.ForeignDataWrapperSet, error) {
+	qstr := `SELECT
+  w.fdwname,
+  pg_catalog.pg_get_userbyid(w.fdwowner),
+  CASE WHEN w.fdwhandler = 0 THEN '' ELSE w.fdwhandler::pg_catalog.regproc::pg_catalog.text END,
+  CASE WHEN w.fdwvalidator = 0 THEN '' ELSE w.fdwvalidator::pg_catalog.regproc::pg_catalog.text END,
+  COALESCE(pg_catalog.array_to_string(w.fdwacl, E'\n'), ''),
+  ` + fdwOptions("w.fdwoptions") + `,
+  COALESCE(d.description, '')
+FROM pg_catalog.pg_foreign_data_wrapper w
+  LEFT JOIN pg_catalog.pg_description d ON d.classoid = w.tableoid AND d.objoid = w.oid AND d.objsubid = 0
+`
+	conds := []string{}
+	vals := []interface{}{}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("w.fdwname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "1", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewForeignDataWrapperSet([]metadata.ForeignDataWrapper{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.ForeignDataWrapper{}
+	for rows.Next() {
+		rec := metadata.ForeignDataWrapper{}
+		err = rows.Scan(&rec.Name, &rec.Owner, &rec.Handler, &rec.Validator, &rec.Privileges, &rec.Options, &rec.Description)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewForeignDataWrapperSet(results), nil
+}
+
+func (r metaReader) Extensions(f metadata.Filter) (*metadata.ExtensionSet, error) {
+	qstr := `SELECT
+  e.extname,
+  e.extversion,
+  n.nspname,
+  COALESCE(d.description, '')
+FROM pg_catalog.pg_extension e
+  LEFT JOIN pg_catalog.pg_namespace n ON n.oid = e.extnamespace
+  LEFT JOIN pg_catalog.pg_description d ON d.objoid = e.oid AND d.classoid = 'pg_catalog.pg_extension'::pg_catalog.regclass
+`
+	conds := []string{}
+	vals := []interface{}{}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("e.extname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "1", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewExtensionSet([]metadata.Extension{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Extension{}
+	for rows.Next() {
+		rec := metadata.Extension{}
+		err = rows.Scan(&rec.Name, &rec.Version, &rec.Schema, &rec.Description)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewExtensionSet(results), nil
+}
+
+func (r metaReader) ExtensionObjects(f metadata.Filter) (*metadata.ExtensionObjectSet, error) {
+	qstr := `SELECT
+  e.extname,
+  pg_catalog.pg_describe_object(d.classid, d.objid, 0)
+FROM pg_catalog.pg_depend d
+  JOIN pg_catalog.pg_extension e ON e.oid = d.refobjid
+`
+	conds := []string{
+		"d.refclassid = 'pg_catalog.pg_extension'::pg_catalog.regclass",
+		"d.deptype = 'e'",
+	}
+	vals := []interface{}{}
+	if f.Parent != "" {
+		vals = append(vals, f.Parent)
+		conds = append(conds, fmt.Sprintf("e.extname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "1, 2", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewExtensionObjectSet([]metadata.ExtensionObject{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.ExtensionObject{}
+	for rows.Next() {
+		rec := metadata.ExtensionObject{}
+		err = rows.Scan(&rec.Extension, &rec.Description)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewExtensionObjectSet(results), nil
+}
+
+// Objects lists the relations, columns, and functions with lower case names
+// matching the filter's name.
+func (r metaReader) Objects(f metadata.Filter) (*metadata.ObjectSet, error) {
+	qstr := `SELECT o.schema, o.name, o.type, o.parent
+FROM (
+  SELECT
+    n.nspname AS schema,
+    c.relname AS name,
+    CASE c.relkind
+      WHEN 'r' THEN 'table'
+      WHEN 'p' THEN 'table'
+      WHEN 'v' THEN 'view'
+      WHEN 'm' THEN 'materialized view'
+      WHEN 'i' THEN 'index'
+      WHEN 'I' THEN 'index'
+      WHEN 'S' THEN 'sequence'
+      WHEN 'f' THEN 'foreign table'
+    END AS type,
+    COALESCE(t.relname, '') AS parent
+  FROM pg_catalog.pg_class c
+    JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+    LEFT JOIN pg_catalog.pg_index i ON i.indexrelid = c.oid
+    LEFT JOIN pg_catalog.pg_class t ON t.oid = i.indrelid
+  WHERE c.relkind IN ('r', 'p', 'v', 'm', 'i', 'I', 'S', 'f')
+  UNION ALL
+  SELECT
+    n.nspname,
+    a.attname,
+    'column',
+    c.relname
+  FROM pg_catalog.pg_attribute a
+    JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+    JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+  WHERE a.attnum > 0
+    AND NOT a.attisdropped
+    AND c.relkind IN ('r', 'p', 'v', 'm', 'f')
+  UNION ALL
+  SELECT
+    n.nspname,
+    p.proname,
+    CASE p.prokind
+      WHEN 'a' THEN 'aggregate'
+      WHEN 'w' THEN 'window'
+      WHEN 'p' THEN 'procedure'
+      ELSE 'function'
+    END,
+    ''
+  FROM pg_catalog.pg_proc p
+    JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+) o`
+	conds := []string{}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "o.schema !~ '^pg_' AND o.schema <> 'information_schema'")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("LOWER(o.name) LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "o.schema, o.name, o.type, o.parent", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewObjectSet([]metadata.Object{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Object{}
+	for rows.Next() {
+		rec := metadata.Object{}
+		err = rows.Scan(&rec.Schema, &rec.Name, &rec.Type, &rec.Table)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewObjectSet(results), nil
+}
+
+// queryRow runs a query returning a single row, scanning it into dest.
+// Roles lists the roles from pg_roles, with the roles they are granted
+// membership in from pg_auth_members, where roles starting with pg_ are
+// system roles.
+func (r metaReader) Roles(f metadata.Filter) (*metadata.RoleSet, error) {
+	qstr := `SELECT
+  r.rolname,
+  r.rolsuper,
+  r.rolcreaterole,
+  r.rolcreatedb,
+  r.rolcanlogin,
+  r.rolreplication,
+  r.rolbypassrls,
+  r.rolconnlimit,
+  COALESCE(r.rolvaliduntil::text, ''),
+  pg_catalog.array_to_string(ARRAY(
+    SELECT b.rolname
+    FROM pg_catalog.pg_auth_members m
+      JOIN pg_catalog.pg_roles b ON b.oid = m.roleid
+    WHERE m.member = r.oid
+    ORDER BY 1
+  ), ', ')
+FROM pg_catalog.pg_roles r
+`
+	conds := []string{}
+	vals := []interface{}{}
+	if !f.WithSystem {
+		conds = append(conds, "r.rolname !~ '^pg_'")
+	}
+	if f.Name != "" {
+		vals = append(vals, f.Name)
+		conds = append(conds, fmt.Sprintf("r.rolname LIKE $%d", len(vals)))
+	}
+	rows, closeRows, err := r.query(qstr, conds, "1", vals...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return metadata.NewRoleSet([]metadata.Role{}), nil
+		}
+		return nil, err
+	}
+	defer closeRows()
+
+	results := []metadata.Role{}
+	for rows.Next() {
+		rec := metadata.Role{}
+		err = rows.Scan(&rec.Name, &rec.Superuser, &rec.CreateRole, &rec.CreateDB, &rec.Login, &rec.Replication, &rec.BypassRLS, &rec.ConnectionLimit, &rec.ValidUntil, &rec.MemberOf)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, rec)
+	}
+	if rows.Err() != nil {
+		return nil, rows.Err()
+	}
+	return metadata.NewRoleSet(results), nil
+}
+
+func (r metaReader) queryRow(qstr string, dest ...interface{}) error {
+	rows, closeRows, err := r.Query(qstr)
+	if err != nil {
+		return err
+	}
+	defer closeRows()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	return rows.Scan(dest...)
+}
+
 func (r metaReader) query(qstr string, conds []string, order string, vals ...interface{}) (*sql.Rows, func(), error) {
 	if len(conds) != 0 {
 		qstr += "\nWHERE " + strings.Join(conds, " AND ")
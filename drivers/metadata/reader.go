@@ -22,8 +22,19 @@ type PluginReader struct {
 	constraintColumns  func(Filter) (*ConstraintColumnSet, error)
 	functions          func(Filter) (*FunctionSet, error)
 	functionColumns    func(Filter) (*FunctionColumnSet, error)
+	functionDefs       func(Filter) (*FunctionSet, error)
 	sequences          func(Filter) (*SequenceSet, error)
 	privilegeSummaries func(Filter) (*PrivilegeSummarySet, error)
+	settings           func(Filter) (*SettingSet, error)
+	materializedViews  func(Filter) (*MaterializedViewSet, error)
+	tableSizes         func(Filter) (*TableSizeSet, error)
+	foreignServers     func(Filter) (*ForeignServerSet, error)
+	foreignTables      func(Filter) (*ForeignTableSet, error)
+	foreignWrappers    func(Filter) (*ForeignDataWrapperSet, error)
+	extensions         func(Filter) (*ExtensionSet, error)
+	extensionObjects   func(Filter) (*ExtensionObjectSet, error)
+	objects            func(Filter) (*ObjectSet, error)
+	roles              func(Filter) (*RoleSet, error)
 }
 
 var _ ExtendedReader = &PluginReader{}
@@ -68,12 +79,45 @@ func NewPluginReader(readers ...Reader) Reader {
 		if r, ok := i.(FunctionColumnReader); ok {
 			p.functionColumns = r.FunctionColumns
 		}
+		if r, ok := i.(FunctionDefinitionReader); ok {
+			p.functionDefs = r.FunctionDefinitions
+		}
 		if r, ok := i.(SequenceReader); ok {
 			p.sequences = r.Sequences
 		}
 		if r, ok := i.(PrivilegeSummaryReader); ok {
 			p.privilegeSummaries = r.PrivilegeSummaries
 		}
+		if r, ok := i.(SettingReader); ok {
+			p.settings = r.Settings
+		}
+		if r, ok := i.(MaterializedViewReader); ok {
+			p.materializedViews = r.MaterializedViews
+		}
+		if r, ok := i.(TableSizeReader); ok {
+			p.tableSizes = r.TableSizes
+		}
+		if r, ok := i.(ForeignServerReader); ok {
+			p.foreignServers = r.ForeignServers
+		}
+		if r, ok := i.(ForeignTableReader); ok {
+			p.foreignTables = r.ForeignTables
+		}
+		if r, ok := i.(ForeignDataWrapperReader); ok {
+			p.foreignWrappers = r.ForeignDataWrappers
+		}
+		if r, ok := i.(ExtensionReader); ok {
+			p.extensions = r.Extensions
+		}
+		if r, ok := i.(ExtensionObjectReader); ok {
+			p.extensionObjects = r.ExtensionObjects
+		}
+		if r, ok := i.(ObjectReader); ok {
+			p.objects = r.Objects
+		}
+		if r, ok := i.(RoleReader); ok {
+			p.roles = r.Roles
+		}
 	}
 	return &p
 }
@@ -155,6 +199,13 @@ func (p PluginReader) Functions(f Filter) (*FunctionSet, error) {
 	return p.functions(f)
 }
 
+func (p PluginReader) FunctionDefinitions(f Filter) (*FunctionSet, error) {
+	if p.functionDefs == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.functionDefs(f)
+}
+
 func (p PluginReader) FunctionColumns(f Filter) (*FunctionColumnSet, error) {
 	if p.functionColumns == nil {
 		return nil, text.ErrNotSupported
@@ -176,6 +227,76 @@ func (p PluginReader) PrivilegeSummaries(f Filter) (*PrivilegeSummarySet, error)
 	return p.privilegeSummaries(f)
 }
 
+func (p PluginReader) Settings(f Filter) (*SettingSet, error) {
+	if p.settings == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.settings(f)
+}
+
+func (p PluginReader) MaterializedViews(f Filter) (*MaterializedViewSet, error) {
+	if p.materializedViews == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.materializedViews(f)
+}
+
+func (p PluginReader) TableSizes(f Filter) (*TableSizeSet, error) {
+	if p.tableSizes == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.tableSizes(f)
+}
+
+func (p PluginReader) ForeignServers(f Filter) (*ForeignServerSet, error) {
+	if p.foreignServers == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.foreignServers(f)
+}
+
+func (p PluginReader) ForeignTables(f Filter) (*ForeignTableSet, error) {
+	if p.foreignTables == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.foreignTables(f)
+}
+
+func (p PluginReader) ForeignDataWrappers(f Filter) (*ForeignDataWrapperSet, error) {
+	if p.foreignWrappers == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.foreignWrappers(f)
+}
+
+func (p PluginReader) Extensions(f Filter) (*ExtensionSet, error) {
+	if p.extensions == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.extensions(f)
+}
+
+func (p PluginReader) ExtensionObjects(f Filter) (*ExtensionObjectSet, error) {
+	if p.extensionObjects == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.extensionObjects(f)
+}
+
+func (p PluginReader) Objects(f Filter) (*ObjectSet, error) {
+	if p.objects == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.objects(f)
+}
+
+func (p PluginReader) Roles(f Filter) (*RoleSet, error) {
+	if p.roles == nil {
+		return nil, text.ErrNotSupported
+	}
+	return p.roles(f)
+}
+
 type LoggingReader struct {
 	db      DB
 	logger  logger
@@ -3,10 +3,17 @@ package metadata
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 
+	"github.com/mattn/go-isatty"
 	"github.com/xo/dburl"
 	"github.com/xo/tblfmt"
 	"github.com/rmasci/usql/env"
@@ -36,7 +43,9 @@ type DefaultWriter struct {
 	systemSchemas map[string]struct{}
 
 	// custom functions for easier overloading
-	listAllDbs func(string, bool) error
+	listAllDbs      func(string, bool) error
+	tableDetails    func(io.Writer, string, string) error
+	quoteIdentifier func(string) string
 }
 
 func NewDefaultWriter(r Reader, opts ...WriterOption) func(db DB, w io.Writer) Writer {
@@ -89,6 +98,22 @@ func WithListAllDbs(f func(string, bool) error) WriterOption {
 	}
 }
 
+// WithIdentifierQuote that quotes identifiers in queries run by the writer,
+// when the database does not support ANSI quoted identifiers
+func WithIdentifierQuote(f func(string) string) WriterOption {
+	return func(w *DefaultWriter) {
+		w.quoteIdentifier = f
+	}
+}
+
+// WithTableDetails that writes additional driver specific details after
+// describing a table
+func WithTableDetails(f func(io.Writer, string, string) error) WriterOption {
+	return func(w *DefaultWriter) {
+		w.tableDetails = f
+	}
+}
+
 // DescribeFunctions matching pattern
 func (w DefaultWriter) DescribeFunctions(u *dburl.URL, funcTypes, pattern string, verbose, showSystem bool) error {
 	r, ok := w.r.(FunctionReader)
@@ -148,6 +173,104 @@ func (w DefaultWriter) DescribeFunctions(u *dburl.URL, funcTypes, pattern string
 	return tblfmt.EncodeAll(w.w, res, params)
 }
 
+// FunctionSource returns the source of the function name, optionally
+// qualified by its schema and followed by a parenthesized list of argument
+// types identifying one of several overloaded functions. The complete
+// definition is returned when the reader is a FunctionDefinitionReader.
+func FunctionSource(r Reader, name string) (string, error) {
+	name, args, hasArgs := strings.Cut(name, "(")
+	if hasArgs {
+		var ok bool
+		if args, ok = strings.CutSuffix(strings.TrimSpace(args), ")"); !ok {
+			return "", text.ErrUnterminatedParenthesis
+		}
+	}
+	name = strings.TrimSpace(name)
+	sp, tp := "", name
+	if i := strings.Index(name, "."); i != -1 {
+		sp, tp = name[:i], name[i+1:]
+	}
+	f := Filter{Schema: sp, Name: tp, WithSystem: true}
+	var res *FunctionSet
+	var err error
+	if fr, ok := r.(FunctionDefinitionReader); ok {
+		res, err = fr.FunctionDefinitions(f)
+	}
+	if res == nil && (err == nil || err == text.ErrNotSupported) {
+		fr, ok := r.(FunctionReader)
+		if !ok {
+			return "", text.ErrNotSupported
+		}
+		res, err = fr.Functions(f)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer res.Close()
+	var matches []*Function
+	for res.Next() {
+		fn := res.Get()
+		if fn.Name != tp || sp != "" && fn.Schema != sp {
+			continue
+		}
+		if hasArgs {
+			if fn.ArgTypes == "" {
+				if fn.ArgTypes, err = functionArgTypes(r, fn); err != nil {
+					return "", err
+				}
+			}
+			if normalizeArgTypes(fn.ArgTypes) != normalizeArgTypes(args) {
+				continue
+			}
+		}
+		matches = append(matches, fn)
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf(text.FunctionNotFound, name)
+	case 1:
+		return matches[0].Source, nil
+	}
+	return "", fmt.Errorf(text.FunctionNotUnique, name)
+}
+
+// functionArgTypes returns the data types of the input parameters of a
+// function.
+func functionArgTypes(r Reader, fn *Function) (string, error) {
+	cr, ok := r.(FunctionColumnReader)
+	if !ok {
+		return "", nil
+	}
+	cols, err := cr.FunctionColumns(Filter{Catalog: fn.Catalog, Schema: fn.Schema, Parent: fn.SpecificName})
+	switch {
+	case err == text.ErrNotSupported:
+		return "", nil
+	case err != nil:
+		return "", err
+	}
+	defer cols.Close()
+	var types []string
+	for cols.Next() {
+		c := cols.Get()
+		// skip result and output params
+		if c.OrdinalPosition == 0 || c.Type == "OUT" {
+			continue
+		}
+		types = append(types, c.DataType)
+	}
+	return strings.Join(types, ", "), nil
+}
+
+// normalizeArgTypes normalizes a comma separated list of argument types for
+// comparison.
+func normalizeArgTypes(s string) string {
+	types := strings.Split(s, ",")
+	for i, typ := range types {
+		types[i] = strings.ToLower(strings.Join(strings.Fields(typ), " "))
+	}
+	return strings.Join(types, ",")
+}
+
 func (w DefaultWriter) getFunctionColumns(c, s, f string) (string, error) {
 	r := w.r.(FunctionColumnReader)
 	cols, err := r.FunctionColumns(Filter{Catalog: c, Schema: s, Parent: f})
@@ -220,7 +343,7 @@ func (w DefaultWriter) DescribeTableDetails(u *dburl.URL, pattern string, verbos
 	_, isICR := w.r.(IndexColumnReader)
 	if isIR && isICR {
 		res, err := ir.Indexes(Filter{Schema: sp, Name: tp, WithSystem: showSystem})
-		if err != nil && err != text.ErrNotSupported {
+		if err != nil && !errors.Is(err, text.ErrNotSupported) {
 			return fmt.Errorf("failed to list indexes for table %s: %w", tp, err)
 		}
 		if res != nil {
@@ -260,20 +383,20 @@ func (w DefaultWriter) describeTableDetails(typ, sp, tp string, verbose, showSys
 
 	columns := []string{"Name", "Type", "Nullable", "Default"}
 	if verbose {
-		columns = append(columns, "Size", "Decimal Digits", "Radix", "Octet Length")
+		columns = append(columns, "Size", "Decimal Digits", "Radix", "Octet Length", "Generated")
 	}
 	res.SetColumns(columns)
 	res.SetScanValues(func(r Result) []interface{} {
 		f := r.(*Column)
 		v := []interface{}{f.Name, f.DataType, f.IsNullable, f.Default}
 		if verbose {
-			v = append(v, f.ColumnSize, f.DecimalDigits, f.NumPrecRadix, f.CharOctetLength)
+			v = append(v, f.ColumnSize, f.DecimalDigits, f.NumPrecRadix, f.CharOctetLength, f.Generated)
 		}
 		return v
 	})
 	params := env.Pall()
 	params["title"] = fmt.Sprintf("%s %s\n", typ, qualifiedIdentifier(sp, tp))
-	return w.encodeWithSummary(res, params, w.tableDetailsSummary(sp, tp))
+	return w.encodeWithSummary(res, params, w.tableDetailsSummary(sp, tp, verbose))
 }
 
 func (w DefaultWriter) encodeWithSummary(res tblfmt.ResultSet, params map[string]string, summary func(io.Writer, int) (int, error)) error {
@@ -290,27 +413,30 @@ func (w DefaultWriter) encodeWithSummary(res tblfmt.ResultSet, params map[string
 	return enc.EncodeAll(w.w)
 }
 
-func (w DefaultWriter) tableDetailsSummary(sp, tp string) func(io.Writer, int) (int, error) {
+func (w DefaultWriter) tableDetailsSummary(sp, tp string, verbose bool) func(io.Writer, int) (int, error) {
 	return func(out io.Writer, _ int) (int, error) {
 		err := w.describeTableIndexes(out, sp, tp)
 		if err != nil {
 			return 0, err
 		}
-		err = w.describeTableConstraints(
-			out,
-			Filter{Schema: sp, Parent: tp},
-			func(r Result) bool {
-				c := r.(*Constraint)
-				return c.Type == "CHECK" && c.CheckClause != "" && !strings.HasSuffix(c.CheckClause, " IS NOT NULL")
-			},
-			"Check constraints:",
-			func(out io.Writer, c *Constraint) error {
-				_, err := fmt.Fprintf(out, "  \"%s\" %s (%s)\n", c.Name, c.Type, c.CheckClause)
-				return err
-			},
-		)
-		if err != nil {
-			return 0, err
+		// check constraints and triggers are only shown in verbose mode
+		if verbose {
+			err = w.describeTableConstraints(
+				out,
+				Filter{Schema: sp, Parent: tp},
+				func(r Result) bool {
+					c := r.(*Constraint)
+					return c.Type == "CHECK" && c.CheckClause != "" && !strings.HasSuffix(c.CheckClause, " IS NOT NULL")
+				},
+				"Check constraints:",
+				func(out io.Writer, c *Constraint) error {
+					_, err := fmt.Fprintf(out, "  \"%s\" %s %s\n", c.Name, c.Type, parenthesize(c.CheckClause))
+					return err
+				},
+			)
+			if err != nil {
+				return 0, err
+			}
 		}
 		err = w.describeTableConstraints(
 			out,
@@ -358,10 +484,22 @@ func (w DefaultWriter) tableDetailsSummary(sp, tp string) func(io.Writer, int) (
 				return err
 			},
 		)
-		err = w.describeTableTriggers(out, sp, tp)
 		if err != nil {
 			return 0, err
 		}
+		if verbose {
+			if err = w.describeTableTriggers(out, sp, tp); err != nil {
+				return 0, err
+			}
+		}
+		if verbose && env.Pall()["describe_size"] == "on" {
+			if err = w.describeTableSize(out, sp, tp); err != nil {
+				return 0, err
+			}
+		}
+		if w.tableDetails != nil {
+			err = w.tableDetails(out, sp, tp)
+		}
 		return 0, err
 	}
 }
@@ -372,7 +510,7 @@ func (w DefaultWriter) describeTableTriggers(out io.Writer, sp, tp string) error
 		return nil
 	}
 	res, err := r.Triggers(Filter{Schema: sp, Parent: tp})
-	if err != nil && err != text.ErrNotSupported {
+	if err != nil && !errors.Is(err, text.ErrNotSupported) {
 		return fmt.Errorf("failed to list triggers for table %s: %w", tp, err)
 	}
 	if res == nil {
@@ -391,13 +529,44 @@ func (w DefaultWriter) describeTableTriggers(out io.Writer, sp, tp string) error
 	return nil
 }
 
+func (w DefaultWriter) describeTableSize(out io.Writer, sp, tp string) error {
+	r, ok := w.r.(TableSizeReader)
+	if !ok {
+		return nil
+	}
+	res, err := r.TableSizes(Filter{Schema: sp, Name: tp})
+	if err != nil && !errors.Is(err, text.ErrNotSupported) {
+		return fmt.Errorf("failed to get size of table %s: %w", tp, err)
+	}
+	if res == nil {
+		return nil
+	}
+	defer res.Close()
+
+	if res.Next() {
+		fmt.Fprintf(out, "Total size: %s\n", formatSize(res.Get().Size))
+	}
+	return nil
+}
+
+// formatSize formats a size in bytes using binary units, in the same way as
+// PostgreSQL's pg_size_pretty.
+func formatSize(size int64) string {
+	units := []string{"bytes", "kB", "MB", "GB", "TB", "PB"}
+	i := 0
+	for ; i < len(units)-1 && (size >= 10*1024 || size <= -10*1024); i++ {
+		size /= 1024
+	}
+	return fmt.Sprintf("%d %s", size, units[i])
+}
+
 func (w DefaultWriter) describeTableIndexes(out io.Writer, sp, tp string) error {
 	r, ok := w.r.(IndexReader)
 	if !ok {
 		return nil
 	}
 	res, err := r.Indexes(Filter{Schema: sp, Parent: tp})
-	if err != nil && err != text.ErrNotSupported {
+	if err != nil && !errors.Is(err, text.ErrNotSupported) {
 		return fmt.Errorf("failed to list indexes for table %s: %w", tp, err)
 	}
 	if res == nil {
@@ -419,11 +588,27 @@ func (w DefaultWriter) describeTableIndexes(out io.Writer, sp, tp string) error
 		if i.IsUnique == YES {
 			unique = "UNIQUE, "
 		}
-		i.Columns, err = w.getIndexColumns(i.Catalog, i.Schema, i.Table, i.Name)
-		if err != nil {
-			return fmt.Errorf("failed to get columns of index %s: %w", i.Name, err)
+		if err := w.fillIndexColumns(i); err != nil {
+			return err
 		}
-		fmt.Fprintf(out, "  \"%s\" %s%s%s (%s)\n", i.Name, primary, unique, i.Type, i.Columns)
+		predicate := ""
+		if i.Predicate != "" {
+			predicate = " WHERE " + i.Predicate
+		}
+		fmt.Fprintf(out, "  \"%s\" %s%s%s (%s)%s\n", i.Name, primary, unique, i.Type, i.Columns, predicate)
+	}
+	return nil
+}
+
+// fillIndexColumns sets the columns of the index from the index columns, when
+// the reader does not already provide them (such as expressions).
+func (w DefaultWriter) fillIndexColumns(i *Index) error {
+	if _, ok := w.r.(IndexColumnReader); !ok || i.Columns != "" {
+		return nil
+	}
+	var err error
+	if i.Columns, err = w.getIndexColumns(i.Catalog, i.Schema, i.Table, i.Name); err != nil {
+		return fmt.Errorf("failed to get columns of index %s: %w", i.Name, err)
 	}
 	return nil
 }
@@ -447,7 +632,7 @@ func (w DefaultWriter) describeTableConstraints(out io.Writer, filter Filter, po
 		return nil
 	}
 	res, err := r.Constraints(filter)
-	if err != nil && err != text.ErrNotSupported {
+	if err != nil && !errors.Is(err, text.ErrNotSupported) {
 		return fmt.Errorf("failed to list constraints: %w", err)
 	}
 	if res == nil {
@@ -470,6 +655,28 @@ func (w DefaultWriter) describeTableConstraints(out io.Writer, filter Filter, po
 	return nil
 }
 
+// parenthesize wraps the expression in parentheses, unless it is already
+// wrapped.
+func parenthesize(expr string) string {
+	if !strings.HasPrefix(expr, "(") || !strings.HasSuffix(expr, ")") {
+		return "(" + expr + ")"
+	}
+	// the first parenthesis must close at the end of the expression
+	depth := 0
+	for i, c := range expr {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		}
+		if depth == 0 && i != len(expr)-1 {
+			return "(" + expr + ")"
+		}
+	}
+	return expr
+}
+
 func (w DefaultWriter) getConstraintColumns(c, s, t, n string) (string, string, error) {
 	r := w.r.(ConstraintColumnReader)
 	cols, err := r.ConstraintColumns(Filter{Catalog: c, Schema: s, Parent: t, Name: n})
@@ -488,7 +695,7 @@ func (w DefaultWriter) getConstraintColumns(c, s, t, n string) (string, string,
 func (w DefaultWriter) describeSequences(sp, tp string, verbose, showSystem bool) (int, error) {
 	r := w.r.(SequenceReader)
 	res, err := r.Sequences(Filter{Schema: sp, Name: tp, WithSystem: showSystem})
-	if err != nil && err != text.ErrNotSupported {
+	if err != nil && !errors.Is(err, text.ErrNotSupported) {
 		return 0, err
 	}
 	if res == nil {
@@ -540,6 +747,9 @@ func (w DefaultWriter) describeIndex(i *Index) error {
 			primary = "primary key, "
 		}
 		_, err := fmt.Fprintf(out, "%s%s, for table %s", primary, i.Type, i.Table)
+		if err == nil && i.Predicate != "" {
+			_, err = fmt.Fprintf(out, ", predicate (%s)", i.Predicate)
+		}
 		return 0, err
 	})
 }
@@ -599,7 +809,14 @@ func (w DefaultWriter) ListTables(u *dburl.URL, tableTypes, pattern string, verb
 	}
 	columns := []string{"Schema", "Name", "Type"}
 	if verbose {
-		columns = append(columns, "Rows", "Size", "Comment")
+		rows := "Rows (est.)"
+		if env.Pall()["describe_exact_rows"] == "on" {
+			if err := w.countRows(res); err != nil {
+				return err
+			}
+			rows = "Rows"
+		}
+		columns = append(columns, rows, "Size", "Comment")
 	}
 	res.SetColumns(columns)
 	res.SetScanValues(func(r Result) []interface{} {
@@ -616,6 +833,34 @@ func (w DefaultWriter) ListTables(u *dburl.URL, tableTypes, pattern string, verb
 	return tblfmt.EncodeAll(w.w, res, params)
 }
 
+// countRows replaces the estimated row counts of the tables in the result set
+// with exact counts.
+func (w DefaultWriter) countRows(res *TableSet) error {
+	quote := w.quoteIdentifier
+	if quote == nil {
+		quote = func(s string) string {
+			return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+		}
+	}
+	for res.Next() {
+		t := res.Get()
+		switch typ := strings.ToUpper(t.Type); {
+		case strings.HasPrefix(typ, "SYSTEM"),
+			!strings.Contains(typ, "TABLE") && typ != "MATERIALIZED VIEW":
+			continue
+		}
+		name := quote(t.Name)
+		if t.Schema != "" {
+			name = quote(t.Schema) + "." + name
+		}
+		if err := w.db.QueryRow("SELECT COUNT(*) FROM " + name).Scan(&t.Rows); err != nil {
+			return fmt.Errorf("failed to count rows of table %s: %w", t.Name, err)
+		}
+	}
+	res.Reset()
+	return nil
+}
+
 // ListSchemas matching pattern
 func (w DefaultWriter) ListSchemas(u *dburl.URL, pattern string, verbose, showSystem bool) error {
 	r, ok := w.r.(SchemaReader)
@@ -635,6 +880,13 @@ func (w DefaultWriter) ListSchemas(u *dburl.URL, pattern string, verbose, showSy
 			return !ok
 		})
 	}
+	if verbose {
+		res.SetColumns([]string{"Schema", "Catalog", "Owner", "Access privileges"})
+		res.SetScanValues(func(r Result) []interface{} {
+			f := r.(*Schema)
+			return []interface{}{f.Schema, f.Catalog, f.Owner, f.AccessPrivileges}
+		})
+	}
 	params := env.Pall()
 	params["title"] = "List of schemas"
 	return tblfmt.EncodeAll(w.w, res, params)
@@ -669,16 +921,40 @@ func (w DefaultWriter) ListIndexes(u *dburl.URL, pattern string, verbose, showSy
 		return nil
 	}
 
+	// omit the details not provided by the reader for any index
+	var predicates, sizes bool
+	if verbose {
+		for res.Next() {
+			i := res.Get()
+			if err := w.fillIndexColumns(i); err != nil {
+				return err
+			}
+			predicates, sizes = predicates || i.Predicate != "", sizes || i.Size != ""
+		}
+		res.Reset()
+	}
 	columns := []string{"Schema", "Name", "Type", "Table"}
 	if verbose {
-		columns = append(columns, "Primary?", "Unique?")
+		columns = append(columns, "Primary?", "Unique?", "Columns")
+		if predicates {
+			columns = append(columns, "Predicate")
+		}
+		if sizes {
+			columns = append(columns, "Size")
+		}
 	}
 	res.SetColumns(columns)
 	res.SetScanValues(func(r Result) []interface{} {
 		f := r.(*Index)
 		v := []interface{}{f.Schema, f.Name, f.Type, f.Table}
 		if verbose {
-			v = append(v, f.IsPrimary, f.IsUnique)
+			v = append(v, f.IsPrimary, f.IsUnique, f.Columns)
+			if predicates {
+				v = append(v, f.Predicate)
+			}
+			if sizes {
+				v = append(v, f.Size)
+			}
 		}
 		return v
 	})
@@ -773,7 +1049,7 @@ func (w DefaultWriter) ShowStats(u *dburl.URL, statTypes, pattern string, verbos
 }
 
 // ListPrivilegeSummaries matching pattern
-func (w DefaultWriter) ListPrivilegeSummaries(u *dburl.URL, pattern string, showSystem bool) error {
+func (w DefaultWriter) ListPrivilegeSummaries(u *dburl.URL, pattern string, verbose, showSystem bool) error {
 	r, ok := w.r.(PrivilegeSummaryReader)
 	if !ok {
 		return fmt.Errorf(text.NotSupportedByDriver, `\dp`, u.Driver)
@@ -791,7 +1067,10 @@ func (w DefaultWriter) ListPrivilegeSummaries(u *dburl.URL, pattern string, show
 		}
 	}
 	res, err := r.PrivilegeSummaries(Filter{Schema: sp, Name: tp, WithSystem: showSystem, Types: types})
-	if err != nil {
+	switch {
+	case errors.Is(err, text.ErrNotSupported):
+		return fmt.Errorf(text.NotSupportedByDriver, `\dp`, u.Driver)
+	case err != nil:
 		return fmt.Errorf("failed to list table privileges: %w", err)
 	}
 	defer res.Close()
@@ -802,6 +1081,11 @@ func (w DefaultWriter) ListPrivilegeSummaries(u *dburl.URL, pattern string, show
 			return !ok
 		})
 	}
+	params := env.Pall()
+	params["title"] = "Access privileges"
+	if verbose {
+		return tblfmt.EncodeAll(w.w, privilegeRows(res), params)
+	}
 
 	res.SetScanValues(func(r Result) []interface{} {
 		f := r.(*PrivilegeSummary)
@@ -815,19 +1099,532 @@ func (w DefaultWriter) ListPrivilegeSummaries(u *dburl.URL, pattern string, show
 		}
 		return v
 	})
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// privilegeRows returns a result set with a row for each individual object
+// and column privilege of the privilege summaries.
+func privilegeRows(res *PrivilegeSummarySet) *resultSet {
+	var results []Result
+	for res.Next() {
+		f := res.Get()
+		for _, p := range f.ObjectPrivileges {
+			results = append(results, privilegeRow{f.Schema, f.Name, f.ObjectType, "", p.Grantee, p.Grantor, p.PrivilegeType, p.IsGrantable})
+		}
+		for _, p := range f.ColumnPrivileges {
+			results = append(results, privilegeRow{f.Schema, f.Name, f.ObjectType, p.Column, p.Grantee, p.Grantor, p.PrivilegeType, p.IsGrantable})
+		}
+	}
+	return &resultSet{
+		results: results,
+		columns: []string{"Schema", "Name", "Type", "Column", "Grantee", "Grantor", "Privilege", "Grantable"},
+	}
+}
 
+// privilegeRow is a single privilege granted on an object or column.
+type privilegeRow struct {
+	schema, name, typ, column, grantee, grantor, privilege string
+	grantable                                              bool
+}
+
+func (r privilegeRow) Values() []interface{} {
+	return []interface{}{r.schema, r.name, r.typ, r.column, r.grantee, r.grantor, r.privilege, r.grantable}
+}
+
+// ListSettings matching pattern
+func (w DefaultWriter) ListSettings(u *dburl.URL, pattern string, verbose bool) error {
+	r, ok := w.r.(SettingReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dconfig`, u.Driver)
+	}
+	res, err := r.Settings(Filter{Name: strings.ReplaceAll(pattern, "*", "%")})
+	switch {
+	case errors.Is(err, text.ErrNotSupported):
+		return fmt.Errorf(text.NotSupportedByDriver, `\dconfig`, u.Driver)
+	case err != nil:
+		return fmt.Errorf("failed to list settings: %w", err)
+	}
+	defer res.Close()
+
+	if verbose {
+		res.SetColumns([]string{"Parameter", "Value", "Type", "Context", "Description"})
+		res.SetScanValues(func(r Result) []interface{} {
+			f := r.(*Setting)
+			return []interface{}{f.Name, f.Value, f.Type, f.Context, f.Description}
+		})
+	}
 	params := env.Pall()
-	params["title"] = "Access privileges"
+	params["title"] = "List of configuration parameters"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// ListMaterializedViews matching pattern
+func (w DefaultWriter) ListMaterializedViews(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	r, ok := w.r.(MaterializedViewReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dm`, u.Driver)
+	}
+	sp, tp, err := parsePattern(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to parse search pattern: %w", err)
+	}
+	res, err := r.MaterializedViews(Filter{Schema: sp, Name: tp, WithSystem: showSystem})
+	switch {
+	case errors.Is(err, text.ErrNotSupported):
+		return fmt.Errorf(text.NotSupportedByDriver, `\dm`, u.Driver)
+	case err != nil:
+		return fmt.Errorf("failed to list materialized views: %w", err)
+	}
+	defer res.Close()
+	if !showSystem {
+		// in case the reader doesn't implement WithSystem
+		res.SetFilter(func(r Result) bool {
+			_, ok := w.systemSchemas[r.(*MaterializedView).Schema]
+			return !ok
+		})
+	}
+	if res.Len() == 0 {
+		fmt.Fprintf(w.w, text.RelationNotFound, pattern)
+		fmt.Fprintln(w.w)
+		return nil
+	}
+	if verbose {
+		res.SetColumns([]string{"Schema", "Name", "Type", "State", "Last refresh", "Definition"})
+		res.SetScanValues(func(r Result) []interface{} {
+			f := r.(*MaterializedView)
+			return []interface{}{f.Schema, f.Name, "materialized view", f.State, f.LastRefresh, f.Definition}
+		})
+	}
+	params := env.Pall()
+	params["title"] = "List of materialized views"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// ListSequences matching pattern, with their current state
+func (w DefaultWriter) ListSequences(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	r, ok := w.r.(SequenceReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\ds+`, u.Driver)
+	}
+	sp, tp, err := parsePattern(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to parse search pattern: %w", err)
+	}
+	res, err := r.Sequences(Filter{Schema: sp, Name: tp, WithSystem: showSystem})
+	switch {
+	case errors.Is(err, text.ErrNotSupported):
+		return fmt.Errorf(text.NotSupportedByDriver, `\ds+`, u.Driver)
+	case err != nil:
+		return fmt.Errorf("failed to list sequences: %w", err)
+	}
+	defer res.Close()
+	if !showSystem {
+		// in case the reader doesn't implement WithSystem
+		res.SetFilter(func(r Result) bool {
+			_, ok := w.systemSchemas[r.(*Sequence).Schema]
+			return !ok
+		})
+	}
+	if res.Len() == 0 {
+		fmt.Fprintf(w.w, text.RelationNotFound, pattern)
+		fmt.Fprintln(w.w)
+		return nil
+	}
+	res.SetColumns([]string{"Schema", "Name", "Type", "Last value", "Increment", "Min", "Max", "Cache", "Cycles?"})
+	res.SetScanValues(func(r Result) []interface{} {
+		f := r.(*Sequence)
+		return []interface{}{f.Schema, f.Name, f.DataType, f.LastValue, f.Increment, f.Min, f.Max, f.Cache, f.Cycles}
+	})
+	params := env.Pall()
+	params["title"] = "List of sequences"
 	return tblfmt.EncodeAll(w.w, res, params)
 }
 
+// ListForeignServers matching pattern
+func (w DefaultWriter) ListForeignServers(u *dburl.URL, pattern string, verbose bool) error {
+	r, ok := w.r.(ForeignServerReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\des`, u.Driver)
+	}
+	res, err := r.ForeignServers(Filter{Name: strings.ReplaceAll(pattern, "*", "%")})
+	switch {
+	case errors.Is(err, text.ErrNotSupported):
+		return fmt.Errorf(text.NotSupportedByDriver, `\des`, u.Driver)
+	case err != nil:
+		return fmt.Errorf("failed to list foreign servers: %w", err)
+	}
+	defer res.Close()
+	if verbose {
+		res.SetColumns([]string{"Name", "Owner", "Foreign-data wrapper", "Access privileges", "Type", "Version", "FDW options", "Description"})
+		res.SetScanValues(func(r Result) []interface{} {
+			f := r.(*ForeignServer)
+			return []interface{}{f.Name, f.Owner, f.Wrapper, f.Privileges, f.Type, f.Version, f.Options, f.Description}
+		})
+	}
+	params := env.Pall()
+	params["title"] = "List of foreign servers"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// ListForeignTables matching pattern
+func (w DefaultWriter) ListForeignTables(u *dburl.URL, pattern string, verbose bool) error {
+	r, ok := w.r.(ForeignTableReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\det`, u.Driver)
+	}
+	sp, tp, err := parsePattern(pattern)
+	if err != nil {
+		return fmt.Errorf("failed to parse search pattern: %w", err)
+	}
+	res, err := r.ForeignTables(Filter{Schema: sp, Name: tp})
+	switch {
+	case errors.Is(err, text.ErrNotSupported):
+		return fmt.Errorf(text.NotSupportedByDriver, `\det`, u.Driver)
+	case err != nil:
+		return fmt.Errorf("failed to list foreign tables: %w", err)
+	}
+	defer res.Close()
+	if verbose {
+		res.SetColumns([]string{"Schema", "Table", "Server", "FDW options", "Description"})
+		res.SetScanValues(func(r Result) []interface{} {
+			f := r.(*ForeignTable)
+			return []interface{}{f.Schema, f.Name, f.Server, f.Options, f.Description}
+		})
+	}
+	params := env.Pall()
+	params["title"] = "List of foreign tables"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// ListForeignDataWrappers matching pattern
+func (w DefaultWriter) ListForeignDataWrappers(u *dburl.URL, pattern string, verbose bool) error {
+	r, ok := w.r.(ForeignDataWrapperReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dew`, u.Driver)
+	}
+	res, err := r.ForeignDataWrappers(Filter{Name: strings.ReplaceAll(pattern, "*", "%")})
+	switch {
+	case errors.Is(err, text.ErrNotSupported):
+		return fmt.Errorf(text.NotSupportedByDriver, `\dew`, u.Driver)
+	case err != nil:
+		return fmt.Errorf("failed to list foreign-data wrappers: %w", err)
+	}
+	defer res.Close()
+	if verbose {
+		res.SetColumns([]string{"Name", "Owner", "Handler", "Validator", "Access privileges", "FDW options", "Description"})
+		res.SetScanValues(func(r Result) []interface{} {
+			f := r.(*ForeignDataWrapper)
+			return []interface{}{f.Name, f.Owner, f.Handler, f.Validator, f.Privileges, f.Options, f.Description}
+		})
+	}
+	params := env.Pall()
+	params["title"] = "List of foreign-data wrappers"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// ListExtensions matching pattern, and in verbose mode, the objects owned by
+// each extension
+func (w DefaultWriter) ListExtensions(u *dburl.URL, pattern string, verbose bool) error {
+	r, ok := w.r.(ExtensionReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dx`, u.Driver)
+	}
+	res, err := r.Extensions(Filter{Name: strings.ReplaceAll(pattern, "*", "%")})
+	switch {
+	case errors.Is(err, text.ErrNotSupported):
+		return fmt.Errorf(text.NotSupportedByDriver, `\dx`, u.Driver)
+	case err != nil:
+		return fmt.Errorf("failed to list extensions: %w", err)
+	}
+	defer res.Close()
+	params := env.Pall()
+	if !verbose {
+		params["title"] = "List of installed extensions"
+		return tblfmt.EncodeAll(w.w, res, params)
+	}
+	or, ok := w.r.(ExtensionObjectReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\dx+`, u.Driver)
+	}
+	for res.Next() {
+		e := res.Get()
+		objs, err := or.ExtensionObjects(Filter{Parent: e.Name})
+		switch {
+		case errors.Is(err, text.ErrNotSupported):
+			return fmt.Errorf(text.NotSupportedByDriver, `\dx+`, u.Driver)
+		case err != nil:
+			return fmt.Errorf("failed to list objects in extension %q: %w", e.Name, err)
+		}
+		params["title"] = fmt.Sprintf("Objects in extension %q", e.Name)
+		err = tblfmt.EncodeAll(w.w, objs, params)
+		objs.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListRoles matching pattern, and in verbose mode, their connection limits,
+// password expiry, and the roles they are members of
+func (w DefaultWriter) ListRoles(u *dburl.URL, pattern string, verbose, showSystem bool) error {
+	r, ok := w.r.(RoleReader)
+	if !ok {
+		return fmt.Errorf(text.NotSupportedByDriver, `\du`, u.Driver)
+	}
+	res, err := r.Roles(Filter{Name: strings.ReplaceAll(pattern, "*", "%"), WithSystem: showSystem})
+	switch {
+	case errors.Is(err, text.ErrNotSupported):
+		return fmt.Errorf(text.NotSupportedByDriver, `\du`, u.Driver)
+	case err != nil:
+		return fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer res.Close()
+	if verbose {
+		res.SetColumns([]string{"Role name", "Attributes", "Connection limit", "Valid until", "Member of"})
+		res.SetScanValues(func(r Result) []interface{} {
+			f := r.(*Role)
+			limit := ""
+			if f.ConnectionLimit >= 0 {
+				limit = strconv.Itoa(f.ConnectionLimit)
+			}
+			return []interface{}{f.Name, f.Attributes(), limit, f.ValidUntil, f.MemberOf}
+		})
+	}
+	params := env.Pall()
+	params["title"] = "List of roles"
+	return tblfmt.EncodeAll(w.w, res, params)
+}
+
+// SearchObjects lists the tables, views, columns, functions, and indexes with
+// names matching pattern, ignoring case. Without wildcards, the pattern
+// matches any name containing it.
+func (w DefaultWriter) SearchObjects(u *dburl.URL, pattern string, showSystem bool) error {
+	like := objectPattern(pattern)
+	var res *ObjectSet
+	var err error
+	if r, ok := w.r.(ObjectReader); ok {
+		res, err = r.Objects(Filter{Name: like, WithSystem: showSystem})
+	}
+	if res == nil && (err == nil || errors.Is(err, text.ErrNotSupported)) {
+		// search the objects listed by the other readers, when the driver has
+		// no catalog query for all objects
+		res, err = w.searchObjects(like, showSystem)
+	}
+	switch {
+	case errors.Is(err, text.ErrNotSupported):
+		return fmt.Errorf(text.NotSupportedByDriver, `\dos`, u.Driver)
+	case err != nil:
+		return fmt.Errorf("failed to search objects: %w", err)
+	}
+	defer res.Close()
+
+	if !showSystem {
+		// in case the reader doesn't implement WithSystem
+		res.SetFilter(func(r Result) bool {
+			_, ok := w.systemSchemas[r.(*Object).Schema]
+			return !ok
+		})
+	}
+	if res.Len() == 0 {
+		fmt.Fprintf(w.w, text.RelationNotFound, pattern)
+		fmt.Fprintln(w.w)
+		return nil
+	}
+
+	params := env.Pall()
+	params["title"] = fmt.Sprintf("Objects matching %q", pattern)
+	if f, ok := w.w.(*os.File); ok && isatty.IsTerminal(f.Fd()) {
+		params["pager_cmd"] = env.All()["PAGER"]
+	}
+	err = tblfmt.EncodeAll(w.w, res, params)
+	if err != nil && params["pager_cmd"] != "" && errors.Is(err, syscall.EPIPE) {
+		// the pager was quit before reading all output
+		return nil
+	}
+	return err
+}
+
+// searchObjects lists the objects matching the lower case LIKE pattern from
+// the tables, columns, functions, and indexes of the reader.
+func (w DefaultWriter) searchObjects(like string, showSystem bool) (*ObjectSet, error) {
+	re := likeRegexp(like)
+	var objs []Object
+	supported := false
+	add := func(err error, f func()) error {
+		switch {
+		case errors.Is(err, text.ErrNotSupported):
+			return nil
+		case err != nil:
+			return err
+		}
+		supported = true
+		f()
+		return nil
+	}
+	var tables []Table
+	if r, ok := w.r.(TableReader); ok {
+		res, err := r.Tables(Filter{WithSystem: showSystem})
+		if err := add(err, func() {
+			defer res.Close()
+			for res.Next() {
+				t := res.Get()
+				if _, ok := w.systemSchemas[t.Schema]; !showSystem && (ok || t.Type == "SYSTEM TABLE") {
+					continue
+				}
+				tables = append(tables, *t)
+				if re.MatchString(strings.ToLower(t.Name)) {
+					typ := strings.ToLower(strings.TrimPrefix(t.Type, "BASE "))
+					objs = append(objs, Object{Schema: t.Schema, Name: t.Name, Type: typ})
+				}
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if r, ok := w.r.(ColumnReader); ok {
+		// list the columns of each table, as not all readers list the columns
+		// of all tables
+		for _, t := range tables {
+			res, err := r.Columns(Filter{Schema: t.Schema, Parent: t.Name, WithSystem: showSystem})
+			if err := add(err, func() {
+				defer res.Close()
+				for res.Next() {
+					c := res.Get()
+					if c.Table == t.Name && re.MatchString(strings.ToLower(c.Name)) {
+						objs = append(objs, Object{Schema: c.Schema, Name: c.Name, Type: "column", Table: c.Table})
+					}
+				}
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if r, ok := w.r.(FunctionReader); ok {
+		res, err := r.Functions(Filter{WithSystem: showSystem})
+		if err := add(err, func() {
+			defer res.Close()
+			for res.Next() {
+				f := res.Get()
+				if re.MatchString(strings.ToLower(f.Name)) {
+					typ := "function"
+					if strings.EqualFold(f.Type, "procedure") {
+						typ = "procedure"
+					}
+					objs = append(objs, Object{Schema: f.Schema, Name: f.Name, Type: typ})
+				}
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if r, ok := w.r.(IndexReader); ok {
+		res, err := r.Indexes(Filter{WithSystem: showSystem})
+		if err := add(err, func() {
+			defer res.Close()
+			for res.Next() {
+				i := res.Get()
+				if re.MatchString(strings.ToLower(i.Name)) {
+					objs = append(objs, Object{Schema: i.Schema, Name: i.Name, Type: "index", Table: i.Table})
+				}
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if !supported {
+		return nil, text.ErrNotSupported
+	}
+	sort.Slice(objs, func(i, j int) bool {
+		a, b := objs[i], objs[j]
+		switch {
+		case a.Schema != b.Schema:
+			return a.Schema < b.Schema
+		case a.Name != b.Name:
+			return a.Name < b.Name
+		case a.Type != b.Type:
+			return a.Type < b.Type
+		}
+		return a.Table < b.Table
+	})
+	// remove duplicates, such as overloaded functions
+	results := []Object{}
+	for i, o := range objs {
+		if i == 0 || o != objs[i-1] {
+			results = append(results, o)
+		}
+	}
+	return NewObjectSet(results), nil
+}
+
+// objectPattern converts an object search pattern to a lower case LIKE
+// pattern, where * and ? match any characters and any single character. A
+// pattern without wildcards matches any name containing it.
+func objectPattern(pattern string) string {
+	pattern = strings.ToLower(pattern)
+	if !strings.ContainsAny(pattern, "*?") {
+		return "%" + pattern + "%"
+	}
+	return strings.NewReplacer("*", "%", "?", "_").Replace(pattern)
+}
+
+// likeRegexp converts a LIKE pattern to a regexp.
+func likeRegexp(like string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, c := range like {
+		switch c {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// parsePattern splits pattern into schema and name patterns, replacing * with
+// %. Identifiers quoted with double quotes or brackets (as used by SQL Server)
+// are matched literally, including any dots, where a doubled closing quote is
+// an escaped quote.
 func parsePattern(pattern string) (string, string, error) {
-	// TODO do proper escaping, quoting etc
-	if strings.ContainsRune(pattern, '.') {
-		parts := strings.SplitN(pattern, ".", 2)
-		return strings.ReplaceAll(parts[0], "*", "%"), strings.ReplaceAll(parts[1], "*", "%"), nil
+	// TODO do proper escaping
+	var parts []string
+	var sb strings.Builder
+	var quote rune
+	r := []rune(pattern)
+	for i := 0; i < len(r); i++ {
+		switch c := r[i]; {
+		case quote != 0 && c == quote && i+1 < len(r) && r[i+1] == quote:
+			sb.WriteRune(c)
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			sb.WriteRune(c)
+		case c == '"':
+			quote = '"'
+		case c == '[':
+			quote = ']'
+		case c == '.' && len(parts) == 0:
+			parts = append(parts, sb.String())
+			sb.Reset()
+		case c == '*':
+			sb.WriteRune('%')
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	if quote != 0 {
+		return "", "", fmt.Errorf("unterminated quoted identifier in %q", pattern)
+	}
+	if len(parts) != 0 {
+		return parts[0], sb.String(), nil
 	}
-	return "", strings.ReplaceAll(pattern, "*", "%"), nil
+	return "", sb.String(), nil
 }
 
 func qualifiedIdentifier(schema, name string) string {
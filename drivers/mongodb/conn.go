@@ -0,0 +1,353 @@
+package mongodb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+)
+
+// errParamsNotSupported is the query parameters not supported error.
+var errParamsNotSupported = errors.New("query parameters are not supported")
+
+func init() {
+	sql.Register("mongodb", drv{})
+}
+
+// drv is a database/sql driver for MongoDB.
+type drv struct{}
+
+// Open satisfies the driver.Driver interface.
+func (d drv) Open(name string) (driver.Conn, error) {
+	c, err := d.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+	return c.Connect(context.Background())
+}
+
+// OpenConnector satisfies the driver.DriverContext interface.
+func (drv) OpenConnector(name string) (driver.Connector, error) {
+	return newConnector(name)
+}
+
+// connector is a database/sql connector for a MongoDB deployment.
+//
+// Queries are either MongoDB database commands written as (relaxed) extended
+// JSON, such as {"find": "books", "filter": {"year": 2004}}, or a simple
+// SELECT of fields from a collection. Database commands may contain a "$db"
+// field, to run the command against a database other than the one in the
+// connection string.
+type connector struct {
+	client *mongo.Client
+	dbName string
+}
+
+// newConnector creates a connector for the connection string.
+func newConnector(dsn string) (*connector, error) {
+	cs, err := connstring.ParseAndValidate(dsn)
+	if err != nil {
+		return nil, err
+	}
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(dsn))
+	if err != nil {
+		return nil, err
+	}
+	dbName := cs.Database
+	if dbName == "" {
+		dbName = "test"
+	}
+	return &connector{client: client, dbName: dbName}, nil
+}
+
+// Connect satisfies the driver.Connector interface.
+func (c *connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{c: c}, nil
+}
+
+// Driver satisfies the driver.Connector interface.
+func (c *connector) Driver() driver.Driver {
+	return drv{}
+}
+
+// Close satisfies the io.Closer interface, disconnecting the client when the
+// sql.DB is closed.
+func (c *connector) Close() error {
+	return c.client.Disconnect(context.Background())
+}
+
+// conn is a connection to a MongoDB deployment. As the client manages its
+// own pool of connections, a conn only wraps the connector's client.
+type conn struct {
+	c *connector
+}
+
+// Prepare satisfies the driver.Conn interface.
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{c: c, query: query}, nil
+}
+
+// Close satisfies the driver.Conn interface.
+func (c *conn) Close() error {
+	return nil
+}
+
+// Begin satisfies the driver.Conn interface.
+func (c *conn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+// Ping satisfies the driver.Pinger interface.
+func (c *conn) Ping(ctx context.Context) error {
+	return c.c.client.Ping(ctx, nil)
+}
+
+// QueryContext satisfies the driver.QueryerContext interface.
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if len(args) != 0 {
+		return nil, errParamsNotSupported
+	}
+	db, cmd, err := c.command(query)
+	if err != nil {
+		return nil, err
+	}
+	var docs []bson.Raw
+	switch cmd[0].Key {
+	case "find", "aggregate", "listCollections", "listIndexes":
+		cur, err := db.RunCommandCursor(ctx, cmd)
+		if err != nil {
+			return nil, err
+		}
+		defer cur.Close(ctx)
+		for cur.Next(ctx) {
+			docs = append(docs, append(bson.Raw(nil), cur.Current...))
+		}
+		if err := cur.Err(); err != nil {
+			return nil, err
+		}
+	default:
+		doc, err := db.RunCommand(ctx, cmd).Raw()
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return newRows(docs)
+}
+
+// ExecContext satisfies the driver.ExecerContext interface.
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if len(args) != 0 {
+		return nil, errParamsNotSupported
+	}
+	db, cmd, err := c.command(query)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := db.RunCommand(ctx, cmd).Raw()
+	if err != nil {
+		return nil, err
+	}
+	var n int64
+	if v, ok := doc.Lookup("n").AsInt64OK(); ok {
+		n = v
+	}
+	return driver.RowsAffected(n), nil
+}
+
+// selectRE matches a query selecting fields from a collection.
+var selectRE = regexp.MustCompile(`(?is)^\s*SELECT\s+(.+?)\s+FROM\s+("[^"]+"|[^\s;]+)(?:\s+LIMIT\s+(\d+))?\s*;?\s*$`)
+
+// command parses the query as either a SELECT or a database command,
+// returning the database to run the command against.
+func (c *conn) command(query string) (*mongo.Database, bson.D, error) {
+	dbName := c.c.dbName
+	var cmd bson.D
+	if m := selectRE.FindStringSubmatch(query); m != nil {
+		cmd = bson.D{{Key: "find", Value: strings.Trim(m[2], `"`)}}
+		if fields := strings.TrimSpace(m[1]); fields != "*" {
+			var projection bson.D
+			for _, field := range strings.Split(fields, ",") {
+				projection = append(projection, bson.E{Key: strings.Trim(strings.TrimSpace(field), `"`), Value: 1})
+			}
+			cmd = append(cmd, bson.E{Key: "projection", Value: projection})
+		}
+		if m[3] != "" {
+			limit, err := strconv.ParseInt(m[3], 10, 64)
+			if err != nil {
+				return nil, nil, err
+			}
+			cmd = append(cmd, bson.E{Key: "limit", Value: limit})
+		}
+	} else {
+		if err := bson.UnmarshalExtJSON([]byte(strings.TrimSuffix(strings.TrimSpace(query), ";")), false, &cmd); err != nil {
+			return nil, nil, fmt.Errorf("query must be a SELECT or a database command document: %w", err)
+		}
+		for i := 0; i < len(cmd); i++ {
+			if cmd[i].Key != "$db" {
+				continue
+			}
+			s, ok := cmd[i].Value.(string)
+			if !ok {
+				return nil, nil, errors.New(`"$db" must be a string`)
+			}
+			dbName, cmd = s, append(cmd[:i], cmd[i+1:]...)
+			i--
+		}
+		if len(cmd) == 0 {
+			return nil, nil, errors.New("empty database command")
+		}
+		// the aggregate command requires a cursor option
+		if cmd[0].Key == "aggregate" && !hasKey(cmd, "cursor") && !hasKey(cmd, "explain") {
+			cmd = append(cmd, bson.E{Key: "cursor", Value: bson.D{}})
+		}
+	}
+	return c.c.client.Database(dbName), cmd, nil
+}
+
+// hasKey returns true when the document contains the key.
+func hasKey(doc bson.D, key string) bool {
+	for _, e := range doc {
+		if e.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// stmt is a prepared statement.
+type stmt struct {
+	c     *conn
+	query string
+}
+
+// Close satisfies the driver.Stmt interface.
+func (s *stmt) Close() error {
+	return nil
+}
+
+// NumInput satisfies the driver.Stmt interface.
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+// Exec satisfies the driver.Stmt interface.
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	if len(args) != 0 {
+		return nil, errParamsNotSupported
+	}
+	return s.c.ExecContext(context.Background(), s.query, nil)
+}
+
+// Query satisfies the driver.Stmt interface.
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	if len(args) != 0 {
+		return nil, errParamsNotSupported
+	}
+	return s.c.QueryContext(context.Background(), s.query, nil)
+}
+
+// rows are the documents returned by a command. The columns are the union
+// of the top-level fields of all the documents, in order of appearance.
+type rows struct {
+	docs []bson.Raw
+	cols []string
+	i    int
+}
+
+// newRows creates rows for the documents.
+func newRows(docs []bson.Raw) (*rows, error) {
+	r := &rows{docs: docs}
+	seen := make(map[string]bool)
+	for _, doc := range docs {
+		elems, err := doc.Elements()
+		if err != nil {
+			return nil, err
+		}
+		for _, elem := range elems {
+			if key := elem.Key(); !seen[key] {
+				seen[key] = true
+				r.cols = append(r.cols, key)
+			}
+		}
+	}
+	return r, nil
+}
+
+// Columns satisfies the driver.Rows interface.
+func (r *rows) Columns() []string {
+	return r.cols
+}
+
+// Close satisfies the driver.Rows interface.
+func (r *rows) Close() error {
+	r.docs = nil
+	return nil
+}
+
+// Next satisfies the driver.Rows interface.
+func (r *rows) Next(dest []driver.Value) error {
+	if r.i >= len(r.docs) {
+		return io.EOF
+	}
+	doc := r.docs[r.i]
+	r.i++
+	for i, col := range r.cols {
+		v, err := convert(doc.Lookup(col))
+		if err != nil {
+			return err
+		}
+		dest[i] = v
+	}
+	return nil
+}
+
+// convert converts a BSON value to a driver value. Documents, arrays, and
+// other BSON specific types are converted to relaxed extended JSON.
+func convert(v bson.RawValue) (driver.Value, error) {
+	switch v.Type {
+	case 0, bsontype.Null, bsontype.Undefined:
+		return nil, nil
+	case bsontype.Double:
+		return v.Double(), nil
+	case bsontype.String:
+		return v.StringValue(), nil
+	case bsontype.Boolean:
+		return v.Boolean(), nil
+	case bsontype.Int32:
+		return int64(v.Int32()), nil
+	case bsontype.Int64:
+		return v.Int64(), nil
+	case bsontype.DateTime:
+		return v.Time(), nil
+	case bsontype.Timestamp:
+		t, _ := v.Timestamp()
+		return time.Unix(int64(t), 0), nil
+	case bsontype.ObjectID:
+		return v.ObjectID().Hex(), nil
+	case bsontype.Decimal128:
+		return v.Decimal128().String(), nil
+	case bsontype.Binary:
+		_, b := v.Binary()
+		return b, nil
+	}
+	// extended JSON can only be generated for documents, so wrap the value
+	// and strip the wrapping document
+	buf, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: v}}, false, false)
+	if err != nil {
+		return nil, err
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(string(buf), `{"v":`), "}"), nil
+}
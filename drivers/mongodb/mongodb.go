@@ -0,0 +1,97 @@
+// Package mongodb defines and registers usql's MongoDB driver.
+//
+// Queries are MongoDB database commands written as extended JSON, or a
+// simple SELECT of fields from a collection.
+//
+// See: https://github.com/mongodb/mongo-go-driver
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/mongo" // DRIVER
+	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/drivers/metadata"
+	"github.com/xo/dburl"
+)
+
+func init() {
+	dburl.Register(dburl.Scheme{
+		Driver:    "mongodb",
+		Generator: genDSN,
+		Transport: dburl.TransportAny,
+		Aliases:   []string{"mongo"},
+	})
+	drivers.Register("mongodb", drivers.Driver{
+		Version: func(ctx context.Context, db drivers.DB) (string, error) {
+			rows, err := db.QueryContext(ctx, `{"buildInfo": 1}`)
+			if err != nil {
+				return "", err
+			}
+			defer rows.Close()
+			docs, err := scanDocs(rows)
+			if err != nil {
+				return "", err
+			}
+			if len(docs) == 0 {
+				return "MongoDB", nil
+			}
+			return fmt.Sprintf("MongoDB %v", docs[0]["version"]), nil
+		},
+		// all commands return a document
+		Process: func(_ *dburl.URL, prefix string, sqlstr string) (string, string, bool, error) {
+			return prefix, sqlstr, true, nil
+		},
+		IsPasswordErr: func(err error) bool {
+			var e mongo.CommandError
+			if errors.As(err, &e) {
+				return e.Code == 18
+			}
+			return strings.Contains(err.Error(), "AuthenticationFailed")
+		},
+		Err: func(err error) (string, string) {
+			var e mongo.CommandError
+			if errors.As(err, &e) {
+				return e.Name, e.Message
+			}
+			return "", err.Error()
+		},
+		NewMetadataReader: NewReader,
+		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
+			writerOpts := []metadata.WriterOption{
+				metadata.WithSystemSchemas(systemDatabases),
+				metadata.WithTableDetails(func(out io.Writer, _, _ string) error {
+					_, err := fmt.Fprintf(out, "Note: field types are approximate, inferred from a sample of up to %d documents.\n", sampleSize)
+					return err
+				}),
+			}
+			return metadata.NewDefaultWriter(NewReader(db, opts...), writerOpts...)(db, w)
+		},
+	})
+}
+
+// genDSN generates a MongoDB connection string from the URL, using the
+// mongodb+srv scheme when the URL's transport is srv.
+func genDSN(u *dburl.URL) (string, string, error) {
+	scheme := "mongodb"
+	if u.Transport == "srv" {
+		scheme += "+srv"
+	}
+	z := &url.URL{
+		Scheme:   scheme,
+		User:     u.User,
+		Host:     u.Host,
+		Path:     u.Path,
+		RawPath:  u.RawPath,
+		RawQuery: u.RawQuery,
+	}
+	if z.Host == "" {
+		z.Host = "localhost"
+	}
+	return z.String(), "", nil
+}
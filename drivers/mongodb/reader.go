@@ -0,0 +1,303 @@
+package mongodb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/drivers/metadata"
+)
+
+// sampleSize is the number of documents sampled when inferring the fields of
+// a collection.
+const sampleSize = 100
+
+// systemDatabases are the databases used internally by MongoDB.
+var systemDatabases = []string{"admin", "config", "local"}
+
+type metaReader struct {
+	metadata.LoggingReader
+}
+
+var _ metadata.CatalogReader = &metaReader{}
+var _ metadata.SchemaReader = &metaReader{}
+var _ metadata.TableReader = &metaReader{}
+var _ metadata.ColumnReader = &metaReader{}
+
+// NewReader creates a metadata reader for MongoDB, where databases are
+// treated as schemas and collections as tables.
+func NewReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
+	return metadata.NewPluginReader(&metaReader{
+		LoggingReader: metadata.NewLoggingReader(db, opts...),
+	})
+}
+
+func (r metaReader) Catalogs(f metadata.Filter) (*metadata.CatalogSet, error) {
+	names, err := r.databases(f.Name, true)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]metadata.Catalog, len(names))
+	for i, name := range names {
+		results[i] = metadata.Catalog{Catalog: name}
+	}
+	return metadata.NewCatalogSet(results), nil
+}
+
+func (r metaReader) Schemas(f metadata.Filter) (*metadata.SchemaSet, error) {
+	names, err := r.databases(f.Name, f.WithSystem)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]metadata.Schema, len(names))
+	for i, name := range names {
+		results[i] = metadata.Schema{Schema: name}
+	}
+	return metadata.NewSchemaSet(results), nil
+}
+
+func (r metaReader) Tables(f metadata.Filter) (*metadata.TableSet, error) {
+	var dbNames []string
+	if f.Schema != "" {
+		var err error
+		if dbNames, err = r.databases(f.Schema, f.WithSystem); err != nil {
+			return nil, err
+		}
+	} else {
+		name, err := r.currentDatabase()
+		if err != nil {
+			return nil, err
+		}
+		dbNames = []string{name}
+	}
+	results := []metadata.Table{}
+	for _, dbName := range dbNames {
+		docs, err := r.query(`{"listCollections": 1, "authorizedCollections": true, "$db": %s}`, dbName)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			name, _ := doc["name"].(string)
+			if !match(f.Name, name) {
+				continue
+			}
+			typ := "TABLE"
+			switch {
+			case doc["type"] == "view":
+				typ = "VIEW"
+			case strings.HasPrefix(name, "system."):
+				typ = "SYSTEM TABLE"
+			}
+			if len(f.Types) != 0 && !contains(f.Types, typ) {
+				continue
+			}
+			results = append(results, metadata.Table{
+				Schema: dbName,
+				Name:   name,
+				Type:   typ,
+			})
+		}
+	}
+	return metadata.NewTableSet(results), nil
+}
+
+// Columns infers the fields of a collection, and their types, from a sample
+// of its documents.
+func (r metaReader) Columns(f metadata.Filter) (*metadata.ColumnSet, error) {
+	dbName := f.Schema
+	if dbName == "" {
+		var err error
+		if dbName, err = r.currentDatabase(); err != nil {
+			return nil, err
+		}
+	}
+	docs, err := r.query(`{"aggregate": %s, "pipeline": [
+  {"$sample": {"size": %d}},
+  {"$project": {"f": {"$objectToArray": "$$ROOT"}}},
+  {"$unwind": {"path": "$f", "includeArrayIndex": "i"}},
+  {"$group": {"_id": {"k": "$f.k", "t": {"$type": "$f.v"}}, "n": {"$sum": 1}, "i": {"$min": "$i"}}},
+  {"$project": {"_id": 0, "name": "$_id.k", "type": "$_id.t", "n": 1, "i": 1}}
+], "$db": %s}`, f.Parent, sampleSize, dbName)
+	if err != nil {
+		return nil, err
+	}
+	// merge the types of each field, ordering the fields by their first
+	// position in the sampled documents
+	type field struct {
+		name  string
+		types []string
+		count int64
+		pos   int64
+	}
+	fields := make(map[string]*field)
+	var total int64
+	for _, doc := range docs {
+		name, _ := doc["name"].(string)
+		typ, _ := doc["type"].(string)
+		n, _ := doc["n"].(int64)
+		pos, _ := doc["i"].(int64)
+		fd, ok := fields[name]
+		if !ok {
+			fd = &field{name: name, pos: pos}
+			fields[name] = fd
+		}
+		fd.types, fd.count = append(fd.types, typ), fd.count+n
+		if pos < fd.pos {
+			fd.pos = pos
+		}
+		// every document has an _id field
+		if name == "_id" && fd.count > total {
+			total = fd.count
+		}
+	}
+	v := make([]*field, 0, len(fields))
+	for _, fd := range fields {
+		if match(f.Name, fd.name) {
+			v = append(v, fd)
+		}
+	}
+	sort.Slice(v, func(i, j int) bool {
+		if v[i].pos != v[j].pos {
+			return v[i].pos < v[j].pos
+		}
+		return v[i].name < v[j].name
+	})
+	results := make([]metadata.Column, len(v))
+	for i, fd := range v {
+		sort.Strings(fd.types)
+		nullable := metadata.NO
+		if fd.count < total || contains(fd.types, "null") {
+			nullable = metadata.YES
+		}
+		results[i] = metadata.Column{
+			Schema:          dbName,
+			Table:           f.Parent,
+			Name:            fd.name,
+			OrdinalPosition: i + 1,
+			DataType:        strings.Join(fd.types, " | "),
+			IsNullable:      nullable,
+		}
+	}
+	return metadata.NewColumnSet(results), nil
+}
+
+// databases returns the names of the databases matching the pattern.
+func (r metaReader) databases(pattern string, withSystem bool) ([]string, error) {
+	docs, err := r.query(`{"listDatabases": 1, "nameOnly": true, "$db": "admin"}`)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	if len(docs) != 0 {
+		s, _ := docs[0]["databases"].(string)
+		var dbs []struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal([]byte(s), &dbs); err != nil {
+			return nil, err
+		}
+		for _, db := range dbs {
+			if match(pattern, db.Name) && (withSystem || !contains(systemDatabases, db.Name)) {
+				names = append(names, db.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// currentDatabase returns the name of the database of the connection.
+func (r metaReader) currentDatabase() (string, error) {
+	docs, err := r.query(`{"dbStats": 1}`)
+	if err != nil {
+		return "", err
+	}
+	if len(docs) == 0 {
+		return "", nil
+	}
+	name, _ := docs[0]["db"].(string)
+	return name, nil
+}
+
+// query runs the database command, with the string arguments encoded as JSON
+// strings, returning the resulting documents.
+func (r metaReader) query(cmd string, v ...interface{}) ([]map[string]interface{}, error) {
+	for i, arg := range v {
+		if s, ok := arg.(string); ok {
+			buf, err := json.Marshal(s)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = string(buf)
+		}
+	}
+	rows, closeRows, err := r.Query(fmt.Sprintf(cmd, v...))
+	if err != nil {
+		return nil, err
+	}
+	defer closeRows()
+	return scanDocs(rows)
+}
+
+// scanDocs scans the rows as documents.
+func scanDocs(rows *sql.Rows) ([]map[string]interface{}, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var docs []map[string]interface{}
+	for rows.Next() {
+		vals := make([]interface{}, len(cols))
+		for i := range vals {
+			vals[i] = new(interface{})
+		}
+		if err := rows.Scan(vals...); err != nil {
+			return nil, err
+		}
+		doc := make(map[string]interface{}, len(cols))
+		for i, col := range cols {
+			doc[col] = *vals[i].(*interface{})
+		}
+		docs = append(docs, doc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// match returns true when s matches the SQL LIKE pattern.
+func match(pattern, s string) bool {
+	if pattern == "" {
+		return true
+	}
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, c := range pattern {
+		switch c {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	ok, _ := regexp.MatchString(sb.String(), s)
+	return ok
+}
+
+// contains returns true when v contains s.
+func contains(v []string, s string) bool {
+	for _, z := range v {
+		if z == s {
+			return true
+		}
+	}
+	return false
+}
@@ -37,6 +37,7 @@ func init() {
 			return metadata.NewDefaultWriter(mymeta.NewReader(db, opts...))(db, w)
 		},
 		Copy:         drivers.CopyWithInsert(func(int) string { return "?" }),
+		Upsert:       drivers.UpsertOnDuplicateKey(func(int) string { return "?" }),
 		NewCompleter: mymeta.NewCompleter,
 	})
 }
@@ -9,15 +9,23 @@
 package mysql
 
 import (
+	"context"
+	"database/sql"
 	"io"
 	"strconv"
+	"strings"
 
 	"github.com/go-sql-driver/mysql" // DRIVER
+	"github.com/xo/dburl"
 	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/drivers/explain"
 	"github.com/rmasci/usql/drivers/metadata"
 	mymeta "github.com/rmasci/usql/drivers/metadata/mysql"
 )
 
+// tlsConfigs is the number of TLS configurations registered with the driver.
+var tlsConfigs int
+
 func init() {
 	drivers.Register("mysql", drivers.Driver{
 		AllowMultilineComments: true,
@@ -29,23 +37,105 @@ func init() {
 			"loc", "Local",
 			"sql_mode", "ansi",
 		}),
+		Open: func(_ context.Context, u *dburl.URL, _, _ func() io.Writer) (func(string, string) (*sql.DB, error), error) {
+			if err := registerTLSConfig(u); err != nil {
+				return nil, err
+			}
+			return sql.Open, nil
+		},
 		Err: func(err error) (string, string) {
 			if e, ok := err.(*mysql.MySQLError); ok {
 				return strconv.Itoa(int(e.Number)), e.Message
 			}
 			return "", err.Error()
 		},
+		ErrDetails: func(err error) []drivers.ErrDetail {
+			if e, ok := err.(*mysql.MySQLError); ok && e.SQLState != [5]byte{} {
+				return []drivers.ErrDetail{{Name: "SQLSTATE", Value: string(e.SQLState[:])}}
+			}
+			return nil
+		},
 		IsPasswordErr: func(err error) bool {
 			if e, ok := err.(*mysql.MySQLError); ok {
 				return e.Number == 1045
 			}
 			return false
 		},
+		TLS: func(ctx context.Context, db drivers.DB) (*drivers.TLSInfo, error) {
+			rows, err := db.QueryContext(ctx, `SHOW SESSION STATUS WHERE Variable_name IN ('Ssl_version', 'Ssl_cipher')`)
+			if err != nil {
+				return nil, err
+			}
+			defer rows.Close()
+			var info drivers.TLSInfo
+			for rows.Next() {
+				var name, value string
+				if err := rows.Scan(&name, &value); err != nil {
+					return nil, err
+				}
+				switch name {
+				case "Ssl_version":
+					info.Version = value
+				case "Ssl_cipher":
+					info.Cipher = value
+				}
+			}
+			if err := rows.Err(); err != nil {
+				return nil, err
+			}
+			info.Encrypted = info.Cipher != ""
+			return &info, nil
+		},
 		NewMetadataReader: mymeta.NewReader,
 		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
-			return metadata.NewDefaultWriter(mymeta.NewReader(db, opts...))(db, w)
+			return metadata.NewDefaultWriter(
+				mymeta.NewReader(db, opts...),
+				metadata.WithIdentifierQuote(func(s string) string {
+					return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+				}),
+			)(db, w)
 		},
 		Copy:         drivers.CopyWithInsert(func(int) string { return "?" }),
+		Upsert:       drivers.UpsertOnDuplicateKey(func(int) string { return "?" }),
 		NewCompleter: mymeta.NewCompleter,
+		Explain: func(ctx context.Context, db drivers.DB, sqlstr string, args ...interface{}) (*explain.Plan, error) {
+			var s string
+			if err := db.QueryRowContext(ctx, "EXPLAIN ANALYZE "+sqlstr, args...).Scan(&s); err != nil {
+				return nil, err
+			}
+			return explain.ParseMySQL(s)
+		},
+		DataTypes: map[string]string{
+			"float":       "DOUBLE",
+			"timestamp":   "DATETIME(6)",
+			"timestamptz": "DATETIME(6)",
+		},
 	}, "memsql", "vitess", "tidb")
 }
+
+// registerTLSConfig registers a TLS configuration built from the client
+// certificate query parameters of the URL with the driver, and changes the
+// URL's DSN to use it.
+func registerTLSConfig(u *dburl.URL) error {
+	tlsConfig, err := drivers.ClientTLSConfig(u)
+	if err != nil || tlsConfig == nil {
+		return err
+	}
+	cfg, err := mysql.ParseDSN(u.DSN)
+	if err != nil {
+		return err
+	}
+	for _, name := range drivers.ClientTLSParams {
+		delete(cfg.Params, name)
+	}
+	if cfg.TLSConfig == "skip-verify" {
+		tlsConfig.InsecureSkipVerify = true
+	}
+	tlsConfigs++
+	cfg.TLSConfig = "usql" + strconv.Itoa(tlsConfigs)
+	if err := mysql.RegisterTLSConfig(cfg.TLSConfig, tlsConfig); err != nil {
+		return err
+	}
+	u.DSN = cfg.FormatDSN()
+	return nil
+}
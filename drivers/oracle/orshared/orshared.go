@@ -63,6 +63,9 @@ func Register(name string, err func(error) (string, string), isPasswordErr func(
 			typ, q := drivers.QueryExecType(prefix, sqlstr)
 			return typ, sqlstr, q, nil
 		},
+		Placeholder: func(n int) string {
+			return fmt.Sprintf(":%d", n)
+		},
 		NewMetadataReader: orameta.NewReader(),
 		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
 			return metadata.NewDefaultWriter(orameta.NewReader()(db, opts...))(db, w)
@@ -70,5 +73,12 @@ func Register(name string, err func(error) (string, string), isPasswordErr func(
 		Copy: drivers.CopyWithInsert(func(n int) string {
 			return fmt.Sprintf(":%d", n)
 		}),
+		Limit: drivers.LimitWithFetchFirst,
+		DataTypes: map[string]string{
+			"int":   "NUMBER(19)",
+			"float": "BINARY_DOUBLE",
+			"bool":  "NUMBER(1)",
+			"text":  "CLOB",
+		},
 	})
 }
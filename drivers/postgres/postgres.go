@@ -13,11 +13,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/lib/pq" // DRIVER
 	"github.com/xo/dburl"
 	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/drivers/explain"
 	"github.com/rmasci/usql/drivers/metadata"
 	pgmeta "github.com/rmasci/usql/drivers/metadata/postgres"
 	"github.com/rmasci/usql/env"
@@ -55,6 +57,19 @@ func init() {
 			if u.Scheme == "cockroachdb" {
 				drivers.ForceQueryParameters([]string{"sslmode", "disable"})(u)
 			}
+			// lib/pq natively reads the client certificate files, so only
+			// rename the MySQL style parameters
+			q, changed := u.Query(), false
+			for _, p := range [][]string{{"ssl-ca", "sslrootcert"}, {"ssl-cert", "sslcert"}, {"ssl-key", "sslkey"}} {
+				if v := q.Get(p[0]); v != "" {
+					q.Del(p[0])
+					q.Set(p[1], v)
+					changed = true
+				}
+			}
+			if changed {
+				u.RawQuery = q.Encode()
+			}
 		},
 		Open: func(ctx context.Context, u *dburl.URL, stdout, stderr func() io.Writer) (func(string, string) (*sql.DB, error), error) {
 			return func(_, dsn string) (*sql.DB, error) {
@@ -89,6 +104,18 @@ func init() {
 			}
 			return "PostgreSQL " + ver, nil
 		},
+		TLS: func(ctx context.Context, db drivers.DB) (*drivers.TLSInfo, error) {
+			var info drivers.TLSInfo
+			var ver, cipher sql.NullString
+			err := db.QueryRowContext(ctx, `SELECT ssl, version, cipher FROM pg_stat_ssl WHERE pid = pg_backend_pid()`).Scan(&info.Encrypted, &ver, &cipher)
+			if err != nil {
+				// pg_stat_ssl is not available on PostgreSQL 9.4 and earlier,
+				// nor on most PostgreSQL compatible databases
+				return nil, nil
+			}
+			info.Version, info.Cipher = ver.String, cipher.String
+			return &info, nil
+		},
 		ChangePassword: func(db drivers.DB, user, newpw, _ string) error {
 			_, err := db.Exec(`ALTER USER ` + user + ` PASSWORD '` + newpw + `'`)
 			return err
@@ -99,12 +126,37 @@ func init() {
 			}
 			return "", err.Error()
 		},
+		ErrDetails: func(err error) []drivers.ErrDetail {
+			e, ok := err.(*pq.Error)
+			if !ok {
+				return nil
+			}
+			return []drivers.ErrDetail{
+				{Name: "Severity", Value: e.Severity},
+				{Name: "Condition", Value: e.Code.Name()},
+				{Name: "Detail", Value: e.Detail},
+				{Name: "Hint", Value: e.Hint},
+				{Name: "Position", Value: e.Position},
+				{Name: "Internal position", Value: e.InternalPosition},
+				{Name: "Internal query", Value: e.InternalQuery},
+				{Name: "Where", Value: e.Where},
+				{Name: "Schema", Value: e.Schema},
+				{Name: "Table", Value: e.Table},
+				{Name: "Column", Value: e.Column},
+				{Name: "Data type", Value: e.DataTypeName},
+				{Name: "Constraint", Value: e.Constraint},
+				{Name: "Location", Value: location(e.Routine, e.File, e.Line)},
+			}
+		},
 		IsPasswordErr: func(err error) bool {
 			if e, ok := err.(*pq.Error); ok {
 				return e.Code.Name() == "invalid_password"
 			}
 			return false
 		},
+		Placeholder: func(n int) string {
+			return "$" + strconv.Itoa(n)
+		},
 		NewMetadataReader: pgmeta.NewReader(),
 		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
 			return metadata.NewDefaultWriter(pgmeta.NewReader()(db, opts...))(db, w)
@@ -182,5 +234,47 @@ func init() {
 
 			return n, rows.Err()
 		},
+		Upsert: drivers.UpsertOnConflict(nil),
+		Cursor: func(ctx context.Context, db drivers.DB, sqlstr string, fetchSize int) (func() (*sql.Rows, error), func() error, error) {
+			// cursors only exist in a transaction, so when not in a
+			// transaction, the cursor is declared in its own transaction
+			tx, commit, rollback := db, func() error { return nil }, func() error { return nil }
+			if conn, ok := db.(*sql.DB); ok {
+				t, err := conn.BeginTx(ctx, nil)
+				if err != nil {
+					return nil, nil, err
+				}
+				tx, commit, rollback = t, t.Commit, t.Rollback
+			}
+			if _, err := tx.ExecContext(ctx, `DECLARE usql_cursor CURSOR FOR `+sqlstr); err != nil {
+				rollback()
+				return nil, nil, err
+			}
+			fetch := func() (*sql.Rows, error) {
+				return tx.QueryContext(ctx, fmt.Sprintf(`FETCH FORWARD %d FROM usql_cursor`, fetchSize))
+			}
+			return fetch, func() error {
+				_, err := tx.ExecContext(ctx, `CLOSE usql_cursor`)
+				if cerr := commit(); err == nil {
+					err = cerr
+				}
+				return err
+			}, nil
+		},
+		Explain: func(ctx context.Context, db drivers.DB, sqlstr string, args ...interface{}) (*explain.Plan, error) {
+			var buf []byte
+			if err := db.QueryRowContext(ctx, "EXPLAIN (ANALYZE, FORMAT JSON) "+sqlstr, args...).Scan(&buf); err != nil {
+				return nil, err
+			}
+			return explain.ParsePostgres(buf)
+		},
 	}, "cockroachdb", "redshift")
 }
+
+// location formats the source location of an error reported by the server.
+func location(routine, file, line string) string {
+	if routine == "" && file == "" {
+		return ""
+	}
+	return routine + ", " + file + ":" + line
+}
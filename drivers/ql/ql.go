@@ -4,6 +4,8 @@
 package ql
 
 import (
+	"strconv"
+
 	"github.com/rmasci/usql/drivers"
 	"modernc.org/ql" // DRIVER
 )
@@ -18,5 +20,8 @@ func init() {
 			"BEGIN TRANSACTION": "COMMIT",
 		},
 		BatchAsTransaction: true,
+		Placeholder: func(n int) string {
+			return "$" + strconv.Itoa(n)
+		},
 	})
 }
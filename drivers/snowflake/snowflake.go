@@ -4,15 +4,26 @@
 package snowflake
 
 import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"database/sql"
+	"encoding/base64"
+	"encoding/pem"
 	"io"
+	"net/url"
+	"os"
 	"strconv"
+	"strings"
 
 	"github.com/snowflakedb/gosnowflake" // DRIVER
+	"github.com/xo/dburl"
 	"github.com/xo/tblfmt"
 	"github.com/rmasci/usql/drivers"
 	"github.com/rmasci/usql/drivers/metadata"
 	infos "github.com/rmasci/usql/drivers/metadata/informationschema"
 	"github.com/rmasci/usql/env"
+	"github.com/rmasci/usql/text"
 )
 
 func init() {
@@ -25,10 +36,39 @@ func init() {
 		infos.WithFunctions(false),
 		infos.WithIndexes(false),
 		infos.WithConstraints(false),
+		infos.WithTriggers(false),
 		infos.WithColumnPrivileges(false),
+		infos.WithSystemSchemas([]string{"INFORMATION_SCHEMA"}),
+		infos.WithCurrentSchema("CURRENT_SCHEMA()"),
+		infos.WithIdentifierCase(identifierCase),
 	)
 	drivers.Register("snowflake", drivers.Driver{
 		AllowMultilineComments: true,
+		Open: func(_ context.Context, u *dburl.URL, _, _ func() io.Writer) (func(string, string) (*sql.DB, error), error) {
+			name := u.Query().Get("private_key_file")
+			if name == "" {
+				return sql.Open, nil
+			}
+			// key pair authentication
+			key, err := readPrivateKey(name)
+			if err != nil {
+				return nil, err
+			}
+			return func(driver, dsn string) (*sql.DB, error) {
+				q := make(url.Values)
+				if i := strings.LastIndexByte(dsn, '?'); i != -1 {
+					var err error
+					if q, err = url.ParseQuery(dsn[i+1:]); err != nil {
+						return nil, err
+					}
+					dsn = dsn[:i]
+				}
+				q.Del("private_key_file")
+				q.Set("authenticator", gosnowflake.AuthTypeJwt.String())
+				q.Set("privateKey", base64.URLEncoding.EncodeToString(key))
+				return sql.Open(driver, dsn+"?"+q.Encode())
+			}, nil
+		},
 		Err: func(err error) (string, string) {
 			if e, ok := err.(*gosnowflake.SnowflakeError); ok {
 				return strconv.Itoa(e.Number), e.Message
@@ -58,3 +98,46 @@ func listAllDbs(db drivers.DB, w io.Writer, pattern string, verbose bool) error
 	params["title"] = "List of databases"
 	return tblfmt.EncodeAll(w, rows, params)
 }
+
+// readPrivateKey reads a PEM encoded RSA private key (PKCS #1 or PKCS #8)
+// from the named file, returning it as PKCS #8 DER, as expected by
+// gosnowflake's privateKey parameter.
+func readPrivateKey(name string) ([]byte, error) {
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(buf)
+	if block == nil {
+		return nil, text.ErrInvalidPrivateKey
+	}
+	var key interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, text.ErrInvalidPrivateKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := key.(*rsa.PrivateKey); !ok {
+		return nil, text.ErrInvalidPrivateKey
+	}
+	return x509.MarshalPKCS8PrivateKey(key)
+}
+
+// identifierCase converts unquoted, lower case identifiers in patterns to
+// upper case, as Snowflake stores unquoted identifiers in upper case. Quoted
+// and mixed case identifiers are matched exactly.
+func identifierCase(s string) string {
+	if len(s) > 1 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	if s == strings.ToLower(s) {
+		return strings.ToUpper(s)
+	}
+	return s
+}
@@ -0,0 +1,56 @@
+//go:build !cgo || moderncsqlite
+
+package sqlite3
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/drivers/sqlite3/sqshared"
+	"github.com/xo/dburl"
+	"modernc.org/sqlite"
+)
+
+func init() {
+	drivers.Register("sqlite3", drivers.Driver{
+		AllowMultilineComments: true,
+		Open: func(_ context.Context, u *dburl.URL, _, _ func() io.Writer) (func(string, string) (*sql.DB, error), error) {
+			return func(_ string, params string) (*sql.DB, error) {
+				return sql.Open("sqlite", params)
+			}, nil
+		},
+		Version: func(ctx context.Context, db drivers.DB) (string, error) {
+			var ver string
+			err := db.QueryRowContext(ctx, `SELECT sqlite_version()`).Scan(&ver)
+			if err != nil {
+				return "", err
+			}
+			return "SQLite3 " + ver, nil
+		},
+		Err: func(err error) (string, string) {
+			if e, ok := err.(*sqlite.Error); ok {
+				// strip the result code description and number surrounding the
+				// message, matching the messages of the cgo driver
+				msg := strings.TrimSuffix(e.Error(), " ("+strconv.Itoa(e.Code())+")")
+				if _, s, ok := strings.Cut(msg, ": "); ok {
+					msg = s
+				}
+				return strconv.Itoa(e.Code()), msg
+			}
+			return "", err.Error()
+		},
+		ConvertBytes:      sqshared.ConvertBytes,
+		NewMetadataReader: sqshared.NewMetadataReader,
+		Copy:              drivers.CopyWithInsert(func(int) string { return "?" }),
+		Upsert:            drivers.UpsertOrReplace(func(int) string { return "?" }),
+		DataTypes: map[string]string{
+			"int":         "INTEGER",
+			"float":       "REAL",
+			"timestamptz": "TIMESTAMP",
+		},
+	})
+}
@@ -1,4 +1,8 @@
-// Package sqlite3 defines and registers usql's SQLite3 driver. Requires CGO.
+//go:build cgo && !moderncsqlite
+
+// Package sqlite3 defines and registers usql's SQLite3 driver. When built
+// without CGO, or with the moderncsqlite build tag, the driver uses the pure
+// Go modernc.org/sqlite package instead.
 //
 // See: https://github.com/mattn/go-sqlite3
 // Group: base
@@ -6,6 +10,7 @@ package sqlite3
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 
 	"github.com/mattn/go-sqlite3" // DRIVER
@@ -37,8 +42,27 @@ func init() {
 			}
 			return code, msg
 		},
+		ErrDetails: func(err error) []drivers.ErrDetail {
+			e, ok := err.(sqlite3.Error)
+			if !ok {
+				return nil
+			}
+			details := []drivers.ErrDetail{
+				{Name: "Extended code", Value: fmt.Sprintf("%d (%s)", int(e.ExtendedCode), e.ExtendedCode.Error())},
+			}
+			if e.SystemErrno != 0 {
+				details = append(details, drivers.ErrDetail{Name: "System error", Value: e.SystemErrno.Error()})
+			}
+			return details
+		},
 		ConvertBytes:      sqshared.ConvertBytes,
 		NewMetadataReader: sqshared.NewMetadataReader,
 		Copy:              drivers.CopyWithInsert(func(int) string { return "?" }),
+		Upsert:            drivers.UpsertOrReplace(func(int) string { return "?" }),
+		DataTypes: map[string]string{
+			"int":         "INTEGER",
+			"float":       "REAL",
+			"timestamptz": "TIMESTAMP",
+		},
 	})
 }
@@ -25,6 +25,7 @@ func NewReader(db drivers.DB, opts ...metadata.ReaderOption) metadata.Reader {
 		infos.WithIndexes(false),
 		infos.WithSequences(false),
 		infos.WithConstraints(false),
+		infos.WithTriggers(false),
 		infos.WithCustomClauses(map[infos.ClauseName]string{
 			infos.FunctionsSecurityType: "''",
 		}),
@@ -81,14 +81,45 @@ func init() {
 			}
 			return "", msg
 		},
+		ErrDetails: func(err error) []drivers.ErrDetail {
+			e, ok := err.(sqlserver.Error)
+			if !ok {
+				return nil
+			}
+			var line string
+			if e.LineNo != 0 {
+				line = strconv.Itoa(int(e.LineNo))
+			}
+			return []drivers.ErrDetail{
+				{Name: "State", Value: strconv.Itoa(int(e.State))},
+				{Name: "Class", Value: strconv.Itoa(int(e.Class))},
+				{Name: "Server", Value: e.ServerName},
+				{Name: "Procedure", Value: e.ProcName},
+				{Name: "Line", Value: line},
+			}
+		},
 		IsPasswordErr: func(err error) bool {
 			return strings.Contains(err.Error(), "Login failed for")
 		},
+		Placeholder:       placeholder,
 		NewMetadataReader: NewReader,
 		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
-			return metadata.NewDefaultWriter(NewReader(db, opts...))(db, w)
+			return metadata.NewDefaultWriter(
+				NewReader(db, opts...),
+				metadata.WithIdentifierQuote(func(s string) string {
+					return "[" + strings.ReplaceAll(s, "]", "]]") + "]"
+				}),
+			)(db, w)
+		},
+		Copy:  drivers.CopyWithInsert(placeholder),
+		Limit: drivers.LimitWithTop,
+		DataTypes: map[string]string{
+			"float":       "FLOAT",
+			"bool":        "BIT",
+			"timestamp":   "DATETIME2",
+			"timestamptz": "DATETIMEOFFSET",
+			"text":        "NVARCHAR(MAX)",
 		},
-		Copy: drivers.CopyWithInsert(placeholder),
 	})
 }
 
@@ -0,0 +1,99 @@
+// Package trino defines and registers usql's Trino (and Presto) driver.
+//
+// See: https://github.com/trinodb/trino-go-client
+package trino
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"strings"
+
+	_ "github.com/trinodb/trino-go-client/trino" // DRIVER
+	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/drivers/metadata"
+	infos "github.com/rmasci/usql/drivers/metadata/informationschema"
+	"github.com/rmasci/usql/env"
+	"github.com/xo/dburl"
+	"github.com/xo/tblfmt"
+)
+
+func init() {
+	newReader := infos.New(
+		infos.WithPlaceholder(func(int) string { return "?" }),
+		infos.WithFunctions(false),
+		infos.WithSequences(false),
+		infos.WithIndexes(false),
+		infos.WithConstraints(false),
+		infos.WithTriggers(false),
+		infos.WithColumnPrivileges(false),
+		infos.WithUsagePrivileges(false),
+		infos.WithSystemSchemas([]string{"information_schema"}),
+		infos.WithCurrentSchema("CURRENT_SCHEMA"),
+		infos.WithCustomClauses(map[infos.ClauseName]string{
+			infos.ColumnsColumnSize:       "0",
+			infos.ColumnsNumericScale:     "0",
+			infos.ColumnsNumericPrecRadix: "0",
+			infos.ColumnsCharOctetLength:  "0",
+			infos.SchemataSchemaOwner:     "''",
+		}),
+	)
+	drivers.Register("trino", drivers.Driver{
+		Version: func(ctx context.Context, db drivers.DB) (string, error) {
+			var ver string
+			err := db.QueryRowContext(ctx, `SELECT node_version FROM system.runtime.nodes WHERE coordinator LIMIT 1`).Scan(&ver)
+			if err != nil {
+				return "", err
+			}
+			return "Trino " + ver, nil
+		},
+		User: func(ctx context.Context, db drivers.DB) (string, error) {
+			var user string
+			if err := db.QueryRowContext(ctx, `SELECT current_user`).Scan(&user); err != nil {
+				return "", err
+			}
+			return user, nil
+		},
+		// the presto scheme uses the same client, as the trino-go-client only
+		// registers the trino database/sql driver
+		Open: func(context.Context, *dburl.URL, func() io.Writer, func() io.Writer) (func(string, string) (*sql.DB, error), error) {
+			return func(_, dsn string) (*sql.DB, error) {
+				return sql.Open("trino", dsn)
+			}, nil
+		},
+		Err: func(err error) (string, string) {
+			return "", strings.TrimPrefix(err.Error(), "trino: ")
+		},
+		// results are paged by the server, so avoid buffering the entire
+		// result set when encoding results
+		BufferRows:        1000,
+		NewMetadataReader: newReader,
+		NewMetadataWriter: func(db drivers.DB, w io.Writer, opts ...metadata.ReaderOption) metadata.Writer {
+			writerOpts := []metadata.WriterOption{
+				metadata.WithSystemSchemas([]string{"information_schema"}),
+				metadata.WithListAllDbs(func(pattern string, verbose bool) error {
+					return listAllDbs(db, w, pattern, verbose)
+				}),
+			}
+			return metadata.NewDefaultWriter(newReader(db, opts...), writerOpts...)(db, w)
+		},
+		Copy: drivers.CopyWithInsert(func(int) string { return "?" }),
+	}, "presto")
+}
+
+// listAllDbs lists the catalogs.
+func listAllDbs(db drivers.DB, w io.Writer, pattern string, verbose bool) error {
+	query, vals := `SHOW CATALOGS`, []interface{}{}
+	if pattern != "" {
+		query, vals = query+` LIKE ?`, append(vals, pattern)
+	}
+	rows, err := db.Query(query, vals...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	params := env.Pall()
+	params["title"] = "List of catalogs"
+	return tblfmt.EncodeAll(w, rows, params)
+}
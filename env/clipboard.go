@@ -0,0 +1,71 @@
+package env
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/rmasci/usql/text"
+)
+
+// Clipboard returns a writer to the system clipboard, copying the written
+// output to the clipboard when closed. Uses pbcopy on macOS, clip.exe on
+// Windows (and WSL), and wl-copy, xclip, or xsel with a Wayland or X11
+// display.
+func Clipboard() (io.WriteCloser, error) {
+	name, args := clipboardCommand()
+	if name == "" {
+		return nil, text.ErrNoClipboardAvailable
+	}
+	return &clipboard{name: name, args: args}, nil
+}
+
+// clipboardCommand returns the command copying its input to the clipboard,
+// or an empty name when there is no clipboard.
+func clipboardCommand() (string, []string) {
+	var cmds [][]string
+	switch {
+	case runtime.GOOS == "darwin":
+		cmds = append(cmds, []string{"pbcopy"})
+	case runtime.GOOS == "windows":
+		cmds = append(cmds, []string{"clip.exe"})
+	default:
+		if os.Getenv("WAYLAND_DISPLAY") != "" {
+			cmds = append(cmds, []string{"wl-copy"})
+		}
+		if os.Getenv("DISPLAY") != "" {
+			cmds = append(cmds, []string{"xclip", "-selection", "clipboard", "-in"}, []string{"xsel", "--clipboard", "--input"})
+		}
+		// WSL
+		cmds = append(cmds, []string{"clip.exe"})
+	}
+	for _, cmd := range cmds {
+		if _, err := exec.LookPath(cmd[0]); err == nil {
+			return cmd[0], cmd[1:]
+		}
+	}
+	return "", nil
+}
+
+// clipboard buffers output, copying it to the clipboard when closed.
+type clipboard struct {
+	bytes.Buffer
+	name string
+	args []string
+}
+
+// Close satisfies the io.Closer interface, copying the buffered output to the
+// clipboard.
+func (c *clipboard) Close() error {
+	cmd := exec.Command(c.name, c.args...)
+	// output is not captured, as xclip and xsel run in the background to
+	// serve the clipboard, keeping captured output open
+	cmd.Stdin = &c.Buffer
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", c.name, err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/rmasci/usql/text"
+)
+
+// commandNameRE matches valid user command names.
+var commandNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// commandArgRE matches positional arguments in a user command template.
+var commandArgRE = regexp.MustCompile(`\$[0-9]+`)
+
+// cmds are the user defined commands, and cmdsFile is the file they are
+// persisted to.
+var (
+	cmds     = make(map[string]string)
+	cmdsFile string
+)
+
+// LoadCommands loads the user defined commands from the file (see
+// CommandsFile), which is also used to persist any commands later defined with
+// SetCommand. A missing file is not an error.
+func LoadCommands(path string) error {
+	cmdsFile = path
+	buf, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return err
+	}
+	m := make(map[string]string)
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	cmds = m
+	return nil
+}
+
+// saveCommands writes the user defined commands to the commands file.
+func saveCommands() error {
+	if cmdsFile == "" {
+		return nil
+	}
+	buf, err := json.MarshalIndent(cmds, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cmdsFile, append(buf, '\n'), 0o600)
+}
+
+// Command returns the query template of a user defined command.
+func Command(name string) (string, bool) {
+	s, ok := cmds[name]
+	return s, ok
+}
+
+// Commands returns the sorted names of the user defined commands.
+func Commands() []string {
+	names := make([]string, 0, len(cmds))
+	for name := range cmds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetCommand defines (or redefines) a user command, persisting it to the
+// commands file.
+func SetCommand(name, tmpl string) error {
+	if !commandNameRE.MatchString(name) {
+		return fmt.Errorf(text.InvalidCommandName, name)
+	}
+	cmds[name] = tmpl
+	return saveCommands()
+}
+
+// UnsetCommand removes a user command, persisting the change to the commands
+// file.
+func UnsetCommand(name string) error {
+	if _, ok := cmds[name]; !ok {
+		return fmt.Errorf(text.UnknownUserCommand, name)
+	}
+	delete(cmds, name)
+	return saveCommands()
+}
+
+// CommandArgs returns the number of positional arguments ($1, $2, ...) used
+// by a user command's query template.
+func CommandArgs(tmpl string) int {
+	n := 0
+	for _, v := range commandArgRE.FindAllString(tmpl, -1) {
+		i, _ := strconv.Atoi(v[1:])
+		n = max(n, i)
+	}
+	return n
+}
+
+// ExpandCommand expands the positional arguments ($1, $2, ...) in a user
+// command's query template with the passed arguments.
+func ExpandCommand(name, tmpl string, args []string) (string, error) {
+	var err error
+	n := 0
+	s := commandArgRE.ReplaceAllStringFunc(tmpl, func(v string) string {
+		i, _ := strconv.Atoi(v[1:])
+		switch {
+		case i == 0:
+			return v
+		case i > len(args):
+			if err == nil {
+				err = fmt.Errorf(text.MissingCommandArgument, name, v)
+			}
+			return v
+		}
+		n = max(n, i)
+		return args[i-1]
+	})
+	switch {
+	case err != nil:
+		return "", err
+	case len(args) > n:
+		return "", text.ErrWrongNumberOfArguments
+	}
+	return s, nil
+}
@@ -0,0 +1,52 @@
+package env
+
+import (
+	"testing"
+)
+
+func TestExpandCommand(t *testing.T) {
+	tests := []struct {
+		tmpl string
+		args []string
+		exp  string
+		err  bool
+	}{
+		{`select 1`, nil, `select 1`, false},
+		{`select * from $1`, []string{"books"}, `select * from books`, false},
+		{`select * from $1 limit $2`, []string{"books", "5"}, `select * from books limit 5`, false},
+		{`select $2, $1, $1`, []string{"a", "b"}, `select b, a, a`, false},
+		{`select $0, $$x$$`, nil, `select $0, $$x$$`, false},
+		{`select * from $1 limit $2`, []string{"books"}, "", true},
+		{`select $1`, []string{"a", "b"}, "", true},
+	}
+	for i, test := range tests {
+		s, err := ExpandCommand("test", test.tmpl, test.args)
+		switch {
+		case test.err && err == nil:
+			t.Fatalf("test %d expected error, got: nil", i)
+		case !test.err && err != nil:
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+	}
+}
+
+func TestCommandArgs(t *testing.T) {
+	tests := []struct {
+		tmpl string
+		exp  int
+	}{
+		{`select 1`, 0},
+		{`select * from $1`, 1},
+		{`select * from $1 limit $2`, 2},
+		{`select $3, $1`, 3},
+		{`select $0`, 0},
+	}
+	for i, test := range tests {
+		if n := CommandArgs(test.tmpl); n != test.exp {
+			t.Errorf("test %d expected %d, got: %d", i, test.exp, n)
+		}
+	}
+}
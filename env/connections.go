@@ -0,0 +1,90 @@
+package env
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/rmasci/usql/text"
+)
+
+// connectionNameRE matches valid connection profile names.
+var connectionNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// conns are the saved connection profiles, and connsFile is the file they are
+// persisted to.
+var (
+	conns     = make(map[string]string)
+	connsFile string
+)
+
+// LoadConnections loads the saved connection profiles from the file (see
+// ConnectionsFile), which is also used to persist any profiles later saved
+// with SetConnection. A missing file is not an error.
+func LoadConnections(path string) error {
+	connsFile = path
+	buf, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return nil
+	case err != nil:
+		return err
+	}
+	m := make(map[string]string)
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	conns = m
+	return nil
+}
+
+// saveConnections writes the saved connection profiles to the connections
+// file.
+func saveConnections() error {
+	if connsFile == "" {
+		return nil
+	}
+	buf, err := json.MarshalIndent(conns, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(connsFile, append(buf, '\n'), 0o600)
+}
+
+// Connection returns the DSN of a saved connection profile.
+func Connection(name string) (string, bool) {
+	s, ok := conns[name]
+	return s, ok
+}
+
+// Connections returns the sorted names of the saved connection profiles.
+func Connections() []string {
+	names := make([]string, 0, len(conns))
+	for name := range conns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetConnection saves (or replaces) a connection profile, persisting it to
+// the connections file.
+func SetConnection(name, dsn string) error {
+	if !connectionNameRE.MatchString(name) {
+		return fmt.Errorf(text.InvalidProfileName, name)
+	}
+	conns[name] = dsn
+	return saveConnections()
+}
+
+// UnsetConnection removes a connection profile, persisting the change to the
+// connections file.
+func UnsetConnection(name string) error {
+	if _, ok := conns[name]; !ok {
+		return fmt.Errorf(text.UnknownProfile, name)
+	}
+	delete(conns, name)
+	return saveConnections()
+}
@@ -0,0 +1,70 @@
+package env
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rmasci/usql/text"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Encoding returns the character encoding and its canonical name for the
+// name, looking up the name first as an IANA name (or alias), and then as a
+// WHATWG label (such as "utf8" or "sjis"). An empty name is UTF-8.
+func Encoding(name string) (encoding.Encoding, string, error) {
+	if name == "" {
+		name = "UTF-8"
+	}
+	enc, err := ianaindex.IANA.Encoding(name)
+	if err != nil || enc == nil {
+		enc, err = htmlindex.Get(name)
+	}
+	if err != nil || enc == nil {
+		return nil, "", fmt.Errorf(text.UnknownEncoding, name, strings.Join(Encodings(), ", "))
+	}
+	canonical, err := encodingName(enc)
+	if err != nil {
+		return nil, "", fmt.Errorf(text.UnknownEncoding, name, strings.Join(Encodings(), ", "))
+	}
+	return enc, canonical, nil
+}
+
+// encodingName returns the preferred MIME name of the encoding, or its IANA
+// name when it does not have one.
+func encodingName(enc encoding.Encoding) (string, error) {
+	if name, err := ianaindex.MIME.Name(enc); err == nil {
+		return name, nil
+	}
+	return ianaindex.IANA.Name(enc)
+}
+
+// Encodings returns the sorted names of the supported character encodings.
+func Encodings() []string {
+	all := []encoding.Encoding{unicode.UTF8}
+	for _, v := range [][]encoding.Encoding{
+		charmap.All,
+		japanese.All,
+		korean.All,
+		simplifiedchinese.All,
+		traditionalchinese.All,
+	} {
+		all = append(all, v...)
+	}
+	var names []string
+	for _, enc := range all {
+		if name, err := encodingName(enc); err == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -4,15 +4,20 @@ package env
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"os/exec"
 	"os/user"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/kenshaw/rasterm"
@@ -36,6 +41,55 @@ func Getenv(keys ...string) (string, bool) {
 	return "", false
 }
 
+// ExpandEnv expands the ${NAME} and $NAME references to environment variables
+// in s (such as in a connection string), where $$ is a literal $, and a $ not
+// followed by a name is left as-is. Returns an error when a referenced
+// variable is not defined, rather than expanding it to an empty string.
+func ExpandEnv(s string) (string, error) {
+	if !strings.Contains(s, "$") {
+		return s, nil
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i == len(s)-1 {
+			sb.WriteByte(s[i])
+			continue
+		}
+		var name string
+		switch c := s[i+1]; {
+		case c == '$':
+			sb.WriteByte('$')
+			i++
+			continue
+		case c == '{':
+			j := strings.IndexByte(s[i+2:], '}')
+			if j == -1 {
+				return "", text.ErrUnterminatedEnvironmentVariable
+			}
+			name, i = s[i+2:i+2+j], i+2+j
+		case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+			j := i + 2
+			for ; j < len(s) && isEnvNameChar(s[j]); j++ {
+			}
+			name, i = s[i+1:j], j-1
+		default:
+			sb.WriteByte('$')
+			continue
+		}
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf(text.UndefinedEnvVar, name)
+		}
+		sb.WriteString(v)
+	}
+	return sb.String(), nil
+}
+
+// isEnvNameChar returns true when c is valid in an environment variable name.
+func isEnvNameChar(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
 // Chdir changes the current working directory to the specified path, or to the
 // user's home directory if path is not specified.
 func Chdir(u *user.User, path string) error {
@@ -73,7 +127,42 @@ func OpenFile(u *user.User, path string, relative bool) (string, *os.File, error
 	return path, f, nil
 }
 
-// EditFile edits a file. If path is empty, then a temporary file will be created.
+// maxURLSize is the maximum size of a file fetched by OpenURL.
+const maxURLSize = 16 << 20
+
+// urlTimeout is the timeout for fetching a file by OpenURL.
+const urlTimeout = 30 * time.Second
+
+// IsURL returns whether path is a http:// or https:// URL.
+func IsURL(path string) bool {
+	s := strings.ToLower(path)
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// OpenURL fetches the contents of a http:// or https:// URL.
+func OpenURL(urlstr string) (io.Reader, error) {
+	cl := &http.Client{Timeout: urlTimeout}
+	res, err := cl.Get(urlstr)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf(text.URLStatusError, res.Status)
+	}
+	buf, err := io.ReadAll(io.LimitReader(res.Body, maxURLSize+1))
+	switch {
+	case err != nil:
+		return nil, err
+	case len(buf) > maxURLSize:
+		return nil, text.ErrURLTooLarge
+	}
+	return bytes.NewReader(buf), nil
+}
+
+// EditFile edits a file. If path is empty, then a temporary file will be
+// created (and removed after editing). Returns an error when the editor exits
+// with a non-zero status.
 func EditFile(u *user.User, path, line, s string) ([]rune, error) {
 	ed := All()["EDITOR"]
 	if ed == "" {
@@ -95,6 +184,7 @@ func EditFile(u *user.User, path, line, s string) ([]rune, error) {
 			return nil, err
 		}
 		path = f.Name()
+		defer os.Remove(path)
 		err = os.WriteFile(path, []byte(strings.TrimSuffix(s, "\n")+"\n"), 0o644)
 		if err != nil {
 			return nil, err
@@ -115,7 +205,11 @@ func EditFile(u *user.User, path, line, s string) ([]rune, error) {
 	c.Stdout = os.Stdout
 	c.Stderr = os.Stderr
 	// run
-	if err := c.Run(); err != nil {
+	var exitErr *exec.ExitError
+	switch err := c.Run(); {
+	case errors.As(err, &exitErr):
+		return nil, fmt.Errorf(text.EditorExitStatus, exitErr.ExitCode())
+	case err != nil:
 		return nil, err
 	}
 	// read
@@ -152,6 +246,33 @@ func RCFile(u *user.User) string {
 	return passfile.Expand(u.HomeDir, path)
 }
 
+// CommandsFile returns the path to the user defined commands file.
+//
+// Defaults to ~/.<command name>_commands, overridden by environment variable
+// <COMMAND NAME>_COMMANDS (ie, ~/.usql_commands and USQL_COMMANDS).
+func CommandsFile(u *user.User) string {
+	n := text.CommandUpper() + "_COMMANDS"
+	path := "~/." + strings.ToLower(n)
+	if s, ok := Getenv(n); ok {
+		path = s
+	}
+	return passfile.Expand(u.HomeDir, path)
+}
+
+// ConnectionsFile returns the path to the saved connection profiles file.
+//
+// Defaults to ~/.<command name>_connections, overridden by environment
+// variable <COMMAND NAME>_CONNECTIONS (ie, ~/.usql_connections and
+// USQL_CONNECTIONS).
+func ConnectionsFile(u *user.User) string {
+	n := text.CommandUpper() + "_CONNECTIONS"
+	path := "~/." + strings.ToLower(n)
+	if s, ok := Getenv(n); ok {
+		path = s
+	}
+	return passfile.Expand(u.HomeDir, path)
+}
+
 // Getshell returns the user's defined SHELL, or system default (if found on
 // path) and the appropriate command-line argument for the returned shell.
 //
@@ -181,8 +302,26 @@ func Getshell() (string, string) {
 	return shell, param
 }
 
-// Shell runs s as a shell. When s is empty the user's SHELL or COMSPEC is
-// used. See Getshell.
+// ShellEnv returns the environment for shell commands, which is the process
+// environment along with the variables, each prefixed with USQL_VAR_ (ie, the
+// variable foo is passed as USQL_VAR_foo).
+func ShellEnv() []string {
+	prefix := text.CommandUpper() + "_VAR_"
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	environ := os.Environ()
+	for _, name := range names {
+		environ = append(environ, prefix+name+"="+vars[name])
+	}
+	return environ
+}
+
+// Shell runs s as a shell, passing the variables in the environment (see
+// ShellEnv). When s is empty the user's SHELL or COMSPEC is used. See
+// Getshell.
 func Shell(s string) error {
 	shell, param := Getshell()
 	if shell == "" {
@@ -195,18 +334,21 @@ func Shell(s string) error {
 	}
 	// drop to shell
 	cmd := exec.Command(shell, params...)
+	cmd.Env = ShellEnv()
 	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
 	_ = cmd.Run()
 	return nil
 }
 
-// Pipe starts a command and returns its input for writing.
+// Pipe starts a command and returns its input for writing, passing the
+// variables in the environment (see ShellEnv).
 func Pipe(c string) (io.WriteCloser, *exec.Cmd, error) {
 	shell, param := Getshell()
 	if shell == "" {
 		return nil, nil, text.ErrNoShellAvailable
 	}
 	cmd := exec.Command(shell, param, c)
+	cmd.Env = ShellEnv()
 	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
 	out, err := cmd.StdinPipe()
 	if err != nil {
@@ -215,8 +357,19 @@ func Pipe(c string) (io.WriteCloser, *exec.Cmd, error) {
 	return out, cmd, cmd.Start()
 }
 
+// OpenOutput opens the file for writing query output, truncating it, or when
+// the file name is prefixed with >>, appending to it.
+func OpenOutput(name string) (*os.File, error) {
+	flag := os.O_TRUNC
+	if strings.HasPrefix(name, ">>") {
+		name, flag = strings.TrimSpace(name[2:]), os.O_APPEND
+	}
+	return os.OpenFile(name, flag|os.O_CREATE|os.O_WRONLY, 0o644)
+}
+
 // Exec executes s using the user's SHELL / COMSPEC with -c (or /c) and
-// returning the captured output. See Getshell.
+// returning the captured output, passing the variables in the environment (see
+// ShellEnv). See Getshell.
 //
 // When SHELL or COMSPEC is not defined, then "sh" / "cmd.exe" will be used
 // instead, assuming it is found on the system's PATH.
@@ -229,7 +382,9 @@ func Exec(s string) (string, error) {
 	if shell == "" {
 		return "", text.ErrNoShellAvailable
 	}
-	buf, err := exec.Command(shell, param, s).CombinedOutput()
+	cmd := exec.Command(shell, param, s)
+	cmd.Env = ShellEnv()
+	buf, err := cmd.CombinedOutput()
 	if err != nil {
 		return "", err
 	}
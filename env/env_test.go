@@ -0,0 +1,37 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/rmasci/usql/text"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("USQL_TEST_PASS", "p@ss")
+	t.Setenv("USQL_TEST_HOST", "localhost")
+	tests := []struct {
+		s   string
+		exp string
+	}{
+		{"pg://user:${USQL_TEST_PASS}@${USQL_TEST_HOST}/db", "pg://user:p@ss@localhost/db"},
+		{"pg://user:$USQL_TEST_PASS@$USQL_TEST_HOST/db", "pg://user:p@ss@localhost/db"},
+		{"pg://user:pa$$word@host/db", "pg://user:pa$word@host/db"},
+		{"pg://user:pa$1@host/$", "pg://user:pa$1@host/$"},
+		{"sqlite:/tmp/a.db", "sqlite:/tmp/a.db"},
+	}
+	for i, test := range tests {
+		s, err := ExpandEnv(test.s)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+	}
+	if _, err := ExpandEnv("pg://user:${USQL_TEST_UNDEFINED}@host"); err == nil {
+		t.Errorf("expected error for undefined variable")
+	}
+	if _, err := ExpandEnv("pg://user:${USQL_TEST_PASS@host"); err != text.ErrUnterminatedEnvironmentVariable {
+		t.Errorf("expected %v, got: %v", text.ErrUnterminatedEnvironmentVariable, err)
+	}
+}
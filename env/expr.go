@@ -0,0 +1,102 @@
+package env
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"strconv"
+
+	"github.com/rmasci/usql/text"
+)
+
+// Eval evaluates a simple arithmetic or comparison expression, such as
+// `(5 + 1) * 2` or `10 / 4 >= 2.5`, returning the result as a string.
+//
+// Supports integer and float literals, true and false, parentheses, the
+// arithmetic operators + - * / %, the comparison operators == != < <= > >=,
+// and the logical operators && || !. Division of integers is integer
+// division.
+func Eval(expr string) (string, error) {
+	x, err := parser.ParseExpr(expr)
+	if err != nil {
+		return "", text.ErrInvalidExpression
+	}
+	v, err := eval(x)
+	if err != nil {
+		return "", err
+	}
+	switch v.Kind() {
+	case constant.Bool:
+		return strconv.FormatBool(constant.BoolVal(v)), nil
+	case constant.Int:
+		return v.ExactString(), nil
+	}
+	f, _ := constant.Float64Val(v)
+	return strconv.FormatFloat(f, 'g', -1, 64), nil
+}
+
+// eval evaluates the expression.
+func eval(x ast.Expr) (constant.Value, error) {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		if x.Kind == token.INT || x.Kind == token.FLOAT {
+			return constant.MakeFromLiteral(x.Value, x.Kind, 0), nil
+		}
+	case *ast.Ident:
+		switch x.Name {
+		case "true", "false":
+			return constant.MakeBool(x.Name == "true"), nil
+		}
+	case *ast.ParenExpr:
+		return eval(x.X)
+	case *ast.UnaryExpr:
+		v, err := eval(x.X)
+		switch {
+		case err != nil:
+			return nil, err
+		case x.Op == token.NOT && v.Kind() == constant.Bool,
+			(x.Op == token.ADD || x.Op == token.SUB) && v.Kind() != constant.Bool:
+			return constant.UnaryOp(x.Op, v, 0), nil
+		}
+	case *ast.BinaryExpr:
+		l, err := eval(x.X)
+		if err != nil {
+			return nil, err
+		}
+		r, err := eval(x.Y)
+		if err != nil {
+			return nil, err
+		}
+		return binaryOp(x.Op, l, r)
+	}
+	return nil, text.ErrInvalidExpression
+}
+
+// binaryOp applies the binary operator to l and r.
+func binaryOp(op token.Token, l, r constant.Value) (constant.Value, error) {
+	isBool := l.Kind() == constant.Bool
+	if isBool != (r.Kind() == constant.Bool) {
+		return nil, text.ErrInvalidExpression
+	}
+	isInt := l.Kind() == constant.Int && r.Kind() == constant.Int
+	switch {
+	case !isBool && (op == token.ADD || op == token.SUB || op == token.MUL):
+		return constant.BinaryOp(l, op, r), nil
+	case !isBool && (op == token.QUO || op == token.REM && isInt):
+		if constant.Sign(r) == 0 {
+			return nil, text.ErrDivisionByZero
+		}
+		if op == token.QUO && isInt {
+			// integer division
+			op = token.QUO_ASSIGN
+		}
+		return constant.BinaryOp(l, op, r), nil
+	case isBool && (op == token.LAND || op == token.LOR):
+		return constant.BinaryOp(l, op, r), nil
+	case op == token.EQL || op == token.NEQ,
+		!isBool && (op == token.LSS || op == token.LEQ || op == token.GTR || op == token.GEQ):
+		return constant.MakeBool(constant.Compare(l, op, r)), nil
+	}
+	return nil, text.ErrInvalidExpression
+}
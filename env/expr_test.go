@@ -0,0 +1,43 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/rmasci/usql/text"
+)
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp string
+		err error
+	}{
+		{`1 + 2 * 3`, "7", nil},
+		{`(1 + 2) * 3`, "9", nil},
+		{`7 / 2`, "3", nil},
+		{`7 / 2.0`, "3.5", nil},
+		{`7 % 3`, "1", nil},
+		{`-5 + 2`, "-3", nil},
+		{`1.5 * 2`, "3", nil},
+		{`3 >= 2`, "true", nil},
+		{`1 == 1.0`, "true", nil},
+		{`2 < 1 || !false`, "true", nil},
+		{`1 / 0`, "", text.ErrDivisionByZero},
+		{`1 % 0`, "", text.ErrDivisionByZero},
+		{`7.5 % 2`, "", text.ErrInvalidExpression},
+		{`true + 1`, "", text.ErrInvalidExpression},
+		{`2 && 3`, "", text.ErrInvalidExpression},
+		{`foo + 1`, "", text.ErrInvalidExpression},
+		{`'a'`, "", text.ErrInvalidExpression},
+		{`1 +`, "", text.ErrInvalidExpression},
+	}
+	for i, test := range tests {
+		s, err := Eval(test.s)
+		if err != test.err {
+			t.Fatalf("test %d expected error %v, got: %v", i, test.err, err)
+		}
+		if s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+	}
+}
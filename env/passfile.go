@@ -0,0 +1,168 @@
+package env
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/rmasci/usql/text"
+	"github.com/xo/dburl"
+	"github.com/xo/dburl/passfile"
+)
+
+// PassfileUser returns the username and password for the URL from the first
+// matching entry in the user's password file (~/.usqlpass, or the path in the
+// USQLPASS environment variable), or for PostgreSQL URLs, from the first
+// matching entry in the user's libpq password file (~/.pgpass, or the path in
+// the PGPASSFILE environment variable). Returns nil when the URL has a
+// password, or when there is no matching entry.
+//
+// Entries are matched as by libpq, with each field matching either exactly,
+// or as a wildcard (*). When the URL has no username, the username of the
+// matching entry is used.
+func PassfileUser(u *dburl.URL, usr *user.User) (*url.Userinfo, error) {
+	var username string
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			return nil, nil
+		}
+		username = u.User.Username()
+	}
+	host, port, dbname := u.Hostname(), u.Port(), strings.TrimPrefix(u.Path, "/")
+	if u.Opaque != "" {
+		host, port, dbname = u.Opaque, "", ""
+	}
+	// protocol:host:port:dbname:user:pass
+	entries, err := readPassfile(passfile.Path(usr.HomeDir, text.PassfileName), 6)
+	if err != nil {
+		return nil, err
+	}
+	protocols := dburl.Protocols(u.Driver)
+	for _, v := range entries {
+		if (v[0] == "*" || slices.Contains(protocols, v[0])) && passfileMatch(v[1:5], host, port, dbname, username) {
+			return passfileUserinfo(v[4], v[5], username), nil
+		}
+	}
+	if u.Driver != "postgres" {
+		return nil, nil
+	}
+	// use libpq's defaults, where unix socket connections match localhost
+	if host == "" || strings.HasPrefix(host, "/") {
+		host = "localhost"
+	}
+	if port == "" {
+		port = "5432"
+	}
+	if username == "" {
+		username = usr.Username
+	}
+	if dbname == "" {
+		dbname = username
+	}
+	// host:port:dbname:user:pass
+	if entries, err = readPassfile(PgpassFile(usr), 5); err != nil {
+		return nil, err
+	}
+	for _, v := range entries {
+		if passfileMatch(v[:4], host, port, dbname, username) {
+			return passfileUserinfo(v[3], v[4], username), nil
+		}
+	}
+	return nil, nil
+}
+
+// PgpassFile returns the path of the user's libpq password file.
+func PgpassFile(u *user.User) string {
+	if s := os.Getenv("PGPASSFILE"); s != "" {
+		return passfile.Expand(u.HomeDir, s)
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "postgresql", "pgpass.conf")
+	}
+	return filepath.Join(u.HomeDir, ".pgpass")
+}
+
+// passfileMatch returns true when each of the entry's host, port, dbname, and
+// user fields match the values. An empty username matches any user.
+func passfileMatch(fields []string, host, port, dbname, username string) bool {
+	for i, v := range []string{host, port, dbname, username} {
+		if fields[i] != "*" && fields[i] != v && (i != 3 || v != "") {
+			return false
+		}
+	}
+	return true
+}
+
+// passfileUserinfo returns the user info for a matching entry.
+func passfileUserinfo(entryUser, password, username string) *url.Userinfo {
+	if entryUser != "*" {
+		username = entryUser
+	}
+	return url.UserPassword(username, password)
+}
+
+// readPassfile reads the entries of the password file, where each entry has
+// n colon separated fields. As with libpq, lines starting with # are
+// comments, and colons and backslashes in a field can be escaped with a
+// backslash. Returns no entries when the file does not exist, and an error
+// when the file is accessible by the group or others.
+func readPassfile(path string, n int) ([][]string, error) {
+	fi, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		return nil, nil
+	case err != nil:
+		return nil, &passfile.FileError{File: path, Err: err}
+	case fi.IsDir():
+		return nil, &passfile.FileError{File: path, Err: passfile.ErrMustNotBeDirectory}
+	case runtime.GOOS != "windows" && fi.Mode().Perm()&0o077 != 0:
+		return nil, &passfile.FileError{File: path, Err: passfile.ErrHasGroupOrWorldAccess}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, &passfile.FileError{File: path, Err: err}
+	}
+	defer f.Close()
+	var entries [][]string
+	s := bufio.NewScanner(f)
+	for i := 1; s.Scan(); i++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		v := splitPassfileLine(line, n)
+		if len(v) != n {
+			return nil, &passfile.FileError{File: path, Err: &passfile.ErrInvalidEntry{Line: i}}
+		}
+		entries = append(entries, v)
+	}
+	if err := s.Err(); err != nil {
+		return nil, &passfile.FileError{File: path, Err: err}
+	}
+	return entries, nil
+}
+
+// splitPassfileLine splits a password file line into at most n fields on
+// unescaped colons.
+func splitPassfileLine(line string, n int) []string {
+	var v []string
+	var sb strings.Builder
+	for r := []rune(line); len(r) != 0; r = r[1:] {
+		switch {
+		case r[0] == '\\' && len(r) > 1:
+			r = r[1:]
+			sb.WriteRune(r[0])
+		case r[0] == ':' && len(v) < n-1:
+			v = append(v, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteRune(r[0])
+		}
+	}
+	return append(v, sb.String())
+}
@@ -0,0 +1,66 @@
+package env
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+
+	"github.com/xo/dburl"
+)
+
+func TestPassfileUser(t *testing.T) {
+	dir := t.TempDir()
+	usqlpass, pgpass := filepath.Join(dir, "usqlpass"), filepath.Join(dir, "pgpass")
+	t.Setenv("USQLPASS", usqlpass)
+	t.Setenv("PGPASSFILE", pgpass)
+	write := func(name, s string, mode os.FileMode) {
+		if err := os.WriteFile(name, []byte(s), mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(name, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(usqlpass, "# comment\nmysql:db.example.com:*:sales:*:my\\:pass\n*:*:*:*:booktest:booktest\n", 0o600)
+	write(pgpass, "localhost:5432:ken:ken:pg#pass\n*:*:inventory:*:pg:pass:word\n", 0o600)
+	usr := &user.User{Username: "ken", HomeDir: dir}
+	tests := []struct {
+		s   string
+		exp string
+	}{
+		{"my://alice@db.example.com/sales", "alice:my:pass"},
+		{"my://alice:secret@db.example.com/sales", ""},
+		{"my://alice@db.example.com/other", ""},
+		{"my://db.example.com/other", "booktest:booktest"},
+		{"pg://alice@localhost/books", ""},
+		{"pg://localhost", "booktest:booktest"},
+		{"pg://ken@localhost", "ken:pg#pass"},
+		{"pg://bob@db.example.com:5433/inventory", "bob:pg:pass:word"},
+		{"sq:/tmp/test.db", "booktest:booktest"},
+	}
+	for i, test := range tests {
+		u, err := dburl.Parse(test.s)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		v, err := PassfileUser(u, usr)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		var s string
+		if v != nil {
+			pass, _ := v.Password()
+			s = v.Username() + ":" + pass
+		}
+		if s != test.exp {
+			t.Errorf("test %d %s expected %q, got: %q", i, test.s, test.exp, s)
+		}
+	}
+	// refuse files accessible by others
+	write(pgpass, "*:*:*:*:pass\n", 0o644)
+	u, _ := dburl.Parse("pg://ken@localhost/ken")
+	if _, err := PassfileUser(u, usr); err == nil {
+		t.Errorf("expected error, got: nil")
+	}
+}
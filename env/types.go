@@ -3,6 +3,7 @@ package env
 import (
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"regexp"
 	"sort"
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
 	syslocale "github.com/jeandeaual/go-locale"
 	"github.com/xo/terminfo"
@@ -26,10 +28,22 @@ func (v varName) String() string {
 }
 
 var varNames = []varName{
+	{
+		"ECHO",
+		"display executed queries to standard error [none, queries, all, errors]; if set to \"errors\", only display failed queries",
+	},
 	{
 		"ECHO_HIDDEN",
 		"if set, display internal queries executed by backslash commands; if set to \"noexec\", just show them without execution",
 	},
+	{
+		"ENCODING",
+		"current client character set encoding",
+	},
+	{
+		"LOOP_I",
+		"iteration of the innermost \\loop being executed, starting at 1",
+	},
 	{
 		"ON_ERROR_STOP",
 		"stop batch execution after error",
@@ -38,6 +52,14 @@ var varNames = []varName{
 		"PROMPT1",
 		"specifies the standard " + text.CommandName + " prompt",
 	},
+	{
+		"PROMPT2",
+		"specifies the prompt used when a statement continues from a previous line (default PROMPT1)",
+	},
+	{
+		"PROMPT3",
+		"specifies the prompt used when recording the body of a \\loop or \\batch (default PROMPT1)",
+	},
 	{
 		"QUIET",
 		"run quietly (same as -q option)",
@@ -49,6 +71,10 @@ var varNames = []varName{
 }
 
 var pvarNames = []varName{
+	{
+		"bind_params",
+		"bind variables in queries as query parameters, instead of interpolating [on, off]",
+	},
 	{
 		"border",
 		"border style (number)",
@@ -57,10 +83,46 @@ var pvarNames = []varName{
 		"columns",
 		"target width for the wrapped format",
 	},
+	{
+		"connect_retries",
+		"number of times to retry a failed connection, 0 to disable (default)",
+	},
+	{
+		"connect_retry_interval",
+		"initial interval between connection retries, doubled after each retry (default 1s)",
+	},
 	{
 		"csv_fieldsep",
 		`field separator for CSV output (default ",")`,
 	},
+	{
+		"csv_header",
+		"enable or disable the header row for CSV output [on, off]",
+	},
+	{
+		"csv_null",
+		"set the string written for a null value in CSV output (default: empty)",
+	},
+	{
+		"csv_quote",
+		`quote character for CSV output, or empty to disable quoting (default '"')`,
+	},
+	{
+		"describe_exact_rows",
+		"count rows exactly in \\dt+ output, instead of using estimates [on, off]",
+	},
+	{
+		"describe_size",
+		"show the total table size in \\d+ output [on, off]",
+	},
+	{
+		"exit_on_empty",
+		"exit with status 2 when the last statement returned no rows [on, off]",
+	},
+	{
+		"exit_on_rows",
+		"exit with status 2 when the last statement returned rows [on, off]",
+	},
 	{
 		"expanded",
 		"expanded output [on, off, auto]",
@@ -77,14 +139,58 @@ var pvarNames = []varName{
 		"footer",
 		"enable or disable display of the table footer [on, off]",
 	},
+	{
+		"footer_format",
+		"text of the table footer, where {rows} is the row count, empty for the default",
+	},
 	{
 		"format",
-		"set output format [unaligned, aligned, wrapped, vertical, html, asciidoc, csv, json, ...]",
+		"set output format [unaligned, aligned, wrapped, vertical, html, asciidoc, csv, json, yaml, ...]",
+	},
+	{
+		"fwf_align",
+		"comma separated alignments of fixed-width (fwf) format columns [l, r], empty for automatic",
+	},
+	{
+		"fwf_widths",
+		"comma separated widths of fixed-width (fwf) format columns, empty for the widest value",
+	},
+	{
+		"g_limit",
+		"treat a numeric \\g argument as a row limit for SELECT queries [on, off]",
+	},
+	{
+		"gexec_confirm",
+		"prompt to confirm destructive statements (DROP, TRUNCATE, and DELETE without WHERE) executed by \\gexec [on, off]",
+	},
+	{
+		"html_class",
+		"CSS class of the table tag in html format",
+	},
+	{
+		"json_indent",
+		"number of spaces to indent pretty-printed JSON values (see json_pretty)",
+	},
+	{
+		"json_pretty",
+		"pretty-print JSON column values in expanded output [on, off]",
 	},
 	{
 		"linestyle",
 		"set the border line drawing style [ascii, old-ascii, unicode]",
 	},
+	{
+		"max_col_width",
+		"truncate aligned output cell values to this many characters, 0 to disable (default)",
+	},
+	{
+		"max_col_width_exclude",
+		"comma separated names of columns to never truncate with max_col_width",
+	},
+	{
+		"metadata_cache_ttl",
+		"duration metadata queries of describe commands and completion are cached, or 0 to disable (see \\refresh)",
+	},
 	{
 		"null",
 		"set the string to be printed in place of a null value",
@@ -101,6 +207,14 @@ var pvarNames = []varName{
 		"pager",
 		"control when an external pager is used [on, off, always]",
 	},
+	{
+		"parquet_compression",
+		"compression codec for \\copy to Parquet files [snappy, zstd, gzip, none]",
+	},
+	{
+		"parquet_row_group_size",
+		"number of rows per row group for \\copy to Parquet files",
+	},
 	{
 		"recordsep",
 		"record (line) separator for unaligned output",
@@ -109,14 +223,34 @@ var pvarNames = []varName{
 		"recordsep_zero",
 		"set record separator for unaligned output to a zero byte",
 	},
+	{
+		"single_transaction",
+		"execute included files (\\i, \\ir) as a single transaction [on, off]",
+	},
 	{
 		"tableattr",
-		"specify attributes for table tag in html format, or proportional column widths for left-aligned data types in latex-longtable format",
+		"specify attributes for table tag in html format (where a border attribute also sets the border style of aligned format), or proportional column widths for left-aligned data types in latex-longtable format",
 	},
 	{
 		"time",
 		`format used to display time/date column values (default "RFC3339Nano")`,
 	},
+	{
+		"timing_file",
+		"append json \\timing output to this file, instead of writing it to the standard error",
+	},
+	{
+		"timing_format",
+		"set the \\timing output format [text, json]",
+	},
+	{
+		"timing_summary",
+		"display a summary of the statement timings after running a file [on, off]",
+	},
+	{
+		"timing_throughput",
+		"display the rows per second throughput with \\timing [on, off]",
+	},
 	{
 		"title",
 		"set the table title for subsequently printed tables",
@@ -140,6 +274,14 @@ var pvarNames = []varName{
 }
 
 var envVarNames = []varName{
+	{
+		text.CommandUpper() + "_COMMANDS",
+		"alternative location for the user defined commands (\\newcmd) file",
+	},
+	{
+		text.CommandUpper() + "_CONNECTIONS",
+		"alternative location for the saved connection profiles (\\cset) file",
+	},
 	{
 		text.CommandUpper() + "_EDITOR, EDITOR, VISUAL",
 		"editor used by the \\e, \\ef, and \\ev commands",
@@ -160,6 +302,10 @@ var envVarNames = []varName{
 		text.CommandUpper() + "_SHOW_HOST_INFORMATION",
 		"display host information when connecting to a database",
 	},
+	{
+		text.CommandUpper() + "PASS",
+		"alternative location for the user's .usqlpass password file",
+	},
 	{
 		text.CommandUpper() + "RC",
 		"alternative location for the user's .usqlrc file",
@@ -168,6 +314,10 @@ var envVarNames = []varName{
 		text.CommandUpper() + "_SSLMODE, SSLMODE",
 		"when set to 'retry', allows postgres connections to attempt to reconnect when no ?sslmode= was specified on the url",
 	},
+	{
+		"PGPASSFILE",
+		"alternative location for the user's .pgpass password file (postgres only)",
+	},
 	{
 		"SYNTAX_HL",
 		"enable syntax highlighting",
@@ -259,6 +409,8 @@ func init() {
 		"PAGER":                 pagerCmd,
 		"EDITOR":                editorCmd,
 		"ON_ERROR_STOP":         "off",
+		"ENCODING":              "UTF-8",
+		"ECHO":                  "none",
 		// prompts
 		"PROMPT1": "%S%N%m%/%R%# ",
 		// syntax highlighting variables
@@ -270,29 +422,54 @@ func init() {
 		"TERM_GRAPHICS":         "none",
 	}
 	// determine locale
-	locale := "en-US"
-	if s, err := syslocale.GetLocale(); err == nil {
-		locale = s
-	}
+	locale := numericLocale()
 	pvars = Vars{
+		"bind_params":              "off",
 		"border":                   "1",
 		"columns":                  "0",
+		"connect_retries":          "0",
+		"connect_retry_interval":   "1s",
 		"csv_fieldsep":             ",",
+		"csv_header":               "on",
+		"csv_null":                 "",
+		"csv_quote":                `"`,
+		"describe_exact_rows":      "off",
+		"describe_size":            "on",
+		"exit_on_empty":            "off",
+		"exit_on_rows":             "off",
 		"expanded":                 "off",
 		"fieldsep":                 "|",
 		"fieldsep_zero":            "off",
 		"footer":                   "on",
+		"footer_format":            "",
 		"format":                   "aligned",
+		"fwf_align":                "",
+		"fwf_widths":               "",
+		"g_limit":                  "off",
+		"gexec_confirm":            "on",
+		"html_class":               "",
+		"json_indent":              "2",
+		"json_pretty":              "on",
 		"linestyle":                "ascii",
 		"locale":                   locale,
+		"max_col_width":            "0",
+		"max_col_width_exclude":    "",
+		"metadata_cache_ttl":       "0",
 		"null":                     "",
 		"numericlocale":            "off",
 		"pager_min_lines":          "0",
 		"pager":                    pager,
+		"parquet_compression":      "snappy",
+		"parquet_row_group_size":   "10000",
 		"recordsep":                "\n",
 		"recordsep_zero":           "off",
+		"single_transaction":       "off",
 		"tableattr":                "",
 		"time":                     "RFC3339Nano",
+		"timing_file":              "",
+		"timing_format":            "text",
+		"timing_summary":           "off",
+		"timing_throughput":        "on",
 		"title":                    "",
 		"tuples_only":              "off",
 		"unicode_border_linestyle": "single",
@@ -321,6 +498,19 @@ func Set(name, value string) error {
 	if err := ValidIdentifier(name); err != nil {
 		return err
 	}
+	if name == "ENCODING" {
+		var err error
+		if _, value, err = Encoding(value); err != nil {
+			return err
+		}
+	}
+	if name == "ECHO" {
+		switch value {
+		case "none", "queries", "all", "errors":
+		default:
+			return text.ErrInvalidEcho
+		}
+	}
 	if name == "ON_ERROR_STOP" || name == "QUIET" {
 		if value == "" {
 			value = "on"
@@ -376,7 +566,7 @@ func Pwrite(w io.Writer) error {
 		switch k {
 		case "csv_fieldsep", "fieldsep", "recordsep", "null":
 			val = strconv.QuoteToASCII(val)
-		case "tableattr", "title":
+		case "footer_format", "html_class", "tableattr", "title":
 			if val != "" {
 				val = strconv.QuoteToASCII(val)
 			}
@@ -387,9 +577,13 @@ func Pwrite(w io.Writer) error {
 }
 
 var (
-	formatRE    = regexp.MustCompile(`^(unaligned|aligned|wrapped|html|asciidoc|latex|latex-longtable|troff-ms|csv|json|vertical)$`)
-	linestlyeRE = regexp.MustCompile(`^(ascii|old-ascii|unicode)$`)
-	borderRE    = regexp.MustCompile(`^(single|double)$`)
+	formatRE      = regexp.MustCompile(`^(unaligned|aligned|wrapped|html|asciidoc|latex|latex-longtable|troff-ms|csv|json|vertical|fwf|yaml)$`)
+	fwfWidthsRE   = regexp.MustCompile(`^\s*[0-9]*\s*(,\s*[0-9]*\s*)*$`)
+	fwfAlignRE    = regexp.MustCompile(`^\s*[lr]?\s*(,\s*[lr]?\s*)*$`)
+	linestlyeRE   = regexp.MustCompile(`^(ascii|old-ascii|unicode)$`)
+	borderRE      = regexp.MustCompile(`^(single|double)$`)
+	compressionRE = regexp.MustCompile(`^(snappy|zstd|gzip|none)$`)
+	timingRE      = regexp.MustCompile(`^(text|json)$`)
 )
 
 func ParseBool(value, name string) (string, error) {
@@ -437,7 +631,7 @@ func Ptoggle(name, extra string) (string, error) {
 		return "", fmt.Errorf(text.UnknownFormatFieldName, name)
 	}
 	switch name {
-	case "border", "columns", "pager_min_lines":
+	case "border", "columns", "connect_retries", "json_indent", "max_col_width", "pager_min_lines", "parquet_row_group_size":
 	case "pager":
 		switch pvars[name] {
 		case "on", "always":
@@ -456,7 +650,7 @@ func Ptoggle(name, extra string) (string, error) {
 		default:
 			panic(fmt.Sprintf("invalid state for field %s", name))
 		}
-	case "fieldsep_zero", "footer", "numericlocale", "recordsep_zero", "tuples_only":
+	case "bind_params", "csv_header", "describe_exact_rows", "describe_size", "exit_on_empty", "exit_on_rows", "fieldsep_zero", "footer", "g_limit", "gexec_confirm", "json_pretty", "numericlocale", "recordsep_zero", "single_transaction", "timing_summary", "timing_throughput", "tuples_only":
 		switch pvars[name] {
 		case "on":
 			pvars[name] = "off"
@@ -475,8 +669,14 @@ func Ptoggle(name, extra string) (string, error) {
 			pvars[name] = "aligned"
 		}
 	case "linestyle":
-	case "csv_fieldsep", "fieldsep", "null", "recordsep", "time", "locale":
-	case "tableattr", "title":
+	case "connect_retry_interval", "csv_fieldsep", "metadata_cache_ttl", "csv_null", "csv_quote", "fieldsep", "null", "recordsep", "time", "locale", "parquet_compression":
+	case "timing_format":
+		if pvars[name] == "text" {
+			pvars[name] = "json"
+		} else {
+			pvars[name] = "text"
+		}
+	case "footer_format", "fwf_align", "fwf_widths", "html_class", "max_col_width_exclude", "tableattr", "timing_file", "title":
 		pvars[name] = ""
 	case "unicode_border_linestyle", "unicode_column_linestyle", "unicode_header_linestyle":
 	default:
@@ -492,7 +692,7 @@ func Pset(name, value string) (string, error) {
 		return "", fmt.Errorf(text.UnknownFormatFieldName, name)
 	}
 	switch name {
-	case "border", "columns", "pager_min_lines":
+	case "border", "columns", "connect_retries", "json_indent", "max_col_width", "pager_min_lines", "parquet_row_group_size":
 		i, _ := strconv.Atoi(value)
 		pvars[name] = fmt.Sprintf("%d", i)
 	case "pager":
@@ -507,7 +707,7 @@ func Pset(name, value string) (string, error) {
 			return "", text.ErrInvalidFormatExpandedType
 		}
 		pvars[name] = s
-	case "fieldsep_zero", "footer", "numericlocale", "recordsep_zero", "tuples_only":
+	case "bind_params", "csv_header", "describe_exact_rows", "describe_size", "exit_on_empty", "exit_on_rows", "fieldsep_zero", "footer", "g_limit", "gexec_confirm", "json_pretty", "numericlocale", "recordsep_zero", "single_transaction", "timing_summary", "timing_throughput", "tuples_only":
 		s, err := ParseBool(value, name)
 		if err != nil {
 			return "", err
@@ -523,7 +723,42 @@ func Pset(name, value string) (string, error) {
 			return "", text.ErrInvalidFormatLineStyle
 		}
 		pvars[name] = value
-	case "csv_fieldsep", "fieldsep", "null", "recordsep", "tableattr", "time", "title", "locale":
+	case "connect_retry_interval":
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			return "", text.ErrInvalidConnectRetryInterval
+		}
+		pvars[name] = value
+	case "metadata_cache_ttl":
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			return "", text.ErrInvalidMetadataCacheTTL
+		}
+		pvars[name] = value
+	case "csv_quote":
+		if utf8.RuneCountInString(value) > 1 {
+			return "", text.ErrInvalidFormatCSVQuote
+		}
+		pvars[name] = value
+	case "parquet_compression":
+		if !compressionRE.MatchString(value) {
+			return "", text.ErrInvalidFormatParquetCompression
+		}
+		pvars[name] = value
+	case "timing_format":
+		if !timingRE.MatchString(value) {
+			return "", text.ErrInvalidFormatTimingFormat
+		}
+		pvars[name] = value
+	case "fwf_widths":
+		if !fwfWidthsRE.MatchString(value) {
+			return "", text.ErrInvalidFormatFWFWidths
+		}
+		pvars[name] = value
+	case "fwf_align":
+		if !fwfAlignRE.MatchString(value) {
+			return "", text.ErrInvalidFormatFWFAlign
+		}
+		pvars[name] = value
+	case "csv_fieldsep", "csv_null", "fieldsep", "footer_format", "html_class", "max_col_width_exclude", "null", "recordsep", "tableattr", "time", "timing_file", "title", "locale":
 		pvars[name] = value
 	case "unicode_border_linestyle", "unicode_column_linestyle", "unicode_header_linestyle":
 		if !borderRE.MatchString(value) {
@@ -564,6 +799,33 @@ func GoTime() string {
 	return tfmt
 }
 
+// numericLocale returns the locale used for numeric output, from the LC_ALL
+// or LC_NUMERIC environment variables, falling back to the system locale.
+func numericLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_NUMERIC"} {
+		if s := posixLocale(os.Getenv(name)); s != "" {
+			return s
+		}
+	}
+	if s, err := syslocale.GetLocale(); err == nil {
+		return s
+	}
+	return "en-US"
+}
+
+// posixLocale converts a POSIX locale name (such as de_DE.UTF-8) to a language
+// tag (such as de-DE), returning an empty string for the C and POSIX locales.
+func posixLocale(s string) string {
+	if i := strings.IndexAny(s, ".@"); i != -1 {
+		s = s[:i]
+	}
+	switch s {
+	case "", "C", "POSIX":
+		return ""
+	}
+	return strings.ReplaceAll(s, "_", "-")
+}
+
 // Listing writes the formatted variables listing to w, separated into different
 // sections for all known variables.
 func Listing(w io.Writer) {
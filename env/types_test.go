@@ -0,0 +1,22 @@
+package env
+
+import "testing"
+
+func TestPosixLocale(t *testing.T) {
+	tests := []struct {
+		s, exp string
+	}{
+		{"de_DE.UTF-8", "de-DE"},
+		{"fr_CH@euro", "fr-CH"},
+		{"en_US", "en-US"},
+		{"C", ""},
+		{"POSIX", ""},
+		{"C.UTF-8", ""},
+		{"", ""},
+	}
+	for i, test := range tests {
+		if s := posixLocale(test.s); s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/env"
+	"github.com/rmasci/usql/metacmd"
+	"github.com/rmasci/usql/text"
+)
+
+// diffResult is a query result compared by \diff.
+type diffResult struct {
+	name string
+	cols []string
+	rows [][]sql.NullString
+}
+
+// execSnapshot executes a query, saving the results as the snapshot compared
+// by \diff.
+func (h *Handler) execSnapshot(ctx context.Context, w io.Writer, opt metacmd.Option, prefix, sqlstr string, qtyp bool) error {
+	if !qtyp {
+		fmt.Fprintln(w, text.NoResultColumns)
+		return nil
+	}
+	res, err := queryDiffResult(ctx, h.DB(), "snapshot", sqlstr, opt.Args...)
+	if err != nil {
+		return err
+	}
+	h.snapshot = res
+	fmt.Fprintf(w, text.SnapshotSaved+"\n", len(res.rows))
+	return nil
+}
+
+// execDiff executes a query on two connections, or on the current connection
+// to compare with the snapshot, writing the rows removed from the first
+// result, and added to the second result, as a unified diff. Rows are matched
+// by the key columns, where matched rows having different values are
+// changed. When no key columns are specified, rows are matched by all
+// columns.
+func (h *Handler) execDiff(ctx context.Context, w io.Writer, opt metacmd.Option, prefix, sqlstr string, qtyp bool) error {
+	if !qtyp {
+		fmt.Fprintln(w, text.NoResultColumns)
+		return nil
+	}
+	var a, b *diffResult
+	switch {
+	case len(opt.DiffConns) == 0 && h.snapshot == nil:
+		return text.ErrNoSnapshot
+	case len(opt.DiffConns) == 0:
+		a = h.snapshot
+		var err error
+		if b, err = queryDiffResult(ctx, h.DB(), "current", sqlstr, opt.Args...); err != nil {
+			return err
+		}
+	default:
+		res := make([]*diffResult, len(opt.DiffConns))
+		for i, name := range opt.DiffConns {
+			var db drivers.DB = h.DB()
+			label := "current"
+			if name != "" {
+				var err error
+				if db, _, err = h.NamedConn(name); err != nil {
+					return err
+				}
+				label = "@" + name
+			}
+			var err error
+			if res[i], err = queryDiffResult(ctx, db, label, sqlstr, opt.Args...); err != nil {
+				return err
+			}
+		}
+		a, b = res[0], res[1]
+	}
+	if !slices.EqualFunc(a.cols, b.cols, strings.EqualFold) {
+		return fmt.Errorf(text.DiffColumns, strings.Join(a.cols, ", "), strings.Join(b.cols, ", "))
+	}
+	keys := make([]int, len(opt.DiffKeys))
+	for i, name := range opt.DiffKeys {
+		if keys[i] = diffColumn(a.cols, name); keys[i] == -1 {
+			return fmt.Errorf(text.DiffKeyNotFound, name)
+		}
+	}
+	if len(keys) == 0 {
+		for i := range a.cols {
+			keys = append(keys, i)
+		}
+	}
+	return writeDiff(w, a, b, keys, env.Pall()["null"])
+}
+
+// queryDiffResult executes the query on the database, reading all rows of the
+// result.
+func queryDiffResult(ctx context.Context, db drivers.DB, name, sqlstr string, args ...interface{}) (*diffResult, error) {
+	rows, err := db.QueryContext(ctx, sqlstr, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	res := &diffResult{name: name, cols: cols}
+	for rows.Next() {
+		row := make([]sql.NullString, len(cols))
+		v := make([]interface{}, len(cols))
+		for i := range row {
+			v[i] = &row[i]
+		}
+		if err := rows.Scan(v...); err != nil {
+			return nil, err
+		}
+		res.rows = append(res.rows, row)
+	}
+	return res, rows.Err()
+}
+
+// writeDiff writes the differences of the results, sorted by the key
+// columns.
+func writeDiff(w io.Writer, a, b *diffResult, keys []int, null string) error {
+	sortDiffRows(a.rows, keys)
+	sortDiffRows(b.rows, keys)
+	format := func(row []sql.NullString) string {
+		v := make([]string, len(row))
+		for i, s := range row {
+			if v[i] = null; s.Valid {
+				v[i] = s.String
+			}
+		}
+		return strings.Join(v, " | ")
+	}
+	var lines []string
+	var added, removed, changed, unchanged int
+	i, j := 0, 0
+	for i < len(a.rows) || j < len(b.rows) {
+		c := 0
+		switch {
+		case i == len(a.rows):
+			c = 1
+		case j == len(b.rows):
+			c = -1
+		default:
+			c = compareDiffRows(a.rows[i], b.rows[j], keys)
+		}
+		switch {
+		case c < 0:
+			lines = append(lines, "-"+format(a.rows[i]))
+			removed, i = removed+1, i+1
+		case c > 0:
+			lines = append(lines, "+"+format(b.rows[j]))
+			added, j = added+1, j+1
+		case compareDiffRows(a.rows[i], b.rows[j], nil) != 0:
+			lines = append(lines, "-"+format(a.rows[i]), "+"+format(b.rows[j]))
+			changed, i, j = changed+1, i+1, j+1
+		default:
+			unchanged, i, j = unchanged+1, i+1, j+1
+		}
+	}
+	if len(lines) == 0 {
+		_, err := fmt.Fprintf(w, text.DiffNone+"\n\n", unchanged)
+		return err
+	}
+	fmt.Fprintln(w, "--- "+a.name)
+	fmt.Fprintln(w, "+++ "+b.name)
+	fmt.Fprintln(w, " "+strings.Join(a.cols, " | "))
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	_, err := fmt.Fprintf(w, text.DiffSummary+"\n\n", added, removed, changed, unchanged)
+	return err
+}
+
+// sortDiffRows sorts the rows by the key columns, and then by all columns.
+func sortDiffRows(rows [][]sql.NullString, keys []int) {
+	slices.SortStableFunc(rows, func(a, b []sql.NullString) int {
+		return cmp.Or(compareDiffRows(a, b, keys), compareDiffRows(a, b, nil))
+	})
+}
+
+// compareDiffRows compares the values of the key columns of the rows, or of
+// all columns when keys is nil.
+func compareDiffRows(a, b []sql.NullString, keys []int) int {
+	if keys == nil {
+		for i := range a {
+			if c := compareDiffValues(a[i], b[i]); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+	for _, i := range keys {
+		if c := compareDiffValues(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareDiffValues compares values as numbers when both are numeric, and
+// otherwise as strings, where NULL is less than any other value.
+func compareDiffValues(a, b sql.NullString) int {
+	switch {
+	case !a.Valid || !b.Valid:
+		return cmpBool(a.Valid, b.Valid)
+	case a.String == b.String:
+		return 0
+	}
+	x, xerr := strconv.ParseFloat(strings.TrimSpace(a.String), 64)
+	y, yerr := strconv.ParseFloat(strings.TrimSpace(b.String), 64)
+	if xerr == nil && yerr == nil {
+		if c := cmp.Compare(x, y); c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(a.String, b.String)
+}
+
+// cmpBool compares booleans, where false is less than true.
+func cmpBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	}
+	return -1
+}
+
+// diffColumn returns the index of the named column, matching an exact name
+// before a case-insensitive one, or -1 when not found.
+func diffColumn(cols []string, name string) int {
+	for i, col := range cols {
+		if col == name {
+			return i
+		}
+	}
+	for i, col := range cols {
+		if strings.EqualFold(col, name) {
+			return i
+		}
+	}
+	return -1
+}
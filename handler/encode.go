@@ -0,0 +1,1528 @@
+package handler
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"maps"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rmasci/usql/jsonenc"
+	"github.com/rmasci/usql/text"
+	"github.com/xo/tblfmt"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+	"gopkg.in/yaml.v3"
+)
+
+// encodeAll encodes all result sets to the writer, using the handler's own
+// encoders for formats not supported by tblfmt.
+func (h *Handler) encodeAll(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string, extra ...tblfmt.Option) error {
+	switch params["format"] {
+	case "ndjson":
+		return encodeNDJSON(w, resultSet, params)
+	case "json":
+		return encodeJSON(w, resultSet, params)
+	case "fwf":
+		return encodeFWF(w, resultSet, params)
+	case "html":
+		return encodeHTML(w, resultSet, params)
+	case "yaml":
+		return encodeYAML(w, resultSet, params)
+	case "csv":
+		opts, err := csvOptions(params)
+		if err != nil {
+			return err
+		}
+		extra = append(extra, opts...)
+	case "aligned", "wrapped":
+		if border, ok := tableattrBorder(params["tableattr"]); ok {
+			// the border attribute of html tables selects the border style
+			params = maps.Clone(params)
+			params["border"] = border
+		}
+		if n, _ := strconv.Atoi(params["max_col_width"]); n > 0 && params["expanded"] != "on" {
+			resultSet = newTruncatedView(resultSet, n, params["max_col_width_exclude"])
+		}
+		if params["numericlocale"] == "on" {
+			extra = append(extra, tblfmt.WithFormatter(newNumericFormatter(resultSet, params)))
+		}
+		if tmpl := params["footer_format"]; tmpl != "" && params["footer"] != "off" && params["tuples_only"] != "on" {
+			extra = append(extra, tblfmt.WithSummary(footerSummary(tmpl)))
+		}
+	}
+	if params["expanded"] == "on" && params["json_pretty"] == "on" && params["format"] != "csv" {
+		n, _ := strconv.Atoi(params["json_indent"])
+		resultSet = newJSONView(resultSet, n)
+	}
+	return tblfmt.EncodeAll(w, resultSet, params, extra...)
+}
+
+// footerSummary returns the table summary for the footer_format template,
+// replacing {rows} with the row count.
+func footerSummary(tmpl string) map[int]func(io.Writer, int) (int, error) {
+	return map[int]func(io.Writer, int) (int, error){
+		-1: func(w io.Writer, count int) (int, error) {
+			return fmt.Fprint(w, strings.ReplaceAll(tmpl, "{rows}", strconv.Itoa(count)))
+		},
+	}
+}
+
+// csvOptions returns the tblfmt options for the CSV dialect params
+// (csv_fieldsep, csv_quote, csv_header, and csv_null).
+func csvOptions(params map[string]string) ([]tblfmt.Option, error) {
+	sep, quote := ',', '"'
+	if s, ok := params["csv_fieldsep"]; ok {
+		if utf8.RuneCountInString(s) != 1 {
+			return nil, tblfmt.ErrInvalidFieldSeparator
+		}
+		sep, _ = utf8.DecodeRuneInString(s)
+	}
+	if s, ok := params["csv_quote"]; ok {
+		quote, _ = utf8.DecodeRuneInString(s)
+		if s == "" {
+			quote = 0
+		}
+	}
+	opts := []tblfmt.Option{
+		tblfmt.WithQuote(quote),
+		tblfmt.WithFormatter(tblfmt.NewEscapeFormatter(tblfmt.WithIsRaw(true, sep, quote))),
+		tblfmt.WithSkipHeader(params["csv_header"] == "off" || params["tuples_only"] == "on"),
+	}
+	if s := params["csv_null"]; s != "" {
+		opts = append(opts, tblfmt.WithEmpty(s))
+	}
+	return opts, nil
+}
+
+// encodeNDJSON encodes all result sets to the writer as newline-delimited
+// JSON, writing one object per row.
+func encodeNDJSON(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string) error {
+	for {
+		if err := encodeJSONResultSet(w, resultSet, params, false); err != nil {
+			return err
+		}
+		if !resultSet.NextResultSet() {
+			return nil
+		}
+	}
+}
+
+// encodeJSON encodes all result sets to the writer as JSON, writing each
+// result set as a single array of row objects on its own line.
+func encodeJSON(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string) error {
+	for {
+		if err := encodeJSONResultSet(w, resultSet, params, true); err != nil {
+			return err
+		}
+		if !resultSet.NextResultSet() {
+			return nil
+		}
+	}
+}
+
+// encodeJSONResultSet encodes a single result set to the writer as JSON,
+// either as newline-delimited objects or as an array of objects.
+//
+// When encoding an array, the array is always closed, so that the output
+// remains valid JSON when an error is encountered reading the rows.
+func encodeJSONResultSet(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string, array bool) (err error) {
+	cols, err := resultSet.Columns()
+	if err != nil {
+		return err
+	}
+	clen := len(cols)
+	if clen == 0 {
+		return tblfmt.ErrResultSetHasNoColumns
+	}
+	// build keys
+	keys := make([][]byte, clen)
+	for i, col := range cols {
+		if params["lower_column_names"] == "true" {
+			col = strings.ToLower(col)
+		}
+		if keys[i], err = jsonenc.Marshal(col); err != nil {
+			return err
+		}
+	}
+	typs := columnTypeNames(resultSet, clen)
+	tfmt := params["time"]
+	if tfmt == "" {
+		tfmt = time.RFC3339Nano
+	}
+	f, _ := w.(interface{ Flush() error })
+	vals := make([]interface{}, clen)
+	for i := range vals {
+		vals[i] = new(interface{})
+	}
+	var buf bytes.Buffer
+	if array {
+		if _, err := w.Write([]byte{'['}); err != nil {
+			return err
+		}
+		defer func() {
+			if _, werr := w.Write([]byte("]\n")); err == nil {
+				err = werr
+			}
+			if f != nil && err == nil {
+				err = f.Flush()
+			}
+		}()
+	}
+	for n := 0; resultSet.Next(); n++ {
+		if err := resultSet.Scan(vals...); err != nil {
+			return err
+		}
+		buf.Reset()
+		if array && n != 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('{')
+		for i, v := range vals {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(keys[i])
+			buf.WriteByte(':')
+			b, err := jsonenc.Marshal(jsonenc.Value(*v.(*interface{}), typs[i], tfmt))
+			if err != nil {
+				return err
+			}
+			buf.Write(b)
+		}
+		buf.WriteByte('}')
+		if !array {
+			buf.WriteByte('\n')
+		}
+		// write each row in a single write, so consumers see complete lines
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		if f != nil {
+			if err := f.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return resultSet.Err()
+}
+
+// encodeYAML encodes all result sets to the writer as YAML, writing each
+// result set as a sequence of row mappings in its own document.
+func encodeYAML(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string) error {
+	for i := 0; ; i++ {
+		if i != 0 {
+			if _, err := w.Write([]byte("---\n")); err != nil {
+				return err
+			}
+		}
+		if err := encodeYAMLResultSet(w, resultSet, params); err != nil {
+			return err
+		}
+		if !resultSet.NextResultSet() {
+			return nil
+		}
+	}
+}
+
+// encodeYAMLResultSet encodes a single result set to the writer as a YAML
+// sequence of row mappings, or as [] when the result set has no rows.
+//
+// Values are typed as for JSON, with numbers, booleans, and NULLs written
+// unquoted, strings quoted only when they would otherwise be read as another
+// type, and multiline strings written as literal block scalars.
+func encodeYAMLResultSet(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string) error {
+	cols, err := resultSet.Columns()
+	if err != nil {
+		return err
+	}
+	clen := len(cols)
+	if clen == 0 {
+		return tblfmt.ErrResultSetHasNoColumns
+	}
+	keys := make([]*yaml.Node, clen)
+	for i, col := range cols {
+		if params["lower_column_names"] == "true" {
+			col = strings.ToLower(col)
+		}
+		keys[i] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: col}
+	}
+	typs := columnTypeNames(resultSet, clen)
+	tfmt := params["time"]
+	if tfmt == "" {
+		tfmt = time.RFC3339Nano
+	}
+	vals := make([]interface{}, clen)
+	for i := range vals {
+		vals[i] = new(interface{})
+	}
+	var buf bytes.Buffer
+	n := 0
+	for ; resultSet.Next(); n++ {
+		if err := resultSet.Scan(vals...); err != nil {
+			return err
+		}
+		row := &yaml.Node{Kind: yaml.MappingNode}
+		for i, v := range vals {
+			row.Content = append(row.Content, keys[i], yamlValue(jsonenc.Value(*v.(*interface{}), typs[i], tfmt)))
+		}
+		// write each row as a single item sequence, so that rows are written
+		// as they are read
+		buf.Reset()
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{row}}); err != nil {
+			return err
+		}
+		if err := enc.Close(); err != nil {
+			return err
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err := resultSet.Err(); err != nil {
+		return err
+	}
+	if n == 0 {
+		_, err = w.Write([]byte("[]\n"))
+	}
+	return err
+}
+
+// yamlBoolRE matches the YAML 1.1 boolean values.
+var yamlBoolRE = regexp.MustCompile(`^(?i:y|yes|n|no|true|false|on|off)$`)
+
+// yamlValue returns the YAML node of a JSON typed value (see jsonenc.Value).
+func yamlValue(v interface{}) *yaml.Node {
+	switch x := v.(type) {
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	case string:
+		n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: x}
+		switch {
+		case strings.Contains(x, "\n"):
+			n.Style = yaml.LiteralStyle
+		case yamlBoolRE.MatchString(x):
+			// quote YAML 1.1 booleans, for older parsers
+			n.Style = yaml.DoubleQuotedStyle
+		}
+		return n
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(x)}
+	case json.Number:
+		return yamlNumber(string(x))
+	case float64:
+		s := strconv.FormatFloat(x, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		return yamlNumber(s)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprintf("%d", x)}
+	}
+	n := new(yaml.Node)
+	if err := n.Encode(v); err != nil {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fmt.Sprintf("%v", v)}
+	}
+	return n
+}
+
+// yamlNumber returns the YAML node of a number.
+func yamlNumber(s string) *yaml.Node {
+	tag := "!!float"
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		tag = "!!int"
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: s}
+}
+
+// columnTypeNames returns the upper case database type names of the result
+// set's columns, when available.
+func columnTypeNames(resultSet tblfmt.ResultSet, clen int) []string {
+	typs := make([]string, clen)
+	if r, ok := resultSet.(interface {
+		ColumnTypes() ([]*sql.ColumnType, error)
+	}); ok {
+		if ct, err := r.ColumnTypes(); err == nil && len(ct) == clen {
+			for i, t := range ct {
+				typs[i] = strings.ToUpper(t.DatabaseTypeName())
+			}
+		}
+	}
+	return typs
+}
+
+// encodeFWF encodes all result sets to the writer as fixed-width fields,
+// writing one record per row.
+func encodeFWF(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string) error {
+	for {
+		if err := encodeFWFResultSet(w, resultSet, params); err != nil {
+			return err
+		}
+		if !resultSet.NextResultSet() {
+			return nil
+		}
+	}
+}
+
+// fwfCell is a fixed-width field value.
+type fwfCell struct {
+	s     string
+	right bool
+}
+
+// encodeFWFResultSet encodes a single result set to the writer as
+// fixed-width fields, without separators between fields.
+//
+// Column widths and alignments are taken from the fwf_widths and fwf_align
+// params. Columns without a width are sized to their widest value, reading
+// all rows before writing any, and columns without an alignment are right
+// aligned when numeric and otherwise left aligned. Values are truncated to
+// the column width, and NULLs are written as blanks.
+func encodeFWFResultSet(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string) error {
+	cols, err := resultSet.Columns()
+	if err != nil {
+		return err
+	}
+	clen := len(cols)
+	if clen == 0 {
+		return tblfmt.ErrResultSetHasNoColumns
+	}
+	typs := columnTypeNames(resultSet, clen)
+	widths, align := make([]int, clen), make([]string, clen)
+	for i, s := range strings.Split(params["fwf_widths"], ",") {
+		if i < clen {
+			widths[i], _ = strconv.Atoi(strings.TrimSpace(s))
+		}
+	}
+	for i, s := range strings.Split(params["fwf_align"], ",") {
+		if i < clen {
+			align[i] = strings.TrimSpace(s)
+		}
+	}
+	right := func(i int, numeric bool) bool {
+		if align[i] != "" {
+			return align[i] == "r"
+		}
+		return numeric || jsonenc.IsNumericType(typs[i])
+	}
+	var header []fwfCell
+	if params["tuples_only"] != "on" {
+		header = make([]fwfCell, clen)
+		for i, col := range cols {
+			header[i] = fwfCell{fwfString(col), right(i, false)}
+		}
+	}
+	var rows [][]fwfCell
+	write := func() error {
+		if header != nil {
+			// align the header with the first row's values
+			for i := range header {
+				if len(rows) != 0 && rows[0][i].s != "" {
+					header[i].right = rows[0][i].right
+				}
+			}
+			rows, header = append([][]fwfCell{header}, rows...), nil
+		}
+		var buf bytes.Buffer
+		for _, row := range rows {
+			buf.Reset()
+			for i, c := range row {
+				s := runewidth.Truncate(c.s, widths[i], "")
+				pad := strings.Repeat(" ", widths[i]-runewidth.StringWidth(s))
+				if c.right {
+					s = pad + s
+				} else {
+					s += pad
+				}
+				buf.WriteString(s)
+			}
+			buf.WriteByte('\n')
+			if _, err := w.Write(buf.Bytes()); err != nil {
+				return err
+			}
+		}
+		rows = rows[:0]
+		return nil
+	}
+	// buffer all rows when a width is determined from the values
+	buffer := false
+	for _, n := range widths {
+		buffer = buffer || n == 0
+	}
+	tfmt := params["time"]
+	vals := make([]interface{}, clen)
+	for i := range vals {
+		vals[i] = new(interface{})
+	}
+	for resultSet.Next() {
+		if err := resultSet.Scan(vals...); err != nil {
+			return err
+		}
+		row := make([]fwfCell, clen)
+		for i, v := range vals {
+			s, numeric := fwfValue(*v.(*interface{}), tfmt)
+			row[i] = fwfCell{s, right(i, numeric)}
+		}
+		rows = append(rows, row)
+		if !buffer {
+			if err := write(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := resultSet.Err(); err != nil {
+		return err
+	}
+	if buffer {
+		auto := make([]bool, clen)
+		for i, n := range widths {
+			auto[i] = n == 0
+			if auto[i] && header != nil {
+				widths[i] = runewidth.StringWidth(header[i].s)
+			}
+		}
+		for _, row := range rows {
+			for i, c := range row {
+				if auto[i] {
+					widths[i] = max(widths[i], runewidth.StringWidth(c.s))
+				}
+			}
+		}
+	}
+	return write()
+}
+
+// fwfValue returns the fixed-width field string of a scanned value, and
+// whether the value is numeric.
+func fwfValue(v interface{}, tfmt string) (string, bool) {
+	switch x := v.(type) {
+	case nil:
+		return "", false
+	case []byte:
+		return fwfString(bytesString(x)), false
+	case string:
+		return fwfString(x), false
+	case float32:
+		return strconv.FormatFloat(float64(x), 'f', -1, 32), true
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, json.Number:
+		return fmt.Sprintf("%v", x), true
+	case time.Time:
+		return x.Format(tfmt), false
+	case fmt.Stringer:
+		return fwfString(x.String()), false
+	}
+	return fwfString(fmt.Sprintf("%v", v)), false
+}
+
+// bytesString returns the string of a binary value, with invalid UTF-8 and
+// control characters escaped as in the table format (such as \x00).
+func bytesString(b []byte) string {
+	return tblfmt.FormatBytes(b, nil, 0, false, false, 0, 0).String()
+}
+
+// fwfString replaces line breaks and tabs in s with spaces, so that a value
+// cannot break a fixed-width record.
+func fwfString(s string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ").Replace(s)
+}
+
+// encodeHTML encodes all result sets to the writer as HTML tables.
+func encodeHTML(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string) error {
+	for {
+		if err := encodeHTMLResultSet(w, resultSet, params); err != nil {
+			return err
+		}
+		if !resultSet.NextResultSet() {
+			return nil
+		}
+	}
+}
+
+// encodeHTMLResultSet encodes a single result set to the writer as an HTML
+// table, captioned with the title.
+//
+// All column names and values are escaped, with line breaks in values written
+// as <br />, and NULLs written as the null param. The table tag has the
+// tableattr params as attributes, and the html_class param as its class. In
+// expanded mode, each row is written as a record of column name and value
+// rows.
+func encodeHTMLResultSet(w io.Writer, resultSet tblfmt.ResultSet, params map[string]string) error {
+	cols, err := resultSet.Columns()
+	if err != nil {
+		return err
+	}
+	clen := len(cols)
+	if clen == 0 {
+		return tblfmt.ErrResultSetHasNoColumns
+	}
+	typs := columnTypeNames(resultSet, clen)
+	var buf bytes.Buffer
+	buf.WriteString("<table")
+	if s := strings.TrimSpace(params["tableattr"]); s != "" {
+		buf.WriteString(" " + s)
+	}
+	if s := params["html_class"]; s != "" {
+		buf.WriteString(` class="` + html.EscapeString(s) + `"`)
+	}
+	buf.WriteString(">\n")
+	if s := params["title"]; s != "" {
+		buf.WriteString("  <caption>" + htmlString(s) + "</caption>\n")
+	}
+	expanded, tuplesOnly := params["expanded"] == "on", params["tuples_only"] == "on"
+	if !expanded && !tuplesOnly {
+		buf.WriteString("  <thead>\n    <tr>\n")
+		for _, col := range cols {
+			buf.WriteString(`      <th align="left">` + htmlString(col) + "</th>\n")
+		}
+		buf.WriteString("    </tr>\n  </thead>\n")
+	}
+	buf.WriteString("  <tbody>\n")
+	tfmt, null := params["time"], htmlString(params["null"])
+	var nl *numericLocale
+	if params["numericlocale"] == "on" {
+		nl = newNumericLocale(params["locale"])
+	}
+	vals := make([]interface{}, clen)
+	for i := range vals {
+		vals[i] = new(interface{})
+	}
+	for n := 1; resultSet.Next(); n++ {
+		if err := resultSet.Scan(vals...); err != nil {
+			return err
+		}
+		switch {
+		case expanded && !tuplesOnly:
+			fmt.Fprintf(&buf, "    <tr>\n      <td colspan=\"2\" align=\"center\">Record %d</td>\n    </tr>\n", n)
+		case !expanded:
+			buf.WriteString("    <tr>\n")
+		}
+		for i, v := range vals {
+			s, align := null, "left"
+			if x := *v.(*interface{}); x != nil {
+				var numeric bool
+				s, numeric = htmlValue(x, tfmt)
+				if numeric || jsonenc.IsNumericType(typs[i]) {
+					align = "right"
+					if nl != nil {
+						if z, ok := nl.format(x, true); ok {
+							s = htmlString(z)
+						}
+					}
+				}
+			}
+			if expanded {
+				buf.WriteString("    <tr>\n" + `      <th align="left">` + htmlString(cols[i]) + "</th>\n")
+			}
+			buf.WriteString(`      <td align="` + align + `">` + s + "</td>\n")
+			if expanded {
+				buf.WriteString("    </tr>\n")
+			}
+		}
+		if !expanded {
+			buf.WriteString("    </tr>\n")
+		}
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			return err
+		}
+		buf.Reset()
+	}
+	if err := resultSet.Err(); err != nil {
+		return err
+	}
+	buf.WriteString("  </tbody>\n</table>\n")
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// htmlValue returns the escaped HTML string of a scanned value, and whether
+// the value is numeric.
+func htmlValue(v interface{}, tfmt string) (string, bool) {
+	switch x := v.(type) {
+	case []byte:
+		return htmlString(bytesString(x)), false
+	case string:
+		return htmlString(x), false
+	}
+	s, numeric := fwfValue(v, tfmt)
+	return htmlString(s), numeric
+}
+
+// tableattrBorder returns the border style (0, 1, or 2) of the border
+// attribute in the tableattr param, where a border attribute without a value
+// is border style 1.
+func tableattrBorder(s string) (string, bool) {
+	for _, attr := range strings.Fields(s) {
+		name, val, ok := strings.Cut(attr, "=")
+		switch {
+		case !strings.EqualFold(name, "border"):
+			continue
+		case !ok:
+			return "1", true
+		}
+		switch val = strings.Trim(val, `"'`); val {
+		case "0", "1", "2":
+			return val, true
+		}
+		return "", false
+	}
+	return "", false
+}
+
+// htmlString escapes s for use as HTML text, writing line breaks as <br />.
+func htmlString(s string) string {
+	s = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(html.EscapeString(s))
+	return strings.ReplaceAll(s, "\n", "<br />\n")
+}
+
+// export encodes the result set to each of the files (keyed by format),
+// streaming each row to the encoders of all files as it is read, so that the
+// query is only executed once, without reading all rows into memory.
+func (h *Handler) export(resultSet tblfmt.ResultSet, params map[string]string, files map[string]string) error {
+	formats := make([]string, 0, len(files))
+	for format := range files {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	cols, err := resultSet.Columns()
+	if err != nil {
+		return err
+	}
+	typs, _ := columnTypes(resultSet)
+	tees := make([]*teeResultSet, len(formats))
+	errs := make([]error, len(formats))
+	var wg sync.WaitGroup
+	for i, format := range formats {
+		tees[i] = newTeeResultSet(cols, typs)
+		wg.Add(1)
+		go func(i int, format string) {
+			defer wg.Done()
+			defer close(tees[i].done)
+			errs[i] = h.exportFile(tees[i], params, format, files[format])
+		}(i, format)
+	}
+	err = teeRows(resultSet, tees)
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportFile encodes the result set to the named file.
+func (h *Handler) exportFile(resultSet tblfmt.ResultSet, params map[string]string, format, name string) error {
+	f, err := os.OpenFile(name, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	p := make(map[string]string, len(params)+1)
+	for k, v := range params {
+		p[k] = v
+	}
+	p["format"] = format
+	if err := h.encodeAll(f, resultSet, p); err != nil {
+		return err
+	}
+	if format == "aligned" {
+		fmt.Fprintln(f)
+	}
+	return f.Close()
+}
+
+// teeRows reads the rows of all result sets, sending each row to all of the
+// tee result sets. Tee result sets whose encoder has finished are skipped.
+func teeRows(resultSet tblfmt.ResultSet, tees []*teeResultSet) error {
+	defer func() {
+		for _, t := range tees {
+			close(t.rows)
+		}
+	}()
+	for {
+		cols, err := resultSet.Columns()
+		if err != nil {
+			return err
+		}
+		for resultSet.Next() {
+			row, vals := make([]interface{}, len(cols)), make([]interface{}, len(cols))
+			for i := range vals {
+				vals[i] = &row[i]
+			}
+			if err := resultSet.Scan(vals...); err != nil {
+				return err
+			}
+			for _, t := range tees {
+				t.send(teeRow{vals: row})
+			}
+		}
+		if err := resultSet.Err(); err != nil {
+			return err
+		}
+		if !resultSet.NextResultSet() {
+			return nil
+		}
+		next := teeRow{set: true}
+		if next.cols, err = resultSet.Columns(); err != nil {
+			return err
+		}
+		next.typs, _ = columnTypes(resultSet)
+		for _, t := range tees {
+			t.send(next)
+		}
+	}
+}
+
+// teeRow is a row sent to a tee result set, or the start of the next result
+// set.
+type teeRow struct {
+	vals []interface{}
+	set  bool
+	cols []string
+	typs []*sql.ColumnType
+}
+
+// teeResultSet is a result set receiving its rows from teeRows, allowing the
+// rows of a result set to be encoded by more than one encoder at a time.
+type teeResultSet struct {
+	rows chan teeRow
+	done chan struct{}
+	cols []string
+	typs []*sql.ColumnType
+	row  []interface{}
+	next *teeRow
+	eof  bool
+}
+
+// newTeeResultSet creates a tee result set for the columns of the first
+// result set.
+func newTeeResultSet(cols []string, typs []*sql.ColumnType) *teeResultSet {
+	return &teeResultSet{
+		rows: make(chan teeRow, 64),
+		done: make(chan struct{}),
+		cols: cols,
+		typs: typs,
+	}
+}
+
+// send sends the row to the result set, unless its encoder has finished.
+func (r *teeResultSet) send(row teeRow) {
+	select {
+	case r.rows <- row:
+	case <-r.done:
+	}
+}
+
+// Next satisfies the tblfmt.ResultSet interface.
+func (r *teeResultSet) Next() bool {
+	if r.eof || r.next != nil {
+		return false
+	}
+	row, ok := <-r.rows
+	switch {
+	case !ok:
+		r.eof = true
+		return false
+	case row.set:
+		r.next = &row
+		return false
+	}
+	r.row = row.vals
+	return true
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (r *teeResultSet) Scan(dest ...interface{}) error {
+	if len(dest) != len(r.row) {
+		return fmt.Errorf("expected %d destination arguments in Scan, not %d", len(r.row), len(dest))
+	}
+	for i, d := range dest {
+		v, ok := d.(*interface{})
+		if !ok {
+			return fmt.Errorf("unsupported Scan destination type %T", d)
+		}
+		*v = r.row[i]
+	}
+	return nil
+}
+
+// Columns satisfies the tblfmt.ResultSet interface.
+func (r *teeResultSet) Columns() ([]string, error) {
+	return r.cols, nil
+}
+
+// ColumnTypes returns the column types of the current result set, when
+// available.
+func (r *teeResultSet) ColumnTypes() ([]*sql.ColumnType, error) {
+	return r.typs, nil
+}
+
+// Close satisfies the tblfmt.ResultSet interface.
+func (r *teeResultSet) Close() error {
+	return nil
+}
+
+// Err satisfies the tblfmt.ResultSet interface.
+func (r *teeResultSet) Err() error {
+	return nil
+}
+
+// NextResultSet satisfies the tblfmt.ResultSet interface, skipping any rows
+// of the current result set not read.
+func (r *teeResultSet) NextResultSet() bool {
+	for r.next == nil && r.Next() {
+	}
+	if r.next == nil {
+		return false
+	}
+	r.cols, r.typs, r.row, r.next = r.next.cols, r.next.typs, nil, nil
+	return true
+}
+
+// bufferedResultSet is a result set whose rows have been read into memory,
+// allowing the rows to be encoded more than once.
+type bufferedResultSet struct {
+	sets     []bufferedSet
+	set, row int
+}
+
+// bufferedSet is a single buffered result set.
+type bufferedSet struct {
+	cols []string
+	typs []*sql.ColumnType
+	rows [][]interface{}
+}
+
+// newBufferedResultSet reads all rows of all result sets into memory.
+func newBufferedResultSet(resultSet tblfmt.ResultSet) (*bufferedResultSet, error) {
+	buf := new(bufferedResultSet)
+	for {
+		cols, err := resultSet.Columns()
+		if err != nil {
+			return nil, err
+		}
+		set := bufferedSet{cols: cols}
+		if r, ok := resultSet.(interface {
+			ColumnTypes() ([]*sql.ColumnType, error)
+		}); ok {
+			set.typs, _ = r.ColumnTypes()
+		}
+		for resultSet.Next() {
+			row, vals := make([]interface{}, len(cols)), make([]interface{}, len(cols))
+			for i := range vals {
+				vals[i] = &row[i]
+			}
+			if err := resultSet.Scan(vals...); err != nil {
+				return nil, err
+			}
+			set.rows = append(set.rows, row)
+		}
+		if err := resultSet.Err(); err != nil {
+			return nil, err
+		}
+		buf.sets = append(buf.sets, set)
+		if !resultSet.NextResultSet() {
+			break
+		}
+	}
+	buf.reset()
+	return buf, nil
+}
+
+// reset rewinds the result set to the first row of the first result set.
+func (r *bufferedResultSet) reset() {
+	r.set, r.row = 0, -1
+}
+
+// Next satisfies the tblfmt.ResultSet interface.
+func (r *bufferedResultSet) Next() bool {
+	if r.set >= len(r.sets) || r.row+1 >= len(r.sets[r.set].rows) {
+		return false
+	}
+	r.row++
+	return true
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (r *bufferedResultSet) Scan(dest ...interface{}) error {
+	row := r.sets[r.set].rows[r.row]
+	if len(dest) != len(row) {
+		return fmt.Errorf("expected %d destination arguments in Scan, not %d", len(row), len(dest))
+	}
+	for i, d := range dest {
+		v, ok := d.(*interface{})
+		if !ok {
+			return fmt.Errorf("unsupported Scan destination type %T", d)
+		}
+		*v = row[i]
+	}
+	return nil
+}
+
+// Columns satisfies the tblfmt.ResultSet interface.
+func (r *bufferedResultSet) Columns() ([]string, error) {
+	if r.set >= len(r.sets) {
+		return nil, nil
+	}
+	return r.sets[r.set].cols, nil
+}
+
+// ColumnTypes returns the column types of the current result set, when
+// available.
+func (r *bufferedResultSet) ColumnTypes() ([]*sql.ColumnType, error) {
+	if r.set >= len(r.sets) {
+		return nil, nil
+	}
+	return r.sets[r.set].typs, nil
+}
+
+// Close satisfies the tblfmt.ResultSet interface.
+func (r *bufferedResultSet) Close() error {
+	return nil
+}
+
+// Err satisfies the tblfmt.ResultSet interface.
+func (r *bufferedResultSet) Err() error {
+	return nil
+}
+
+// NextResultSet satisfies the tblfmt.ResultSet interface.
+func (r *bufferedResultSet) NextResultSet() bool {
+	if r.set+1 >= len(r.sets) {
+		return false
+	}
+	r.set, r.row = r.set+1, -1
+	return true
+}
+
+// newCrosstabView creates a crosstab view of the result set. When a
+// horizontal sort column (sortcolH) is specified, the horizontal header
+// values are ordered by the sort column's values, numerically when all values
+// are numbers, and lexically otherwise.
+func newCrosstabView(resultSet tblfmt.ResultSet, params []string, extra ...tblfmt.Option) (tblfmt.ResultSet, error) {
+	if len(params) < 4 || params[3] == "" {
+		return tblfmt.NewCrosstabView(resultSet, append(extra, tblfmt.WithParams(params...))...)
+	}
+	buf, err := newBufferedResultSet(resultSet)
+	if err != nil {
+		return nil, err
+	}
+	cols, _ := buf.Columns()
+	hindex, sindex := 1, crosstabIndex(cols, params[3])
+	if params[1] != "" {
+		hindex = crosstabIndex(cols, params[1])
+	}
+	switch {
+	case hindex < 0 || hindex >= len(cols):
+		return nil, tblfmt.ErrCrosstabHorizontalColumnNotInResult
+	case sindex < 0:
+		return nil, tblfmt.ErrCrosstabHorizontalSortColumnNotInResult
+	}
+	// collect the sort value for each horizontal header value
+	formatter := tblfmt.NewEscapeFormatter(tblfmt.WithIsRaw(true, 0, 0))
+	keys, seen := make(map[string]string), make(map[string]string)
+	numeric := true
+	for buf.Next() {
+		row := buf.sets[buf.set].rows[buf.row]
+		h, s := row[hindex], row[sindex]
+		v, err := formatter.Format([]interface{}{&h, &s})
+		if err != nil {
+			return nil, err
+		}
+		hk, sk := v[0].String(), v[1].String()
+		if z, ok := keys[hk]; ok && z != sk {
+			return nil, text.ErrCrosstabAmbiguousSortValue
+		}
+		if z, ok := seen[sk]; ok && z != hk {
+			return nil, text.ErrCrosstabDuplicateSortValue
+		}
+		keys[hk], seen[sk] = sk, hk
+		if _, err := strconv.ParseFloat(sk, 64); err != nil {
+			numeric = false
+		}
+	}
+	less := func(a, b string) bool {
+		return a < b
+	}
+	if numeric {
+		less = func(a, b string) bool {
+			x, _ := strconv.ParseFloat(a, 64)
+			y, _ := strconv.ParseFloat(b, 64)
+			return x < y
+		}
+	}
+	// build view without the sort column, and reorder its columns
+	buf.reset()
+	view, err := tblfmt.NewCrosstabView(buf, append(extra, tblfmt.WithParams(params[:3]...))...)
+	if err != nil {
+		return nil, err
+	}
+	vcols, err := view.Columns()
+	if err != nil {
+		return nil, err
+	}
+	order := make([]int, len(vcols))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order[1:], func(i, j int) bool {
+		return less(keys[vcols[order[i+1]]], keys[vcols[order[j+1]]])
+	})
+	return &sortedView{ResultSet: view, order: order}, nil
+}
+
+// crosstabIndex returns the index of the crosstab column param s in cols,
+// matching either the column's name or its (1 based) position.
+func crosstabIndex(cols []string, s string) int {
+	s = strings.TrimSpace(s)
+	if i, err := strconv.Atoi(s); err == nil {
+		if i--; i >= 0 && i < len(cols) {
+			return i
+		}
+		return -1
+	}
+	for i, col := range cols {
+		if strings.EqualFold(s, strings.TrimSpace(col)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// sortedView wraps a result set, reordering its columns.
+type sortedView struct {
+	tblfmt.ResultSet
+	order []int
+}
+
+// Columns satisfies the tblfmt.ResultSet interface.
+func (view *sortedView) Columns() ([]string, error) {
+	cols, err := view.ResultSet.Columns()
+	if err != nil {
+		return nil, err
+	}
+	z := make([]string, len(view.order))
+	for i, j := range view.order {
+		z[i] = cols[j]
+	}
+	return z, nil
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (view *sortedView) Scan(dest ...interface{}) error {
+	if len(dest) != len(view.order) {
+		return fmt.Errorf("expected %d destination arguments in Scan, not %d", len(view.order), len(dest))
+	}
+	z := make([]interface{}, len(dest))
+	for i := range z {
+		z[view.order[i]] = dest[i]
+	}
+	return view.ResultSet.Scan(z...)
+}
+
+// columnTypes returns the column types of a wrapped result set, when
+// available, so that views do not hide the column types used by tblfmt (see
+// tblfmt.WithUseColumnTypes).
+func columnTypes(resultSet tblfmt.ResultSet) ([]*sql.ColumnType, error) {
+	if r, ok := resultSet.(interface {
+		ColumnTypes() ([]*sql.ColumnType, error)
+	}); ok {
+		return r.ColumnTypes()
+	}
+	return nil, tblfmt.ErrResultSetHasNoColumnTypes
+}
+
+// limitedView wraps a result set, returning at most a number of rows of each
+// result set (see \g LIMIT), for queries not limited by the database.
+type limitedView struct {
+	tblfmt.ResultSet
+	limit, row int
+}
+
+// newLimitedView creates a limited view of the result set.
+func newLimitedView(resultSet tblfmt.ResultSet, limit int) *limitedView {
+	return &limitedView{
+		ResultSet: resultSet,
+		limit:     limit,
+	}
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (view *limitedView) ColumnTypes() ([]*sql.ColumnType, error) {
+	return columnTypes(view.ResultSet)
+}
+
+// Next satisfies the tblfmt.ResultSet interface.
+func (view *limitedView) Next() bool {
+	if view.row >= view.limit || !view.ResultSet.Next() {
+		return false
+	}
+	view.row++
+	return true
+}
+
+// NextResultSet satisfies the tblfmt.ResultSet interface.
+func (view *limitedView) NextResultSet() bool {
+	view.row = 0
+	return view.ResultSet.NextResultSet()
+}
+
+// truncatedView wraps a result set, truncating string values to a maximum
+// number of runes (see max_col_width).
+type truncatedView struct {
+	tblfmt.ResultSet
+	width   int
+	exclude map[string]bool
+	skip    []bool
+}
+
+// newTruncatedView creates a truncated view of the result set, excluding the
+// comma separated column names.
+func newTruncatedView(resultSet tblfmt.ResultSet, width int, exclude string) *truncatedView {
+	view := &truncatedView{
+		ResultSet: resultSet,
+		width:     width,
+		exclude:   make(map[string]bool),
+	}
+	for _, s := range strings.Split(exclude, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			view.exclude[strings.ToLower(s)] = true
+		}
+	}
+	return view
+}
+
+// Columns satisfies the tblfmt.ResultSet interface.
+func (view *truncatedView) Columns() ([]string, error) {
+	cols, err := view.ResultSet.Columns()
+	if err != nil {
+		return nil, err
+	}
+	view.skip = make([]bool, len(cols))
+	for i, col := range cols {
+		view.skip[i] = view.exclude[strings.ToLower(strings.TrimSpace(col))]
+	}
+	return cols, nil
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (view *truncatedView) ColumnTypes() ([]*sql.ColumnType, error) {
+	return columnTypes(view.ResultSet)
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (view *truncatedView) Scan(dest ...interface{}) error {
+	if err := view.ResultSet.Scan(dest...); err != nil {
+		return err
+	}
+	for i, d := range dest {
+		p, ok := d.(*interface{})
+		if !ok || (i < len(view.skip) && view.skip[i]) {
+			continue
+		}
+		switch v := (*p).(type) {
+		case string:
+			*p = truncate(v, view.width)
+		case []byte:
+			if utf8.Valid(v) {
+				*p = []byte(truncate(string(v), view.width))
+			}
+		}
+	}
+	return nil
+}
+
+// jsonView wraps a result set, pretty-printing the values of JSON columns
+// (see json_pretty).
+type jsonView struct {
+	tblfmt.ResultSet
+	indent string
+	json   []bool
+}
+
+// newJSONView creates a view of the result set indenting JSON values with
+// indent spaces.
+func newJSONView(resultSet tblfmt.ResultSet, indent int) *jsonView {
+	return &jsonView{
+		ResultSet: resultSet,
+		indent:    strings.Repeat(" ", max(indent, 0)),
+	}
+}
+
+// Columns satisfies the tblfmt.ResultSet interface.
+func (view *jsonView) Columns() ([]string, error) {
+	cols, err := view.ResultSet.Columns()
+	if err != nil {
+		return nil, err
+	}
+	view.json = make([]bool, len(cols))
+	for i, typ := range columnTypeNames(view.ResultSet, len(cols)) {
+		view.json[i] = strings.Contains(typ, "JSON")
+	}
+	return cols, nil
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (view *jsonView) ColumnTypes() ([]*sql.ColumnType, error) {
+	return columnTypes(view.ResultSet)
+}
+
+// Scan satisfies the tblfmt.ResultSet interface. Values that are not valid
+// JSON are left as-is.
+func (view *jsonView) Scan(dest ...interface{}) error {
+	if err := view.ResultSet.Scan(dest...); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for i, d := range dest {
+		p, ok := d.(*interface{})
+		if !ok || i >= len(view.json) || !view.json[i] {
+			continue
+		}
+		buf.Reset()
+		switch v := (*p).(type) {
+		case string:
+			if json.Indent(&buf, []byte(v), "", view.indent) == nil {
+				*p = buf.String()
+			}
+		case []byte:
+			if json.Indent(&buf, v, "", view.indent) == nil {
+				*p = bytes.Clone(buf.Bytes())
+			}
+		}
+	}
+	return nil
+}
+
+// decodedView wraps a result set, decoding string values from the client
+// encoding (see \encoding).
+type decodedView struct {
+	tblfmt.ResultSet
+	dec *encoding.Decoder
+}
+
+// newDecodedView creates a view of the result set decoding string values
+// from the encoding.
+func newDecodedView(resultSet tblfmt.ResultSet, enc encoding.Encoding) *decodedView {
+	return &decodedView{
+		ResultSet: resultSet,
+		dec:       enc.NewDecoder(),
+	}
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (view *decodedView) ColumnTypes() ([]*sql.ColumnType, error) {
+	return columnTypes(view.ResultSet)
+}
+
+// Scan satisfies the tblfmt.ResultSet interface.
+func (view *decodedView) Scan(dest ...interface{}) error {
+	if err := view.ResultSet.Scan(dest...); err != nil {
+		return err
+	}
+	for _, d := range dest {
+		p, ok := d.(*interface{})
+		if !ok {
+			continue
+		}
+		switch v := (*p).(type) {
+		case string:
+			s, err := view.dec.String(v)
+			if err != nil {
+				return err
+			}
+			*p = s
+		case []byte:
+			b, err := view.dec.Bytes(v)
+			if err != nil {
+				return err
+			}
+			*p = b
+		}
+	}
+	return nil
+}
+
+// truncate truncates s to n runes, replacing the last rune with an ellipsis
+// when s is longer than n runes.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	i, j := 0, 0
+	for j = range s {
+		if i == n-1 {
+			break
+		}
+		i++
+	}
+	return s[:j] + "…"
+}
+
+// numericLocale formats numbers with the digit group and decimal separators
+// of a locale (see numericlocale).
+type numericLocale struct {
+	group   string
+	decimal string
+}
+
+// newNumericLocale creates a numeric locale for the locale, determining its
+// separators from a sample number formatted for the locale. Unknown locales
+// use the separators of English.
+func newNumericLocale(locale string) *numericLocale {
+	tag := language.English
+	if t, err := language.Parse(locale); err == nil {
+		tag = t
+	}
+	var seps []string
+	var buf strings.Builder
+	for _, r := range message.NewPrinter(tag).Sprint(number.Decimal(1234567.5, number.MinFractionDigits(1))) {
+		switch {
+		case !unicode.IsDigit(r):
+			buf.WriteRune(r)
+		case buf.Len() != 0:
+			seps = append(seps, buf.String())
+			buf.Reset()
+		}
+	}
+	switch len(seps) {
+	case 3:
+		return &numericLocale{group: seps[0], decimal: seps[2]}
+	case 1:
+		return &numericLocale{decimal: seps[0]}
+	}
+	return &numericLocale{group: ",", decimal: "."}
+}
+
+// decimalRE matches a decimal number.
+var decimalRE = regexp.MustCompile(`^([-+]?)([0-9]+)(?:\.([0-9]+))?$`)
+
+// format formats v with the locale's separators, returning false when v is
+// not a number. String values are formatted only when numeric is true (ie,
+// the value's column has a numeric type), retaining their scale, so that
+// decimals are displayed without loss of precision.
+func (nl *numericLocale) format(v interface{}, numeric bool) (string, bool) {
+	var s string
+	switch x := v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		s = fmt.Sprintf("%d", x)
+	case float32:
+		if math.IsInf(float64(x), 0) || math.IsNaN(float64(x)) || math.Abs(float64(x)) >= 1e21 {
+			return "", false
+		}
+		s = strconv.FormatFloat(float64(x), 'f', -1, 32)
+	case float64:
+		if math.IsInf(x, 0) || math.IsNaN(x) || math.Abs(x) >= 1e21 {
+			return "", false
+		}
+		s = strconv.FormatFloat(x, 'f', -1, 64)
+	case []byte:
+		if !numeric {
+			return "", false
+		}
+		s = string(x)
+	case string:
+		if !numeric {
+			return "", false
+		}
+		s = x
+	default:
+		return "", false
+	}
+	m := decimalRE.FindStringSubmatch(s)
+	if m == nil {
+		return "", false
+	}
+	var buf strings.Builder
+	buf.WriteString(m[1])
+	for i, c := range m[2] {
+		if i != 0 && (len(m[2])-i)%3 == 0 {
+			buf.WriteString(nl.group)
+		}
+		buf.WriteRune(c)
+	}
+	if m[3] != "" {
+		buf.WriteString(nl.decimal + m[3])
+	}
+	return buf.String(), true
+}
+
+// numericFormatter wraps a formatter, formatting numbers with the separators
+// of a locale, and right aligning the values of numeric columns.
+type numericFormatter struct {
+	tblfmt.Formatter
+	resultSet tblfmt.ResultSet
+	locale    *numericLocale
+	numeric   []bool
+}
+
+// newNumericFormatter creates a numeric formatter for the result set, using
+// the time and locale params.
+func newNumericFormatter(resultSet tblfmt.ResultSet, params map[string]string) *numericFormatter {
+	tfmt := params["time"]
+	if tfmt == "" {
+		tfmt = time.RFC3339
+	}
+	return &numericFormatter{
+		Formatter: tblfmt.NewEscapeFormatter(tblfmt.WithTimeFormat(tfmt)),
+		resultSet: resultSet,
+		locale:    newNumericLocale(params["locale"]),
+	}
+}
+
+// Header satisfies the tblfmt.Formatter interface, determining the numeric
+// columns of the current result set.
+func (f *numericFormatter) Header(headers []string) ([]*tblfmt.Value, error) {
+	f.numeric = make([]bool, len(headers))
+	for i, typ := range columnTypeNames(f.resultSet, len(headers)) {
+		f.numeric[i] = jsonenc.IsNumericType(typ)
+	}
+	return f.Formatter.Header(headers)
+}
+
+// Format satisfies the tblfmt.Formatter interface.
+func (f *numericFormatter) Format(vals []interface{}) ([]*tblfmt.Value, error) {
+	res, err := f.Formatter.Format(vals)
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range vals {
+		if p, ok := v.(*interface{}); ok {
+			v = *p
+		}
+		if res[i] == nil {
+			continue
+		}
+		if s, ok := f.locale.format(v, i < len(f.numeric) && f.numeric[i]); ok {
+			res[i] = &tblfmt.Value{
+				Buf:   []byte(s),
+				Width: runewidth.StringWidth(s),
+				Align: tblfmt.AlignRight,
+				Raw:   true,
+			}
+		}
+	}
+	return res, nil
+}
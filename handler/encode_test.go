@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"bytes"
+	"database/sql"
+	"io"
+	"strings"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/xo/tblfmt"
+)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name   string
+		encode func(io.Writer, tblfmt.ResultSet, map[string]string) error
+		params map[string]string
+		exp    string
+	}{
+		{
+			"ndjson", encodeNDJSON, nil,
+			`{"id":1,"name":"a <b>\nc","price":12.5,"data":"AP8="}` + "\n" +
+				`{"id":2,"name":null,"price":null,"data":null}` + "\n",
+		},
+		{
+			"json", encodeJSON, nil,
+			`[{"id":1,"name":"a <b>\nc","price":12.5,"data":"AP8="},{"id":2,"name":null,"price":null,"data":null}]` + "\n",
+		},
+		{
+			"yaml", encodeYAML, nil,
+			`- id: 1
+  name: |-
+    a <b>
+    c
+  price: 12.5
+  data: AP8=
+- id: 2
+  name: null
+  price: null
+  data: null
+`,
+		},
+		{
+			"fwf", encodeFWF, nil,
+			"idname   pricedata    \n" +
+				" 1a <b> c 12.5\\x00\\xff\n" +
+				" 2                    \n",
+		},
+		{
+			"fwf widths", encodeFWF, map[string]string{"fwf_widths": "3,4,6,8", "fwf_align": "l", "tuples_only": "on"},
+			"1  a <b  12.5\\x00\\xff\n" +
+				"2" + strings.Repeat(" ", 20) + "\n",
+		},
+		{
+			"html", encodeHTML, map[string]string{"null": "NULL"},
+			`<table>
+  <thead>
+    <tr>
+      <th align="left">id</th>
+      <th align="left">name</th>
+      <th align="left">price</th>
+      <th align="left">data</th>
+    </tr>
+  </thead>
+  <tbody>
+    <tr>
+      <td align="right">1</td>
+      <td align="left">a &lt;b&gt;<br />
+c</td>
+      <td align="right">12.5</td>
+      <td align="left">\x00\xff</td>
+    </tr>
+    <tr>
+      <td align="right">2</td>
+      <td align="left">NULL</td>
+      <td align="left">NULL</td>
+      <td align="left">NULL</td>
+    </tr>
+  </tbody>
+</table>
+`,
+		},
+	}
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(`CREATE TABLE t (id INTEGER, name TEXT, price DECIMAL(10,2), data BLOB);
+INSERT INTO t VALUES (1, 'a <b>' || char(10) || 'c', 12.5, x'00ff'), (2, NULL, NULL, NULL)`); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rows, err := db.Query(`SELECT * FROM t ORDER BY id`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rows.Close()
+			var buf bytes.Buffer
+			if err := test.encode(&buf, rows, test.params); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if s := buf.String(); s != test.exp {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.exp, s)
+			}
+		})
+	}
+}
@@ -1,5 +1,13 @@
 package handler
 
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rmasci/usql/text"
+)
+
 // Error wraps handler errors
 type Error struct {
 	Buf string
@@ -23,3 +31,34 @@ func (e *Error) Error() string { return e.Err.Error() }
 
 // Unwrap returns the original error
 func (e *Error) Unwrap() error { return e.Err }
+
+// PosError wraps the error of a statement or command read from a file with
+// its position, and the positions of the \i commands including the file.
+type PosError struct {
+	// Pos is the file name and line number, as file:line.
+	Pos string
+	// Stack is the positions of the including \i commands, outermost first.
+	Stack []string
+	Err   error
+}
+
+// Error satisfies the error interface, returning the original error message
+// prefixed with the position.
+func (e *PosError) Error() string { return e.Pos + ": " + e.Err.Error() }
+
+// Unwrap returns the original error
+func (e *PosError) Unwrap() error { return e.Err }
+
+// PrintError writes err to w. When err has a position (ie, a *PosError), the
+// error is prefixed by its position, and followed by the include stack.
+func PrintError(w io.Writer, err error) {
+	var pe *PosError
+	if !errors.As(err, &pe) {
+		fmt.Fprintln(w, "error:", err)
+		return
+	}
+	fmt.Fprintf(w, "%s: error: %v\n", pe.Pos, pe.Err)
+	for i := len(pe.Stack) - 1; i >= 0; i-- {
+		fmt.Fprintln(w, fmt.Sprintf(text.IncludedFrom, pe.Stack[i]))
+	}
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/gohxs/readline"
+	"github.com/mattn/go-runewidth"
+	"github.com/rmasci/usql/env"
+	"github.com/rmasci/usql/metacmd"
+	"github.com/rmasci/usql/text"
+)
+
+// graphBlocks are the partial block characters used for the fractional part
+// of a bar, in eighths.
+var graphBlocks = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+
+// execGraph executes a query and displays a column of the results as a
+// horizontal bar chart, labeling each bar with another column's value.
+//
+// When not specified, the label is the first column, and the value is the
+// first of the other columns having only numeric (or NULL) values. When the
+// result has a single column, rows are labeled by their row number.
+func (h *Handler) execGraph(ctx context.Context, w io.Writer, opt metacmd.Option, prefix, sqlstr string, qtyp bool) error {
+	if !qtyp {
+		fmt.Fprintln(w, text.NoResultColumns)
+		return nil
+	}
+	rows, err := h.DB().QueryContext(ctx, sqlstr, opt.Args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	if len(cols) == 0 {
+		fmt.Fprintln(w, text.NoResultColumns)
+		return nil
+	}
+	var res [][]sql.NullString
+	for rows.Next() {
+		row := make([]sql.NullString, len(cols))
+		v := make([]interface{}, len(cols))
+		for i := range row {
+			v[i] = &row[i]
+		}
+		if err := rows.Scan(v...); err != nil {
+			return err
+		}
+		res = append(res, row)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	params := env.Pall()
+	// determine columns
+	label, value := -1, -1
+	if len(cols) == 1 {
+		value = 0
+	} else {
+		label = 0
+	}
+	if s := opt.Params["label"]; s != "" {
+		if label, err = graphColumn(cols, s); err != nil {
+			return err
+		}
+	}
+	if s := opt.Params["value"]; s != "" {
+		if value, err = graphColumn(cols, s); err != nil {
+			return err
+		}
+	} else if value == -1 {
+		for i := range cols {
+			if i != label && graphNumeric(res, i) {
+				value = i
+				break
+			}
+		}
+		if value == -1 {
+			return text.ErrGraphNoNumericColumn
+		}
+	}
+	// collect labels and values
+	labels, values, valid := make([]string, len(res)), make([]float64, len(res)), make([]bool, len(res))
+	var lo, hi float64
+	for i, row := range res {
+		switch {
+		case label == -1:
+			labels[i] = strconv.Itoa(i + 1)
+		case row[label].Valid:
+			labels[i] = row[label].String
+		default:
+			labels[i] = params["null"]
+		}
+		if !row[value].Valid {
+			continue
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(row[value].String), 64)
+		if err != nil {
+			return fmt.Errorf(text.GraphColumnNotNumeric, cols[value], row[value].String)
+		}
+		values[i], valid[i] = f, true
+		lo, hi = min(lo, f), max(hi, f)
+	}
+	if len(res) == 0 {
+		fmt.Fprintf(w, text.RowCount+"\n\n", 0)
+		return nil
+	}
+	// determine widths
+	width := 80
+	if n, _ := strconv.Atoi(params["columns"]); n > 0 {
+		width = n
+	} else if h.isTerminal() {
+		if n := readline.GetScreenWidth(); n > 0 {
+			width = n
+		}
+	}
+	labelWidth, valueWidth := 0, 0
+	strs := make([]string, len(res))
+	for i := range res {
+		labelWidth = max(labelWidth, runewidth.StringWidth(labels[i]))
+		if valid[i] {
+			strs[i] = strconv.FormatFloat(values[i], 'f', -1, 64)
+		} else {
+			strs[i] = params["null"]
+		}
+		valueWidth = max(valueWidth, runewidth.StringWidth(strs[i]))
+	}
+	labelWidth = min(labelWidth, max(width/3, 1))
+	barWidth := max(width-labelWidth-valueWidth-3, 10)
+	// split the bar width on either side of the zero axis
+	neg := 0
+	if lo < 0 {
+		neg = int(math.Round(float64(barWidth) * -lo / (hi - lo)))
+	}
+	scale := 0.0
+	if hi-lo > 0 {
+		scale = float64(barWidth) / (hi - lo)
+	}
+	for i := range res {
+		var left, right string
+		switch f := values[i] * scale; {
+		case !valid[i]:
+		case f < 0:
+			n := min(int(math.Round(-f)), neg)
+			left = strings.Repeat("█", n)
+		default:
+			n := int(f * 8)
+			right = strings.Repeat("█", n/8) + graphBlocks[n%8]
+		}
+		if _, err := fmt.Fprintf(w, "%s %s%s%s %s\n",
+			runewidth.FillRight(runewidth.Truncate(labels[i], labelWidth, "…"), labelWidth),
+			strings.Repeat(" ", neg-runewidth.StringWidth(left)), left,
+			runewidth.FillRight(right, barWidth-neg),
+			runewidth.FillLeft(strs[i], valueWidth),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// graphColumn returns the index of the named column, or of the (1-based)
+// column number.
+func graphColumn(cols []string, name string) (int, error) {
+	for i, col := range cols {
+		if col == name {
+			return i, nil
+		}
+	}
+	for i, col := range cols {
+		if strings.EqualFold(col, name) {
+			return i, nil
+		}
+	}
+	if n, err := strconv.Atoi(name); err == nil && 0 < n && n <= len(cols) {
+		return n - 1, nil
+	}
+	return -1, fmt.Errorf(text.GraphColumnNotFound, name)
+}
+
+// graphNumeric returns true when all non-NULL values of the column in the
+// result are numeric.
+func graphNumeric(res [][]sql.NullString, i int) bool {
+	for _, row := range res {
+		if !row[i].Valid {
+			continue
+		}
+		if _, err := strconv.ParseFloat(strings.TrimSpace(row[i].String), 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -6,8 +6,12 @@ import (
 	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
+	"hash/fnv"
 	"io"
 	"log"
 	"net/url"
@@ -21,6 +25,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 	"unicode"
@@ -28,11 +33,14 @@ import (
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/formatters"
 	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/mattn/go-isatty"
+	"github.com/mattn/go-runewidth"
 	"github.com/xo/dburl"
 	"github.com/xo/dburl/passfile"
 	"github.com/xo/tblfmt"
 	"github.com/rmasci/usql/drivers"
 	"github.com/rmasci/usql/drivers/completer"
+	"github.com/rmasci/usql/drivers/explain"
 	"github.com/rmasci/usql/drivers/metadata"
 	"github.com/rmasci/usql/env"
 	"github.com/rmasci/usql/metacmd"
@@ -57,14 +65,46 @@ type Handler struct {
 	nopw bool
 	// timing of every command executed
 	timing bool
+	// timingVerbose breaks down the timing of every command executed
+	timingVerbose bool
+	// timings is the timing breakdown of the last executed command
+	timings timings
+	// tee additionally receives encoded query results, when set
+	tee io.Writer
 	// singleLineMode is single line mode
 	singleLineMode bool
 	// query statement buffer
 	buf *stmt.Stmt
+	// src is the name of the included file being read, and stack the
+	// positions of the \i commands including it, used to report the
+	// position of errors
+	src   string
+	stack []string
+	// conditional blocks, and the statement buffer saved when entering an
+	// inactive branch
+	cond    stmt.Cond
+	condBuf *stmt.Stmt
+	// loop body being recorded
+	loop *loop
+	// summary of the statements executed in a batch, when set
+	summary *summary
+	// promptWidth is the display width of the last PROMPT1
+	promptWidth int
+	// timingSummary of the statements executed in a file, when set
+	timingSummary *timingSummary
+	// metadataCache caches the metadata queries of the current connection
+	metadataCache *metadata.Cache
+	// snapshot is the query result saved by \snapshot, compared by \diff
+	snapshot *diffResult
+	// returnedRows is the number of rows returned by the last executed
+	// statement, or -1 when it was not a query (see exit_on_rows)
+	returnedRows int64
 	// last statement
 	last       string
 	lastPrefix string
 	lastRaw    string
+	// last query error
+	lastErr error
 	// batch
 	batch    bool
 	batchEnd string
@@ -72,6 +112,11 @@ type Handler struct {
 	u  *dburl.URL
 	db *sql.DB
 	tx *sql.Tx
+	// singleTx is set while running in a single transaction, where
+	// statements and commands beginning or ending a transaction are ignored
+	singleTx bool
+	// named connections opened with \c @NAME, shared with sub handlers
+	conns map[string]*namedConn
 	// out file or pipe
 	out io.WriteCloser
 }
@@ -92,12 +137,27 @@ func New(l rline.IO, user *user.User, wd string, nopw bool) *Handler {
 		}
 	}
 	h := &Handler{
-		l:    l,
-		user: user,
-		wd:   wd,
-		nopw: nopw,
-		buf:  stmt.New(f),
-	}
+		l:     l,
+		user:  user,
+		wd:    wd,
+		nopw:  nopw,
+		conns: make(map[string]*namedConn),
+		// the ttl is read on each query, so changes apply to cached
+		// completers
+		metadataCache: metadata.NewCache(func() time.Duration {
+			s, _ := env.Pget("metadata_cache_ttl")
+			d, _ := time.ParseDuration(s)
+			return d
+		}),
+		returnedRows: -1,
+	}
+	h.buf = stmt.New(func() ([]rune, error) {
+		r, err := f()
+		if err == nil && h.loop != nil {
+			h.loop.body = append(h.loop.body, string(r))
+		}
+		return r, err
+	})
 	if iactive {
 		l.SetOutput(h.outputHighlighter)
 	}
@@ -119,6 +179,16 @@ func (h *Handler) SetTiming(timing bool) {
 	h.timing = timing
 }
 
+// GetTimingVerbose gets the verbose timing toggle.
+func (h *Handler) GetTimingVerbose() bool {
+	return h.timingVerbose
+}
+
+// SetTimingVerbose sets the verbose timing toggle.
+func (h *Handler) SetTimingVerbose(timingVerbose bool) {
+	h.timingVerbose = timingVerbose
+}
+
 // outputHighlighter returns s as a highlighted string, based on the current
 // buffer and syntax highlighting settings.
 func (h *Handler) outputHighlighter(s string) string {
@@ -241,10 +311,10 @@ func (h *Handler) Run() error {
 		var execute bool
 		// set prompt
 		if iactive {
-			h.l.Prompt(h.Prompt(env.Get("PROMPT1")))
+			h.l.Prompt(h.nextPrompt())
 		}
 		// read next statement/command
-		cmd, paramstr, err := h.buf.Next(env.Unquote(h.user, false, env.All()))
+		cmd, paramstr, err := h.buf.Next(h.unquote())
 		switch {
 		case h.singleLineMode && err == nil:
 			execute = h.buf.Len != 0
@@ -253,10 +323,52 @@ func (h *Handler) Run() error {
 			continue
 		case err != nil:
 			if err == io.EOF {
+				switch {
+				case h.loop != nil && h.loop.summary != nil:
+					return text.ErrUnterminatedBatch
+				case h.loop != nil:
+					return text.ErrUnterminatedLoop
+				case h.cond.Depth() != 0:
+					return text.ErrUnterminatedIf
+				}
 				return lastErr
 			}
 			return err
 		}
+		// record loop body, executing it on the closing \endloop
+		if h.loop != nil {
+			h.buf.Restore(h.loop.buf)
+			switch name := strings.TrimPrefix(cmd, `\`); {
+			case name == "endloop" || name == "endbatch":
+				h.loop.depth--
+			case metacmd.IsLoop(name):
+				h.loop.depth++
+			}
+			if h.loop.depth != 0 {
+				continue
+			}
+			l := h.loop
+			h.loop = nil
+			run := h.runLoop
+			if l.summary != nil {
+				run = h.runBatch
+			}
+			if err := run(l); err != nil {
+				lastErr = WrapErr(cmd, err)
+				if !iactive && env.All()["ON_ERROR_STOP"] == "on" {
+					return err
+				}
+			}
+			continue
+		}
+		// skip input in inactive conditional branches
+		active := h.cond.Active()
+		if !active {
+			h.buf.Restore(h.condBuf)
+			if !metacmd.IsConditional(strings.TrimPrefix(cmd, `\`)) {
+				continue
+			}
+		}
 		var opt metacmd.Option
 		if cmd != "" {
 			cmd = strings.TrimPrefix(cmd, `\`)
@@ -271,15 +383,25 @@ func (h *Handler) Run() error {
 				case err == text.ErrMissingRequiredArgument:
 					fmt.Fprintln(stderr, fmt.Sprintf(text.MissingRequiredArg, cmd))
 				default:
-					fmt.Fprintln(stderr, "error:", err)
+					h.printErr(h.buf.Lines(), err)
 				}
 				continue
 			}
 			// run
 			opt, err = r.Run(h)
+			if active && !h.cond.Active() {
+				h.condBuf = h.buf.Save()
+			}
+			if h.loop != nil && h.loop.buf == nil {
+				h.loop.buf = h.buf.Save()
+			}
 			if err != nil && err != rline.ErrInterrupt {
 				lastErr = WrapErr(cmd, err)
-				fmt.Fprintln(stderr, "error:", err)
+				// errors of included files have already been displayed
+				var he *Error
+				if !errors.As(err, &he) {
+					h.printErr(h.buf.Lines(), err)
+				}
 				continue
 			}
 			// print unused command parameters
@@ -288,7 +410,7 @@ func (h *Handler) Run() error {
 					return true, s, nil
 				})
 				if err != nil {
-					fmt.Fprintln(stderr, "error:", err)
+					h.printErr(h.buf.Lines(), err)
 				}
 				if !ok {
 					break
@@ -328,6 +450,12 @@ func (h *Handler) Run() error {
 		}
 		// execute buf
 		if execute || h.buf.Ready() || opt.Exec != metacmd.ExecNone {
+			// line of the statement, or of the command executing the last
+			// statement
+			line := h.buf.Line
+			if h.buf.Len == 0 {
+				line = h.buf.Lines()
+			}
 			// intercept batch query
 			if h.u != nil {
 				typ, end, batch := drivers.IsBatchQueryPrefix(h.u, h.buf.Prefix)
@@ -335,13 +463,13 @@ func (h *Handler) Run() error {
 				case h.batch && batch:
 					err = fmt.Errorf("cannot perform %s in existing batch", typ)
 					lastErr = WrapErr(h.buf.String(), err)
-					fmt.Fprintln(stderr, "error:", err)
+					h.printErr(line, err)
 					continue
 				// cannot use \g* while accumulating statements for batch queries
 				case h.batch && typ != h.batchEnd && opt.Exec != metacmd.ExecNone:
 					err = errors.New("cannot force batch execution")
 					lastErr = WrapErr(h.buf.String(), err)
-					fmt.Fprintln(stderr, "error:", err)
+					h.printErr(line, err)
 					continue
 				case batch:
 					h.batch, h.batchEnd = true, end
@@ -377,23 +505,57 @@ func (h *Handler) Run() error {
 				}
 				// execute
 				out := stdout
-				if h.out != nil {
+				switch {
+				case h.summary != nil:
+					out = io.Discard
+				case h.out != nil:
 					out = h.out
 				}
-				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
-				if err = h.Execute(ctx, out, opt, h.lastPrefix, h.last, forceBatch); err != nil {
-					lastErr = WrapErr(h.last, err)
+				echo := env.Get("ECHO")
+				if echo == "queries" || echo == "all" {
+					h.echo(text.EchoQuery, h.last)
+				}
+				ctx, exit, stop := h.interruptContext()
+				start := time.Now()
+				if err = h.executeInterruptible(ctx, exit, out, opt, h.lastPrefix, h.last, forceBatch); err != nil {
+					if errors.Is(err, text.ErrInterrupted) {
+						stop()
+						return err
+					}
+					if ctx.Err() != nil && errors.Is(err, context.Canceled) {
+						err = text.ErrQueryCanceled
+					}
+					h.lastErr, lastErr = err, WrapErr(h.last, err)
+					if echo == "errors" {
+						h.echo(text.EchoFailedQuery, h.last)
+					}
+				}
+				if h.timingSummary != nil {
+					h.timingSummary.add(h.last, time.Since(start))
+				}
+				// errors of statements in a batch are displayed in the summary
+				if h.summary != nil {
+					h.summary.add(h.last, err, time.Since(start))
+					interrupted := ctx.Err() != nil
+					stop()
+					if err != nil && (h.summary.stop || interrupted) {
+						return err
+					}
+					continue
+				}
+				if err != nil {
 					if env.All()["ON_ERROR_STOP"] == "on" {
 						if iactive {
-							fmt.Fprintln(stderr, "error:", err)
+							h.printErr(line, err)
 							h.buf.Reset([]rune{}) // empty the buffer so no other statements are run
+							stop()
 							continue
 						} else {
 							stop()
-							return err
+							return h.posErr(line, err)
 						}
 					} else {
-						fmt.Fprintln(stderr, "error:", err)
+						h.printErr(line, err)
 					}
 				}
 				stop()
@@ -402,16 +564,101 @@ func (h *Handler) Run() error {
 	}
 }
 
+// interruptExitWindow is the time after an interrupt (Ctrl-C) during which a
+// second interrupt exits.
+const interruptExitWindow = 2 * time.Second
+
+// interruptContext returns a context for executing a statement that is
+// canceled by an interrupt, aborting the running query (sending a cancel
+// request to the server, where the driver supports it) without exiting. The
+// returned exit channel is closed by a second interrupt within
+// interruptExitWindow, such as when the driver is unable to cancel the query.
+func (h *Handler) interruptContext() (context.Context, <-chan struct{}, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, done, exit := make(chan os.Signal, 1), make(chan struct{}), make(chan struct{})
+	signal.Notify(ch, os.Interrupt)
+	go func() {
+		var last time.Time
+		for {
+			select {
+			case <-done:
+				return
+			case <-ch:
+				if !last.IsZero() && time.Since(last) < interruptExitWindow {
+					close(exit)
+					return
+				}
+				last = time.Now()
+				cancel()
+			}
+		}
+	}()
+	var once sync.Once
+	return ctx, exit, func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+			cancel()
+		})
+	}
+}
+
+// executeInterruptible executes the query as with Execute, returning
+// text.ErrInterrupted without waiting for the query to finish when exit is
+// closed.
+func (h *Handler) executeInterruptible(ctx context.Context, exit <-chan struct{}, w io.Writer, opt metacmd.Option, prefix, sqlstr string, forceTrans bool) error {
+	ch := make(chan error, 1)
+	go func() {
+		ch <- h.Execute(ctx, w, opt, prefix, sqlstr, forceTrans)
+	}()
+	select {
+	case err := <-ch:
+		return err
+	case <-exit:
+		return text.ErrInterrupted
+	}
+}
+
 // Execute executes a query against the connected database.
 func (h *Handler) Execute(ctx context.Context, w io.Writer, opt metacmd.Option, prefix, sqlstr string, forceTrans bool) error {
 	if h.db == nil {
 		return text.ErrNotConnected
 	}
+	h.returnedRows = -1
 	// determine type and pre process string
 	prefix, sqlstr, qtyp, err := drivers.Process(h.u, prefix, sqlstr)
 	if err != nil {
 		return drivers.WrapErr(h.u.Driver, err)
 	}
+	if opt.Limit != 0 {
+		var ok bool
+		if sqlstr, ok = drivers.Limit(h.u, sqlstr, opt.Limit); ok {
+			// limited by the database, otherwise only the first rows of the
+			// results are written
+			opt.Limit = 0
+		}
+	}
+	// bind variables as query parameters
+	if bind, _ := env.Pget("bind_params"); bind == "on" {
+		vars := env.All()
+		var names []string
+		sqlstr, names = h.buf.BindVars(sqlstr, func(name string) bool {
+			_, ok := vars[name]
+			return ok
+		}, drivers.Placeholder(h.u))
+		for _, name := range names {
+			opt.Args = append(opt.Args, vars[name])
+		}
+	}
+	// ignore statements beginning or ending a transaction in a single
+	// transaction, where batches are already run in the transaction
+	if h.singleTx {
+		if isTransactionStmt(sqlstr) {
+			fmt.Fprintln(h.l.Stderr(), fmt.Sprintf(text.TransactionIgnored, stmt.FindPrefix(sqlstr, true, true, true)))
+			return nil
+		}
+		forceTrans = false
+	}
 	// start a transaction if forced
 	if forceTrans {
 		if err = h.BeginTx(ctx, nil); err != nil {
@@ -426,6 +673,22 @@ func (h *Handler) Execute(ctx context.Context, w io.Writer, opt metacmd.Option,
 		f = h.execSet
 	case metacmd.ExecWatch:
 		f = h.execWatch
+	case metacmd.ExecStore:
+		f = h.execStore
+	case metacmd.ExecDesc:
+		f = h.execDesc
+	case metacmd.ExecGraph:
+		f = h.execGraph
+	case metacmd.ExecProfile:
+		f = h.execProfile
+	case metacmd.ExecDiff:
+		f = h.execDiff
+	case metacmd.ExecSnapshot:
+		f = h.execSnapshot
+	}
+	// statements other than queries may change the schema
+	if !qtyp {
+		defer h.metadataCache.Clear()
 	}
 	if err = drivers.WrapErr(h.u.Driver, f(ctx, w, opt, prefix, sqlstr, qtyp)); err != nil {
 		if forceTrans {
@@ -440,6 +703,22 @@ func (h *Handler) Execute(ctx context.Context, w io.Writer, opt metacmd.Option,
 	return nil
 }
 
+// unquote returns the unquote func used when reading statements. When
+// bind_params is enabled, unquoted variables are left as-is in the statement
+// buffer, so that they can be bound as query parameters on execution.
+func (h *Handler) unquote() func(string, bool) (bool, string, error) {
+	f := env.Unquote(h.user, false, env.All())
+	if bind, _ := env.Pget("bind_params"); bind != "on" {
+		return f
+	}
+	return func(s string, isvar bool) (bool, string, error) {
+		if isvar && s != "" && s[0] != '\'' && s[0] != '"' {
+			return false, "", nil
+		}
+		return f(s, isvar)
+	}
+}
+
 // Reset resets the handler's query statement buffer.
 func (h *Handler) Reset(r []rune) {
 	h.buf.Reset(r)
@@ -523,9 +802,38 @@ func (h *Handler) Reset(r []rune) {
 // To insert a percent sign into your prompt, write %%. The default prompts are
 // '%/%R%x%# ' for prompts 1 and 2, and '>> ' for prompt 3.
 func (h *Handler) Prompt(prompt string) string {
+	s, _ := h.prompt(prompt)
+	return s
+}
+
+// nextPrompt returns the prompt for the next line of input: PROMPT3 when
+// recording the body of a loop or batch, PROMPT2 when continuing a statement,
+// and otherwise (or when not set) PROMPT1.
+func (h *Handler) nextPrompt() string {
+	var name string
+	switch {
+	case h.loop != nil:
+		name = "PROMPT3"
+	case h.buf.Len != 0:
+		name = "PROMPT2"
+	}
+	if v, ok := env.All()[name]; name != "" && ok {
+		s, _ := h.prompt(v)
+		return s
+	}
+	s, width := h.prompt(env.Get("PROMPT1"))
+	h.promptWidth = width
+	return s
+}
+
+// prompt parses a prompt, returning the prompt and its display width,
+// excluding the characters between %[ and %].
+func (h *Handler) prompt(prompt string) (string, int) {
 	r, connected := []rune(prompt), h.db != nil
 	end := len(r)
 	var buf []byte
+	// start is the start of the invisible characters after %[
+	start, invisible := -1, 0
 	for i := 0; i < end; i++ {
 		if r[i] != '%' {
 			buf = append(buf, string(r[i])...)
@@ -637,17 +945,67 @@ func (h *Handler) Prompt(prompt string) string {
 			}
 		// case 'p': // the process id of the connected backend -- never going to be supported
 		case 'R': // statement state
-			buf = append(buf, h.buf.State()...)
-		case 'x': // empty when not in a transaction block, * in transaction block, ! in failed transaction block, or ? when indeterminate
-		case 'l': // line number
+			switch {
+			case !h.cond.Active():
+				buf = append(buf, '@')
+			case h.singleLineMode && h.buf.Len == 0:
+				buf = append(buf, '^')
+			default:
+				buf = append(buf, h.buf.State()...)
+			}
+		case 'x': // empty when not in a transaction block, * in transaction block, or ? when not connected
+			switch {
+			case !connected:
+				buf = append(buf, '?')
+			case h.tx != nil:
+				buf = append(buf, '*')
+			}
+		case 'l': // line number of the statement
+			n := 1
+			if h.buf.Len != 0 {
+				n = countLines(h.buf.Buf) + 1
+			}
+			buf = strconv.AppendInt(buf, int64(n), 10)
 		case ':': // variable value
+			if j := runesIndex(r, i+2, end, ':'); j != -1 {
+				buf = append(buf, env.Get(string(r[i+2:j]))...)
+				i = j - 1
+			}
 		case '`': // value of the evaluated command
-		case '[', ']':
-		case 'w':
+		case '[': // start of invisible characters
+			start = len(buf)
+		case ']':
+			if start != -1 {
+				invisible += runewidth.StringWidth(string(buf[start:]))
+				start = -1
+			}
+		case 'w': // whitespace of the width of the last PROMPT1
+			buf = append(buf, strings.Repeat(" ", h.promptWidth)...)
 		}
 		i++
 	}
-	return string(buf)
+	return string(buf), runewidth.StringWidth(string(buf)) - invisible
+}
+
+// countLines returns the number of lines in r.
+func countLines(r []rune) int {
+	n := 1
+	for _, c := range r {
+		if c == '\n' {
+			n++
+		}
+	}
+	return n
+}
+
+// runesIndex returns the index of c in r, starting at i, or -1.
+func runesIndex(r []rune, i, end int, c rune) int {
+	for ; i < end; i++ {
+		if r[i] == c {
+			return i
+		}
+	}
+	return -1
 }
 
 // IO returns the io for the handler.
@@ -683,11 +1041,181 @@ func (h *Handler) LastRaw() string {
 	return h.lastRaw
 }
 
+// LastError returns the error of the last failed query.
+func (h *Handler) LastError() error {
+	return h.lastErr
+}
+
+// ReturnedRows returns the number of rows returned by the last executed
+// statement, or false when the statement was not a query returning rows, such
+// as an INSERT whose affected row count is in ROW_COUNT.
+func (h *Handler) ReturnedRows() (int64, bool) {
+	return h.returnedRows, h.returnedRows >= 0
+}
+
 // Buf returns the current query statement buffer.
 func (h *Handler) Buf() *stmt.Stmt {
 	return h.buf
 }
 
+// Cond returns the conditional block state.
+func (h *Handler) Cond() *stmt.Cond {
+	return &h.cond
+}
+
+// Loop starts recording a loop body, to be executed count times.
+func (h *Handler) Loop(count int) error {
+	h.loop = &loop{count: count, depth: 1}
+	return nil
+}
+
+// loop is a loop body being recorded.
+type loop struct {
+	count int
+	depth int
+	body  []string
+	// summary is the summary of a batch, when recording a batch body
+	summary *summary
+	// buf is the statement buffer saved when the loop was started
+	buf *stmt.Stmt
+}
+
+// runLoop executes the recorded loop body, setting LOOP_I to the iteration,
+// until the loop count is reached or interrupted.
+func (h *Handler) runLoop(l *loop) error {
+	// drop the line containing the closing \endloop
+	body := strings.Join(l.body[:len(l.body)-1], "\n")
+	prev, ok := env.All()["LOOP_I"]
+	defer func() {
+		if ok {
+			_ = env.Set("LOOP_I", prev)
+		} else {
+			_ = env.Unset("LOOP_I")
+		}
+	}()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	for i := 1; i <= l.count && ctx.Err() == nil; i++ {
+		_ = env.Set("LOOP_I", strconv.Itoa(i))
+		p := h.sub(strings.NewReader(body), h.wd)
+		p.out = h.out
+		err := p.Run()
+		h.db, h.u, h.tx, h.out = p.db, p.u, p.tx, p.out
+		if err != nil && env.All()["ON_ERROR_STOP"] == "on" {
+			return err
+		}
+	}
+	return nil
+}
+
+// Batch starts recording a batch body, to be executed statement by statement.
+func (h *Handler) Batch(stopOnError bool) error {
+	h.loop = &loop{count: 1, depth: 1, summary: &summary{stop: stopOnError}}
+	return nil
+}
+
+// runBatch executes the recorded batch body, displaying the status, rows
+// affected, and duration of each executed statement instead of its results.
+func (h *Handler) runBatch(l *loop) error {
+	// drop the line containing the closing \endbatch
+	body := strings.Join(l.body[:len(l.body)-1], "\n")
+	p := h.sub(strings.NewReader(body), h.wd)
+	p.out, p.summary = h.out, l.summary
+	err := p.Run()
+	h.db, h.u, h.tx, h.out = p.db, p.u, p.tx, p.out
+	out := h.l.Stdout()
+	if h.out != nil {
+		out = h.out
+	}
+	l.summary.write(out)
+	return err
+}
+
+// summary is the status of the statements executed in a batch.
+type summary struct {
+	// stop stops the batch at the first failed statement
+	stop    bool
+	results []summaryResult
+}
+
+// summaryResult is the status of an executed statement.
+type summaryResult struct {
+	sqlstr string
+	rows   string
+	err    error
+	d      time.Duration
+}
+
+// add adds the status of an executed statement.
+func (s *summary) add(sqlstr string, err error, d time.Duration) {
+	res := summaryResult{sqlstr: sqlstr, err: err, d: d}
+	if err == nil {
+		res.rows = env.Get("ROW_COUNT")
+	}
+	s.results = append(s.results, res)
+}
+
+// write writes the status of each statement, followed by the totals.
+func (s *summary) write(w io.Writer) {
+	fmt.Fprintf(w, "%4s  %-6s  %8s  %13s  %s\n", "#", "Status", "Rows", "Duration", "Statement")
+	var failed int
+	var total time.Duration
+	for i, res := range s.results {
+		status, rows := "OK", res.rows
+		if res.err != nil {
+			status, rows = "ERROR", "-"
+			failed++
+		}
+		fmt.Fprintf(w, "%4d  %-6s  %8s  %10.3f ms  %s\n", i+1, status, rows, ms(res.d), summarize(res.sqlstr, 60))
+		if res.err != nil {
+			fmt.Fprintf(w, "%4s  error: %v\n", "", res.err)
+		}
+		total += res.d
+	}
+	fmt.Fprintf(w, text.BatchSummary+"\n", len(s.results), failed, ms(total))
+}
+
+// timingSummary is the summary of the timings of the statements executed in
+// a file.
+type timingSummary struct {
+	count   int
+	total   time.Duration
+	slowest time.Duration
+	// slowestIdx and slowestSQL are the position and text of the slowest
+	// statement
+	slowestIdx int
+	slowestSQL string
+}
+
+// add adds the timing of an executed statement.
+func (s *timingSummary) add(sqlstr string, d time.Duration) {
+	s.count++
+	s.total += d
+	if s.count == 1 || d > s.slowest {
+		s.slowest, s.slowestIdx, s.slowestSQL = d, s.count, sqlstr
+	}
+}
+
+// write writes the number of statements, the total and average time, and
+// the slowest statement.
+func (s *timingSummary) write(w io.Writer) {
+	if s.count == 0 {
+		return
+	}
+	fmt.Fprintf(w, text.TimingSummary+"\n", s.count, ms(s.total), ms(s.total)/float64(s.count))
+	fmt.Fprintf(w, text.TimingSlowest+"\n", ms(s.slowest), s.slowestIdx, summarize(s.slowestSQL, 60))
+}
+
+// summarize collapses the whitespace of a statement, truncating it to n
+// runes.
+func summarize(sqlstr string, n int) string {
+	s := []rune(strings.Join(strings.Fields(sqlstr), " "))
+	if len(s) > n {
+		return string(s[:n-3]) + "..."
+	}
+	return string(s)
+}
+
 // Highlight highlights using the current environment settings.
 func (h *Handler) Highlight(w io.Writer, buf string) error {
 	vars := env.All()
@@ -718,17 +1246,41 @@ func (h *Handler) Highlight(w io.Writer, buf string) error {
 // an appropriate driver (mysql, postgres, sqlite3) depending on the type (unix
 // domain socket, directory, or regular file, respectively).
 func (h *Handler) Open(ctx context.Context, params ...string) error {
+	// named connection
+	var name string
+	if len(params) != 0 && strings.HasPrefix(params[0], "@") {
+		if name, params = params[0][1:], params[1:]; name == "" {
+			return text.ErrInvalidConnectionName
+		}
+	}
 	// build a list of all possible connStrings for the completer
 	connStrings := h.connStrings()
-	if len(params) == 0 || params[0] == "" {
+	if name == "" && (len(params) == 0 || params[0] == "") {
 		h.l.Completer(completer.NewDefaultCompleter(completer.WithConnStrings(connStrings)))
 		return nil
 	}
 	if h.tx != nil {
 		return text.ErrPreviousTransactionExists
 	}
+	if len(params) == 0 {
+		return h.useConn(ctx, name)
+	}
+	// saved connection profile
+	if len(params) == 1 {
+		if dsn, ok := env.Connection(params[0]); ok {
+			params = []string{dsn}
+		}
+	}
+	// expand environment variables
+	dsnParams := make([]string, len(params))
+	for i, s := range params {
+		var err error
+		if dsnParams[i], err = env.ExpandEnv(s); err != nil {
+			return err
+		}
+	}
 	if len(params) < 2 {
-		urlstr := params[0]
+		urlstr := dsnParams[0]
 		// parse dsn
 		u, err := dburl.Parse(urlstr)
 		if err != nil {
@@ -739,25 +1291,25 @@ func (h *Handler) Open(ctx context.Context, params ...string) error {
 		h.forceParams(h.u)
 	} else {
 		h.u = &dburl.URL{
-			Driver: params[0],
-			DSN:    strings.Join(params[1:], " "),
+			Driver: dsnParams[0],
+			DSN:    strings.Join(dsnParams[1:], " "),
 		}
 	}
 	// open connection
-	var err error
-	h.db, err = drivers.Open(ctx, h.u, h.GetOutput, h.IO().Stderr)
-	if err != nil && !drivers.IsPasswordErr(h.u, err) {
+	opened, err := h.connect(ctx)
+	if !opened && !drivers.IsPasswordErr(h.u, err) {
 		defer h.Close()
 		return err
 	}
 	// set buffer options
 	drivers.ConfigStmt(h.u, h.buf)
-	// force error/check connection
 	if err == nil {
-		if err = drivers.Ping(ctx, h.u, h.db); err == nil {
-			h.l.Completer(drivers.NewCompleter(ctx, h.u, h.db, readerOpts(), completer.WithConnStrings(connStrings)))
-			return h.Version(ctx)
+		if name != "" {
+			h.conns[name] = &namedConn{u: h.u, db: h.db}
 		}
+		h.metadataCache.Clear()
+		h.l.Completer(drivers.NewCompleter(ctx, h.u, h.metadataCache.DB(h.db), readerOpts(), completer.WithConnStrings(connStrings)))
+		return h.Version(ctx)
 	}
 	// bail without getting password
 	if h.nopw || !drivers.IsPasswordErr(h.u, err) || len(params) > 1 || !h.l.Interactive() {
@@ -774,9 +1326,40 @@ func (h *Handler) Open(ctx context.Context, params ...string) error {
 		return err
 	}
 	// reconnect
+	if name != "" {
+		return h.Open(ctx, "@"+name, dsn)
+	}
 	return h.Open(ctx, dsn)
 }
 
+// namedConn is a connection opened with \c @NAME.
+type namedConn struct {
+	u  *dburl.URL
+	db *sql.DB
+}
+
+// useConn makes the named connection the current connection.
+func (h *Handler) useConn(ctx context.Context, name string) error {
+	c, ok := h.conns[name]
+	if !ok {
+		return fmt.Errorf(text.UnknownConnection, name)
+	}
+	h.u, h.db = c.u, c.db
+	drivers.ConfigStmt(h.u, h.buf)
+	h.metadataCache.Clear()
+	h.l.Completer(drivers.NewCompleter(ctx, h.u, h.metadataCache.DB(h.db), readerOpts(), completer.WithConnStrings(h.connStrings())))
+	return h.Version(ctx)
+}
+
+// NamedConn returns the named connection opened with \c @NAME.
+func (h *Handler) NamedConn(name string) (*sql.DB, *dburl.URL, error) {
+	c, ok := h.conns[name]
+	if !ok {
+		return nil, nil, fmt.Errorf(text.UnknownConnection, name)
+	}
+	return c.db, c.u, nil
+}
+
 func (h *Handler) connStrings() []string {
 	entries, err := passfile.Entries(h.user.HomeDir, text.PassfileName)
 	if err != nil {
@@ -811,18 +1394,53 @@ func (h *Handler) connStrings() []string {
 		}
 		names = append(names, fmt.Sprintf("%s://%s%s%s%s", entry.Protocol, user, host, port, dbname))
 	}
+	names = append(names, env.Connections()...)
 	sort.Strings(names)
 	return names
 }
 
+// connect opens and checks the connection to the database, retrying a
+// failed connection with exponential backoff up to connect_retries times.
+// Returns whether the database was opened (even when the connection check
+// failed) and any error.
+func (h *Handler) connect(ctx context.Context) (bool, error) {
+	params := env.Pall()
+	retries, _ := strconv.Atoi(params["connect_retries"])
+	interval, _ := time.ParseDuration(params["connect_retry_interval"])
+	for i := 1; ; i++ {
+		var err error
+		h.db, err = drivers.Open(ctx, h.u, h.GetOutput, h.IO().Stderr)
+		opened := err == nil
+		if opened {
+			// force error/check connection
+			err = drivers.Ping(ctx, h.u, h.db)
+		}
+		if err == nil || i > retries || drivers.IsPasswordErr(h.u, err) {
+			return opened, err
+		}
+		if h.db != nil {
+			h.db.Close()
+			h.db = nil
+		}
+		fmt.Fprintln(h.l.Stderr(), "error:", fmt.Sprintf(text.ConnectRetry, err, interval, i, retries))
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(interval):
+		}
+		interval *= 2
+	}
+}
+
 // forceParams forces connection parameters on a database URL, adding any
 // driver specific required parameters, and the username/password when a
-// matching entry exists in the PASS file.
+// matching entry exists in the PASS file (or for PostgreSQL, the .pgpass
+// file).
 func (h *Handler) forceParams(u *dburl.URL) {
 	// force driver parameters
 	drivers.ForceParams(u)
 	// see if password entry is present
-	user, err := passfile.Match(u, h.user.HomeDir, text.PassfileName)
+	user, err := env.PassfileUser(u, h.user)
 	switch {
 	case err != nil:
 		fmt.Fprintln(h.l.Stderr(), "error:", err)
@@ -834,12 +1452,52 @@ func (h *Handler) forceParams(u *dburl.URL) {
 	*u = *z
 }
 
+// connLost determines if the error from a query was caused by a lost
+// database connection (or a previously failed reconnection), by checking for
+// driver.ErrBadConn or a failed ping of the connection. Connections in a
+// transaction are never treated as lost, as the transaction cannot be
+// recovered.
+func (h *Handler) connLost(ctx context.Context, err error) bool {
+	switch {
+	case h.u == nil || h.tx != nil:
+		return false
+	case h.db == nil, errors.Is(err, driver.ErrBadConn):
+		return true
+	}
+	return drivers.Ping(ctx, h.u, h.db) != nil
+}
+
+// reconnect closes and reopens the current database connection, retrying
+// the connection per connect_retries.
+func (h *Handler) reconnect(ctx context.Context) error {
+	prev := h.db
+	if h.db != nil {
+		h.db.Close()
+		h.db = nil
+	}
+	if _, err := h.connect(ctx); err != nil {
+		return drivers.WrapErr(h.u.Driver, err)
+	}
+	for _, c := range h.conns {
+		if prev != nil && c.db == prev {
+			c.db = h.db
+		}
+	}
+	h.metadataCache.Clear()
+	h.l.Completer(drivers.NewCompleter(ctx, h.u, h.metadataCache.DB(h.db), readerOpts(), completer.WithConnStrings(h.connStrings())))
+	return nil
+}
+
 // Password collects a password from input, and returns a modified DSN
 // including the collected password.
 func (h *Handler) Password(dsn string) (string, error) {
 	if dsn == "" {
 		return "", text.ErrMissingDSN
 	}
+	dsn, err := env.ExpandEnv(dsn)
+	if err != nil {
+		return "", err
+	}
 	u, err := dburl.Parse(dsn)
 	if err != nil {
 		return "", err
@@ -853,7 +1511,8 @@ func (h *Handler) Password(dsn string) (string, error) {
 		return "", err
 	}
 	u.User = url.UserPassword(user, pass)
-	return u.String(), nil
+	// escape, as the dsn is expanded again when opened
+	return strings.ReplaceAll(u.String(), "$", "$$"), nil
 }
 
 // Close closes the database connection if it is open.
@@ -862,9 +1521,15 @@ func (h *Handler) Close() error {
 		return text.ErrPreviousTransactionExists
 	}
 	if h.db != nil {
+		for name, c := range h.conns {
+			if c.db == h.db {
+				delete(h.conns, name)
+			}
+		}
 		err := h.db.Close()
 		drv := h.u.Driver
 		h.db, h.u = nil, nil
+		h.metadataCache.Clear()
 		return drivers.WrapErr(drv, err)
 	}
 	return nil
@@ -985,15 +1650,72 @@ func (h *Handler) Print(format string, a ...interface{}) {
 	fmt.Fprintln(h.l.Stdout(), fmt.Sprintf(format, a...))
 }
 
+// echo writes the interpolated query to standard error, as set by the ECHO
+// variable.
+func (h *Handler) echo(format, sqlstr string) {
+	fmt.Fprintln(h.l.Stderr(), fmt.Sprintf(format, sqlstr))
+}
+
 // execWatch repeatedly executes a query against the database.
+//
+// When the watch has a file or |pipe containing strftime-like tokens, the
+// tokens are expanded on each iteration, writing each iteration to a new
+// file.
 func (h *Handler) execWatch(ctx context.Context, w io.Writer, opt metacmd.Option, prefix, sqlstr string, qtyp bool) error {
-	for {
-		// this is the actual output that psql has: "Mon Jan 2006 3:04:05 PM MST"
-		// fmt.Fprintf(w, "%s (every %fs)\n\n", time.Now().Format("Mon Jan 2006 3:04:05 PM MST"), float64(opt.Watch)/float64(time.Second))
-		fmt.Fprintf(w, "%s (every %v)\n", time.Now().Format(time.RFC1123), opt.Watch)
-		fmt.Fprintln(w)
-		if err := h.execSingle(ctx, w, opt, prefix, sqlstr, qtyp); err != nil {
-			return err
+	pipeName := opt.Params["pipe"]
+	// hash results to detect changes between iterations
+	var prev []byte
+	var hasher hash.Hash
+	if opt.WatchUntilChange {
+		hasher = fnv.New64a()
+		h.tee = hasher
+		defer func() { h.tee = nil }()
+	}
+	var reconnects int
+	for i := 1; ; i++ {
+		now := time.Now()
+		iter := opt
+		if pipeName != "" {
+			iter.Params = make(map[string]string, len(opt.Params))
+			for k, v := range opt.Params {
+				iter.Params[k] = v
+			}
+			iter.Params["pipe"] = strftime(pipeName, now)
+		} else {
+			// this is the actual output that psql has: "Mon Jan 2006 3:04:05 PM MST"
+			// fmt.Fprintf(w, "%s (every %fs)\n\n", now.Format("Mon Jan 2006 3:04:05 PM MST"), float64(opt.Watch)/float64(time.Second))
+			fmt.Fprintf(w, "%s (every %v)\n", now.Format(time.RFC1123), opt.Watch)
+			fmt.Fprintln(w)
+		}
+		err := text.ErrNotConnected
+		if h.db != nil {
+			err = h.execSingle(ctx, w, iter, prefix, sqlstr, qtyp)
+		}
+		if err != nil {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return nil
+			}
+			if reconnects >= opt.WatchReconnects || !h.connLost(ctx, err) {
+				return err
+			}
+			reconnects++
+			fmt.Fprintln(h.l.Stderr(), "error:", fmt.Sprintf(text.WatchReconnect, err, reconnects, opt.WatchReconnects))
+			if err := h.reconnect(ctx); err != nil {
+				fmt.Fprintln(h.l.Stderr(), "error:", err)
+			}
+		} else {
+			reconnects = 0
+		}
+		if hasher != nil {
+			sum := hasher.Sum(nil)
+			if prev != nil && !bytes.Equal(prev, sum) {
+				return nil
+			}
+			prev = sum
+			hasher.Reset()
+		}
+		if i == opt.WatchCount {
+			return nil
 		}
 		select {
 		case <-ctx.Done():
@@ -1014,27 +1736,123 @@ func (h *Handler) execSingle(ctx context.Context, w io.Writer, opt metacmd.Optio
 		f = h.query
 	}
 	// exec
+	h.timings = timings{}
 	start := time.Now()
 	if err := f(ctx, w, opt, prefix, sqlstr); err != nil {
 		return err
 	}
 	if h.timing {
 		d := time.Since(start)
+		if env.Pall()["timing_format"] == "json" {
+			return h.writeTiming(sqlstr, d)
+		}
 		format := text.TimingDesc
-		v := []interface{}{float64(d.Microseconds()) / 1000}
+		v := []interface{}{ms(d)}
 		if d > 1*time.Second {
 			format += " (%v)"
 			v = append(v, d.Round(1*time.Millisecond))
 		}
+		if h.timingVerbose {
+			format += text.TimingVerboseDesc
+			v = append(v, ms(h.timings.conn), ms(h.timings.exec), ms(h.timings.fetch))
+		}
+		// sub-microsecond durations would divide by (approximately) zero
+		if n, err := strconv.ParseInt(env.Get("ROW_COUNT"), 10, 64); err == nil && n > 0 && d >= time.Microsecond && env.Pall()["timing_throughput"] == "on" {
+			format += text.TimingThroughput
+			v = append(v, float64(n)/d.Seconds())
+		}
 		h.Print(format, v...)
 	}
 	return nil
 }
 
+// timings is the timing breakdown of an executed command.
+type timings struct {
+	// conn is the connection acquisition time.
+	conn time.Duration
+	// exec is the query execution time, until the driver call returns.
+	exec time.Duration
+	// fetch is the time spent fetching, scanning, and encoding rows.
+	fetch time.Duration
+}
+
+// ms returns d in milliseconds.
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// timingRecord is a timing written as a JSON object.
+type timingRecord struct {
+	Time        string   `json:"time"`
+	Statement   string   `json:"statement"`
+	Rows        int64    `json:"rows"`
+	DurationMS  float64  `json:"duration_ms"`
+	ConnMS      *float64 `json:"connection_ms,omitempty"`
+	ExecutionMS *float64 `json:"execution_ms,omitempty"`
+	FetchMS     *float64 `json:"fetch_ms,omitempty"`
+}
+
+// writeTiming writes the timing of an executed statement as a single line
+// JSON object to the timing_file, or to standard error when not set. The
+// statement is identified by a hash of its text, so that timings of the same
+// statement can be aggregated.
+func (h *Handler) writeTiming(sqlstr string, d time.Duration) error {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(sqlstr))
+	rec := timingRecord{
+		Time:       time.Now().Format(time.RFC3339Nano),
+		Statement:  fmt.Sprintf("%016x", hasher.Sum64()),
+		DurationMS: ms(d),
+	}
+	rec.Rows, _ = strconv.ParseInt(env.Get("ROW_COUNT"), 10, 64)
+	if h.timingVerbose {
+		conn, exec, fetch := ms(h.timings.conn), ms(h.timings.exec), ms(h.timings.fetch)
+		rec.ConnMS, rec.ExecutionMS, rec.FetchMS = &conn, &exec, &fetch
+	}
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	w := h.l.Stderr()
+	if name := env.Pall()["timing_file"]; name != "" {
+		f, err := env.OpenOutput(">>" + name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	_, err = fmt.Fprintln(w, string(buf))
+	return err
+}
+
+// queryer is the common interface for a sql.DB, sql.Tx, and sql.Conn.
+type queryer interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+}
+
+// conn returns the database connection to use for a query, recording the
+// connection acquisition time when verbose timing is enabled. The returned
+// func must be called after the query's results are no longer needed.
+func (h *Handler) conn(ctx context.Context) (queryer, func(), error) {
+	db, ok := h.DB().(*sql.DB)
+	if !ok || !h.timing || !h.timingVerbose {
+		return h.DB(), func() {}, nil
+	}
+	start := time.Now()
+	conn, err := db.Conn(ctx)
+	h.timings.conn = time.Since(start)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, func() { conn.Close() }, nil
+}
+
 // execSet executes a SQL query, setting all returned columns as variables.
 func (h *Handler) execSet(ctx context.Context, w io.Writer, opt metacmd.Option, prefix, sqlstr string, _ bool) error {
 	// query
-	rows, err := h.DB().QueryContext(ctx, sqlstr)
+	rows, err := h.DB().QueryContext(ctx, sqlstr, opt.Args...)
 	if err != nil {
 		return err
 	}
@@ -1070,21 +1888,118 @@ func (h *Handler) execSet(ctx context.Context, w io.Writer, opt metacmd.Option,
 	return nil
 }
 
+// execStore executes a SQL query, writing the raw bytes of the single
+// returned value to a file.
+func (h *Handler) execStore(ctx context.Context, w io.Writer, opt metacmd.Option, prefix, sqlstr string, _ bool) error {
+	// query
+	rows, err := h.DB().QueryContext(ctx, sqlstr, opt.Args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	// check cols
+	cols, err := rows.Columns()
+	switch {
+	case err != nil:
+		return err
+	case len(cols) != 1:
+		return text.ErrTooManyColumns
+	case !rows.Next():
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return text.ErrNoRows
+	}
+	// scan without copying the value, and write directly to the file
+	var buf sql.RawBytes
+	if err := rows.Scan(&buf); err != nil {
+		return err
+	}
+	name := opt.Params["file"]
+	f, err := os.OpenFile(name, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if rows.Next() {
+		os.Remove(name)
+		return text.ErrTooManyRows
+	}
+	return rows.Err()
+}
+
+// execDesc writes the names and types of the columns of a query's result,
+// without fetching any rows. The query is limited to 0 rows with the driver's
+// limit syntax (see drivers.Limit), and is never executed unmodified, as
+// doing so could fetch all rows or modify data.
+func (h *Handler) execDesc(ctx context.Context, w io.Writer, opt metacmd.Option, prefix, sqlstr string, qtyp bool) error {
+	if !qtyp {
+		fmt.Fprintln(w, text.NoResultColumns)
+		return nil
+	}
+	sqlstr, ok := drivers.Limit(h.u, sqlstr, 0)
+	if !ok {
+		return text.ErrCannotDescribeQuery
+	}
+	rows, err := h.DB().QueryContext(ctx, sqlstr, opt.Args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	cols, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	if len(cols) == 0 {
+		fmt.Fprintln(w, text.NoResultColumns)
+		return nil
+	}
+	v := make([]metadata.ResultColumn, len(cols))
+	for i, col := range cols {
+		typ := col.DatabaseTypeName()
+		if nullable, ok := col.Nullable(); ok && !nullable {
+			typ += " NOT NULL"
+		}
+		v[i] = metadata.ResultColumn{Name: col.Name(), Type: typ}
+	}
+	return h.encodeAll(w, metadata.NewResultColumnSet(v), env.Pall())
+}
+
+// execProfile executes a query with the database's EXPLAIN ANALYZE, writing
+// the resulting plan as a tree. Falls back to writing the unmodified results
+// of EXPLAIN on databases without structured execution plans.
+func (h *Handler) execProfile(ctx context.Context, w io.Writer, opt metacmd.Option, prefix, sqlstr string, qtyp bool) error {
+	plan, ok, err := drivers.Explain(ctx, h.u, h.DB(), sqlstr, opt.Args...)
+	switch {
+	case err != nil:
+		return err
+	case !ok:
+		return h.execSingle(ctx, w, opt, "EXPLAIN", "EXPLAIN "+sqlstr, true)
+	}
+	return explain.Write(w, plan)
+}
+
 // execExec executes a query and re-executes all columns of all rows as if they
 // were their own queries.
-func (h *Handler) execExec(ctx context.Context, w io.Writer, _ metacmd.Option, prefix, sqlstr string, qtyp bool) error {
+func (h *Handler) execExec(ctx context.Context, w io.Writer, opt metacmd.Option, prefix, sqlstr string, qtyp bool) error {
 	// query
-	rows, err := h.DB().QueryContext(ctx, sqlstr)
+	rows, err := h.DB().QueryContext(ctx, sqlstr, opt.Args...)
 	if err != nil {
 		return err
 	}
 	// execRows
-	if err := h.execRows(ctx, w, rows); err != nil {
+	if err := h.execRows(ctx, w, opt, rows); err != nil {
 		return err
 	}
 	// check for additional result sets ...
 	for rows.NextResultSet() {
-		if err := h.execRows(ctx, w, rows); err != nil {
+		if err := h.execRows(ctx, w, opt, rows); err != nil {
 			return err
 		}
 	}
@@ -1093,38 +2008,76 @@ func (h *Handler) execExec(ctx context.Context, w io.Writer, _ metacmd.Option, p
 
 // query executes a query against the database.
 func (h *Handler) query(ctx context.Context, w io.Writer, opt metacmd.Option, typ, sqlstr string) error {
+	db, release, err := h.conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
 	// run query
-	rows, err := h.DB().QueryContext(ctx, sqlstr)
+	start := time.Now()
+	rows, err := db.QueryContext(ctx, sqlstr, opt.Args...)
+	h.timings.exec = time.Since(start)
 	if err != nil {
 		return err
 	}
 	defer rows.Close()
+	defer func(start time.Time) {
+		h.timings.fetch = time.Since(start)
+	}(time.Now())
 	params := env.Pall()
 	params["time"] = env.GoTime()
 	for k, v := range opt.Params {
 		params[k] = v
 	}
-	var pipe io.WriteCloser
+	if opt.Exec == metacmd.ExecJSON {
+		params["format"] = "ndjson"
+	}
+	if params["format"] == "html" && params["title"] == "" {
+		// caption html tables with the query
+		params["title"] = strings.Join(strings.Fields(sqlstr), " ")
+	}
+	var pipe, clip io.WriteCloser
 	var cmd *exec.Cmd
-	if pipeName := params["pipe"]; pipeName != "" || h.out != nil {
-		if params["expanded"] == "auto" && params["columns"] == "" {
-			// don't rely on terminal size when piping output to a file or cmd
-			params["expanded"] = "off"
-		}
-		if pipeName != "" {
+	if pipeName := params["pipe"]; pipeName != "" || h.out != nil || opt.Clipboard {
+		switch {
+		case opt.Clipboard:
+			// copied to the clipboard once all results are written
+			if clip, err = env.Clipboard(); err != nil {
+				return err
+			}
+			w = clip
+		case pipeName != "":
 			if pipeName[0] == '|' {
 				pipe, cmd, err = env.Pipe(pipeName[1:])
 			} else {
-				pipe, err = os.OpenFile(pipeName, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0o644)
+				pipe, err = env.OpenOutput(pipeName)
 			}
 			if err != nil {
 				return err
 			}
+			// always close, so that output is flushed when canceled
+			defer func() {
+				pipe.Close()
+				if cmd != nil {
+					cmd.Wait()
+				}
+			}()
 			w = pipe
 		}
-	} else if opt.Exec != metacmd.ExecWatch {
+	} else if opt.Exec != metacmd.ExecWatch && opt.Exec != metacmd.ExecJSON && opt.Exec != metacmd.ExecExport && h.isTerminal() {
 		params["pager_cmd"] = env.All()["PAGER"]
 	}
+	if c, _ := strconv.Atoi(params["columns"]); params["expanded"] == "auto" && c == 0 {
+		// measure rows against the width of the terminal, using aligned
+		// output when piping output to a file or cmd, or when not writing to
+		// a terminal, so that output is deterministic
+		switch w := h.l.Width(); {
+		case pipe != nil || clip != nil || h.out != nil || w == 0:
+			params["expanded"] = "off"
+		default:
+			params["columns"] = strconv.Itoa(w)
+		}
+	}
 	// set up column type config
 	var extra []tblfmt.Option
 	switch f := drivers.ColumnTypes(h.u); {
@@ -1133,11 +2086,17 @@ func (h *Handler) query(ctx context.Context, w io.Writer, opt metacmd.Option, ty
 	case drivers.UseColumnTypes(h.u):
 		extra = append(extra, tblfmt.WithUseColumnTypes(true))
 	}
+	if n := drivers.BufferRows(h.u); n != 0 {
+		extra = append(extra, tblfmt.WithCount(n))
+	}
 	// wrap query with crosstab
 	resultSet := tblfmt.ResultSet(rows)
+	if opt.Limit != 0 {
+		resultSet = newLimitedView(resultSet, opt.Limit)
+	}
 	if opt.Exec == metacmd.ExecCrosstab {
 		var err error
-		resultSet, err = tblfmt.NewCrosstabView(rows, append(extra, tblfmt.WithParams(opt.Crosstab...))...)
+		resultSet, err = newCrosstabView(rows, opt.Crosstab, extra...)
 		if err != nil {
 			return err
 		}
@@ -1146,9 +2105,29 @@ func (h *Handler) query(ctx context.Context, w io.Writer, opt metacmd.Option, ty
 	if drivers.LowerColumnNames(h.u) {
 		params["lower_column_names"] = "true"
 	}
+	// decode strings from the client encoding
+	enc, name, err := env.Encoding(env.Get("ENCODING"))
+	if err != nil {
+		return err
+	}
+	if name != "UTF-8" {
+		resultSet = newDecodedView(resultSet, enc)
+	}
+	counter := &rowCounter{ResultSet: resultSet}
+	resultSet = counter
+	if opt.Exec == metacmd.ExecExport {
+		if err := h.export(resultSet, params, opt.Export); err != nil {
+			return err
+		}
+		h.returnedRows = counter.count
+		return env.Set("ROW_COUNT", strconv.FormatInt(counter.count, 10))
+	}
+	if h.tee != nil {
+		w = io.MultiWriter(w, h.tee)
+	}
 	// encode and handle error conditions
-	switch err := tblfmt.EncodeAll(w, resultSet, params, extra...); {
-	case err != nil && cmd != nil && errors.Is(err, syscall.EPIPE):
+	switch err := h.encodeAll(w, resultSet, params, extra...); {
+	case err != nil && (cmd != nil || params["pager_cmd"] != "") && errors.Is(err, syscall.EPIPE):
 		// broken pipe means pager quit before consuming all data, which might be expected
 		return nil
 	case err != nil && h.u.Driver == "sqlserver" && err == tblfmt.ErrResultSetHasNoColumns && strings.HasPrefix(typ, "EXEC"):
@@ -1160,17 +2139,37 @@ func (h *Handler) query(ctx context.Context, w io.Writer, opt metacmd.Option, ty
 	case params["format"] == "aligned":
 		fmt.Fprintln(w)
 	}
-	if pipe != nil {
-		pipe.Close()
-		if cmd != nil {
-			cmd.Wait()
+	if clip != nil {
+		if err := clip.Close(); err != nil {
+			return err
 		}
 	}
-	return err
+	h.returnedRows = counter.count
+	return env.Set("ROW_COUNT", strconv.FormatInt(counter.count, 10))
+}
+
+// rowCounter wraps a result set, counting the rows read.
+type rowCounter struct {
+	tblfmt.ResultSet
+	count int64
+}
+
+// Next satisfies the tblfmt.ResultSet interface.
+func (c *rowCounter) Next() bool {
+	if c.ResultSet.Next() {
+		c.count++
+		return true
+	}
+	return false
+}
+
+// ColumnTypes returns the column types of the wrapped result set.
+func (c *rowCounter) ColumnTypes() ([]*sql.ColumnType, error) {
+	return columnTypes(c.ResultSet)
 }
 
 // execRows executes all the columns in the row.
-func (h *Handler) execRows(ctx context.Context, w io.Writer, rows *sql.Rows) error {
+func (h *Handler) execRows(ctx context.Context, w io.Writer, opt metacmd.Option, rows *sql.Rows) error {
 	// get columns
 	cols, err := drivers.Columns(h.u, rows)
 	if err != nil {
@@ -1179,6 +2178,7 @@ func (h *Handler) execRows(ctx context.Context, w io.Writer, rows *sql.Rows) err
 	// process rows
 	res := metacmd.Option{Exec: metacmd.ExecOnly}
 	clen, tfmt := len(cols), env.GoTime()
+	confirm := env.Pall()["gexec_confirm"] == "on"
 	for rows.Next() {
 		if clen != 0 {
 			row, err := h.scan(rows, clen, tfmt)
@@ -1187,7 +2187,23 @@ func (h *Handler) execRows(ctx context.Context, w io.Writer, rows *sql.Rows) err
 			}
 			// execute
 			for _, sqlstr := range row {
-				if err = h.Execute(ctx, w, res, stmt.FindPrefix(sqlstr, true, true, true), sqlstr, false); err != nil {
+				prefix := stmt.FindPrefix(sqlstr, true, true, true)
+				switch {
+				case opt.DryRun:
+					fmt.Fprintln(w, strings.TrimRight(sqlstr, "; \t\r\n")+";")
+					continue
+				case confirm && isDestructive(prefix, sqlstr):
+					ok, all, err := h.confirmExec(sqlstr)
+					switch {
+					case err != nil:
+						return err
+					case !ok:
+						fmt.Fprintf(h.l.Stderr(), text.GexecSkipped+"\n", summarize(sqlstr, 60))
+						continue
+					}
+					confirm = !all
+				}
+				if err = h.Execute(ctx, w, res, prefix, sqlstr, false); err != nil {
 					return err
 				}
 			}
@@ -1196,6 +2212,43 @@ func (h *Handler) execRows(ctx context.Context, w io.Writer, rows *sql.Rows) err
 	return nil
 }
 
+// whereRE matches a WHERE clause.
+var whereRE = regexp.MustCompile(`(?i)\bWHERE\b`)
+
+// isDestructive returns true when the statement drops or truncates an object,
+// or deletes all rows of a table (a DELETE without a WHERE clause).
+func isDestructive(prefix, sqlstr string) bool {
+	switch word, _, _ := strings.Cut(prefix, " "); word {
+	case "DROP", "TRUNCATE":
+		return true
+	case "DELETE":
+		return !whereRE.MatchString(sqlstr)
+	}
+	return false
+}
+
+// confirmExec prompts to confirm executing a destructive \gexec statement,
+// returning whether to execute it, and whether to execute all remaining
+// statements without prompting. Statements cannot be confirmed when not
+// interactive.
+func (h *Handler) confirmExec(sqlstr string) (bool, bool, error) {
+	if !h.l.Interactive() {
+		return false, false, text.ErrGexecNotConfirmed
+	}
+	h.l.Prompt(fmt.Sprintf(text.GexecConfirm, summarize(sqlstr, 60)))
+	r, err := h.l.Next()
+	if err != nil {
+		return false, false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(string(r))) {
+	case "y", "yes":
+		return true, false, nil
+	case "a", "all":
+		return true, true, nil
+	}
+	return false, false, nil
+}
+
 // scan scans a row.
 func (h *Handler) scan(rows *sql.Rows, clen int, tfmt string) ([]string, error) {
 	// scan to []interface{}
@@ -1252,8 +2305,15 @@ func (h *Handler) scan(rows *sql.Rows, clen int, tfmt string) ([]string, error)
 }
 
 // exec does a database exec.
-func (h *Handler) exec(ctx context.Context, w io.Writer, _ metacmd.Option, typ, sqlstr string) error {
-	res, err := h.DB().ExecContext(ctx, sqlstr)
+func (h *Handler) exec(ctx context.Context, w io.Writer, opt metacmd.Option, typ, sqlstr string) error {
+	db, release, err := h.conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+	start := time.Now()
+	res, err := db.ExecContext(ctx, sqlstr, opt.Args...)
+	h.timings.exec = time.Since(start)
 	if err != nil {
 		_ = env.Set("ROW_COUNT", "0")
 		return err
@@ -1284,6 +2344,10 @@ func (h *Handler) BeginTx(ctx context.Context, txOpts *sql.TxOptions) error {
 	if h.db == nil {
 		return text.ErrNotConnected
 	}
+	if h.singleTx {
+		fmt.Fprintln(h.l.Stderr(), fmt.Sprintf(text.TransactionIgnored, `\begin`))
+		return nil
+	}
 	if h.tx != nil {
 		return text.ErrPreviousTransactionExists
 	}
@@ -1300,6 +2364,10 @@ func (h *Handler) Commit() error {
 	if h.db == nil {
 		return text.ErrNotConnected
 	}
+	if h.singleTx {
+		fmt.Fprintln(h.l.Stderr(), fmt.Sprintf(text.TransactionIgnored, `\commit`))
+		return nil
+	}
 	if h.tx == nil {
 		return text.ErrNoPreviousTransactionExists
 	}
@@ -1316,6 +2384,10 @@ func (h *Handler) Rollback() error {
 	if h.db == nil {
 		return text.ErrNotConnected
 	}
+	if h.singleTx {
+		fmt.Fprintln(h.l.Stderr(), fmt.Sprintf(text.TransactionIgnored, `\rollback`))
+		return nil
+	}
 	if h.tx == nil {
 		return text.ErrNoPreviousTransactionExists
 	}
@@ -1327,18 +2399,162 @@ func (h *Handler) Rollback() error {
 	return nil
 }
 
+// SingleTransaction runs f in a single transaction, committing the
+// transaction when f succeeds, and otherwise rolling it back. When already in
+// a transaction, f is run in the existing transaction.
+func (h *Handler) SingleTransaction(ctx context.Context, f func() error) error {
+	if h.tx != nil {
+		return f()
+	}
+	if err := h.BeginTx(ctx, nil); err != nil {
+		return err
+	}
+	h.singleTx = true
+	err := f()
+	h.singleTx = false
+	switch {
+	case h.tx == nil:
+		return err
+	case err != nil:
+		_ = h.Rollback()
+		return err
+	}
+	return h.Commit()
+}
+
+// isTransactionStmt returns true when the statement only begins or ends a
+// transaction.
+func isTransactionStmt(sqlstr string) bool {
+	if strings.Contains(strings.TrimRight(strings.TrimSpace(sqlstr), "; \t\r\n"), ";") {
+		return false
+	}
+	words := strings.Fields(stmt.FindPrefix(sqlstr, true, true, true))
+	if len(words) == 0 {
+		return false
+	}
+	next := ""
+	if len(words) > 1 {
+		next = words[1]
+	}
+	switch words[0] {
+	case "BEGIN":
+		switch next {
+		case "", "TRANSACTION", "TRAN", "WORK", "ISOLATION", "READ", "DEFERRED", "IMMEDIATE", "EXCLUSIVE":
+			return true
+		}
+	case "START":
+		return next == "TRANSACTION"
+	case "COMMIT", "END", "ABORT", "ROLLBACK":
+		switch next {
+		case "", "TRANSACTION", "TRAN", "WORK":
+			return len(words) < 3 || words[2] != "TO"
+		}
+	}
+	return false
+}
+
 // Include includes the specified path.
 func (h *Handler) Include(path string, relative bool) error {
-	if relative && !filepath.IsAbs(path) {
-		path = filepath.Join(h.wd, path)
+	var rd io.Reader
+	var err error
+	wd, name := h.wd, path
+	switch {
+	case path == "-":
+		rd, name = os.Stdin, "<stdin>"
+	case env.IsURL(path):
+		if rd, err = env.OpenURL(path); err != nil {
+			return err
+		}
+	default:
+		if relative && !filepath.IsAbs(path) {
+			path = filepath.Join(h.wd, path)
+		}
+		// open
+		var f *os.File
+		if path, f, err = env.OpenFile(h.user, path, relative); err != nil {
+			return err
+		}
+		defer f.Close()
+		rd, wd = f, filepath.Dir(path)
+	}
+	p := h.sub(rd, wd)
+	p.src, p.stack = name, h.stack
+	if h.src != "" {
+		p.stack = append(append([]string(nil), h.stack...), fmt.Sprintf("%s:%d", h.src, h.buf.Lines()))
+	}
+	// summarize the timings of the outermost file, including the statements
+	// of nested files, when timing_summary is on once the file has run
+	if p.timingSummary == nil {
+		p.timingSummary = &timingSummary{}
+		defer func() {
+			if s, _ := env.Pget("timing_summary"); s == "on" {
+				p.timingSummary.write(h.l.Stderr())
+			}
+		}()
 	}
-	// open
-	path, f, err := env.OpenFile(h.user, path, relative)
-	if err != nil {
+	run := p.Run
+	if single, _ := env.Pget("single_transaction"); single == "on" {
+		run = func() error {
+			return p.SingleTransaction(context.Background(), p.Run)
+		}
+	}
+	err = run()
+	h.db, h.u, h.tx = p.db, p.u, p.tx
+	return err
+}
+
+// posErr wraps err with the position of the line when reading an included
+// file.
+func (h *Handler) posErr(line int, err error) error {
+	var pe *PosError
+	if h.src == "" || errors.As(err, &pe) {
 		return err
 	}
-	defer f.Close()
-	r := bufio.NewReader(f)
+	return &PosError{Pos: fmt.Sprintf("%s:%d", h.src, line), Stack: h.stack, Err: err}
+}
+
+// printErr displays err, with the position of the line when reading an
+// included file.
+func (h *Handler) printErr(line int, err error) {
+	PrintError(h.l.Stderr(), h.posErr(line, err))
+}
+
+// RunString runs the queries and commands in the string, as if they were
+// included from a file. A final query not terminated by a semicolon is also
+// executed.
+func (h *Handler) RunString(s string) error {
+	p := h.sub(strings.NewReader(s), h.wd)
+	p.out = h.out
+	err := p.Run()
+	if err == nil && p.buf.Len != 0 && p.db != nil {
+		p.last, p.lastPrefix, p.lastRaw = p.buf.String(), p.buf.Prefix, p.buf.RawString()
+		p.buf.Reset(nil)
+		out := p.l.Stdout()
+		if p.out != nil {
+			out = p.out
+		}
+		echo := env.Get("ECHO")
+		if echo == "queries" || echo == "all" {
+			p.echo(text.EchoQuery, p.last)
+		}
+		ctx, exit, stop := p.interruptContext()
+		err = p.executeInterruptible(ctx, exit, out, metacmd.Option{}, p.lastPrefix, p.last, false)
+		if err != nil && ctx.Err() != nil && errors.Is(err, context.Canceled) {
+			err = text.ErrQueryCanceled
+		}
+		stop()
+		if err != nil && echo == "errors" {
+			p.echo(text.EchoFailedQuery, p.last)
+		}
+	}
+	h.db, h.u, h.tx, h.out = p.db, p.u, p.tx, p.out
+	return err
+}
+
+// sub creates a handler for reading commands from the reader, sharing the
+// handler's connection.
+func (h *Handler) sub(rd io.Reader, wd string) *Handler {
+	r := bufio.NewReader(rd)
 	// setup rline
 	l := &rline.Rline{
 		N: func() ([]rune, error) {
@@ -1373,13 +2589,13 @@ func (h *Handler) Include(path string, relative bool) error {
 		Out: h.l.Stdout(),
 		Err: h.l.Stderr(),
 		Pw:  h.l.Password,
+		W:   h.l.Width,
 	}
-	p := New(l, h.user, filepath.Dir(path), h.nopw)
-	p.db, p.u = h.db, h.u
+	p := New(l, h.user, wd, h.nopw)
+	p.db, p.u, p.tx, p.singleTx, p.conns = h.db, h.u, h.tx, h.singleTx, h.conns
+	p.timingSummary, p.metadataCache = h.timingSummary, h.metadataCache
 	drivers.ConfigStmt(p.u, p.buf)
-	err = p.Run()
-	h.db, h.u = p.db, p.u
-	return err
+	return p
 }
 
 // MetadataWriter loads the metadata writer for the
@@ -1387,7 +2603,20 @@ func (h *Handler) MetadataWriter(ctx context.Context) (metadata.Writer, error) {
 	if h.db == nil {
 		return nil, text.ErrNotConnected
 	}
-	return drivers.NewMetadataWriter(ctx, h.u, h.db, h.l.Stdout(), readerOpts()...)
+	return drivers.NewMetadataWriter(ctx, h.u, h.metadataCache.DB(h.db), h.l.Stdout(), readerOpts()...)
+}
+
+// MetadataReader loads the metadata reader for the current connection.
+func (h *Handler) MetadataReader(ctx context.Context) (metadata.Reader, error) {
+	if h.db == nil {
+		return nil, text.ErrNotConnected
+	}
+	return drivers.NewMetadataReader(ctx, h.u, h.metadataCache.DB(h.db), h.l.Stdout(), readerOpts()...)
+}
+
+// RefreshMetadata clears the cached metadata of the current connection.
+func (h *Handler) RefreshMetadata() {
+	h.metadataCache.Clear()
 }
 
 // GetOutput gets the output writer.
@@ -1398,6 +2627,15 @@ func (h *Handler) GetOutput() io.Writer {
 	return h.out
 }
 
+// isTerminal determines if the standard output is a terminal, and thus if
+// results can be sent to the pager.
+func (h *Handler) isTerminal() bool {
+	if f, ok := h.l.Stdout().(*os.File); ok && f != os.Stdout {
+		return isatty.IsTerminal(f.Fd())
+	}
+	return h.l.Cygwin() || isatty.IsTerminal(os.Stdout.Fd())
+}
+
 // SetOutput sets the output writer.
 func (h *Handler) SetOutput(o io.WriteCloser) {
 	if h.out != nil {
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 	"unicode"
 )
 
@@ -29,3 +31,54 @@ func lastcolor(s string) string {
 	}
 	return strings.Join(ansiRE.FindAllString(s, -1), "")
 }
+
+// strftime formats t using strftime-like tokens in s.
+func strftime(s string, t time.Time) string {
+	var b strings.Builder
+	r := []rune(s)
+	for i := 0; i < len(r); i++ {
+		if r[i] != '%' || i == len(r)-1 {
+			b.WriteRune(r[i])
+			continue
+		}
+		i++
+		switch r[i] {
+		case 'Y':
+			b.WriteString(t.Format("2006"))
+		case 'y':
+			b.WriteString(t.Format("06"))
+		case 'm':
+			b.WriteString(t.Format("01"))
+		case 'd':
+			b.WriteString(t.Format("02"))
+		case 'H':
+			b.WriteString(t.Format("15"))
+		case 'I':
+			b.WriteString(t.Format("03"))
+		case 'M':
+			b.WriteString(t.Format("04"))
+		case 'S':
+			b.WriteString(t.Format("05"))
+		case 'p':
+			b.WriteString(t.Format("PM"))
+		case 'b':
+			b.WriteString(t.Format("Jan"))
+		case 'a':
+			b.WriteString(t.Format("Mon"))
+		case 'j':
+			b.WriteString(t.Format("002"))
+		case 'Z':
+			b.WriteString(t.Format("MST"))
+		case 'z':
+			b.WriteString(t.Format("-0700"))
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case '%':
+			b.WriteRune('%')
+		default:
+			b.WriteRune('%')
+			b.WriteRune(r[i])
+		}
+	}
+	return b.String()
+}
@@ -8,17 +8,25 @@ package internal
 // tags.
 func KnownBuildTags() map[string]string {
 	return map[string]string{
-		"cassandra": "cql",       // github.com/MichaelS11/go-cql-driver
-		"cosmos":    "cosmos",    // github.com/btnguyen2k/gocosmos
-		"csvq":      "csvq",      // github.com/mithrandie/csvq-driver
-		"mymysql":   "mymysql",   // github.com/ziutek/mymysql/godrv
-		"mysql":     "mysql",     // github.com/go-sql-driver/mysql
-		"odbc":      "odbc",      // github.com/alexbrainman/odbc
-		"oracle":    "oracle",    // github.com/sijms/go-ora/v2
-		"postgres":  "postgres",  // github.com/lib/pq
-		"ql":        "ql",        // modernc.org/ql
-		"snowflake": "snowflake", // github.com/snowflakedb/gosnowflake
-		"sqlite3":   "sqlite3",   // github.com/mattn/go-sqlite3
-		"sqlserver": "sqlserver", // github.com/microsoft/go-mssqldb
+		"bigquery":   "bigquery",    // cloud.google.com/go/bigquery
+		"cassandra":  "cql",         // github.com/MichaelS11/go-cql-driver
+		"clickhouse": "clickhouse",  // github.com/ClickHouse/clickhouse-go/v2
+		"cosmos":     "cosmos",      // github.com/btnguyen2k/gocosmos
+		"csvq":       "csvq",        // github.com/mithrandie/csvq-driver
+		"duckdb":     "duckdb",      // github.com/marcboeker/go-duckdb
+		"firebird":   "firebirdsql", // github.com/nakagami/firebirdsql
+		"godror":     "godror",      // github.com/godror/godror
+		"influxdb":   "influxdb",    // github.com/apache/arrow/go/v14/arrow/flight/flightsql
+		"mongodb":    "mongodb",     // go.mongodb.org/mongo-driver
+		"mymysql":    "mymysql",     // github.com/ziutek/mymysql/godrv
+		"mysql":      "mysql",       // github.com/go-sql-driver/mysql
+		"odbc":       "odbc",        // github.com/alexbrainman/odbc
+		"oracle":     "oracle",      // github.com/sijms/go-ora/v2
+		"postgres":   "postgres",    // github.com/lib/pq
+		"ql":         "ql",          // modernc.org/ql
+		"snowflake":  "snowflake",   // github.com/snowflakedb/gosnowflake
+		"sqlite3":    "sqlite3",     // github.com/mattn/go-sqlite3
+		"sqlserver":  "sqlserver",   // github.com/microsoft/go-mssqldb
+		"trino":      "trino",       // github.com/trinodb/trino-go-client
 	}
 }
@@ -0,0 +1,97 @@
+// Package jsonenc contains the JSON encoding of result values shared by the
+// JSON output formats and the JSON Lines export of \copy.
+package jsonenc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Value converts a scanned value of the database type typ (upper cased) to a
+// value suitable for JSON encoding, retaining numeric and boolean types, and
+// base64 encoding binary data. Time values are formatted with tfmt.
+func Value(v interface{}, typ, tfmt string) interface{} {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case []byte:
+		if IsBinaryType(typ) || !utf8.Valid(x) {
+			return base64.StdEncoding.EncodeToString(x)
+		}
+		return stringValue(string(x), typ)
+	case string:
+		return stringValue(x, typ)
+	case float32:
+		return floatValue(float64(x))
+	case float64:
+		return floatValue(x)
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, json.Number:
+		return x
+	case time.Time:
+		return x.Format(tfmt)
+	case fmt.Stringer:
+		return x.String()
+	}
+	// preserve values that can be natively marshaled (maps, slices, etc)
+	if _, err := json.Marshal(v); err == nil {
+		return v
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// stringValue returns s as a JSON number when typ is numeric, otherwise as a
+// string.
+func stringValue(s, typ string) interface{} {
+	if IsNumericType(typ) && json.Valid([]byte(s)) {
+		if _, err := json.Number(s).Float64(); err == nil {
+			return json.Number(s)
+		}
+	}
+	return s
+}
+
+// floatValue returns f, or its string representation when f cannot be
+// represented in JSON.
+func floatValue(f float64) interface{} {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Sprintf("%v", f)
+	}
+	return f
+}
+
+// Marshal marshals v as JSON, without escaping HTML characters.
+func Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
+
+// IsBinaryType returns true when the database type name is a binary type.
+func IsBinaryType(typ string) bool {
+	for _, s := range []string{"BYTEA", "BLOB", "BINARY", "RAW", "IMAGE"} {
+		if strings.Contains(typ, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNumericType returns true when the database type name is a numeric type.
+func IsNumericType(typ string) bool {
+	for _, s := range []string{"INT", "NUMERIC", "DECIMAL", "NUMBER", "FLOAT", "DOUBLE", "REAL", "MONEY"} {
+		if strings.Contains(typ, s) && !strings.Contains(typ, "INTERVAL") && !strings.Contains(typ, "POINT") {
+			return true
+		}
+	}
+	return false
+}
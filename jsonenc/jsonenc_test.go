@@ -0,0 +1,38 @@
+package jsonenc
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		typ string
+		v   interface{}
+		exp string
+	}{
+		{"NUMERIC", nil, `null`},
+		{"NUMERIC", []byte("12.50"), `12.50`},
+		{"NUMERIC", "NaN", `"NaN"`},
+		{"BYTEA", []byte("ab"), `"YWI="`},
+		{"TEXT", []byte{0xff, 0xfe}, `"//4="`},
+		{"TEXT", []byte("text"), `"text"`},
+		{"TEXT", "12", `"12"`},
+		{"TEXT", "<a & b>", `"<a & b>"`},
+		{"", int64(7), `7`},
+		{"", true, `true`},
+		{"", 1.5, `1.5`},
+		{"", math.Inf(1), `"+Inf"`},
+		{"", time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), `"2020-01-02T03:04:05Z"`},
+	}
+	for i, test := range tests {
+		b, err := Marshal(Value(test.v, test.typ, time.RFC3339))
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if string(b) != test.exp {
+			t.Errorf("test %d expected %s, got: %s", i, test.exp, string(b))
+		}
+	}
+}
@@ -51,7 +51,7 @@ func main() {
 	if err != nil && err != io.EOF && err != rline.ErrInterrupt {
 		var he *handler.Error
 		if !errors.As(err, &he) {
-			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			handler.PrintError(os.Stderr, err)
 		}
 		var e *drivers.Error
 		if errors.As(err, &e) && e.Err == text.ErrDriverNotAvailable {
@@ -69,6 +69,12 @@ func main() {
 			}
 			fmt.Fprintf(os.Stderr, "\ntry:\n\n  go install -tags %s github.com/rmasci/usql@%s\n\n", tag, rev)
 		}
+		switch {
+		case errors.Is(err, text.ErrExitOnRows), errors.Is(err, text.ErrExitOnEmpty):
+			os.Exit(2)
+		case errors.Is(err, text.ErrInterrupted):
+			os.Exit(130)
+		}
 		os.Exit(1)
 	}
 }
@@ -131,6 +137,10 @@ func run(args *Args, u *user.User) error {
 		return err
 	}
 	defer l.Close()
+	// saved connection profiles
+	if err = env.LoadConnections(env.ConnectionsFile(u)); err != nil {
+		return err
+	}
 	// create handler
 	h := handler.New(l, u, wd, args.NoPassword)
 	// force a password ...
@@ -145,14 +155,13 @@ func run(args *Args, u *user.User) error {
 	if err = h.Open(context.Background(), dsn); err != nil {
 		return err
 	}
-	// start transaction
-	if args.SingleTransaction {
-		if h.IO().Interactive() {
-			return text.ErrSingleTransactionCannotBeUsedWithInteractiveMode
-		}
-		if err = h.BeginTx(context.Background(), nil); err != nil {
-			return err
-		}
+	// single transaction
+	if args.SingleTransaction && h.IO().Interactive() {
+		return text.ErrSingleTransactionCannotBeUsedWithInteractiveMode
+	}
+	// user defined commands
+	if err = env.LoadCommands(env.CommandsFile(u)); err != nil {
+		return err
 	}
 	// rc file
 	if rc := env.RCFile(u); !args.NoRC && rc != "" {
@@ -165,13 +174,33 @@ func run(args *Args, u *user.User) error {
 	if len(args.CommandOrFiles) != 0 {
 		f = runCommandOrFiles(h, args.CommandOrFiles)
 	}
-	// run
+	// run, committing the transaction only when all statements succeed
+	if args.SingleTransaction {
+		run := f
+		f = func() error {
+			return h.SingleTransaction(context.Background(), run)
+		}
+	}
 	if err = f(); err != nil {
 		return err
 	}
-	// commit
-	if args.SingleTransaction {
-		return h.Commit()
+	return checkRowCount(h)
+}
+
+// checkRowCount returns an error when exit_on_rows or exit_on_empty is
+// enabled, based on the rows returned by the last executed statement. The
+// affected row counts of other statements are ignored.
+func checkRowCount(h *handler.Handler) error {
+	count, ok := h.ReturnedRows()
+	if !ok {
+		return nil
+	}
+	pvars := env.Pall()
+	switch {
+	case count != 0 && pvars["exit_on_rows"] == "on":
+		return text.ErrExitOnRows
+	case count == 0 && pvars["exit_on_empty"] == "on":
+		return text.ErrExitOnEmpty
 	}
 	return nil
 }
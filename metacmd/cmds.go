@@ -2,21 +2,30 @@ package metacmd
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"os/signal"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
+	"github.com/mattn/go-isatty"
 	"github.com/xo/dburl"
+	"github.com/rmasci/usql/copyfile"
 	"github.com/rmasci/usql/drivers"
+	"github.com/rmasci/usql/drivers/metadata"
 	"github.com/rmasci/usql/env"
+	"github.com/rmasci/usql/rline"
 	"github.com/rmasci/usql/text"
 )
 
@@ -26,9 +35,15 @@ type Cmd struct {
 	Name    string
 	Desc    Desc
 	Aliases map[string]Desc
+	// Usages are additional usages of the command, listed after its aliases.
+	Usages  []Desc
 	Process func(*Params) error
 }
 
+// defaultWatchReconnects is the default number of consecutive reconnection
+// attempts made by \watch after the connection is lost.
+const defaultWatchReconnects = 3
+
 // cmds is the set of commands.
 var cmds []Cmd
 
@@ -89,19 +104,83 @@ func init() {
 				return nil
 			},
 		},
+		ErrVerbose: {
+			Section: SectionGeneral,
+			Name:    "errverbose",
+			Desc:    Desc{"show most recent error message at maximum verbosity", ""},
+			Process: func(p *Params) error {
+				stderr := p.Handler.IO().Stderr()
+				err := p.Handler.LastError()
+				if err == nil {
+					fmt.Fprintln(stderr, text.NoPreviousError)
+					return nil
+				}
+				details := drivers.ErrDetails(err)
+				var n int
+				for _, d := range details {
+					n = max(n, len(d.Name))
+				}
+				for _, d := range details {
+					fmt.Fprintf(stderr, "%-*s %s\n", n+1, d.Name+":", d.Value)
+				}
+				return nil
+			},
+		},
 		ConnectionInfo: {
 			Section: SectionConnection,
 			Name:    "conninfo",
-			Desc:    Desc{"display information about the current database connection", ""},
+			Desc:    Desc{"display information about the current database connection", "[-json]"},
 			Process: func(p *Params) error {
-				if db, u := p.Handler.DB(), p.Handler.URL(); db != nil && u != nil {
-					p.Handler.Print(text.ConnInfo, u.Driver, u.DSN)
-				} else {
+				ok, opt, err := p.GetOptional(true)
+				switch {
+				case err != nil:
+					return err
+				case opt == "":
+				case ok && opt == "json":
+					return connInfoJSON(p)
+				default:
+					return fmt.Errorf(text.InvalidOption, opt)
+				}
+				db, u := p.Handler.DB(), p.Handler.URL()
+				if db == nil || u == nil {
 					p.Handler.Print(text.NotConnected)
+					return nil
+				}
+				p.Handler.Print(text.ConnInfo, u.Driver, u.DSN)
+				info, err := drivers.TLS(context.Background(), u, db)
+				switch {
+				case err != nil:
+					return err
+				case info == nil:
+					p.Handler.Print(text.ConnInfoTLSUnknown)
+				case !info.Encrypted:
+					p.Handler.Print(text.ConnInfoNoTLS)
+				default:
+					p.Handler.Print(text.ConnInfoTLS, cmp.Or(info.Version, "unknown"), cmp.Or(info.Cipher, "unknown"))
 				}
 				return nil
 			},
 		},
+		Encoding: {
+			Section: SectionConnection,
+			Name:    "encoding",
+			Desc:    Desc{"show or set client encoding", "[ENCODING]"},
+			Process: func(p *Params) error {
+				name, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case name != "":
+					return env.Set("ENCODING", name)
+				}
+				_, name, err = env.Encoding(env.Get("ENCODING"))
+				if err != nil {
+					return err
+				}
+				p.Handler.Print("%s", name)
+				return nil
+			},
+		},
 		Drivers: {
 			Section: SectionGeneral,
 			Name:    "drivers",
@@ -139,6 +218,7 @@ func init() {
 			Desc:    Desc{"connect to database url", "DSN"},
 			Aliases: map[string]Desc{
 				"c":       {"connect to database with driver and parameters", "DRIVER PARAMS..."},
+				"c ":      {"connect to database url as named connection (or switch to it)", "@NAME [DSN]"},
 				"connect": {},
 			},
 			Process: func(p *Params) error {
@@ -160,6 +240,42 @@ func init() {
 				return p.Handler.Close()
 			},
 		},
+		SetConnection: {
+			Section: SectionConnection,
+			Name:    "cset",
+			Desc:    Desc{"save a connection profile opened with \\c NAME, or delete it", "NAME [DSN]"},
+			Aliases: map[string]Desc{
+				"clist": {"list saved connection profiles", ""},
+			},
+			Process: func(p *Params) error {
+				if p.Name == "clist" {
+					out := p.Handler.IO().Stdout()
+					for _, name := range env.Connections() {
+						dsn, _ := env.Connection(name)
+						fmt.Fprintln(out, name, "=", "'"+redactString(dsn)+"'")
+					}
+					return nil
+				}
+				name, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case name == "":
+					return text.ErrMissingRequiredArgument
+				}
+				ok, dsn, err := p.GetOK(true)
+				switch {
+				case err != nil:
+					return err
+				case !ok:
+					return env.UnsetConnection(name)
+				}
+				if _, err := dburl.Parse(dsn); err != nil {
+					return err
+				}
+				return env.SetConnection(name, dsn)
+			},
+		},
 		Password: {
 			Section: SectionConnection,
 			Name:    "password",
@@ -184,14 +300,23 @@ func init() {
 		Exec: {
 			Section: SectionQueryExecute,
 			Name:    "g",
-			Desc:    Desc{"execute query (and send results to file or |pipe)", "[(OPTIONS)] [FILE] or ;"},
+			Desc:    Desc{"execute query (and send results to file or |pipe)", "[(OPTIONS)] [LIMIT] [FILE] or ;"},
 			Aliases: map[string]Desc{
-				"gexec":        {"execute query and execute each value of the result", ""},
+				"clip":         {"execute query and copy results to the clipboard", "[(OPTIONS)]"},
+				"export":       {"execute query and write results to files in multiple formats", "FORMAT=FILE..."},
+				"gdesc":        {"describe result of query, without executing it", ""},
+				"graph":        {"execute query and display a column of results as a bar chart", "[LABEL [VALUE]]"},
+				"gexec":        {"execute query and execute each value of the result (-n to only show them)", "[-n]"},
+				"gjson":        {"execute query and write results as newline-delimited JSON", "[FILE]"},
 				"gset":         {"execute query and store results in " + text.CommandName + " variables", "[PREFIX]"},
+				"gstore":       {"execute query and write the single resulting value to file as raw bytes", "FILE"},
 				"gx":           {`as \g, but forces expanded output mode`, `[(OPTIONS)] [FILE]`},
 				"G":            {`as \g, but forces vertical output mode`, `[(OPTIONS)] [FILE]`},
 				"crosstabview": {"execute query and display results in crosstab", "[(OPTIONS)] [COLUMNS]"},
-				"watch":        {"execute query every specified interval", "[(OPTIONS)] [DURATION]"},
+				"diff":         {"execute query on two connections (or the current one and the snapshot) and show the differing rows", "[@NAME [@NAME]] [KEY...]"},
+				"snapshot":     {`execute query and save results as the snapshot compared by \diff`, ""},
+				"profile":      {"execute query with EXPLAIN ANALYZE and display the plan as a tree", ""},
+				"watch":        {"execute query every specified interval (optionally count=N, until_change, reconnect=N)", "[(OPTIONS)] [DURATION] [FILE]"},
 			},
 			Process: func(p *Params) error {
 				p.Option.Exec = ExecOnly
@@ -202,8 +327,108 @@ func init() {
 						return err
 					}
 					p.Option.ParseParams(params, "pipe")
+					// a leading number is the row limit, followed by the file
+					if s, _ := env.Pget("g_limit"); s == "on" {
+						limit, name, _ := strings.Cut(p.Option.Params["pipe"], " ")
+						if n, err := strconv.Atoi(limit); err == nil && n > 0 {
+							p.Option.Limit, p.Option.Params["pipe"] = n, strings.TrimSpace(name)
+						}
+					}
+				case "clip":
+					params, err := p.GetAll(true)
+					if err != nil {
+						return err
+					}
+					if err := p.Option.ParseParams(params, "pipe"); err != nil {
+						return err
+					}
+					if p.Option.Params["pipe"] != "" {
+						return text.ErrWrongNumberOfArguments
+					}
+					p.Option.Clipboard = true
+				case "export":
+					p.Option.Exec = ExecExport
+					params, err := p.GetAll(true)
+					if err != nil {
+						return err
+					}
+					if len(params) == 0 {
+						return text.ErrMissingRequiredArgument
+					}
+					p.Option.Export = make(map[string]string, len(params))
+					for _, param := range params {
+						i := strings.Index(param, "=")
+						if i < 1 || i == len(param)-1 {
+							return text.ErrInvalidFormatOption
+						}
+						format, name := param[:i], param[i+1:]
+						if !exportFormatRE.MatchString(format) {
+							return text.ErrInvalidExportFormat
+						}
+						p.Option.Export[format] = name
+					}
+				case "gdesc":
+					p.Option.Exec = ExecDesc
+				case "profile":
+					p.Option.Exec = ExecProfile
+				case "diff":
+					p.Option.Exec = ExecDiff
+					params, err := p.GetAll(true)
+					if err != nil {
+						return err
+					}
+					for _, s := range params {
+						switch {
+						case !strings.HasPrefix(s, "@"):
+							p.Option.DiffKeys = append(p.Option.DiffKeys, s)
+						case len(p.Option.DiffKeys) != 0 || len(p.Option.DiffConns) == 2:
+							return text.ErrWrongNumberOfArguments
+						case len(s) == 1:
+							return text.ErrInvalidConnectionName
+						default:
+							p.Option.DiffConns = append(p.Option.DiffConns, s[1:])
+						}
+					}
+					// a single connection is compared to the current connection
+					if len(p.Option.DiffConns) == 1 {
+						p.Option.DiffConns = []string{"", p.Option.DiffConns[0]}
+					}
+				case "snapshot":
+					p.Option.Exec = ExecSnapshot
+				case "graph":
+					p.Option.Exec = ExecGraph
+					params, err := p.GetAll(true)
+					switch {
+					case err != nil:
+						return err
+					case len(params) > 2:
+						return text.ErrWrongNumberOfArguments
+					}
+					p.Option.Params = make(map[string]string)
+					for i, name := range []string{"label", "value"} {
+						if i < len(params) {
+							p.Option.Params[name] = params[i]
+						}
+					}
 				case "gexec":
 					p.Option.Exec = ExecExec
+					params, err := p.GetAll(true)
+					if err != nil {
+						return err
+					}
+					for _, s := range params {
+						if s != "-n" {
+							return fmt.Errorf(text.InvalidOption, s)
+						}
+						p.Option.DryRun = true
+					}
+				case "gjson":
+					p.Option.Exec = ExecJSON
+					params, err := p.GetAll(true)
+					if err != nil {
+						return err
+					}
+					p.Option.ParseParams(params, "pipe")
 				case "gset":
 					p.Option.Exec = ExecSet
 					params, err := p.GetAll(true)
@@ -211,6 +436,20 @@ func init() {
 						return err
 					}
 					p.Option.ParseParams(params, "prefix")
+					// check the prefix before executing the query
+					if s := p.Option.Params["prefix"]; s != "" && env.ValidIdentifier(s) != nil {
+						return fmt.Errorf(text.CouldNotSetVariable, s)
+					}
+				case "gstore":
+					p.Option.Exec = ExecStore
+					name, err := p.Get(true)
+					switch {
+					case err != nil:
+						return err
+					case name == "":
+						return text.ErrMissingRequiredArgument
+					}
+					p.Option.Params = map[string]string{"file": name}
 				case "G":
 					params, err := p.GetAll(true)
 					if err != nil {
@@ -240,21 +479,50 @@ func init() {
 				case "watch":
 					p.Option.Exec = ExecWatch
 					p.Option.Watch = 2 * time.Second
-					ok, s, err := p.GetOK(true)
-					switch {
-					case err != nil:
+					params, err := p.GetAll(true)
+					if err != nil {
 						return err
-					case ok:
-						d, err := time.ParseDuration(s)
-						if err != nil {
-							if f, err := strconv.ParseFloat(s, 64); err == nil {
-								d = time.Duration(f * float64(time.Second))
-							}
-						}
+					}
+					if err := p.Option.ParseParams(params, "pipe"); err != nil {
+						return err
+					}
+					// the first remaining parameter is the duration, followed by
+					// optional count=N, until_change, and reconnect=N, and an
+					// optional file or |pipe
+					p.Option.WatchReconnects = defaultWatchReconnects
+					if s, ok := p.Option.Params["pipe"]; ok {
+						s, pipe, _ := strings.Cut(s, " ")
+						d := parseDuration(s)
 						if d == 0 {
 							return text.ErrInvalidWatchDuration
 						}
 						p.Option.Watch = d
+						for {
+							opt, rest, _ := strings.Cut(strings.TrimSpace(pipe), " ")
+							if opt == "until_change" {
+								p.Option.WatchUntilChange = true
+							} else if v, ok := strings.CutPrefix(opt, "count="); ok {
+								n, err := strconv.Atoi(v)
+								if err != nil || n < 1 {
+									return text.ErrInvalidWatchCount
+								}
+								p.Option.WatchCount = n
+							} else if v, ok := strings.CutPrefix(opt, "reconnect="); ok {
+								n, err := strconv.Atoi(v)
+								if err != nil || n < 0 {
+									return text.ErrInvalidWatchReconnects
+								}
+								p.Option.WatchReconnects = n
+							} else {
+								break
+							}
+							pipe = rest
+						}
+						if pipe = strings.TrimSpace(pipe); pipe != "" {
+							p.Option.Params["pipe"] = pipe
+						} else {
+							delete(p.Option.Params, "pipe")
+						}
 					}
 				}
 				return nil
@@ -281,8 +549,12 @@ func init() {
 				}
 				// reset if no error
 				n, err := env.EditFile(p.Handler.User(), path, line, s)
-				if err != nil {
+				switch {
+				case err != nil:
 					return err
+				case path == "" && strings.TrimSpace(string(n)) == "":
+					// keep the buffer when the query was deleted
+					return nil
 				}
 				// save edited buffer to history
 				p.Handler.IO().Save(string(n))
@@ -413,9 +685,14 @@ func init() {
 				if err != nil {
 					return err
 				}
-				v, err := p.Get(true)
-				if err != nil {
+				ok, v, err := p.GetOK(true)
+				switch {
+				case err != nil:
 					return err
+				case n == "":
+					return text.ErrMissingRequiredArgument
+				case !ok:
+					return os.Unsetenv(n)
 				}
 				return os.Setenv(n, v)
 			},
@@ -423,15 +700,20 @@ func init() {
 		Timing: {
 			Section: SectionOperatingSystem,
 			Name:    "timing",
-			Desc:    Desc{"toggle timing of commands", "[on|off]"},
+			Desc:    Desc{"toggle timing of commands", "[on|off|verbose]"},
 			Process: func(p *Params) error {
 				v, err := p.Get(true)
 				if err != nil {
 					return err
 				}
-				if v == "" {
+				p.Handler.SetTimingVerbose(false)
+				switch {
+				case v == "":
 					p.Handler.SetTiming(!p.Handler.GetTiming())
-				} else {
+				case v == "verbose":
+					p.Handler.SetTiming(true)
+					p.Handler.SetTimingVerbose(true)
+				default:
 					s, err := env.ParseBool(v, "\\timing")
 					if err != nil {
 						stderr := p.Handler.IO().Stderr()
@@ -445,7 +727,10 @@ func init() {
 					p.Handler.SetTiming(b)
 				}
 				setting := "off"
-				if p.Handler.GetTiming() {
+				switch {
+				case p.Handler.GetTimingVerbose():
+					setting = "verbose"
+				case p.Handler.GetTiming():
 					setting = "on"
 				}
 				p.Handler.Print(text.TimingSet, setting)
@@ -463,7 +748,7 @@ func init() {
 		Out: {
 			Section: SectionInputOutput,
 			Name:    "o",
-			Desc:    Desc{"send all query results to file or |pipe", "[FILE]"},
+			Desc:    Desc{"send all query results to file (>>FILE to append) or |pipe", "[FILE]"},
 			Aliases: map[string]Desc{"out": {}},
 			Process: func(p *Params) error {
 				if out := p.Handler.GetOutput(); out != nil {
@@ -481,7 +766,7 @@ func init() {
 				if pipe[0] == '|' {
 					out, _, err = env.Pipe(pipe[1:])
 				} else {
-					out, err = os.OpenFile(pipe, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0o644)
+					out, err = env.OpenOutput(pipe)
 				}
 				if err != nil {
 					return err
@@ -493,7 +778,7 @@ func init() {
 		Include: {
 			Section: SectionInputOutput,
 			Name:    "i",
-			Desc:    Desc{"execute commands from file", "FILE"},
+			Desc:    Desc{"execute commands from file, http(s) URL, or stdin (-)", "FILE"},
 			Aliases: map[string]Desc{
 				"ir":               {`as \i, but relative to location of current script`, `FILE`},
 				"include":          {},
@@ -506,11 +791,68 @@ func init() {
 				}
 				relative := p.Name == "ir" || p.Name == "include_relative"
 				if err := p.Handler.Include(path, relative); err != nil {
-					return fmt.Errorf("%s: %v", path, err)
+					return fmt.Errorf("%s: %w", path, err)
 				}
 				return nil
 			},
 		},
+		Conditional: {
+			Section: SectionConditional,
+			Name:    "if",
+			Desc:    Desc{"begin conditional block", "EXPR"},
+			Aliases: map[string]Desc{
+				"elif":  {"alternative within current conditional block", "EXPR"},
+				"else":  {"final alternative within current conditional block", ""},
+				"endif": {"end conditional block", ""},
+			},
+			Process: func(p *Params) error {
+				cond := p.Handler.Cond()
+				switch p.Name {
+				case "else":
+					return cond.Else()
+				case "endif":
+					return cond.Endif()
+				}
+				eval := func() (bool, error) {
+					vals, err := p.GetAll(true)
+					if err != nil {
+						return false, err
+					}
+					return condValue(p.Name, strings.Join(vals, " "))
+				}
+				// expressions are not evaluated in skipped branches
+				defer p.GetRaw()
+				if p.Name == "elif" {
+					return cond.Elif(eval)
+				}
+				return cond.If(eval)
+			},
+		},
+		Loop: {
+			Section: SectionConditional,
+			Name:    "loop",
+			Desc:    Desc{"repeat the commands up to \\endloop N times, setting LOOP_I", "N"},
+			Aliases: map[string]Desc{
+				"endloop": {"end loop", ""},
+			},
+			Process: func(p *Params) error {
+				if p.Name == "endloop" {
+					return text.ErrEndloopWithoutLoop
+				}
+				s, err := p.Get(true)
+				switch {
+				case err != nil:
+					return err
+				case s == "":
+					return text.ErrMissingRequiredArgument
+				}
+				n, err := strconv.Atoi(s)
+				if err != nil || n < 0 {
+					return text.ErrInvalidLoopCount
+				}
+				return p.Handler.Loop(n)
+			},
+		},
 		Transact: {
 			Section: SectionTransaction,
 			Name:    "begin",
@@ -575,7 +917,7 @@ func init() {
 		Prompt: {
 			Section: SectionVariables,
 			Name:    "prompt",
-			Desc:    Desc{"prompt user to set variable", "[-TYPE] <VAR> [PROMPT]"},
+			Desc:    Desc{"prompt user to set variable (-s for no echo)", "[-s|-TYPE] <VAR> [PROMPT]"},
 			Process: func(p *Params) error {
 				typ := "string"
 				ok, n, err := p.GetOptional(true)
@@ -584,6 +926,10 @@ func init() {
 				}
 				if ok {
 					typ = n
+					// silent entry, as psql
+					if typ == "s" {
+						typ = "password"
+					}
 					n, err = p.Get(true)
 					if err != nil {
 						return err
@@ -637,6 +983,32 @@ func init() {
 				return env.Set(n, strings.Join(vals, ""))
 			},
 		},
+		Eval: {
+			Section: SectionVariables,
+			Name:    "eval",
+			Desc:    Desc{"set internal variable to the result of an arithmetic or comparison expression", "NAME EXPR"},
+			Process: func(p *Params) error {
+				n, err := p.Get(true)
+				if err != nil {
+					return err
+				}
+				vals, err := p.GetAll(true)
+				switch {
+				case err != nil:
+					return err
+				case n == "" || len(vals) == 0:
+					return text.ErrMissingRequiredArgument
+				}
+				if err := env.ValidIdentifier(n); err != nil {
+					return err
+				}
+				v, err := env.Eval(strings.Join(vals, " "))
+				if err != nil {
+					return err
+				}
+				return env.Set(n, v)
+			},
+		},
 		Unset: {
 			Section: SectionVariables,
 			Name:    "unset",
@@ -649,6 +1021,47 @@ func init() {
 				return env.Unset(n)
 			},
 		},
+		NewCmd: {
+			Section: SectionVariables,
+			Name:    "newcmd",
+			Desc:    Desc{"define a command running a query template with arguments $1, $2, ..., or list all", "[NAME TEMPLATE]"},
+			Aliases: map[string]Desc{
+				"delcmd": {"delete a user defined command", "NAME"},
+			},
+			Process: func(p *Params) error {
+				if p.Name == "delcmd" {
+					name, err := p.Get(true)
+					if err != nil {
+						return err
+					}
+					return env.UnsetCommand(strings.TrimPrefix(name, `\`))
+				}
+				ok, name, err := p.GetOK(true)
+				switch {
+				case err != nil:
+					return err
+				case !ok:
+					out := p.Handler.IO().Stdout()
+					for _, name := range env.Commands() {
+						tmpl, _ := env.Command(name)
+						fmt.Fprintln(out, `\`+name, "=", "'"+tmpl+"'")
+					}
+					return nil
+				}
+				name = strings.TrimPrefix(name, `\`)
+				if _, ok := cmdMap[name]; ok {
+					return fmt.Errorf(text.BuiltinCommandName, name)
+				}
+				vals, err := p.GetAll(false)
+				switch {
+				case err != nil:
+					return err
+				case len(vals) == 0:
+					return text.ErrMissingRequiredArgument
+				}
+				return env.SetCommand(name, strings.Join(vals, " "))
+			},
+		},
 		SetFormatVar: {
 			Section: SectionFormatting,
 			Name:    "pset",
@@ -742,16 +1155,25 @@ func init() {
 			Name:    "d[S+]",
 			Desc:    Desc{"list tables, views, and sequences or describe table, view, sequence, or index", "[NAME]"},
 			Aliases: map[string]Desc{
-				"da[S+]": {"list aggregates", "[PATTERN]"},
-				"df[S+]": {"list functions", "[PATTERN]"},
-				"dm[S+]": {"list materialized views", "[PATTERN]"},
-				"dv[S+]": {"list views", "[PATTERN]"},
-				"ds[S+]": {"list sequences", "[PATTERN]"},
-				"dn[S+]": {"list schemas", "[PATTERN]"},
-				"dt[S+]": {"list tables", "[PATTERN]"},
-				"di[S+]": {"list indexes", "[PATTERN]"},
-				"dp[S]":  {"list table, view, and sequence access privileges", "[PATTERN]"},
-				"l[+]":   {"list databases", ""},
+				"da[S+]":     {"list aggregates", "[PATTERN]"},
+				"df[S+]":     {"list functions", "[PATTERN]"},
+				"dm[S+]":     {"list materialized views", "[PATTERN]"},
+				"dv[S+]":     {"list views", "[PATTERN]"},
+				"ds[S+]":     {"list sequences", "[PATTERN]"},
+				"dn[S+]":     {"list schemas", "[PATTERN]"},
+				"dt[S+]":     {"list tables", "[PATTERN]"},
+				"di[S+]":     {"list indexes", "[PATTERN]"},
+				"dos[S]":     {"search tables, views, columns, functions, and indexes", "PATTERN"},
+				"dp[S+]":     {"list table, view, and sequence access privileges", "[PATTERN]"},
+				"z[S+]":      {`same as \dp`, "[PATTERN]"},
+				"du[S+]":     {"list roles", "[PATTERN]"},
+				"droles[S+]": {`same as \du`, "[PATTERN]"},
+				"dconfig[+]": {"list configuration parameters", "[PATTERN]"},
+				"des[+]":     {"list foreign servers", "[PATTERN]"},
+				"det[+]":     {"list foreign tables", "[PATTERN]"},
+				"dew[+]":     {"list foreign-data wrappers", "[PATTERN]"},
+				"dx[+]":      {"list extensions", "[PATTERN]"},
+				"l[+]":       {"list databases", ""},
 			},
 			Process: func(p *Params) error {
 				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
@@ -775,7 +1197,14 @@ func init() {
 					return m.ListTables(p.Handler.URL(), "tvmsE", pattern, verbose, showSystem)
 				case "df", "da":
 					return m.DescribeFunctions(p.Handler.URL(), name, pattern, verbose, showSystem)
-				case "dt", "dtv", "dtm", "dts", "dv", "dm", "ds":
+				case "dm":
+					return m.ListMaterializedViews(p.Handler.URL(), pattern, verbose, showSystem)
+				case "ds":
+					if verbose {
+						return m.ListSequences(p.Handler.URL(), pattern, verbose, showSystem)
+					}
+					return m.ListTables(p.Handler.URL(), name, pattern, verbose, showSystem)
+				case "dt", "dtv", "dtm", "dts", "dv":
 					return m.ListTables(p.Handler.URL(), name, pattern, verbose, showSystem)
 				case "dn":
 					return m.ListSchemas(p.Handler.URL(), pattern, verbose, showSystem)
@@ -783,8 +1212,25 @@ func init() {
 					return m.ListIndexes(p.Handler.URL(), pattern, verbose, showSystem)
 				case "l":
 					return m.ListAllDbs(p.Handler.URL(), pattern, verbose)
-				case "dp":
-					return m.ListPrivilegeSummaries(p.Handler.URL(), pattern, showSystem)
+				case "dp", "z":
+					return m.ListPrivilegeSummaries(p.Handler.URL(), pattern, verbose, showSystem)
+				case "du", "droles":
+					return m.ListRoles(p.Handler.URL(), pattern, verbose, showSystem)
+				case "dconfig":
+					return m.ListSettings(p.Handler.URL(), pattern, verbose)
+				case "des":
+					return m.ListForeignServers(p.Handler.URL(), pattern, verbose)
+				case "det":
+					return m.ListForeignTables(p.Handler.URL(), pattern, verbose)
+				case "dew":
+					return m.ListForeignDataWrappers(p.Handler.URL(), pattern, verbose)
+				case "dx":
+					return m.ListExtensions(p.Handler.URL(), pattern, verbose)
+				case "dos":
+					if pattern == "" {
+						return text.ErrMissingRequiredArgument
+					}
+					return m.SearchObjects(p.Handler.URL(), pattern, showSystem)
 				}
 				return nil
 			},
@@ -827,6 +1273,63 @@ func init() {
 				return m.ShowStats(p.Handler.URL(), name, pattern, verbose, k)
 			},
 		},
+		ShowFunction: {
+			Section: SectionInformational,
+			Name:    "sf[+]",
+			Desc:    Desc{"show a function's definition", "FUNCNAME"},
+			Process: func(p *Params) error {
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				r, err := p.Handler.MetadataReader(ctx)
+				if err != nil {
+					return err
+				}
+				params, err := p.GetAll(true)
+				if err != nil {
+					return err
+				}
+				name := strings.Join(params, " ")
+				if name == "" {
+					return text.ErrMissingRequiredArgument
+				}
+				src, err := metadata.FunctionSource(r, name)
+				switch {
+				case err == text.ErrNotSupported:
+					return fmt.Errorf(text.NotSupportedByDriver, `\sf`, p.Handler.URL().Driver)
+				case err != nil:
+					return err
+				}
+				src = strings.TrimRight(src, "\n")
+				// the function body starts at the first line matching
+				// functionBodyRE, as psql
+				body := 0
+				for i, line := range strings.Split(src, "\n") {
+					if functionBodyRE.MatchString(line) {
+						body = i
+						break
+					}
+				}
+				if p.Handler.IO().Interactive() && env.All()["SYNTAX_HL"] == "true" {
+					b := new(bytes.Buffer)
+					if p.Handler.Highlight(b, src) == nil {
+						src = b.String()
+					}
+				}
+				out := p.Handler.GetOutput()
+				if !strings.ContainsRune(p.Name, '+') {
+					fmt.Fprintln(out, src)
+					return nil
+				}
+				for i, line := range strings.Split(strings.TrimRight(src, "\n"), "\n") {
+					if i < body {
+						fmt.Fprintln(out, "        "+line)
+						continue
+					}
+					fmt.Fprintf(out, "%-7d %s\n", i-body+1, line)
+				}
+				return nil
+			},
+		},
 		Copy: {
 			Section: SectionInputOutput,
 			Name:    "copy",
@@ -834,9 +1337,22 @@ func init() {
 			Aliases: map[string]Desc{
 				"copy": {"copy query from source url to columns of table on destination url", "SRC DST QUERY TABLE(A,...)"},
 			},
+			Usages: []Desc{
+				{"copy query results (or table) to file", "(QUERY) TO FILE [(OPTIONS)]"},
+				{"copy file to table (or columns of table)", "TABLE FROM FILE [(OPTIONS)]"},
+				{"copy query results (or table) between named connections", "(QUERY) ON @A TO TABLE ON @B"},
+			},
 			Process: func(p *Params) error {
 				ctx := context.Background()
 				stdout, stderr := p.Handler.IO().Stdout, p.Handler.IO().Stderr
+				// copy to or from a file
+				switch c, ok, err := copyfile.Parse(string(p.Params.R)); {
+				case err != nil:
+					return err
+				case ok:
+					p.GetRaw()
+					return copyFile(ctx, p, c)
+				}
 				srcDsn, err := p.Get(true)
 				if err != nil {
 					return err
@@ -887,6 +1403,141 @@ func init() {
 				return nil
 			},
 		},
+		Batch: {
+			Section: SectionQueryExecute,
+			Name:    "batch",
+			Desc:    Desc{"execute the statements up to \\endbatch, displaying a summary of each statement", "[continue|stop]"},
+			Aliases: map[string]Desc{
+				"endbatch": {"end batch", ""},
+			},
+			Process: func(p *Params) error {
+				if p.Name == "endbatch" {
+					return text.ErrEndbatchWithoutBatch
+				}
+				s, err := p.Get(true)
+				if err != nil {
+					return err
+				}
+				stop := env.All()["ON_ERROR_STOP"] == "on"
+				switch s {
+				case "":
+				case "continue":
+					stop = false
+				case "stop":
+					stop = true
+				default:
+					return fmt.Errorf(text.InvalidOption, s)
+				}
+				return p.Handler.Batch(stop)
+			},
+		},
+		Waitfor: {
+			Section: SectionQueryExecute,
+			Name:    "waitfor",
+			Desc:    Desc{"execute query until its value satisfies the condition (optionally timeout DURATION, interval DURATION)", "QUERY OP VALUE"},
+			Process: func(p *Params) error {
+				args, err := p.GetAll(true)
+				switch {
+				case err != nil:
+					return err
+				case len(args) < 3:
+					return text.ErrMissingRequiredArgument
+				case !waitforOps[args[1]]:
+					return fmt.Errorf(text.InvalidOption, args[1])
+				}
+				w := waitfor{query: args[0], op: args[1], value: args[2], interval: 2 * time.Second}
+				for opts := args[3:]; len(opts) != 0; opts = opts[2:] {
+					if len(opts) < 2 {
+						return text.ErrMissingRequiredArgument
+					}
+					d := parseDuration(opts[1])
+					if d == 0 {
+						return text.ErrInvalidWatchDuration
+					}
+					switch opts[0] {
+					case "timeout":
+						w.timeout = d
+					case "interval":
+						w.interval = d
+					default:
+						return fmt.Errorf(text.InvalidOption, opts[0])
+					}
+				}
+				db := p.Handler.DB()
+				if db == nil {
+					return text.ErrNotConnected
+				}
+				ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer cancel()
+				return w.run(ctx, db)
+			},
+		},
+		Refresh: {
+			Section: SectionInformational,
+			Name:    "refresh",
+			Desc:    Desc{"clear the metadata cached for describe commands and completion", ""},
+			Process: func(p *Params) error {
+				p.Handler.RefreshMetadata()
+				p.Handler.Print(text.MetadataRefreshed)
+				return nil
+			},
+		},
+		Menu: {
+			Section: SectionVariables,
+			Name:    "menu",
+			Desc:    Desc{"pick a user defined command by number, loading its query into the query buffer", ""},
+			Process: func(p *Params) error {
+				l := p.Handler.IO()
+				names := env.Commands()
+				switch {
+				case !l.Interactive():
+					return text.ErrNotInteractive
+				case len(names) == 0:
+					return text.ErrNoUserCommands
+				}
+				out := l.Stdout()
+				for i, name := range names {
+					tmpl, _ := env.Command(name)
+					fmt.Fprintf(out, "%*d) \\%s = '%s'\n", len(strconv.Itoa(len(names))), i+1, name, tmpl)
+				}
+				// read selection, prompting again until valid
+				var tmpl, name string
+				for tmpl == "" {
+					s, ok, err := readMenuLine(l, fmt.Sprintf(text.MenuPrompt, len(names)))
+					switch {
+					case err != nil:
+						return err
+					case !ok || s == "" || strings.EqualFold(s, "q"):
+						return nil
+					}
+					if i, err := strconv.Atoi(s); err == nil && 0 < i && i <= len(names) {
+						name = names[i-1]
+						tmpl, _ = env.Command(name)
+						continue
+					}
+					fmt.Fprintln(l.Stderr(), fmt.Sprintf(text.MenuInvalidChoice, s, len(names)))
+				}
+				// read arguments
+				args := make([]string, env.CommandArgs(tmpl))
+				for i := range args {
+					s, ok, err := readMenuLine(l, fmt.Sprintf(text.MenuArgPrompt, "$"+strconv.Itoa(i+1)))
+					switch {
+					case err != nil:
+						return err
+					case !ok:
+						return nil
+					}
+					args[i] = s
+				}
+				s, err := env.ExpandCommand(name, tmpl, args)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(out, s)
+				p.Handler.Buf().Reset([]rune(s))
+				return nil
+			},
+		},
 	}
 	// set up map
 	cmdMap = make(map[string]Metacmd, len(cmds))
@@ -924,3 +1575,420 @@ func init() {
 		sectMap[c.Section] = append(sectMap[c.Section], mc)
 	}
 }
+
+// copyFile copies rows between the current connection and a file.
+func copyFile(ctx context.Context, p *Params, c *copyfile.Copy) error {
+	u, conn := p.Handler.URL(), p.Handler.DB()
+	if c.Conn != "" {
+		db, z, err := p.Handler.NamedConn(c.Conn)
+		if err != nil {
+			return err
+		}
+		u, conn = z, db
+	}
+	if u == nil {
+		return text.ErrNotConnected
+	}
+	if c.Dest != "" {
+		return copyConns(ctx, p, c, conn)
+	}
+	opts, err := copyfile.NewOptions(c.Path, c.Params, env.Pall())
+	if err != nil {
+		return err
+	}
+	opts.TimeFormat = env.GoTime()
+	if err := c.MapColumns(opts); err != nil {
+		return err
+	}
+	if opts.Filter != nil && !c.From {
+		return fmt.Errorf(text.InvalidOption, "filter")
+	}
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt)
+	defer cancel()
+	if opts.Create {
+		if !c.From {
+			return fmt.Errorf(text.InvalidOption, "create")
+		}
+		if opts.Columns, err = c.Infer(ctx, opts); err != nil {
+			return err
+		}
+		query := copyfile.CreateTableQuery(c.Table, opts.Columns, func(typ string) string {
+			return drivers.DataType(u, typ)
+		})
+		p.Handler.Print("%s", query)
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	opts.Progress = newProgress(p, opts.ProgressInterval)
+	opts.Progress.Start()
+	defer opts.Progress.Stop()
+	var n int64
+	if c.From {
+		// copy using the current connection when not in a transaction, as
+		// in-memory and file locked databases cannot be opened a second time
+		db, _ := conn.(*sql.DB)
+		if db != nil && (len(c.Params) == 0 || len(c.Params) == 1 && c.Params["format"] != "") {
+			var ok bool
+			if n, ok, err = drivers.CopyFile(ctx, u, db, c.Path, opts.Format, c.Table, c.Columns); ok {
+				if err != nil {
+					return err
+				}
+				opts.Progress.Stop()
+				p.Handler.Print("COPY %d", n)
+				return nil
+			}
+		}
+		if opts.OnError == "continue" || opts.OnConflict != "" || opts.BatchSize != 0 || opts.RowsPerInsert != 1 {
+			insert := func(cols []string, rows int) (string, error) {
+				return copyfile.InsertQuery("INSERT INTO", c.Table, cols, rows, drivers.Placeholder(u)), nil
+			}
+			if opts.OnConflict != "" {
+				insert = func(cols []string, rows int) (string, error) {
+					return drivers.Upsert(u, c.Table, cols, opts.ConflictColumns, rows)
+				}
+			}
+			n, rejected, err := c.Import(ctx, conn, insert, opts)
+			if err != nil {
+				// rows imported before the error are committed
+				if n != 0 {
+					opts.Progress.Stop()
+					p.Handler.Print("COPY %d", n)
+				}
+				return err
+			}
+			opts.Progress.Stop()
+			switch {
+			case opts.OnError == "continue" && opts.Filter != nil:
+				p.Handler.Print(text.CopyRejectedFiltered, n, rejected, opts.Filter.Filtered())
+			case opts.OnError == "continue":
+				p.Handler.Print(text.CopyRejected, n, rejected)
+			case opts.Filter != nil:
+				p.Handler.Print(text.CopyFiltered, n, opts.Filter.Filtered())
+			default:
+				p.Handler.Print("COPY %d", n)
+			}
+			return nil
+		}
+		rows, closeRows, err := copyfile.Open(ctx, c.Path, opts)
+		if err != nil {
+			return err
+		}
+		defer closeRows()
+		table, err := c.InsertTable(rows)
+		if err != nil {
+			return err
+		}
+		switch {
+		case db != nil:
+			n, err = drivers.CopyDB(ctx, u, db, rows, table)
+		default:
+			n, err = drivers.Copy(ctx, u, p.Handler.IO().Stdout, p.Handler.IO().Stderr, rows, table)
+		}
+		if err != nil {
+			return err
+		}
+	} else {
+		// fetch the rows in batches from a server-side cursor, when supported
+		// by the driver
+		fetch, closeCursor, ok, err := drivers.Cursor(ctx, u, conn, c.SelectQuery(), opts.FetchSize)
+		switch {
+		case err != nil:
+			return err
+		case ok:
+			n, err = copyfile.WriteBatches(ctx, c.Path, fetch, opts)
+			if cerr := closeCursor(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+		default:
+			rows, err := conn.QueryContext(ctx, c.SelectQuery())
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			if n, err = copyfile.Write(ctx, c.Path, rows, opts); err != nil {
+				return err
+			}
+		}
+	}
+	opts.Progress.Stop()
+	if opts.Filter != nil {
+		p.Handler.Print(text.CopyFiltered, n, opts.Filter.Filtered())
+		return nil
+	}
+	p.Handler.Print("COPY %d", n)
+	return nil
+}
+
+// copyConns copies the results of the query (or table) to the table on the
+// destination connection.
+func copyConns(ctx context.Context, p *Params, c *copyfile.Copy, conn drivers.DB) error {
+	db, u, err := p.Handler.NamedConn(c.Dest)
+	if err != nil {
+		return err
+	}
+	opts, err := copyfile.NewTransferOptions(c.Params)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := signal.NotifyContext(ctx, os.Interrupt)
+	defer cancel()
+	rows, err := conn.QueryContext(ctx, c.SelectQuery())
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	progress := func(n int64) {
+		if env.Get("QUIET") != "on" {
+			fmt.Fprintln(p.Handler.IO().Stderr(), fmt.Sprintf(text.CopyProgress, n))
+		}
+	}
+	opts.Reporter = newProgress(p, opts.ProgressInterval)
+	opts.Reporter.Start()
+	n, err := copyfile.Transfer(ctx, rows, opts, func(rows *sql.Rows) (int64, error) {
+		return drivers.CopyDB(ctx, u, db, rows, c.Path)
+	}, progress)
+	opts.Reporter.Stop()
+	if err != nil {
+		// batches copied before the error are committed
+		if n != 0 {
+			p.Handler.Print("COPY %d", n)
+		}
+		return err
+	}
+	p.Handler.Print("COPY %d", n)
+	return nil
+}
+
+// newProgress creates the progress of a copy, written to stderr every
+// interval, or nil when the interval is 0 or QUIET is on.
+func newProgress(p *Params, interval time.Duration) *copyfile.Progress {
+	if interval == 0 || env.Get("QUIET") == "on" {
+		return nil
+	}
+	stderr := p.Handler.IO().Stderr()
+	f, ok := stderr.(*os.File)
+	return copyfile.NewProgress(stderr, ok && isatty.IsTerminal(f.Fd()), interval)
+}
+
+// connInfo is the connection information written by \conninfo -json.
+type connInfo struct {
+	Connected bool   `json:"connected"`
+	Driver    string `json:"driver,omitempty"`
+	Host      string `json:"host,omitempty"`
+	Port      string `json:"port,omitempty"`
+	DBName    string `json:"dbname,omitempty"`
+	User      string `json:"user,omitempty"`
+	DSN       string `json:"dsn,omitempty"`
+	// SSL is "on", "off", or "unknown" when not exposed by the driver.
+	SSL        string `json:"ssl,omitempty"`
+	SSLVersion string `json:"ssl_version,omitempty"`
+	SSLCipher  string `json:"ssl_cipher,omitempty"`
+}
+
+// connInfoJSON writes the current connection information as JSON.
+func connInfoJSON(p *Params) error {
+	var info connInfo
+	if db, u := p.Handler.DB(), p.Handler.URL(); db != nil && u != nil {
+		info = connInfo{
+			Connected: true,
+			Driver:    u.Driver,
+			DSN:       redactDSN(u),
+		}
+		switch {
+		case u.Opaque != "":
+			info.DBName = u.Opaque
+		case u.Scheme != "":
+			v := strings.Split(u.Normalize("\x00", "", 0), "\x00")
+			info.Host, info.Port, info.DBName, info.User = v[1], v[2], v[3], v[4]
+		}
+		tls, err := drivers.TLS(context.Background(), u, db)
+		switch {
+		case err != nil:
+			return err
+		case tls == nil:
+			info.SSL = "unknown"
+		case !tls.Encrypted:
+			info.SSL = "off"
+		default:
+			info.SSL, info.SSLVersion, info.SSLCipher = "on", tls.Version, tls.Cipher
+		}
+	}
+	buf, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	p.Handler.Print("%s", buf)
+	return nil
+}
+
+var (
+	// functionBodyRE matches the first line of a function body in a function
+	// definition.
+	functionBodyRE = regexp.MustCompile(`^(AS|BEGIN|RETURN)\b`)
+	// passwordKeyRE matches password key/value pairs in a DSN.
+	passwordKeyRE = regexp.MustCompile(`(?i)\b(password|passwd|pwd|pass)(\s*=\s*)('[^']*'|"[^"]*"|[^\s;&]*)`)
+	// passwordUserinfoRE matches the password in a DSN's user info.
+	passwordUserinfoRE = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*://)?([^:@/\s]*):([^@/\s]+)@`)
+	// exportFormatRE matches the formats supported by \export.
+	exportFormatRE = regexp.MustCompile(`^(unaligned|aligned|wrapped|html|asciidoc|latex|latex-longtable|troff-ms|csv|json|vertical|ndjson|fwf|yaml)$`)
+)
+
+// redactDSN returns the DSN of the URL with any password redacted, handling
+// URLs built from a raw DSN string.
+func redactDSN(u *dburl.URL) string {
+	dsn := u.DSN
+	if u.Scheme != "" {
+		// regenerate the DSN without the password
+		v := *u
+		if v.User != nil {
+			v.User = url.User(v.User.Username())
+		}
+		if z, err := dburl.Parse(v.String()); err == nil {
+			dsn = z.DSN
+		}
+	}
+	dsn = redactString(dsn)
+	// ensure the password is never present
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok && pass != "" {
+			for _, s := range []string{pass, url.QueryEscape(pass), url.PathEscape(pass)} {
+				dsn = strings.ReplaceAll(dsn, s, "xxxxx")
+			}
+		}
+	}
+	return dsn
+}
+
+// redactString returns the URL or DSN string with any password redacted.
+func redactString(s string) string {
+	s = passwordKeyRE.ReplaceAllString(s, "${1}${2}xxxxx")
+	return passwordUserinfoRE.ReplaceAllString(s, "${1}${2}:xxxxx@")
+}
+
+// parseDuration parses a duration, or a number of seconds, returning 0 when
+// invalid.
+func parseDuration(s string) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			d = time.Duration(f * float64(time.Second))
+		}
+	}
+	return d
+}
+
+// waitforOps are the comparison operators of a \waitfor condition.
+var waitforOps = map[string]bool{
+	"=": true, "==": true, "!=": true, "<>": true,
+	"<": true, "<=": true, ">": true, ">=": true,
+}
+
+// waitfor is a \waitfor condition.
+type waitfor struct {
+	query    string
+	op       string
+	value    string
+	interval time.Duration
+	timeout  time.Duration
+}
+
+// run executes the query every interval until its value satisfies the
+// condition, returning an error when the timeout elapses.
+func (w waitfor) run(ctx context.Context, db drivers.DB) error {
+	if w.timeout != 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.timeout)
+		defer cancel()
+	}
+	last := "NULL"
+	for {
+		var v sql.NullString
+		err := db.QueryRowContext(ctx, w.query).Scan(&v)
+		switch {
+		case errors.Is(ctx.Err(), context.DeadlineExceeded):
+			return fmt.Errorf(text.WaitforTimeout, w.timeout, last)
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case err != nil && !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+		if last = "NULL"; v.Valid {
+			last = v.String
+		}
+		if w.match(v) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(w.interval):
+		}
+	}
+}
+
+// match returns true when the value satisfies the condition. Values are
+// compared as numbers when both are numeric, and otherwise as strings. A
+// NULL value (or no row) only matches the value NULL.
+func (w waitfor) match(v sql.NullString) bool {
+	if null := strings.EqualFold(w.value, "NULL"); !v.Valid || null {
+		eq := !v.Valid && null
+		switch w.op {
+		case "=", "==":
+			return eq
+		case "!=", "<>":
+			return !eq
+		}
+		return false
+	}
+	c := strings.Compare(v.String, w.value)
+	x, errX := strconv.ParseFloat(v.String, 64)
+	y, errY := strconv.ParseFloat(w.value, 64)
+	if errX == nil && errY == nil {
+		c = cmp.Compare(x, y)
+	}
+	switch w.op {
+	case "=", "==":
+		return c == 0
+	case "!=", "<>":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	}
+	return c >= 0
+}
+
+// condValue evaluates the expression of a conditional block command, which is
+// either a boolean value or an expression evaluated by env.Eval.
+func condValue(name, expr string) (bool, error) {
+	if expr == "" {
+		return false, text.ErrMissingRequiredArgument
+	}
+	if v, err := env.ParseBool(expr, `\`+name+` expression`); err == nil {
+		return v == "on", nil
+	}
+	if v, err := env.Eval(expr); err == nil && (v == "true" || v == "false") {
+		return v == "true", nil
+	}
+	return false, fmt.Errorf(text.FormatFieldInvalidValue, expr, `\`+name+` expression`, "Boolean")
+}
+
+// readMenuLine reads a trimmed line for \menu, returning false when the read
+// was interrupted or the input ended.
+func readMenuLine(l rline.IO, prompt string) (string, bool, error) {
+	l.Prompt(prompt)
+	r, err := l.Next()
+	switch {
+	case err == rline.ErrInterrupt || err == io.EOF:
+		return "", false, nil
+	case err != nil:
+		return "", false, err
+	}
+	return strings.TrimSpace(string(r)), true, nil
+}
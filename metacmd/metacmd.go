@@ -3,6 +3,7 @@
 package metacmd
 
 import (
+	"github.com/rmasci/usql/env"
 	"github.com/rmasci/usql/stmt"
 	"github.com/rmasci/usql/text"
 )
@@ -14,6 +15,9 @@ type Metacmd uint
 func Decode(name string, params *stmt.Params) (Runner, error) {
 	mc, ok := cmdMap[name]
 	if !ok || name == "" {
+		if tmpl, ok := env.Command(name); ok {
+			return userCmd(name, tmpl, params), nil
+		}
 		return nil, text.ErrUnknownCommand
 	}
 	cmd := cmds[mc]
@@ -28,6 +32,39 @@ func Decode(name string, params *stmt.Params) (Runner, error) {
 	}), nil
 }
 
+// userCmd creates a Runner for a user defined command, running the command's
+// query template with the positional arguments expanded.
+func userCmd(name, tmpl string, params *stmt.Params) Runner {
+	return RunnerFunc(func(h Handler) (Option, error) {
+		p := &Params{
+			Handler: h,
+			Name:    name,
+			Params:  params,
+		}
+		args, err := p.GetAll(true)
+		if err != nil {
+			return Option{}, err
+		}
+		s, err := env.ExpandCommand(name, tmpl, args)
+		if err != nil {
+			return Option{}, err
+		}
+		return Option{}, h.RunString(s)
+	})
+}
+
+// IsConditional determines if the command name (or alias) is a conditional
+// block command, which must be processed even when input is being skipped.
+func IsConditional(name string) bool {
+	return cmdMap[name] == Conditional
+}
+
+// IsLoop determines if the command name (or alias) is a loop or batch command,
+// which must be processed while the loop or batch body is being recorded.
+func IsLoop(name string) bool {
+	return cmdMap[name] == Loop || cmdMap[name] == Batch
+}
+
 // Command types.
 const (
 	// None is an empty command.
@@ -44,10 +81,15 @@ const (
 	Copy
 	// Disconnect is the disconnect meta command (\Z).
 	Disconnect
+	// SetConnection is the saved connection profile meta command (\cset,
+	// \clist).
+	SetConnection
 	// Password is the change password meta command (\password).
 	Password
 	// ConnectionInfo is the connection info meta command (\conninfo).
 	ConnectionInfo
+	// Encoding is the client encoding meta command (\encoding).
+	Encoding
 	// Drivers is the driver info meta command (\drivers).
 	Drivers
 	// Describe is the describe meta command (\d and variants).
@@ -74,18 +116,38 @@ const (
 	Out
 	// Include is the system include file meta command (\i and variants).
 	Include
+	// Conditional is the conditional block meta command (\if, \elif, \else, \endif).
+	Conditional
+	// Loop is the loop meta command (\loop, \endloop).
+	Loop
 	// Transact is the transaction meta command (\begin, \commit, \rollback).
 	Transact
 	// Prompt is the variable prompt meta command (\prompt).
 	Prompt
 	// SetVar is the set variable meta command (\set).
 	SetVar
+	// Eval is the evaluate expression meta command (\eval).
+	Eval
 	// Unset is the variable unset meta command (\unset).
 	Unset
+	// NewCmd is the user defined command meta command (\newcmd, \delcmd).
+	NewCmd
 	// SetFormatVar is the set format variable meta commands (\pset, \a, \C, \f, \H, \t, \T, \x).
 	SetFormatVar
 	// Timing is the timing meta command (\timing).
 	Timing
 	// Stats is the show stats meta command (\ss and variants).
 	Stats
+	// ShowFunction is the show function definition meta command (\sf).
+	ShowFunction
+	// ErrVerbose is the show last error meta command (\errverbose).
+	ErrVerbose
+	// Waitfor is the wait for condition meta command (\waitfor).
+	Waitfor
+	// Batch is the batch meta command (\batch, \endbatch).
+	Batch
+	// Refresh is the refresh metadata cache meta command (\refresh).
+	Refresh
+	// Menu is the saved query menu meta command (\menu).
+	Menu
 )
@@ -24,6 +24,7 @@ const (
 	SectionHelp            Section = "Help"
 	SectionTransaction     Section = "Transaction"
 	SectionInputOutput     Section = "Input/Output"
+	SectionConditional     Section = "Conditional"
 	SectionInformational   Section = "Informational"
 	SectionFormatting      Section = "Formatting"
 	SectionConnection      Section = "Connection"
@@ -40,7 +41,7 @@ func (s Section) String() string {
 // SectionOrder is the order of sections to display via Listing.
 var SectionOrder = []Section{
 	SectionGeneral, SectionQueryExecute, SectionQueryBuffer, SectionHelp,
-	SectionInputOutput, SectionInformational, SectionFormatting,
+	SectionInputOutput, SectionConditional, SectionInformational, SectionFormatting,
 	SectionTransaction,
 	SectionConnection, SectionOperatingSystem, SectionVariables,
 }
@@ -71,6 +72,10 @@ func Listing(w io.Writer) {
 				s, opts := optText(cmd.Aliases[alias])
 				descs, plen = add(descs, `  \`+strings.TrimSpace(alias)+opts, s, plen)
 			}
+			for _, usage := range cmd.Usages {
+				s, opts := optText(usage)
+				descs, plen = add(descs, `  \`+cmd.Name+opts, s, plen)
+			}
 		}
 		sectionDescs[section] = descs
 	}
@@ -31,20 +31,33 @@ type Handler interface {
 	Last() string
 	// LastRaw returns the last raw (non-interpolated) query.
 	LastRaw() string
+	// LastError returns the error of the last failed query.
+	LastError() error
 	// Buf returns the current query buffer.
 	Buf() *stmt.Stmt
+	// Cond returns the conditional block state.
+	Cond() *stmt.Cond
+	// Loop starts recording a loop body, to be executed count times.
+	Loop(count int) error
+	// Batch starts recording a batch body, to be executed statement by
+	// statement.
+	Batch(stopOnError bool) error
 	// Reset resets the last and current query buffer.
 	Reset([]rune)
 	// Open opens a database connection.
 	Open(context.Context, ...string) error
 	// Close closes the current database connection.
 	Close() error
+	// NamedConn returns the named connection opened with \c @NAME.
+	NamedConn(string) (*sql.DB, *dburl.URL, error)
 	// ChangePassword changes the password for a user.
 	ChangePassword(string) (string, error)
 	// ReadVar reads a variable of a specified type.
 	ReadVar(string, string) (string, error)
 	// Include includes a file.
 	Include(string, bool) error
+	// RunString runs the queries and commands in a string.
+	RunString(string) error
 	// Begin begins a transaction.
 	Begin(*sql.TxOptions) error
 	// Commit commits the current transaction.
@@ -57,12 +70,20 @@ type Handler interface {
 	GetTiming() bool
 	// SetTiming mode.
 	SetTiming(bool)
+	// GetTimingVerbose mode.
+	GetTimingVerbose() bool
+	// SetTimingVerbose mode.
+	SetTimingVerbose(bool)
 	// GetOutput writer.
 	GetOutput() io.Writer
 	// SetOutput writer.
 	SetOutput(io.WriteCloser)
 	// MetadataWriter retrieves the metadata writer for the handler.
 	MetadataWriter(context.Context) (metadata.Writer, error)
+	// MetadataReader retrieves the metadata reader for the handler.
+	MetadataReader(context.Context) (metadata.Reader, error)
+	// RefreshMetadata clears the handler's cached metadata.
+	RefreshMetadata()
 	// Print formats according to a format specifier and writes to handler's standard output.
 	Print(string, ...interface{})
 }
@@ -99,6 +120,31 @@ const (
 	ExecCrosstab
 	// ExecWatch indicates repeated execution with a fixed time interval.
 	ExecWatch
+	// ExecJSON indicates execution and writing results as newline-delimited
+	// JSON (\gjson).
+	ExecJSON
+	// ExecExport indicates execution and writing results to multiple files
+	// (\export).
+	ExecExport
+	// ExecStore indicates execution and writing the raw bytes of a single
+	// value to a file (\gstore).
+	ExecStore
+	// ExecDesc indicates describing the columns of the result without
+	// fetching any rows (\gdesc).
+	ExecDesc
+	// ExecGraph indicates execution and displaying a column of the results
+	// as a bar chart (\graph).
+	ExecGraph
+	// ExecProfile indicates execution with the database's EXPLAIN ANALYZE,
+	// and displaying the resulting plan as a tree (\profile).
+	ExecProfile
+	// ExecDiff indicates execution on two connections (or the current
+	// connection and the snapshot), and displaying the differing rows
+	// (\diff).
+	ExecDiff
+	// ExecSnapshot indicates execution and saving the results as the
+	// snapshot compared by \diff (\snapshot).
+	ExecSnapshot
 )
 
 // Option contains parsed result options of a metacmd.
@@ -113,6 +159,32 @@ type Option struct {
 	Crosstab []string
 	// Watch is the watch duration interval.
 	Watch time.Duration
+	// WatchCount is the maximum number of watch iterations (0 is unlimited).
+	WatchCount int
+	// WatchUntilChange stops watching the first time the result changes.
+	WatchUntilChange bool
+	// WatchReconnects is the maximum number of consecutive reconnection
+	// attempts after the connection is lost while watching.
+	WatchReconnects int
+	// Export are the export file names, keyed by format.
+	Export map[string]string
+	// Args are the query arguments for variables bound as query parameters
+	// (see bind_params).
+	Args []interface{}
+	// DryRun writes the statements that \gexec would execute, instead of
+	// executing them.
+	DryRun bool
+	// Limit is the maximum number of rows of the query (see \g LIMIT and
+	// g_limit).
+	Limit int
+	// Clipboard copies the results to the clipboard (\clip).
+	Clipboard bool
+	// DiffConns are the names of the connections whose results are compared
+	// (\diff), where an empty name is the current connection. The current
+	// connection's results are compared to the snapshot when empty.
+	DiffConns []string
+	// DiffKeys are the key columns rows are matched by (\diff).
+	DiffKeys []string
 }
 
 func (opt *Option) ParseParams(params []string, defaultKey string) error {
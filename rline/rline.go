@@ -7,6 +7,7 @@ import (
 	"os"
 
 	"github.com/gohxs/readline"
+	"github.com/mattn/go-isatty"
 )
 
 var (
@@ -40,6 +41,9 @@ type IO interface {
 	Password(string) (string, error)
 	// SetOutput sets the output filter func.
 	SetOutput(func(string) string)
+	// Width returns the width of the terminal in columns, or 0 when the
+	// standard out is not a terminal.
+	Width() int
 }
 
 // Rline provides a type compatible with the IO interface.
@@ -55,6 +59,7 @@ type Rline struct {
 	A    func(readline.AutoCompleter)
 	S    func(string) error
 	Pw   func(string) (string, error)
+	W    func() int
 }
 
 // Next returns the next line of runes (excluding '\n') from the input.
@@ -128,6 +133,15 @@ func (l *Rline) SetOutput(f func(string) string) {
 	l.Inst.Config.Output = f
 }
 
+// Width returns the width of the terminal in columns, or 0 when the standard
+// out is not a terminal.
+func (l *Rline) Width() int {
+	if l.W != nil {
+		return l.W()
+	}
+	return 0
+}
+
 // New creates a new readline input/output handler.
 func New(interactive, cygwin, forceNonInteractive bool, out, histfile string) (IO, error) {
 	var closers []func() error
@@ -221,5 +235,15 @@ func New(interactive, cygwin, forceNonInteractive bool, out, histfile string) (I
 		},
 		S:  l.SaveHistory,
 		Pw: pw,
+		W: func() int {
+			fd := os.Stdout.Fd()
+			if out != "" || !isatty.IsTerminal(fd) && !isatty.IsCygwinTerminal(fd) {
+				return 0
+			}
+			if w := readline.GetScreenWidth(); w > 0 {
+				return w
+			}
+			return 0
+		},
 	}, nil
 }
@@ -0,0 +1,124 @@
+package stmt
+
+import (
+	"github.com/rmasci/usql/text"
+)
+
+// CondState is the state of a conditional (\if) block.
+type CondState int
+
+// Conditional block states.
+const (
+	// CondNone is outside of any conditional block.
+	CondNone CondState = iota
+	// CondTrue is an \if or \elif branch being executed.
+	CondTrue
+	// CondFalse is an \if or \elif branch being skipped, when no prior branch
+	// in the block has been executed.
+	CondFalse
+	// CondIgnored is a branch being skipped, when either the enclosing block is
+	// being skipped or a prior branch in the block has been executed.
+	CondIgnored
+	// CondElseTrue is an \else branch being executed.
+	CondElseTrue
+	// CondElseFalse is an \else branch being skipped.
+	CondElseFalse
+)
+
+// Cond is a stack of nested conditional blocks.
+type Cond struct {
+	stack []CondState
+}
+
+// State returns the state of the innermost conditional block.
+func (c *Cond) State() CondState {
+	if len(c.stack) == 0 {
+		return CondNone
+	}
+	return c.stack[len(c.stack)-1]
+}
+
+// Depth returns the number of open conditional blocks.
+func (c *Cond) Depth() int {
+	return len(c.stack)
+}
+
+// Active determines if input is being executed (ie, not skipped).
+func (c *Cond) Active() bool {
+	switch c.State() {
+	case CondNone, CondTrue, CondElseTrue:
+		return true
+	}
+	return false
+}
+
+// If opens a conditional block. The condition is only evaluated when the
+// enclosing block is active.
+//
+// When the condition cannot be evaluated, the block is opened as false and the
+// error is returned.
+func (c *Cond) If(f func() (bool, error)) error {
+	if !c.Active() {
+		c.stack = append(c.stack, CondIgnored)
+		return nil
+	}
+	c.stack = append(c.stack, CondFalse)
+	return c.eval(f)
+}
+
+// Elif switches to an alternative branch of the innermost conditional block.
+// The condition is only evaluated when no prior branch has been executed.
+func (c *Cond) Elif(f func() (bool, error)) error {
+	switch c.State() {
+	case CondNone:
+		return text.ErrElifWithoutIf
+	case CondElseTrue, CondElseFalse:
+		return text.ErrElifAfterElse
+	case CondTrue:
+		c.set(CondIgnored)
+	case CondFalse:
+		return c.eval(f)
+	}
+	return nil
+}
+
+// Else switches to the final branch of the innermost conditional block.
+func (c *Cond) Else() error {
+	switch c.State() {
+	case CondNone:
+		return text.ErrElseWithoutIf
+	case CondElseTrue, CondElseFalse:
+		return text.ErrElseAfterElse
+	case CondFalse:
+		c.set(CondElseTrue)
+	default:
+		c.set(CondElseFalse)
+	}
+	return nil
+}
+
+// Endif closes the innermost conditional block.
+func (c *Cond) Endif() error {
+	if len(c.stack) == 0 {
+		return text.ErrEndifWithoutIf
+	}
+	c.stack = c.stack[:len(c.stack)-1]
+	return nil
+}
+
+// eval evaluates f, setting the innermost block to true when f is true.
+func (c *Cond) eval(f func() (bool, error)) error {
+	b, err := f()
+	if err != nil {
+		return err
+	}
+	if b {
+		c.set(CondTrue)
+	}
+	return nil
+}
+
+// set sets the state of the innermost block.
+func (c *Cond) set(state CondState) {
+	c.stack[len(c.stack)-1] = state
+}
@@ -0,0 +1,66 @@
+package stmt
+
+import (
+	"testing"
+
+	"github.com/rmasci/usql/text"
+)
+
+func TestCond(t *testing.T) {
+	tests := []struct {
+		cmds   string
+		active []bool
+		err    error
+	}{
+		{"t", []bool{true}, nil},
+		{"f", []bool{false}, nil},
+		{"f e", []bool{false, true}, nil},
+		{"t e", []bool{true, false}, nil},
+		{"f T F e", []bool{false, true, false, false}, nil},
+		{"f F T e", []bool{false, false, true, false}, nil},
+		{"f t x", []bool{false, false, false}, nil},
+		{"t f x x", []bool{true, false, true, true}, nil},
+		{"f t T e x", []bool{false, false, false, false, false}, nil},
+		{"x", nil, text.ErrEndifWithoutIf},
+		{"e", nil, text.ErrElseWithoutIf},
+		{"T", nil, text.ErrElifWithoutIf},
+		{"t e e", []bool{true, false}, text.ErrElseAfterElse},
+		{"t e T", []bool{true, false}, text.ErrElifAfterElse},
+	}
+	for i, test := range tests {
+		var c Cond
+		var active []bool
+		var err error
+		for _, r := range test.cmds {
+			f := func() (bool, error) { return r == 't' || r == 'T', nil }
+			switch r {
+			case 't', 'f':
+				err = c.If(f)
+			case 'T', 'F':
+				err = c.Elif(f)
+			case 'e':
+				err = c.Else()
+			case 'x':
+				err = c.Endif()
+			default:
+				continue
+			}
+			if err != nil {
+				break
+			}
+			active = append(active, c.Active())
+		}
+		if err != test.err {
+			t.Fatalf("test %d expected error %v, got: %v", i, test.err, err)
+		}
+		if len(active) != len(test.active) {
+			t.Fatalf("test %d expected %v, got: %v", i, test.active, active)
+		}
+		for j := range active {
+			if active[j] != test.active[j] {
+				t.Errorf("test %d expected %v, got: %v", i, test.active, active)
+				break
+			}
+		}
+	}
+}
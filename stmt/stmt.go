@@ -58,12 +58,19 @@ type Stmt struct {
 	Len int
 	// Prefix is the detected prefix of the statement.
 	Prefix string
+	// Line is the line number of the rune source where the statement in Buf
+	// starts.
+	Line int
 	// Vars is the list of encountered variables.
 	Vars []*Var
 	// r is the unprocessed runes.
 	r []rune
 	// rlen is the number of unprocessed runes.
 	rlen int
+	// line is the line number of the last runes read from the rune source,
+	// and nl the number of line ends in them, as the rune source may
+	// return a line together with the empty lines following it.
+	line, nl int
 	// quote indicates currently parsing a quoted string.
 	quote rune
 	// quoteDollarTag is the parsed tag of a dollar quoted string
@@ -136,7 +143,7 @@ func (b *Stmt) Ready() bool {
 // Reset resets the statement buffer.
 func (b *Stmt) Reset(r []rune) {
 	// reset buf
-	b.Buf, b.Len, b.Prefix, b.Vars = nil, 0, "", nil
+	b.Buf, b.Len, b.Prefix, b.Line, b.Vars = nil, 0, "", 0, nil
 	// quote state
 	b.quote, b.quoteDollarTag = 0, ""
 	// multicomment state
@@ -150,6 +157,41 @@ func (b *Stmt) Reset(r []rune) {
 	}
 }
 
+// Save returns a copy of the collected statement and its parsing state, for
+// use with Restore.
+func (b *Stmt) Save() *Stmt {
+	s := *b
+	s.Buf, s.Vars = append([]rune(nil), b.Buf...), append([]*Var(nil), b.Vars...)
+	s.r, s.rlen = nil, 0
+	return &s
+}
+
+// Restore restores the collected statement and its parsing state previously
+// returned by Save, retaining any unprocessed runes and the number of lines
+// read.
+func (b *Stmt) Restore(s *Stmt) {
+	r, rlen, line, nl := b.r, b.rlen, b.line, b.nl
+	*b = *s
+	b.Buf, b.Vars = append([]rune(nil), s.Buf...), append([]*Var(nil), s.Vars...)
+	b.r, b.rlen, b.line, b.nl = r, rlen, line, nl
+}
+
+// Lines returns the line number of the last line read from the rune source.
+func (b *Stmt) Lines() int {
+	return b.line
+}
+
+// countLineEnds returns the number of line ends in r.
+func countLineEnds(r []rune) int {
+	var n int
+	for _, c := range r {
+		if c == '\n' {
+			n++
+		}
+	}
+	return n
+}
+
 // lineend is the slice to use when appending a line.
 var lineend = []rune{'\n'}
 
@@ -196,7 +238,7 @@ func (b *Stmt) Next(unquote func(string, bool) (bool, string, error)) (string, s
 		if err != nil {
 			return "", "", err
 		}
-		b.rlen = len(b.r)
+		b.rlen, b.line, b.nl = len(b.r), b.line+1+b.nl, countLineEnds(b.r)
 	}
 	var cmd, params string
 	var ok bool
@@ -315,6 +357,7 @@ parse:
 		st := 0
 		if b.Len == 0 {
 			st, _ = findNonSpace(b.r, 0, i)
+			b.Line = b.line
 		}
 		// log.Printf(">> appending: `%s`", string(r[st:i]))
 		b.Append(b.r[st:i], lineend)
@@ -382,6 +425,59 @@ func (b *Stmt) State() string {
 	return "="
 }
 
+// BindVars replaces the variables (ie, :name or @name) in s that are defined
+// according to defined with the query parameter placeholders generated by pf,
+// returning the modified statement and the names of the replaced variables in
+// order. Variables contained in quoted strings and comments are not replaced.
+func (b *Stmt) BindVars(s string, defined func(string) bool, pf func(int) string) (string, []string) {
+	r := []rune(s)
+	end := len(r)
+	var z []rune
+	var names []string
+	var last int
+	for i := 0; i < end; i++ {
+		c, next := r[i], grab(r, i+1, end)
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			i, _ = readString(r, i+1, end, c, "")
+		case b.allowDollar && c == '$':
+			if id, pos, ok := readDollarAndTag(r, i, end); ok {
+				i, _ = readString(r, pos+1, end, '$', id)
+			}
+		case c == '-' && next == '-',
+			b.allowCComments && c == '/' && next == '/',
+			b.allowHashComments && c == '#':
+			i, _ = findRune(r, i, end, '\n')
+		case b.allowMultilineComments && c == '/' && next == '*':
+			i, _ = readMultilineComment(r, i+2, end)
+		case c == ':' && next == ':', c == '@' && next == '@':
+			// skip casts (ie, ::) and system variables (ie, @@name)
+			for i++; i+1 < end && isVarRune(r[i+1]); i++ {
+			}
+		case (c == ':' || c == '@') && (i == 0 || !isVarRune(r[i-1])):
+			j := i + 1
+			for ; j < end && isVarRune(r[j]); j++ {
+			}
+			name := string(r[i+1 : j])
+			if name == "" || !defined(name) {
+				continue
+			}
+			names = append(names, name)
+			z = append(append(z, r[last:i]...), []rune(pf(len(names)))...)
+			last, i = j, j-1
+		}
+	}
+	if names == nil {
+		return s, nil
+	}
+	return string(append(z, r[last:]...)), names
+}
+
+// isVarRune returns true when c is a valid variable name character.
+func isVarRune(c rune) bool {
+	return c == '_' || unicode.IsLetter(c) || unicode.IsNumber(c)
+}
+
 // Option is a statement buffer option.
 type Option func(*Stmt)
 
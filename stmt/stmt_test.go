@@ -1,6 +1,7 @@
 package stmt
 
 import (
+	"fmt"
 	"io"
 	"os/user"
 	"reflect"
@@ -276,6 +277,58 @@ func TestEmptyVariablesRawString(t *testing.T) {
 	}
 }
 
+func TestLine(t *testing.T) {
+	// empty lines are returned with the preceding line, as by the handler
+	b := New(sp("select 1;\n|select|  2; select 3;|\\p|select 4", "|"))
+	var lines []int
+	for {
+		cmd, _, err := b.Next(func(string, bool) (bool, string, error) { return false, "", nil })
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		switch {
+		case b.Ready():
+			lines = append(lines, b.Line)
+			b.Reset(nil)
+		case cmd != "":
+			lines = append(lines, -b.Lines())
+		}
+	}
+	if exp := []int{1, 3, 4, -5}; !reflect.DeepEqual(lines, exp) {
+		t.Errorf("expected lines %v, got: %v", exp, lines)
+	}
+}
+
+func TestBindVars(t *testing.T) {
+	tests := []struct {
+		s     string
+		exp   string
+		names []string
+	}{
+		{`select 1`, `select 1`, nil},
+		{`select :a, :b`, `select $1, :b`, []string{"a"}},
+		{`select * from t where a = :a and c = @c`, `select * from t where a = $1 and c = $2`, []string{"a", "c"}},
+		{`select ':a', ":a", $$:a$$ -- :a`, `select ':a', ":a", $$:a$$ -- :a`, nil},
+		{`select /* :a */ :a::int, @@a, x:a`, `select /* :a */ $1::int, @@a, x:a`, []string{"a"}},
+		{`select :a, :a`, `select $1, $2`, []string{"a", "a"}},
+	}
+	b := New(nil, WithAllowDollar(true), WithAllowMultilineComments(true))
+	defined := func(name string) bool { return name == "a" || name == "c" }
+	pf := func(n int) string { return fmt.Sprintf("$%d", n) }
+	for i, test := range tests {
+		s, names := b.BindVars(test.s, defined, pf)
+		if s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+		if !reflect.DeepEqual(names, test.names) {
+			t.Errorf("test %d expected names %v, got: %v", i, test.names, names)
+		}
+	}
+}
+
 // cc combines commands with params.
 func cc(cmds []string, params []string) []string {
 	if len(cmds) == 0 {
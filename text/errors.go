@@ -7,10 +7,17 @@ import (
 var (
 	// ErrNotConnected is the not connected error.
 	ErrNotConnected = errors.New("not connected")
+	// ErrGexecNotConfirmed is the destructive \gexec statement not confirmed
+	// error.
+	ErrGexecNotConfirmed = errors.New(`\gexec: destructive statement requires confirmation (set \pset gexec_confirm off to skip)`)
+	// ErrQueryCanceled is the query canceled error.
+	ErrQueryCanceled = errors.New("canceling statement due to user request")
 	// ErrNoSuchFileOrDirectory is the no such file or directory error.
 	ErrNoSuchFileOrDirectory = errors.New("no such file or directory")
 	// ErrCannotIncludeDirectories is the cannot include directories error.
 	ErrCannotIncludeDirectories = errors.New("cannot include directories")
+	// ErrURLTooLarge is the url too large error.
+	ErrURLTooLarge = errors.New("url content exceeds maximum size of 16 MiB")
 	// ErrMissingDSN is the missing dsn error.
 	ErrMissingDSN = errors.New("missing dsn")
 	// ErrNoPreviousTransactionExists is the no previous transaction exists error.
@@ -35,6 +42,10 @@ var (
 	ErrUnterminatedQuotedString = errors.New("unterminated quoted string")
 	// ErrNoShellAvailable is the no SHELL available error.
 	ErrNoShellAvailable = errors.New("no SHELL available")
+	// ErrNoClipboardAvailable is the no clipboard available error.
+	ErrNoClipboardAvailable = errors.New("no clipboard available: requires pbcopy (macOS), clip.exe (Windows or WSL), or wl-copy, xclip, or xsel with a display")
+	// ErrNoUserCommands is the no user defined commands error.
+	ErrNoUserCommands = errors.New(`no user defined commands (define one with \newcmd)`)
 	// ErrNotInteractive is the not interactive error.
 	ErrNotInteractive = errors.New("not interactive")
 	// ErrInvalidType is the invalid type error.
@@ -45,8 +56,12 @@ var (
 	ErrInvalidValue = errors.New("invalid value")
 	// ErrTooManyRows is the too many rows error.
 	ErrTooManyRows = errors.New("too many rows")
+	// ErrNoRows is the no rows error.
+	ErrNoRows = errors.New("no rows")
+	// ErrTooManyColumns is the too many columns error.
+	ErrTooManyColumns = errors.New("too many columns")
 	// ErrInvalidFormatType is the invalid format type error.
-	ErrInvalidFormatType = errors.New(`\pset: allowed formats are unaligned, aligned, wrapped, html, asciidoc, latex, latex-longtable, troff-ms, json, csv`)
+	ErrInvalidFormatType = errors.New(`\pset: allowed formats are unaligned, aligned, wrapped, html, asciidoc, latex, latex-longtable, troff-ms, json, csv, fwf, yaml`)
 	// ErrInvalidFormatPagerType is the invalid format pager error.
 	ErrInvalidFormatPagerType = errors.New(`\pset: allowed pager values are on, off, always`)
 	// ErrInvalidFormatExpandedType is the invalid format expanded error.
@@ -55,12 +70,41 @@ var (
 	ErrInvalidFormatLineStyle = errors.New(`\pset: allowed line styles are ascii, old-ascii, unicode`)
 	// ErrInvalidFormatBorderLineStyle is the invalid format border line style error.
 	ErrInvalidFormatBorderLineStyle = errors.New(`\pset: allowed Unicode border line styles are single, double`)
+	// ErrInvalidFormatCSVQuote is the invalid format CSV quote error.
+	ErrInvalidFormatCSVQuote = errors.New(`\pset: csv_quote must be a single character or empty`)
+	// ErrInvalidFormatFWFWidths is the invalid format fixed-width widths error.
+	ErrInvalidFormatFWFWidths = errors.New(`\pset: fwf_widths must be a comma separated list of column widths`)
+	// ErrInvalidFormatFWFAlign is the invalid format fixed-width alignments
+	// error.
+	ErrInvalidFormatFWFAlign = errors.New(`\pset: fwf_align must be a comma separated list of column alignments (l or r)`)
+	// ErrInvalidFormatTimingFormat is the invalid format timing format error.
+	ErrInvalidFormatTimingFormat = errors.New(`\pset: allowed timing formats are text, json`)
+	// ErrInvalidConnectRetryInterval is the invalid connect retry interval error.
+	ErrInvalidConnectRetryInterval = errors.New(`\pset: connect_retry_interval must be a valid duration`)
+	// ErrInvalidMetadataCacheTTL is the invalid metadata cache ttl error.
+	ErrInvalidMetadataCacheTTL = errors.New(`\pset: metadata_cache_ttl must be a valid duration`)
+	// ErrInvalidFormatParquetCompression is the invalid format parquet compression error.
+	ErrInvalidFormatParquetCompression = errors.New(`\pset: allowed Parquet compression codecs are snappy, zstd, gzip, none`)
+	// ErrInvalidEcho is the invalid echo error.
+	ErrInvalidEcho = errors.New(`\set: allowed ECHO values are none, queries, all, errors`)
 	// ErrInvalidQuotedString is the invalid quoted string error.
 	ErrInvalidQuotedString = errors.New(`invalid quoted string`)
+	// ErrUnterminatedEnvironmentVariable is the unterminated environment variable error.
+	ErrUnterminatedEnvironmentVariable = errors.New("unterminated ${ environment variable reference")
+	// ErrCrosstabDuplicateSortValue is the crosstab duplicate sort value error.
+	ErrCrosstabDuplicateSortValue = errors.New("crosstab horizontal sort column has the same value for more than one horizontal header value")
+	// ErrCrosstabAmbiguousSortValue is the crosstab ambiguous sort value error.
+	ErrCrosstabAmbiguousSortValue = errors.New("crosstab horizontal header value has more than one horizontal sort column value")
+	// ErrInvalidExportFormat is the invalid export format error.
+	ErrInvalidExportFormat = errors.New(`\export: allowed formats are unaligned, aligned, wrapped, html, asciidoc, latex, latex-longtable, troff-ms, json, csv, vertical, ndjson, fwf, yaml`)
 	// ErrInvalidFormatOption is the invalid format option error.
 	ErrInvalidFormatOption = errors.New("invalid format option")
 	// ErrInvalidWatchDuration is the invalid watch duration error.
 	ErrInvalidWatchDuration = errors.New("invalid watch duration")
+	// ErrInvalidWatchCount is the invalid watch count error.
+	ErrInvalidWatchCount = errors.New("invalid watch count")
+	// ErrInvalidWatchReconnects is the invalid watch reconnects error.
+	ErrInvalidWatchReconnects = errors.New("invalid watch reconnect count")
 	// ErrUnableToNormalizeURL is the unable to normalize URL error.
 	ErrUnableToNormalizeURL = errors.New("unable to normalize URL")
 	// ErrInvalidIsolationLevel is the invalid isolation level error.
@@ -71,4 +115,64 @@ var (
 	ErrWrongNumberOfArguments = errors.New("wrong number of arguments")
 	// ErrUnknownFileType is the unknown file type error.
 	ErrUnknownFileType = errors.New("unknown file type")
+	// ErrUnterminatedParenthesis is the unterminated parenthesis error.
+	ErrUnterminatedParenthesis = errors.New("unterminated parenthesis")
+	// ErrCopyFromQuery is the copy from query error.
+	ErrCopyFromQuery = errors.New("cannot copy from a file to a query")
+	// ErrCopyFromConnection is the copy from connection error.
+	ErrCopyFromConnection = errors.New("cannot copy from a table on another connection (copy to the table instead)")
+	// ErrCopyBatchInTransaction is the copy batch in transaction error.
+	ErrCopyBatchInTransaction = errors.New(`\copy: batch_size cannot be used in a transaction`)
+	// ErrCopyBatchOnError is the copy batch with on_error continue error.
+	ErrCopyBatchOnError = errors.New(`\copy: batch_size and rows_per_insert cannot be used with on_error continue`)
+	// ErrInvalidConnectionName is the invalid connection name error.
+	ErrInvalidConnectionName = errors.New("invalid connection name (must be @NAME)")
+	// ErrInvalidExpression is the invalid expression error.
+	ErrInvalidExpression = errors.New("invalid expression")
+	// ErrDivisionByZero is the division by zero error.
+	ErrDivisionByZero = errors.New("division by zero")
+	// ErrElifWithoutIf is the elif without if error.
+	ErrElifWithoutIf = errors.New(`\elif: no matching \if`)
+	// ErrElifAfterElse is the elif after else error.
+	ErrElifAfterElse = errors.New(`\elif: cannot occur after \else`)
+	// ErrElseWithoutIf is the else without if error.
+	ErrElseWithoutIf = errors.New(`\else: no matching \if`)
+	// ErrElseAfterElse is the else after else error.
+	ErrElseAfterElse = errors.New(`\else: cannot occur after \else`)
+	// ErrEndifWithoutIf is the endif without if error.
+	ErrEndifWithoutIf = errors.New(`\endif: no matching \if`)
+	// ErrEndloopWithoutLoop is the endloop without loop error.
+	ErrEndloopWithoutLoop = errors.New(`\endloop: no matching \loop`)
+	// ErrEndbatchWithoutBatch is the endbatch without batch error.
+	ErrEndbatchWithoutBatch = errors.New(`\endbatch: no matching \batch`)
+	// ErrUnterminatedIf is the unterminated if error.
+	ErrUnterminatedIf = errors.New(`reached end of input without finding closing \endif`)
+	// ErrUnterminatedLoop is the unterminated loop error.
+	ErrUnterminatedLoop = errors.New(`reached end of input without finding closing \endloop`)
+	// ErrUnterminatedBatch is the unterminated batch error.
+	ErrUnterminatedBatch = errors.New(`reached end of input without finding closing \endbatch`)
+	// ErrInvalidLoopCount is the invalid loop count error.
+	ErrInvalidLoopCount = errors.New("invalid loop count")
+	// ErrInvalidPrivateKey is the invalid private key error.
+	ErrInvalidPrivateKey = errors.New("private key file must contain an unencrypted PEM encoded RSA private key")
+	// ErrExitOnRows is the exit on rows error.
+	ErrExitOnRows = errors.New("last statement returned rows")
+	// ErrExitOnEmpty is the exit on empty error.
+	ErrExitOnEmpty = errors.New("last statement returned no rows")
+	// ErrInterrupted is the interrupted again error.
+	ErrInterrupted = errors.New("interrupted again, exiting")
+	// ErrInvalidRootCertificate is the invalid root certificate error.
+	ErrInvalidRootCertificate = errors.New("root certificate file must contain at least one PEM encoded certificate")
+	// ErrMissingClientCertificate is the missing client certificate error.
+	ErrMissingClientCertificate = errors.New("a client certificate and key must be specified together")
+	// ErrNoSnapshot is the no snapshot error.
+	ErrNoSnapshot = errors.New(`\diff: no snapshot (save one with \snapshot)`)
+	// ErrGraphNoNumericColumn is the graph no numeric column error.
+	ErrGraphNoNumericColumn = errors.New(`\graph: result has no numeric column`)
+	// ErrCompressedParquet is the compressed parquet file error.
+	ErrCompressedParquet = errors.New("compressed parquet files are not supported (use the compression option to set the parquet codec)")
+	// ErrJSONLImport is the JSON Lines import error.
+	ErrJSONLImport = errors.New("importing JSON Lines files is not supported")
+	// ErrCannotDescribeQuery is the cannot describe query error.
+	ErrCannotDescribeQuery = errors.New(`\gdesc: query cannot be described without executing it`)
 )
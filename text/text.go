@@ -31,6 +31,9 @@ var (
 	RowCount              = `(%d rows)`
 	AvailableDrivers      = `Available Drivers:`
 	ConnInfo              = `Connected with driver %s (%s)`
+	ConnInfoTLS           = `SSL connection (protocol: %s, cipher: %s)`
+	ConnInfoNoTLS         = `SSL: not encrypted`
+	ConnInfoTLSUnknown    = `SSL: unknown`
 	EnterPassword         = `Enter password: `
 	EnterPreviousPassword = `Enter previous password: `
 	PasswordsDoNotMatch   = `Passwords do not match, trying again ...`
@@ -38,6 +41,33 @@ var (
 	ConfirmPassword       = `Confirm password: `
 	PasswordChangeFailed  = `\password for %q failed: %v`
 	CouldNotSetVariable   = `could not set variable %q`
+	NoResultColumns       = `The command has no result, or the result has no columns.`
+	CopyMaxErrors         = `\copy: aborted after %d rejected rows`
+	CopyRejected          = `COPY %d (%d rows rejected)`
+	CopyMapColumnNotFound = `\copy: mapped column %q not found in file header`
+	CopyMapNotInColumns   = `\copy: mapped column %q not in column list`
+	CopyFilterNotFound    = `\copy: filter column %q not found in file header`
+	CopyInvalidFilter     = `\copy: invalid filter: %v`
+	CopyFiltered          = `COPY %d (%d rows filtered)`
+	CopyRejectedFiltered  = `COPY %d (%d rows rejected, %d rows filtered)`
+	CopyUnknownColumn     = `\copy: on_conflict column %q does not exist in table %s`
+	CopyColumnCount       = `\copy: %d columns specified, but the file has %d columns`
+	CopyProgress          = `COPY %d (in progress)`
+	CopyProgressRate      = `COPY %d (in progress, %d rows/s)`
+	CopyProgressETA       = `COPY %d (in progress, %d rows/s, %d%%, ETA %s)`
+	UnknownConnection     = `connection @%s does not exist`
+	GexecConfirm          = `Execute %q? [y/N/a] `
+	GexecSkipped          = `Skipped: %s`
+	EditorExitStatus      = `editor exited with status %d, changes discarded`
+	InvalidProfileName    = `invalid connection profile name %q`
+	UnknownProfile        = `%s is not a saved connection profile`
+	GraphColumnNotFound   = `\graph: column %q not found in result`
+	GraphColumnNotNumeric = `\graph: column %q has non-numeric value %q`
+	DiffKeyNotFound       = `\diff: key column %q not found in result`
+	DiffColumns           = `\diff: results have different columns: %s and %s`
+	DiffSummary           = `(%d added, %d removed, %d changed, %d unchanged)`
+	DiffNone              = `No differences: %d unchanged`
+	SnapshotSaved         = `Saved snapshot (%d rows)`
 	// PasswordChangeSucceeded = `\password succeeded for %q`
 	HelpDesc          string
 	HelpDescShort     = `Use \? for help or press control-C to clear the input buffer.`
@@ -52,27 +82,64 @@ var (
 	}
 	QuitDesc                = `Use \q to quit.`
 	UnknownFormatFieldName  = `unknown option: %s`
+	UnknownEncoding         = `unknown encoding: %s (supported: %s)`
+	InvalidCommandName      = `invalid command name %q`
+	BuiltinCommandName      = `\%s is a built-in command`
+	UnknownUserCommand      = `\%s is not a user defined command`
+	MissingCommandArgument  = `\%s: missing argument %s`
+	MenuPrompt              = `Select a query [1-%d], or q to quit: `
+	MenuInvalidChoice       = `invalid selection %q: enter a number from 1 to %d`
+	MenuArgPrompt           = `%s: `
 	FormatFieldInvalid      = `unrecognized value %q for "%s"`
 	FormatFieldInvalidValue = `unrecognized value %q for "%s": %s expected`
 	FormatFieldNameSetMap   = map[string]string{
+		`bind_params`:              `Query parameter binding is %s.`,
 		`border`:                   `Border style is %d.`,
 		`columns`:                  `Target width is %d.`,
+		`connect_retries`:          `Connection retries is %d.`,
+		`connect_retry_interval`:   `Connection retry interval is %s.`,
+		`csv_fieldsep`:             `Field separator for CSV is %q.`,
+		`csv_header`:               `CSV header is %s.`,
+		`csv_null`:                 `CSV null display is %q.`,
+		`csv_quote`:                `CSV quote is %q.`,
+		`describe_exact_rows`:      `Exact row counting in describe output is %s.`,
+		`describe_size`:            `Table size in describe output is %s.`,
+		`exit_on_empty`:            `Exit on empty result is %s.`,
+		`exit_on_rows`:             `Exit on rows is %s.`,
 		`expanded`:                 `Expanded display is %s.`,
 		`expanded_auto`:            `Expanded display is used automatically.`,
 		`fieldsep`:                 `Field separator is %q.`,
 		`fieldsep_zero`:            `Field separator is zero byte.`,
 		`footer`:                   `Default footer is %s.`,
+		`footer_format`:            `Footer format is %q.`,
 		`format`:                   `Output format is %s.`,
+		`fwf_align`:                `Fixed-width column alignments are %q.`,
+		`fwf_widths`:               `Fixed-width column widths are %q.`,
+		`g_limit`:                  `Row limit argument of \g is %s.`,
+		`gexec_confirm`:            `Confirmation of destructive \gexec statements is %s.`,
+		`html_class`:               `HTML table class is %q.`,
+		`json_indent`:              `JSON indent is %d.`,
+		`json_pretty`:              `JSON pretty-printing is %s.`,
 		`linestyle`:                `Line style is %s.`,
 		`locale`:                   `Locale is %q.`,
+		`max_col_width`:            `Maximum column width is %d.`,
+		`max_col_width_exclude`:    `Columns excluded from truncation are %q.`,
+		`metadata_cache_ttl`:       `Metadata cache duration is %s.`,
 		`null`:                     `Null display is %q.`,
 		`numericlocale`:            `Locale-adjusted numeric output is %s.`,
 		`pager`:                    `Pager usage is %s.`,
 		`pager_min_lines`:          `Pager won't be used for less than %d line(s).`,
+		`parquet_compression`:      `Parquet compression is %s.`,
+		`parquet_row_group_size`:   `Parquet row group size is %d.`,
 		`recordsep`:                `Field separator is %q.`,
 		`recordsep_zero`:           `Record separator is zero byte.`,
+		`single_transaction`:       `Single transaction mode is %s.`,
 		`tableattr`:                `Table attributes are %q.`,
 		`time`:                     `Time display is %s.`,
+		`timing_file`:              `Timing output file is %q.`,
+		`timing_format`:            `Timing format is %s.`,
+		`timing_summary`:           `Timing summary is %s.`,
+		`timing_throughput`:        `Timing throughput display is %s.`,
 		`title`:                    `Title is %q.`,
 		`tuples_only`:              `Tuples only is %s.`,
 		`unicode_border_linestyle`: `Unicode border line style is %q.`,
@@ -80,15 +147,40 @@ var (
 		`unicode_header_linestyle`: `Unicode header line style is %q.`,
 	}
 	FormatFieldNameUnsetMap = map[string]string{
-		`tableattr`: `Table attributes unset.`,
-		`title`:     `Title is unset.`,
+		`footer_format`:         `Footer format unset.`,
+		`fwf_align`:             `Fixed-width column alignments unset.`,
+		`fwf_widths`:            `Fixed-width column widths unset.`,
+		`html_class`:            `HTML table class unset.`,
+		`max_col_width_exclude`: `Columns excluded from truncation unset.`,
+		`tableattr`:             `Table attributes unset.`,
+		`timing_file`:           `Timing output file unset.`,
+		`title`:                 `Title is unset.`,
 	}
 	TimingSet            = `Timing is %s.`
 	TimingDesc           = `Time: %0.3f ms`
+	TimingVerboseDesc    = ` (connection: %0.3f ms, execution: %0.3f ms, fetch: %0.3f ms)`
+	TimingThroughput     = ` (%.0f rows/s)`
 	InvalidValue         = `invalid -%s value %q: %s`
 	NotSupportedByDriver = `%s not supported by %s driver`
+	URLStatusError       = `unexpected HTTP status: %s`
 	RelationNotFound     = `Did not find any relation named "%s".`
+	MetadataRefreshed    = `Metadata cache cleared.`
+	NoPreviousError      = `There is no previous error.`
+	FunctionNotFound     = `function "%s" does not exist`
+	FunctionNotUnique    = `more than one function named "%s"`
+	ConnectRetry         = `connection failed: %v (retrying in %v, attempt %d of %d)`
+	WatchReconnect       = `connection lost: %v (reconnecting, attempt %d of %d)`
+	WaitforTimeout       = `\waitfor: timed out after %v (last value: %s)`
+	BatchSummary         = `Batch: %d statements, %d failed (%0.3f ms)`
+	TimingSummary        = `Timing: %d statements in %0.3f ms (average %0.3f ms)`
+	TimingSlowest        = `Slowest: %0.3f ms (statement %d): %s`
+	EchoQuery            = `QUERY:  %s`
+	EchoFailedQuery      = `STATEMENT:  %s`
 	InvalidOption        = `invalid option %q`
+	InvalidQueryPlan     = `invalid query plan: %v`
+	TransactionIgnored   = `WARNING: %s ignored in single transaction mode`
+	IncludedFrom         = `  included from %s`
+	UndefinedEnvVar      = `environment variable %s is not defined`
 	NotificationReceived = `Asynchronous notification %q %sreceived from server process with PID %d.`
 	NotificationPayload  = `with payload %q `
 	UnknownShortAlias    = `(unk)`